@@ -0,0 +1,119 @@
+package logger_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// Field allowlist
+
+// logAllowlisted logs through a logger with an allowlist of "user_id" and
+// "amount", and returns the entries written
+func logAllowlisted(t *testing.T, policy logger.FieldViolation) []map[string]any {
+	t.Helper()
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithFieldAllowlist([]string{"user_id", "amount"}, policy),
+		logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	log.Info("clean", logger.F.String("user_id", "u-1"), logger.F.Int("amount", 10))
+	log.Info("call site", logger.F.String("user_id", "u-1"), logger.F.String("pan", "4111111111111111"))
+	log.With(logger.F.String("cvv", "123")).Info("bound", logger.F.Int("amount", 10))
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return testutil.ReadJSONLines(t, logPath)
+}
+
+func TestFieldAllowlistDropField(t *testing.T) {
+	violations := logger.GetMetrics().FieldViolations.WithLabelValues("pan")
+	before := promtestutil.ToFloat64(violations)
+	entries := logAllowlisted(t, logger.FieldViolationDropField)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if e := entries[1]; e["pan"] != nil || e["user_id"] != "u-1" {
+		t.Errorf("Expected pan dropped and user_id kept, got %v", e)
+	}
+	if e := entries[2]; e["cvv"] != nil || e["amount"] != float64(10) {
+		t.Errorf("Expected bound cvv dropped and amount kept, got %v", e)
+	}
+	if got := promtestutil.ToFloat64(violations) - before; got != 1 {
+		t.Errorf("Expected 1 violation for pan, got %v", got)
+	}
+}
+
+func TestFieldAllowlistDropEntry(t *testing.T) {
+	entries := logAllowlisted(t, logger.FieldViolationDropEntry)
+	if len(entries) != 1 || entries[0]["msg"] != "clean" {
+		t.Fatalf("Expected only the clean entry, got %v", entries)
+	}
+}
+
+func TestFieldAllowlistRedact(t *testing.T) {
+	entries := logAllowlisted(t, logger.FieldViolationRedact)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if e := entries[1]; e["pan"] != "REDACTED" {
+		t.Errorf("Expected pan redacted, got %v", e["pan"])
+	}
+	if e := entries[2]; e["cvv"] != "REDACTED" {
+		t.Errorf("Expected bound cvv redacted, got %v", e["cvv"])
+	}
+	// Built-in keys need no allowlisting
+	for _, key := range []string{"ts", "level", "msg"} {
+		if entries[0][key] == nil {
+			t.Errorf("Expected built-in key %q, got %v", key, entries[0])
+		}
+	}
+}
+
+func TestFieldAllowlistBoundedLabels(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithFieldAllowlist(nil, logger.FieldViolationDropField),
+		logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+
+	// Keys past the first 100 share hashed labels
+	for i := 0; i < 300; i++ {
+		log.Info(fmt.Sprintf("spray %d", i), logger.F.Int(fmt.Sprintf("spray_%d", i), i))
+	}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(logger.GetMetrics().FieldViolations)
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	if n := len(mfs[0].GetMetric()); n > 100+64 {
+		t.Errorf("Expected at most %d labels, got %d", 100+64, n)
+	}
+	if got := promtestutil.ToFloat64(logger.GetMetrics().FieldViolations.WithLabelValues("spray_299")); got != 0 {
+		t.Errorf("Expected spray_299 under a hashed label, got %v under its own", got)
+	}
+
+	if _, err := logger.NewProduction(logger.WithFieldAllowlist(nil, "mask")); err == nil {
+		t.Error("Expected an invalid policy to fail the build")
+	}
+}
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"github.com/HoangAnhNguyen269/loggerkit/testutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	logger "github.com/HoangAnhNguyen269/loggerkit"
 	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx" // Import to register the builder
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/elasticx"
 )
 
 // A) Core API & Options
@@ -139,6 +141,64 @@ func TestWithOptionsComposition(t *testing.T) {
 	}
 }
 
+func TestSinkErrorPolicy(t *testing.T) {
+	badES := logger.ElasticSink{
+		Addresses:      []string{"http://127.0.0.1:1"},
+		PingOnStartup:  true,
+		StartupTimeout: 200 * time.Millisecond,
+	}
+
+	// Fail-fast is the default
+	if _, err := logger.NewProduction(logger.WithElastic(badES)); err == nil || !strings.Contains(err.Error(), "elasticsearch") {
+		t.Errorf("Expected construction to fail on the elasticsearch sink, got %v", err)
+	}
+
+	tempFile, cleanup := testutil.TempFile(t, "test-log", ".log")
+	defer cleanup()
+	diag := &testutil.SafeBuffer{}
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: tempFile}),
+		logger.WithElastic(badES),
+		logger.WithSinkErrorPolicy(logger.SinkErrorSkip),
+		logger.WithDiagnostics(diag),
+	)
+	if err != nil {
+		t.Fatalf("Expected the logger to start without the elasticsearch sink: %v", err)
+	}
+	log.Info("Still logging to file")
+	log.Close(context.Background())
+
+	report := logger.Report(log.With(logger.F.String("k", "v")))
+	if !report.Degraded() || len(report.Skipped) != 1 || report.Skipped[0].Sink != "elasticsearch" {
+		t.Errorf("Expected the elasticsearch sink to be reported as skipped, got %+v", report)
+	}
+	if strings.Join(report.Sinks, ",") != "file" {
+		t.Errorf("Expected only the file sink in use, got %v", report.Sinks)
+	}
+	if !strings.Contains(diag.String(), "skipping elasticsearch sink") {
+		t.Errorf("Expected a diagnostic for the skipped sink, got %q", diag.String())
+	}
+	if content, _ := os.ReadFile(tempFile); !strings.Contains(string(content), "Still logging to file") {
+		t.Error("Expected the remaining sink to be used")
+	}
+
+	// Skipping every sink still fails
+	_, err = logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithElastic(badES),
+		logger.WithSinkErrorPolicy(logger.SinkErrorSkip),
+		logger.WithDiagnostics(diag),
+	)
+	if err == nil {
+		t.Error("Expected an error when no sink is left")
+	}
+
+	if _, err := logger.NewProduction(logger.WithSinkErrorPolicy("ignore")); err == nil {
+		t.Error("Expected an error for an unknown policy")
+	}
+}
+
 // B) Fields & Helpers
 
 func TestLoggerWithAndFields(t *testing.T) {
@@ -219,6 +279,47 @@ func TestLegacyVsNewFieldHelpers(t *testing.T) {
 	}
 }
 
+func TestDurationAndTimeEncoding(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	at := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	var log logger.Logger
+	output, err := testutil.CaptureStdout(func() {
+		var err error
+		log, err = logger.NewDevelopment(
+			logger.WithTimeFormat(time.RFC3339),
+			logger.WithEncoderOptions(logger.EncoderOptions{DurationUnit: logger.DurationMillis}),
+			logger.WithElastic(logger.ElasticSink{Addresses: []string{mockES.URL}, FlushInterval: time.Minute}),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		log.Info("Request served", logger.F.Duration("elapsed", 1500*time.Millisecond), logger.F.Time("at", at))
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture stdout: %v", err)
+	}
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	raw := mockES.GetRawDocs()
+	if len(raw) != 1 {
+		t.Fatalf("Expected 1 document, got %d", len(raw))
+	}
+	if !strings.Contains(raw[0], `"elapsed":1500,`) || !strings.Contains(raw[0], `"at":"2024-03-01T12:30:00Z"`) {
+		t.Errorf("Expected integer milliseconds and RFC3339 time in %s", raw[0])
+	}
+	if !strings.Contains(output, `"elapsed": "1.5s"`) {
+		t.Errorf("Expected a human duration on the dev console, got %q", output)
+	}
+
+	if _, err := logger.NewProduction(logger.WithEncoderOptions(logger.EncoderOptions{DurationUnit: "min"})); err == nil {
+		t.Error("Expected an invalid duration unit to fail the build")
+	}
+}
+
 // D) Sampling & Levels
 
 func TestSamplingZapSemantics(t *testing.T) {
@@ -260,6 +361,128 @@ func TestSamplingZapSemantics(t *testing.T) {
 	}
 }
 
+func TestSamplingMarker(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC))
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewProduction(
+		logger.WithClock(clock),
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithSampling(logger.Sampling{Initial: 1, Thereafter: 100}),
+		logger.WithSamplingMarker(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	// Within one second the 1st and 101st entries get through
+	for i := 0; i < 200; i++ {
+		log.Info("Repeated", logger.F.Int("i", i))
+	}
+	log.Info("Unique")
+	// A new second starts over, but still reports the drops since the 101st
+	clock.Advance(time.Second)
+	log.Info("Repeated", logger.F.Int("i", 200))
+	closeLogger(t, log)
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	want := []struct {
+		i       float64
+		dropped any
+	}{{0, nil}, {100, float64(99)}, {-1, nil}, {200, float64(99)}}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %d: %v", len(want), len(entries), entries)
+	}
+	for n, w := range want {
+		e := entries[n]
+		if w.i >= 0 && e["i"] != w.i {
+			t.Errorf("Entry %d: expected i=%v, got %v", n, w.i, e["i"])
+		}
+		if e["sampled_dropped"] != w.dropped {
+			t.Errorf("Entry %d: expected sampled_dropped=%v, got %v", n, w.dropped, e["sampled_dropped"])
+		}
+	}
+}
+
+func TestPerSinkSampling(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []logger.Option
+	}{
+		{"Serial", nil},
+		{"ParallelSinks", []logger.Option{logger.WithParallelSinks()}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockES := testutil.NewElasticsearchMock()
+			defer mockES.Close()
+			clock := testutil.NewFakeClock(time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC))
+			logPath := filepath.Join(t.TempDir(), "app.log")
+
+			log, err := logger.NewProduction(append([]logger.Option{
+				logger.WithClock(clock),
+				logger.WithConsoleDisabled(),
+				// Without the override the file would get the first 10 too
+				logger.WithSampling(logger.Sampling{Initial: 10, Thereafter: 1000}),
+				logger.WithFile(logger.FileSink{Path: logPath, Sampling: &logger.Sampling{}}),
+				logger.WithElastic(logger.ElasticSink{
+					Addresses: []string{mockES.URL},
+					Sampling:  &logger.Sampling{Initial: 10, Thereafter: 20},
+				}),
+			}, tc.opts...)...)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			for i := 0; i < 100; i++ {
+				log.Info("Identical message", logger.F.Int("i", i))
+			}
+			closeLogger(t, log)
+
+			if entries := testutil.ReadJSONLines(t, logPath); len(entries) != 100 {
+				t.Errorf("Expected the file to get all 100 entries, got %d", len(entries))
+			}
+			// The first 10, then the 30th, 50th, 70th and 90th
+			docs := mockES.GetReceivedDocs()
+			if len(docs) != 14 {
+				t.Fatalf("Expected 14 sampled documents, got %d", len(docs))
+			}
+			if docs[10]["i"] != float64(29) || docs[13]["i"] != float64(89) {
+				t.Errorf("Expected the every-20th entries after the first 10, got %v and %v", docs[10]["i"], docs[13]["i"])
+			}
+		})
+	}
+}
+
+func TestPerSinkSamplingInherits(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+	clock := testutil.NewFakeClock(time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC))
+	logPath := filepath.Join(t.TempDir(), "app.log")
+
+	log, err := logger.NewProduction(
+		logger.WithClock(clock),
+		logger.WithConsoleDisabled(),
+		logger.WithSampling(logger.Sampling{Initial: 5, Thereafter: 1000}),
+		logger.WithFile(logger.FileSink{Path: logPath}), // Inherits the global sampling
+		logger.WithElastic(logger.ElasticSink{Addresses: []string{mockES.URL}, Sampling: &logger.Sampling{}}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		log.Info("Identical message", logger.F.Int("i", i))
+	}
+	closeLogger(t, log)
+
+	if entries := testutil.ReadJSONLines(t, logPath); len(entries) != 5 {
+		t.Errorf("Expected the file sampled like Options.Sampling, got %d entries", len(entries))
+	}
+	if docs := mockES.GetReceivedDocs(); len(docs) != 50 {
+		t.Errorf("Expected every entry in Elasticsearch, got %d", len(docs))
+	}
+}
+
 func TestStacktraceAt(t *testing.T) {
 	output, err := testutil.CaptureStdout(func() {
 		log, err := logger.NewProduction(
@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrAuditNotConfigured is returned by Audit for a logger built without an AuditSink
+var ErrAuditNotConfigured = errors.New("audit sink not configured")
+
+// Auditor is implemented by loggers that can write audit events
+type Auditor interface {
+	Audit(ctx context.Context, msg string, fields ...Field) error
+}
+
+// Audit writes a compliance event to log's AuditSink, together with the fields
+// bound through With and WithContext. Audit events are never sampled, truncated or
+// dropped: Audit blocks until every audit output has stored the event and returns
+// an error when one of them fails or ctx is done first.
+func Audit(ctx context.Context, log Logger, msg string, fields ...Field) error {
+	a, ok := log.(Auditor)
+	if !ok {
+		return ErrAuditNotConfigured
+	}
+	return a.Audit(ctx, msg, fields...)
+}
@@ -0,0 +1,153 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/elasticx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// Audit events
+
+func TestAuditBypassesSampling(t *testing.T) {
+	dir := t.TempDir()
+	logPath, auditPath := filepath.Join(dir, "app.log"), filepath.Join(dir, "audit.log")
+
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithService("billing"),
+		logger.WithSampling(logger.Sampling{Initial: 1, Thereafter: 1000}),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithAudit(logger.AuditSink{File: &logger.FileSink{Path: auditPath}}),
+		logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	events := logger.GetMetrics().AuditEvents.WithLabelValues("file")
+	before := promtestutil.ToFloat64(events)
+
+	actor := log.With(logger.F.String("actor", "alice"))
+	for i := 0; i < 20; i++ {
+		actor.Info("refund issued")
+		if err := logger.Audit(context.Background(), actor, "refund issued", logger.F.Int("seq", i)); err != nil {
+			t.Fatalf("Audit failed: %v", err)
+		}
+	}
+	closeLogger(t, log)
+
+	if docs := testutil.ReadJSONLines(t, logPath); len(docs) != 1 {
+		t.Errorf("Expected regular entries to be sampled down to 1, got %d", len(docs))
+	}
+	audits := testutil.ReadJSONLines(t, auditPath)
+	if len(audits) != 20 {
+		t.Fatalf("Expected all 20 audit events, got %d", len(audits))
+	}
+	for i, doc := range audits {
+		if doc["seq"] != float64(i) || doc["actor"] != "alice" || doc["audit"] != true || doc["service"] != "billing" {
+			t.Errorf("Unexpected audit event %d: %v", i, doc)
+		}
+	}
+	if got := promtestutil.ToFloat64(events) - before; got != 20 {
+		t.Errorf("Expected audit_events_total to grow by 20, got %v", got)
+	}
+}
+
+func TestAuditFailureReturnsError(t *testing.T) {
+	testCases := []struct {
+		name   string
+		status int
+		ctxErr bool
+	}{
+		{"Rejected", http.StatusBadRequest, false},
+		{"BackpressureUntilDeadline", http.StatusTooManyRequests, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := testutil.NewElasticsearchMock()
+			defer mock.Close()
+			mock.SetResponse(tc.status, `{"error":"nope"}`)
+
+			log, err := logger.NewProduction(
+				logger.WithConsoleDisabled(),
+				logger.WithElastic(logger.ElasticSink{Addresses: []string{mock.URL}}),
+				logger.WithAudit(logger.AuditSink{ElasticIndex: "audit-<service>"}),
+				logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			defer closeLogger(t, log)
+			failures := logger.GetMetrics().AuditFailures.WithLabelValues("elasticsearch")
+			before := promtestutil.ToFloat64(failures)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+			err = logger.Audit(ctx, log, "role granted", logger.F.String("role", "admin"))
+			if err == nil {
+				t.Fatal("Expected the failed audit write to return an error")
+			}
+			if got := errors.Is(err, context.DeadlineExceeded); got != tc.ctxErr {
+				t.Errorf("Expected deadline error=%v, got %v", tc.ctxErr, err)
+			}
+			if tc.ctxErr && mock.CountRequests(http.MethodPost, "/audit-app/_doc") < 2 {
+				t.Errorf("Expected retries while backpressured, got %d requests", mock.GetRequestCount())
+			}
+			if got := promtestutil.ToFloat64(failures) - before; got != 1 {
+				t.Errorf("Expected audit_failures_total to grow by 1, got %v", got)
+			}
+		})
+	}
+}
+
+func TestAuditElasticIndex(t *testing.T) {
+	mock := testutil.NewElasticsearchMock()
+	defer mock.Close()
+	mock.SetResponse(http.StatusCreated, `{"result":"created"}`)
+
+	log, err := logger.NewProduction(
+		logger.WithClock(testutil.NewFakeClock(time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC))),
+		logger.WithConsoleDisabled(),
+		logger.WithService("billing"),
+		logger.WithElastic(logger.ElasticSink{Addresses: []string{mock.URL}}),
+		logger.WithAudit(logger.AuditSink{ElasticIndex: "<service>-audit-%Y"}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer closeLogger(t, log)
+
+	if err := logger.Audit(context.Background(), log, "login"); err != nil {
+		t.Fatalf("Audit failed: %v", err)
+	}
+	path := "/billing-audit-2026/_doc"
+	if n := mock.CountRequests(http.MethodPost, path); n != 1 {
+		t.Errorf("Expected one index request to %s, got %v", path, mock.GetRequests())
+	}
+}
+
+func TestAuditNotConfigured(t *testing.T) {
+	log, err := logger.NewProduction()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+
+	if err := logger.Audit(context.Background(), log, "login"); !errors.Is(err, logger.ErrAuditNotConfigured) {
+		t.Errorf("Expected ErrAuditNotConfigured, got %v", err)
+	}
+
+	_, err = logger.NewProduction(logger.WithAudit(logger.AuditSink{ElasticIndex: "audit"}))
+	if err == nil || !strings.Contains(err.Error(), "Elastic sink") {
+		t.Errorf("Expected an error for ElasticIndex without an Elastic sink, got %v", err)
+	}
+}
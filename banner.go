@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"net/url"
+	"sort"
+)
+
+// StartupBannerMessage is the message of the entry WithStartupBanner logs
+const StartupBannerMessage = "logger started"
+
+// redacted replaces secrets in the startup banner
+const redacted = "REDACTED"
+
+// BannerFields describes the effective configuration for the startup banner:
+// env, level, the sinks of report with their key parameters, sampling and
+// metrics. Passwords, API keys, tokens, shared keys, header values and URL
+// credentials are replaced with "REDACTED".
+func (o Options) BannerFields(report BuildReport) []Field {
+	fields := []Field{
+		F.String("service", o.Service),
+		F.String("env", string(o.Env)),
+		F.String("level", string(o.Level)),
+		F.Any("sinks", report.Sinks),
+	}
+	if len(report.Skipped) > 0 {
+		skipped := make(map[string]string, len(report.Skipped))
+		for _, s := range report.Skipped {
+			skipped[s.Sink] = s.Err.Error()
+		}
+		fields = append(fields, F.Any("skipped_sinks", skipped))
+	}
+
+	fields = append(fields, F.Any("sampling", samplingBanner(o.Sampling)))
+	fields = append(fields, F.Any("metrics", map[string]any{
+		"enabled":       o.Metrics.Enabled,
+		"auto_register": o.Metrics.AutoRegister,
+		"service_label": o.Metrics.ServiceLabel,
+	}))
+
+	if o.File != nil {
+		fields = append(fields, F.Any("file", fileBanner(o.File)))
+	}
+	if o.Elastic != nil {
+		fields = append(fields, F.Any("elasticsearch", elasticBanner(o.Elastic)))
+	}
+	if o.OTLP != nil {
+		fields = append(fields, F.Any("otlp", map[string]any{
+			"endpoint": o.OTLP.Endpoint,
+			"protocol": o.OTLP.Protocol,
+			"insecure": o.OTLP.Insecure,
+			"headers":  redactValues(o.OTLP.Headers),
+		}))
+	}
+	if o.Fluent != nil {
+		fluent := map[string]any{"address": o.Fluent.Address, "tag": o.Fluent.Tag, "require_ack": o.Fluent.RequireAck}
+		if o.Fluent.SharedKey != "" {
+			fluent["shared_key"] = redacted
+		}
+		fields = append(fields, F.Any("fluent", fluent))
+	}
+	if o.Network != nil {
+		fields = append(fields, F.Any("network", map[string]any{
+			"network": o.Network.Network,
+			"address": o.Network.Address,
+			"tls":     o.Network.TLS != nil,
+		}))
+	}
+	if o.Alert != nil {
+		fields = append(fields, F.Any("alert", map[string]any{
+			"webhook_url": redactWebhook(o.Alert.WebhookURL),
+			"min_level":   string(o.Alert.MinLevel),
+			"threshold":   o.Alert.Threshold,
+		}))
+	}
+	if o.SQLite != nil {
+		fields = append(fields, F.Any("sqlite", map[string]any{"path": o.SQLite.Path, "table": o.SQLite.Table}))
+	}
+	if o.Audit != nil {
+		audit := map[string]any{"elastic_index": o.Audit.ElasticIndex}
+		if o.Audit.File != nil {
+			audit["file"] = o.Audit.File.Path
+		}
+		fields = append(fields, F.Any("audit", audit))
+	}
+	return fields
+}
+
+func fileBanner(f *FileSink) map[string]any {
+	return map[string]any{
+		"path":         f.Path,
+		"max_size_mb":  f.MaxSizeMB,
+		"max_backups":  f.MaxBackups,
+		"max_age_days": f.MaxAgeDays,
+		"compress":     f.Compress,
+		"sampling":     sinkSamplingBanner(f.Sampling),
+	}
+}
+
+func samplingBanner(s *Sampling) any {
+	if s == nil {
+		return "off"
+	}
+	return map[string]any{"initial": s.Initial, "thereafter": s.Thereafter}
+}
+
+// sinkSamplingBanner describes a sink's sampling override
+func sinkSamplingBanner(s *Sampling) any {
+	switch {
+	case s == nil:
+		return "inherit"
+	case *s == (Sampling{}):
+		return "off"
+	default:
+		return samplingBanner(s)
+	}
+}
+
+func elasticBanner(e *ElasticSink) map[string]any {
+	addresses := make([]string, len(e.Addresses))
+	for i, a := range e.Addresses {
+		addresses[i] = redactURL(a)
+	}
+	index := e.Index
+	if index == "" {
+		index = "<service>-%Y.%m.%d"
+	}
+	elastic := map[string]any{
+		"addresses":      addresses,
+		"index":          index,
+		"flush_interval": e.FlushInterval.String(),
+		"dlq_path":       e.DLQPath,
+		"client":         e.Client != nil,
+		"sampling":       sinkSamplingBanner(e.Sampling),
+	}
+	if e.CloudID != "" {
+		elastic["cloud_id"] = redacted
+	}
+	if e.Username != "" {
+		elastic["username"] = e.Username
+	}
+	for key, secret := range map[string]string{
+		"password":      e.Password,
+		"api_key":       e.APIKey,
+		"service_token": e.ServiceToken,
+	} {
+		if secret != "" {
+			elastic[key] = redacted
+		}
+	}
+	if len(e.ClientKey) > 0 {
+		elastic["client_key"] = redacted
+	}
+	return elastic
+}
+
+// redactValues keeps the keys of m, e.g. header names, and hides their values
+func redactValues(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k+"="+redacted)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// redactURL hides the password of a URL with credentials
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	return u.String()
+}
+
+// redactWebhook keeps the host of a webhook URL; the path usually is the secret
+func redactWebhook(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return redacted
+	}
+	return u.Scheme + "://" + u.Host + "/" + redacted
+}
@@ -3,11 +3,14 @@ package logger_test
 import (
 	"context"
 	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	"path/filepath"
 	"testing"
 	"time"
 
 	logger "github.com/HoangAnhNguyen269/loggerkit"
 	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/elasticx"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // K) Benchmarks
@@ -32,17 +35,26 @@ func BenchmarkLoggingConsole(b *testing.B) {
 }
 
 func BenchmarkLoggingFile(b *testing.B) {
+	b.Run("Unbuffered", func(b *testing.B) {
+		benchmarkLoggingFile(b)
+	})
+	b.Run("Buffered", func(b *testing.B) {
+		benchmarkLoggingFile(b, logger.WithBufferedWrites(logger.BufferOptions{}))
+	})
+}
+
+func benchmarkLoggingFile(b *testing.B, opts ...logger.Option) {
 	tempFile, cleanup := testutil.TempFile(b, "bench-log", ".log")
 	defer cleanup()
 
-	log, err := logger.NewProduction(
+	log, err := logger.NewProduction(append([]logger.Option{
 		logger.WithFile(logger.FileSink{
 			Path:       tempFile,
 			MaxSizeMB:  100, // Large enough to avoid rotation during benchmark
 			MaxBackups: 1,
 		}),
 		logger.WithConsoleDisabled(),
-	)
+	}, opts...)...)
 	if err != nil {
 		b.Fatalf("Failed to create logger: %v", err)
 	}
@@ -88,9 +100,52 @@ func BenchmarkLoggingESStub(b *testing.B) {
 	})
 }
 
+func BenchmarkESCompression(b *testing.B) {
+	for _, compress := range []bool{false, true} {
+		name := "Plain"
+		if compress {
+			name = "Gzip"
+		}
+		b.Run(name, func(b *testing.B) {
+			mockES := testutil.NewElasticsearchMock()
+			defer mockES.Close()
+
+			log, err := logger.NewProduction(
+				logger.WithElastic(logger.ElasticSink{
+					Addresses:           []string{mockES.URL},
+					FlushInterval:       100 * time.Millisecond,
+					CompressRequestBody: compress,
+				}),
+				logger.WithConsoleDisabled(),
+				func(o *logger.Options) { o.Sampling = nil }, // Ship every entry
+			)
+			if err != nil {
+				b.Fatalf("Failed to create logger: %v", err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				log.Info("Benchmark ES compression message",
+					logger.F.String("component", "benchmark"),
+					logger.F.Int("iteration", i),
+					logger.F.Duration("elapsed", time.Microsecond*100),
+				)
+			}
+			if err := log.Close(context.Background()); err != nil {
+				b.Fatalf("Failed to close logger: %v", err)
+			}
+			b.StopTimer()
+
+			// Bytes on the wire per logged entry, as measured by the mock server
+			b.ReportMetric(float64(mockES.GetBytesReceived())/float64(b.N), "wire-bytes/op")
+		})
+	}
+}
+
 func BenchmarkFieldHelpers(b *testing.B) {
 	log, err := logger.NewProduction(
 		logger.WithConsoleDisabled(), // Minimize output overhead
+		logger.WithFile(logger.FileSink{Path: filepath.Join(b.TempDir(), "bench.log")}),
 	)
 	if err != nil {
 		b.Fatalf("Failed to create logger: %v", err)
@@ -124,6 +179,59 @@ func BenchmarkFieldHelpers(b *testing.B) {
 			}
 		})
 	})
+
+	b.Run("FV", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				log.Info("FV fields",
+					logger.FV("str", "value"),
+					logger.FV("int", 42),
+					logger.FV("bool", true),
+					logger.FV("dur", time.Millisecond),
+				)
+			}
+		})
+	})
+}
+
+func BenchmarkDedupFields(b *testing.B) {
+	b.Run("Default", func(b *testing.B) {
+		benchmarkBoundFields(b)
+	})
+	b.Run("Dedup", func(b *testing.B) {
+		benchmarkBoundFields(b, logger.WithDedupFields())
+	})
+}
+
+func benchmarkBoundFields(b *testing.B, opts ...logger.Option) {
+	tempFile, cleanup := testutil.TempFile(b, "bench-log", ".log")
+	defer cleanup()
+
+	log, err := logger.NewProduction(append([]logger.Option{
+		logger.WithFile(logger.FileSink{Path: tempFile, MaxSizeMB: 100}),
+		logger.WithConsoleDisabled(),
+		func(o *logger.Options) { o.Sampling = nil },
+	}, opts...)...)
+	if err != nil {
+		b.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+
+	bound := log.With(
+		logger.F.String("service", "test"),
+		logger.F.String("component", "bench"),
+		logger.F.String("region", "eu"),
+	)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			bound.Info("Bound fields message",
+				logger.F.String("operation", "test"),
+				logger.F.Int("iteration", 1),
+			)
+		}
+	})
 }
 
 func BenchmarkWithChaining(b *testing.B) {
@@ -183,6 +291,49 @@ func BenchmarkContextLogger(b *testing.B) {
 	})
 }
 
+// BenchmarkWithContext measures WithContext alone: a logger with nothing to
+// extract, one whose keys are missing from the context, and a traced request
+func BenchmarkWithContext(b *testing.B) {
+	type ctxKey string
+	reqKey, userKey := ctxKey("request_id"), ctxKey("user_id")
+	keys := logger.ContextKeys{RequestIDKey: reqKey, UserIDKey: userKey}
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01},
+		SpanID:     trace.SpanID{0x02},
+		TraceFlags: trace.FlagsSampled,
+	})
+	full := trace.ContextWithSpanContext(context.Background(), sc)
+	full = context.WithValue(full, reqKey, "req-123")
+	full = context.WithValue(full, userKey, "user-456")
+
+	cases := []struct {
+		name string
+		opts []logger.Option
+		ctx  context.Context
+	}{
+		{"NoKeys", []logger.Option{logger.WithoutTraceExtraction()}, context.WithValue(context.Background(), reqKey, "req-123")},
+		{"NoKeysTracing", nil, context.WithValue(context.Background(), reqKey, "req-123")},
+		{"KeysNoValues", []logger.Option{logger.WithContext(keys)}, context.Background()},
+		{"Full", []logger.Option{logger.WithContext(keys)}, full},
+	}
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			log, err := logger.NewProduction(append(c.opts, logger.WithConsoleDisabled(), logger.WithRing(logger.RingSink{Capacity: 1}))...)
+			if err != nil {
+				b.Fatalf("Failed to create logger: %v", err)
+			}
+			defer log.Close(context.Background())
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = log.WithContext(c.ctx)
+			}
+		})
+	}
+}
+
 func BenchmarkSampling(b *testing.B) {
 	log, err := logger.NewProduction(
 		logger.WithSampling(logger.Sampling{
@@ -218,6 +369,7 @@ func BenchmarkLogLevels(b *testing.B) {
 	b.ResetTimer()
 
 	b.Run("Debug_Filtered", func(b *testing.B) {
+		b.ReportAllocs()
 		b.RunParallel(func(pb *testing.PB) {
 			for pb.Next() {
 				log.Debug("Debug message", logger.F.String("level", "debug"))
@@ -283,3 +435,52 @@ func BenchmarkMultipleFields(b *testing.B) {
 		})
 	})
 }
+
+func BenchmarkLogFourFields(b *testing.B) {
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: filepath.Join(b.TempDir(), "bench.log")}),
+	)
+	if err != nil {
+		b.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Info("four fields",
+			logger.F.String("user", "u-1"),
+			logger.F.Int("attempt", 3),
+			logger.F.Bool("ok", true),
+			logger.F.Duration("elapsed", time.Millisecond),
+		)
+	}
+}
+
+func TestFilteredLevelAllocs(t *testing.T) {
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: filepath.Join(t.TempDir(), "app.log")}),
+		logger.WithLevel(logger.InfoLevel),
+		logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+
+	// Only the call's own field arguments may allocate for a disabled level
+	allocs := testing.AllocsPerRun(1000, func() {
+		log.Debug("Debug message", logger.F.String("level", "debug"))
+	})
+	if allocs > 2 {
+		t.Errorf("Expected a filtered debug call to skip conversion, got %.0f allocs", allocs)
+	}
+	noFields := testing.AllocsPerRun(1000, func() {
+		log.Debug("Debug message")
+	})
+	if noFields != 0 {
+		t.Errorf("Expected no allocations for a filtered call without fields, got %.0f", noFields)
+	}
+}
@@ -1,16 +1,130 @@
 package logger
 
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultProvider is the builder used when Options.Provider is empty
+const DefaultProvider = "zapx"
+
+var (
+	// ErrNoBuilder is returned by the constructors when the default provider is not registered
+	ErrNoBuilder = errors.New(`no logger builder registered: add import _ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"`)
+	// ErrUnknownProvider is returned for an Options.Provider nobody registered
+	ErrUnknownProvider = errors.New("logger provider not registered")
+)
+
 // NewBuilder interface for creating loggers
 type NewBuilder interface {
 	NewWithOptions(opts Options) (Logger, error)
 }
 
-// Global builder instance
-var globalBuilder NewBuilder
+// registeredBuilder is a builder with the package that registered it
+type registeredBuilder struct {
+	builder NewBuilder
+	pkg     string
+}
+
+var (
+	buildersMu sync.RWMutex
+	builders   = map[string]registeredBuilder{
+		"nop": {builder: nopBuilder{}, pkg: "github.com/HoangAnhNguyen269/loggerkit"},
+	}
+)
+
+// RegisterBuilder makes builder available as Options.Provider name, normally
+// from a provider's init. It panics when another package already registered
+// name; the same package may replace its own builder.
+func RegisterBuilder(name string, builder NewBuilder) {
+	registerBuilder(name, builder, callerPackage(2))
+}
 
-// SetBuilder sets the global logger builder
+// SetBuilder registers builder as the "default" provider, used when
+// Options.Provider is empty and zapx is not imported.
+//
+// Deprecated: use RegisterBuilder with the provider's name.
 func SetBuilder(builder NewBuilder) {
-	globalBuilder = builder
+	registerBuilder("default", builder, callerPackage(2))
+}
+
+func registerBuilder(name string, builder NewBuilder, pkg string) {
+	buildersMu.Lock()
+	defer buildersMu.Unlock()
+	if prev, ok := builders[name]; ok && prev.pkg != pkg {
+		panic(fmt.Sprintf("loggerkit: logger builder %q already registered by %s, %s can't register another", name, prev.pkg, pkg))
+	}
+	builders[name] = registeredBuilder{builder: builder, pkg: pkg}
+}
+
+// HasBuilder reports whether the default provider is registered, for
+// frameworks that fall back to their own logging without one
+func HasBuilder() bool {
+	_, err := getBuilder("")
+	return err == nil
+}
+
+// Providers returns the names of the registered builders, sorted
+func Providers() []string {
+	buildersMu.RLock()
+	defer buildersMu.RUnlock()
+	names := make([]string, 0, len(builders))
+	for name := range builders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getBuilder resolves Options.Provider: empty is DefaultProvider, or the builder
+// registered through SetBuilder when that is missing
+func getBuilder(name string) (NewBuilder, error) {
+	buildersMu.RLock()
+	r, ok := builders[name]
+	if name == "" {
+		if r, ok = builders[DefaultProvider]; !ok {
+			r, ok = builders["default"]
+		}
+	}
+	buildersMu.RUnlock()
+	if ok {
+		return r.builder, nil
+	}
+
+	registered := strings.Join(Providers(), ", ")
+	if name == "" || name == DefaultProvider {
+		return nil, fmt.Errorf("%w (registered: %s)", ErrNoBuilder, registered)
+	}
+	return nil, fmt.Errorf("%w: %q (registered: %s)", ErrUnknownProvider, name, registered)
+}
+
+// callerPackage returns the import path of the function skip frames up
+func callerPackage(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return "unknown"
+	}
+	name := runtime.FuncForPC(pc).Name() // e.g. example.com/a/b.init.0
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+		return name[:slash+1+dot]
+	}
+	return name
+}
+
+func newWithBuilder(opts Options) (Logger, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	builder, err := getBuilder(opts.Provider)
+	if err != nil {
+		return nil, err
+	}
+	return builder.NewWithOptions(opts)
 }
 
 // NewDevelopment creates a development logger
@@ -19,7 +133,7 @@ func NewDevelopment(opts ...Option) (Logger, error) {
 	for _, opt := range opts {
 		opt(&config)
 	}
-	return globalBuilder.NewWithOptions(config)
+	return newWithBuilder(config)
 }
 
 // NewProduction creates a production logger
@@ -28,39 +142,17 @@ func NewProduction(opts ...Option) (Logger, error) {
 	for _, opt := range opts {
 		opt(&config)
 	}
-	return globalBuilder.NewWithOptions(config)
+	return newWithBuilder(config)
 }
 
-// MustNew creates a logger using the legacy Config struct
+// MustNew creates a production logger from the legacy Config struct and
+// panics when cfg is invalid or the logger can't be built
 func MustNew(cfg *Config) Logger {
-	// Convert legacy Config to new Options
-	opts := Options{
-		Env:          EnvProd,
-		Service:      "app",
-		Level:        cfg.Level,
-		TimeFormat:   "",
-		EnableCaller: true,
-		StacktraceAt: ErrorLevel,
-	}
-
-	if cfg.FileConfig != nil {
-		opts.File = &FileSink{
-			Path:       cfg.FileConfig.Filename,
-			MaxSizeMB:  cfg.FileConfig.MaxSize,
-			MaxBackups: cfg.FileConfig.MaxBackups,
-			MaxAgeDays: cfg.FileConfig.MaxAge,
-			Compress:   cfg.FileConfig.Compress,
-		}
-	}
-
-	if cfg.ElasticConfig != nil {
-		opts.Elastic = &ElasticSink{
-			Addresses: []string{cfg.ElasticConfig.URL},
-			Index:     cfg.ElasticConfig.Index,
-		}
+	opts, err := cfg.Options()
+	if err != nil {
+		panic(err)
 	}
-
-	log, err := globalBuilder.NewWithOptions(opts)
+	log, err := newWithBuilder(opts)
 	if err != nil {
 		panic(err)
 	}
@@ -89,6 +181,53 @@ type ElasticConfig struct {
 	Index string
 }
 
+// Options converts c to the Options v1 built it with: a production logger
+// without sampling whose console writes JSON unless JSON is false, and that
+// logs nothing to the console unless ConsoleEnabled is set. An empty Level is
+// InfoLevel.
+func (c *Config) Options() (Options, error) {
+	level := InfoLevel
+	if c.Level != "" {
+		lvl, err := ParseLevel(string(c.Level))
+		if err != nil {
+			return Options{}, fmt.Errorf("failed to convert legacy config: %w", err)
+		}
+		level = lvl
+	}
+
+	console := ConsoleText
+	if c.JSON {
+		console = ConsoleJSON
+	}
+	opts := Options{
+		Env:            EnvProd,
+		Service:        "app",
+		Level:          level,
+		Encoder:        EncoderOptions{Console: console},
+		EnableCaller:   true,
+		StacktraceAt:   ErrorLevel,
+		DisableConsole: !c.ConsoleEnabled,
+	}
+
+	if c.FileConfig != nil {
+		opts.File = &FileSink{
+			Path:       c.FileConfig.Filename,
+			MaxSizeMB:  c.FileConfig.MaxSize,
+			MaxBackups: c.FileConfig.MaxBackups,
+			MaxAgeDays: c.FileConfig.MaxAge,
+			Compress:   c.FileConfig.Compress,
+		}
+	}
+
+	if c.ElasticConfig != nil {
+		opts.Elastic = &ElasticSink{
+			Addresses: []string{c.ElasticConfig.URL},
+			Index:     c.ElasticConfig.Index,
+		}
+	}
+	return opts, nil
+}
+
 // DefaultConfig returns a default legacy config
 func DefaultConfig() *Config {
 	return &Config{
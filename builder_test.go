@@ -0,0 +1,97 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+)
+
+// Builder registration and provider selection
+
+type stubBuilder struct{ name string }
+
+func (b stubBuilder) NewWithOptions(logger.Options) (logger.Logger, error) {
+	return nil, errors.New(b.name)
+}
+
+func TestMissingBuilder(t *testing.T) {
+	restore := logger.SwapBuilderForTest(logger.DefaultProvider, nil, "")
+	defer restore()
+
+	if logger.HasBuilder() {
+		t.Error("Expected HasBuilder to be false")
+	}
+	_, err := logger.NewProduction()
+	if !errors.Is(err, logger.ErrNoBuilder) {
+		t.Fatalf("Expected ErrNoBuilder, got %v", err)
+	}
+	if want := `import _ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"`; !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected the error to explain the blank import, got %q", err)
+	}
+}
+
+func TestDoubleRegistrationPanics(t *testing.T) {
+	if !logger.HasBuilder() {
+		t.Fatal("Expected the zapx builder to be registered")
+	}
+
+	func() {
+		defer func() {
+			msg := fmt.Sprint(recover())
+			if !strings.Contains(msg, "provider/zapx") || !strings.Contains(msg, "loggerkit_test") {
+				t.Errorf("Expected a panic naming both packages, got %q", msg)
+			}
+		}()
+		logger.RegisterBuilder(logger.DefaultProvider, stubBuilder{"stub"})
+	}()
+
+	// The first registration stays in place
+	log, err := logger.NewProduction()
+	if err != nil {
+		t.Fatalf("Expected the zapx builder to remain, got %v", err)
+	}
+	log.Close(context.Background())
+
+	// A package may replace its own builder
+	defer logger.SwapBuilderForTest("stub", nil, "")()
+	logger.RegisterBuilder("stub", stubBuilder{"first"})
+	logger.RegisterBuilder("stub", stubBuilder{"second"})
+	if _, err := logger.NewProduction(logger.WithProvider("stub")); err == nil || err.Error() != "second" {
+		t.Errorf("Expected the replaced stub builder, got %v", err)
+	}
+}
+
+func TestSelectProvider(t *testing.T) {
+	defer logger.SwapBuilderForTest("alpha", nil, "")()
+	defer logger.SwapBuilderForTest("beta", nil, "")()
+	logger.RegisterBuilder("alpha", stubBuilder{"alpha"})
+	logger.RegisterBuilder("beta", stubBuilder{"beta"})
+
+	for _, name := range []string{"alpha", "beta"} {
+		if _, err := logger.NewProduction(logger.WithProvider(name)); err == nil || err.Error() != name {
+			t.Errorf("Expected builder %s, got %v", name, err)
+		}
+	}
+
+	log, err := logger.NewProduction(logger.WithProvider("nop"))
+	if err != nil {
+		t.Fatalf("Failed to create nop logger: %v", err)
+	}
+	log.Named("x").With(logger.F.Int("n", 1)).Fatal("discarded")
+	if err := log.Close(context.Background()); err != nil {
+		t.Errorf("Expected nop Close to succeed, got %v", err)
+	}
+
+	_, err = logger.NewProduction(logger.WithProvider("slog"))
+	if !errors.Is(err, logger.ErrUnknownProvider) {
+		t.Fatalf("Expected ErrUnknownProvider, got %v", err)
+	}
+	if want := "(registered: " + strings.Join(logger.Providers(), ", ") + ")"; !strings.Contains(err.Error(), want) || !strings.Contains(want, "alpha, beta, nop") {
+		t.Errorf("Expected the registered providers in %q", err)
+	}
+}
@@ -0,0 +1,39 @@
+package logger
+
+import "time"
+
+// Clock tells loggerkit the time: entry timestamps (and so sampling ticks and
+// Elasticsearch index names), DLQ timestamps, retry backoffs, alert windows and
+// periodic flushes. File rotation is left to lumberjack, which keeps its own
+// clock. It satisfies zapcore.Clock. Swap it in tests with WithClock
+// and testutil.FakeClock.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) *time.Ticker
+	// After is a one-shot timer, like time.After
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the real clock, used when Options.Clock is nil
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) NewTicker(d time.Duration) *time.Ticker { return time.NewTicker(d) }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// ClockOrDefault returns o.Clock, or SystemClock when it is nil
+func (o Options) ClockOrDefault() Clock {
+	if o.Clock == nil {
+		return SystemClock
+	}
+	return o.Clock
+}
+
+// WithClock replaces the real clock, mainly so tests can control time
+func WithClock(c Clock) Option {
+	return func(o *Options) {
+		o.Clock = c
+	}
+}
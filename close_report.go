@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"context"
+	"time"
+)
+
+// CloseReport describes what Close did, sink by sink
+type CloseReport struct {
+	Duration time.Duration     // Time Close took in total
+	Sinks    []SinkCloseReport // Sinks with a closer, in build order
+}
+
+// Sink returns the report of the sink named name
+func (r CloseReport) Sink(name string) (SinkCloseReport, bool) {
+	for _, s := range r.Sinks {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return SinkCloseReport{}, false
+}
+
+// SinkCloseReport is how one sink closed
+type SinkCloseReport struct {
+	Name     string
+	Duration time.Duration // Time the sink took to close, or until ctx was done
+	Finished bool          // False when ctx was done before the sink closed
+	Err      error         // Why the sink failed to flush or close, e.g. a failed file sync
+	Counts   *CloseCounts  // Nil for sinks that don't count their entries
+	Indexer  *IndexerStats // Bulk indexer counters after closing; nil for sinks without one
+}
+
+// CloseCounts are what a buffering sink did with its entries while closing
+type CloseCounts struct {
+	Pending      int64 // Entries buffered or in flight when Close started
+	Delivered    int64 // Entries delivered while closing
+	DeadLettered int64 // Entries written to the dead letter queue while closing
+}
+
+// IndexerStats are the counters of the Elasticsearch bulk indexer, summed over
+// the sink's lifetime. Added = Flushed + Failed once everything was sent, so
+// they reconcile what a logger wrote with what reached Elasticsearch.
+type IndexerStats struct {
+	Added    uint64 // Documents queued
+	Flushed  uint64 // Documents Elasticsearch accepted
+	Failed   uint64 // Documents rejected, or lost with a failed bulk request
+	Indexed  uint64 // Documents Elasticsearch indexed
+	Created  uint64 // Documents Elasticsearch created (data streams)
+	Requests uint64 // Bulk requests sent
+}
+
+func (s IndexerStats) add(o IndexerStats) IndexerStats {
+	return IndexerStats{
+		Added:    s.Added + o.Added,
+		Flushed:  s.Flushed + o.Flushed,
+		Failed:   s.Failed + o.Failed,
+		Indexed:  s.Indexed + o.Indexed,
+		Created:  s.Created + o.Created,
+		Requests: s.Requests + o.Requests,
+	}
+}
+
+// CloseReporter is implemented by loggers that can report what Close did
+type CloseReporter interface {
+	CloseWithReport(ctx context.Context) (CloseReport, error)
+}
+
+// CloseWithReport closes log like Close and reports what each sink flushed and
+// how long it took; loggers that can't report return an empty report
+func CloseWithReport(ctx context.Context, log Logger) (CloseReport, error) {
+	if r, ok := log.(CloseReporter); ok {
+		return r.CloseWithReport(ctx)
+	}
+	return CloseReport{}, log.Close(ctx)
+}
@@ -0,0 +1,211 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/elasticx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newReportLogger logs n entries to an Elasticsearch sink that only sends them
+// when closed, next to a file sink
+func newReportLogger(t *testing.T, mockES *testutil.ElasticsearchMockServer, n int) logger.Logger {
+	t.Helper()
+	log, _ := testutil.FileLogger(t, logger.WithElastic(logger.ElasticSink{
+		Addresses:     []string{mockES.URL},
+		FlushInterval: time.Hour, // Only Close sends
+		DLQPath:       filepath.Join(t.TempDir(), "dlq.log"),
+		Retry:         logger.Retry{Max: 0},
+	}))
+	for i := 0; i < n; i++ {
+		log.Info(fmt.Sprintf("Pending %d", i))
+	}
+	return log
+}
+
+func TestCloseReportPendingDocs(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+	log := newReportLogger(t, mockES, 3)
+
+	report, err := logger.CloseWithReport(context.Background(), log)
+	if err != nil {
+		t.Fatalf("CloseWithReport failed: %v", err)
+	}
+	if !mockES.WaitForDocs(3, time.Second) {
+		t.Fatalf("Expected Close to deliver 3 documents, got %d", len(mockES.GetReceivedDocs()))
+	}
+
+	es, ok := report.Sink("elasticsearch")
+	if !ok {
+		t.Fatalf("Expected an elasticsearch sink in %+v", report)
+	}
+	if !es.Finished || es.Err != nil || es.Duration <= 0 {
+		t.Errorf("Expected a clean elasticsearch close with its duration, got %+v", es)
+	}
+	if es.Counts == nil || *es.Counts != (logger.CloseCounts{Pending: 3, Delivered: 3}) {
+		t.Errorf("Expected 3 pending documents delivered, got %+v", es.Counts)
+	}
+
+	file, ok := report.Sink("file")
+	if !ok || !file.Finished || file.Err != nil || file.Counts != nil {
+		t.Errorf("Expected a clean file close without counts, got %+v (found %v)", file, ok)
+	}
+	if report.Duration < es.Duration {
+		t.Errorf("Expected the total duration to cover the sinks, got %v < %v", report.Duration, es.Duration)
+	}
+
+	// Later calls close nothing
+	if again, err := logger.CloseWithReport(context.Background(), log); err != nil || len(again.Sinks) != 0 {
+		t.Errorf("Expected an empty report from a second Close, got %+v, %v", again, err)
+	}
+}
+
+func TestCloseReportDeadLettered(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+	mockES.FailNextDocs(2, 400)
+	log := newReportLogger(t, mockES, 3)
+
+	report, err := logger.CloseWithReport(context.Background(), log)
+	if err != nil {
+		t.Fatalf("CloseWithReport failed: %v", err)
+	}
+	es, _ := report.Sink("elasticsearch")
+	if es.Counts == nil || *es.Counts != (logger.CloseCounts{Pending: 3, Delivered: 1, DeadLettered: 2}) {
+		t.Errorf("Expected 1 delivered and 2 dead-lettered of 3 pending, got %+v", es.Counts)
+	}
+}
+
+func TestCloseReportUnfinished(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+	mockES.SetLatency(2 * time.Second)
+	log := newReportLogger(t, mockES, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	report, err := logger.CloseWithReport(ctx, log)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected Close to run out of time, got %v", err)
+	}
+
+	es, _ := report.Sink("elasticsearch")
+	if es.Finished || !errors.Is(es.Err, context.DeadlineExceeded) {
+		t.Errorf("Expected the elasticsearch sink reported as unfinished, got %+v", es)
+	}
+	if es.Counts == nil || es.Counts.Pending != 2 || es.Counts.Delivered != 0 {
+		t.Errorf("Expected 2 pending documents none delivered, got %+v", es.Counts)
+	}
+	if file, _ := report.Sink("file"); !file.Finished {
+		t.Errorf("Expected the file sink to finish regardless, got %+v", file)
+	}
+}
+
+func TestCloseReportNotSupported(t *testing.T) {
+	log, err := logger.NewProduction(logger.WithProvider("nop"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	report, err := logger.CloseWithReport(context.Background(), log)
+	if err != nil || len(report.Sinks) != 0 {
+		t.Errorf("Expected an empty report, got %+v, %v", report, err)
+	}
+}
+
+func TestIndexerStatsReconcile(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+	mockES.FailNextDocs(1, 400)
+	registry := prometheus.NewRegistry()
+	for _, collector := range logger.MetricsCollectors() {
+		registry.MustRegister(collector)
+	}
+
+	const service = "indexer-stats"
+	log, err := logger.NewProduction(
+		logger.WithService(service),
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			FlushInterval: time.Hour, // Only FlushSink and Close send
+			DLQPath:       filepath.Join(t.TempDir(), "dlq.log"),
+		}),
+		logger.WithConsoleDisabled(),
+		logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		log.Info(fmt.Sprintf("Before flush %d", i))
+	}
+	// A flush swaps the bulk indexer; its counts must carry over
+	if err := logger.FlushSink(context.Background(), log, "elasticsearch"); err != nil {
+		t.Fatalf("FlushSink failed: %v", err)
+	}
+	log.Info("After flush 0")
+	log.Info("After flush 1")
+
+	sinks := logger.Sinks(log)
+	if len(sinks) != 1 || sinks[0].Indexer == nil {
+		t.Fatalf("Expected indexer stats on the elasticsearch sink, got %+v", sinks)
+	}
+	if live := *sinks[0].Indexer; live.Added != 5 || live.Flushed+live.Failed != 3 || live.Requests != 1 {
+		t.Errorf("Expected 5 added and 3 sent before Close, got %+v", live)
+	}
+
+	report, err := logger.CloseWithReport(context.Background(), log)
+	if err != nil {
+		t.Fatalf("CloseWithReport failed: %v", err)
+	}
+	es, _ := report.Sink("elasticsearch")
+	if es.Indexer == nil {
+		t.Fatalf("Expected indexer stats in the close report, got %+v", es)
+	}
+	stats := *es.Indexer
+	received := uint64(len(mockES.GetReceivedDocs()))
+	if stats.Indexed+stats.Created != received || received != 4 {
+		t.Errorf("Expected the indexer to count the %d documents the server kept, got %+v", received, stats)
+	}
+	if stats.Added != 5 || stats.Flushed != 4 || stats.Failed != 1 || stats.Requests != 2 {
+		t.Errorf("Expected 5 added, 4 accepted and 1 failed in 2 requests, got %+v", stats)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	want := map[string]float64{
+		"es_indexer_added_total":    5,
+		"es_indexer_flushed_total":  4,
+		"es_indexer_failed_total":   1,
+		"es_indexer_requests_total": 2,
+		"es_indexer_indexed_total":  float64(stats.Indexed),
+	}
+	for _, mf := range families {
+		expected, ok := want[mf.GetName()]
+		if !ok {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			if m.GetLabel()[0].GetValue() != service {
+				continue
+			}
+			if got := m.GetCounter().GetValue(); got != expected {
+				t.Errorf("Expected %s %v after Close, got %v", mf.GetName(), expected, got)
+			}
+			delete(want, mf.GetName())
+		}
+	}
+	if len(want) != 0 {
+		t.Errorf("Missing series for service %q: %v", service, want)
+	}
+}
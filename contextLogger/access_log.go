@@ -0,0 +1,168 @@
+package contextLogger
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+)
+
+// AccessLogOptions configures AccessLogMiddleware
+type AccessLogOptions struct {
+	// RequestIDHeader is read for the request_id field when the request context
+	// carries no request ID (default "X-Request-ID")
+	RequestIDHeader string
+	// Route returns the route template for the route field. By default the
+	// http.ServeMux pattern is used when the request went through one.
+	Route func(r *http.Request) string
+	// SkipPaths are not logged, e.g. health checks
+	SkipPaths []string
+	// SampleSuccess logs only 1 in SampleSuccess requests below status 400 (0 or 1 logs all)
+	SampleSuccess int
+	// Context names the trace fields and the context key of the request ID, e.g.
+	// one generated by HTTPMiddlewareWithOptions; pass the logger's Options.Context
+	Context logger.ContextKeys
+}
+
+// AccessLogMiddleware logs one entry per request with its method, path, status,
+// bytes written, duration, remote address, request ID and user agent. The level
+// follows the status: error for 5xx, warn for 4xx and info otherwise.
+func AccessLogMiddleware(log logger.Logger, opts AccessLogOptions) func(http.Handler) http.Handler {
+	if opts.RequestIDHeader == "" {
+		opts.RequestIDHeader = "X-Request-ID"
+	}
+	skip := make(map[string]bool, len(opts.SkipPaths))
+	for _, p := range opts.SkipPaths {
+		skip[p] = true
+	}
+	idKey := requestIDKey(opts.Context)
+	var successes atomic.Uint64
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			rw := &accessLogWriter{ResponseWriter: w}
+			next.ServeHTTP(rw, r)
+
+			status := rw.status
+			if status == 0 && !rw.hijacked {
+				status = http.StatusOK // The handler never wrote anything
+			}
+			if status < 400 && opts.SampleSuccess > 1 && (successes.Add(1)-1)%uint64(opts.SampleSuccess) != 0 {
+				return
+			}
+
+			fields := []logger.Field{
+				logger.F.String("method", r.Method),
+				logger.F.String("path", r.URL.Path),
+				logger.F.Int("status", status),
+				logger.F.Any("bytes", rw.bytes),
+				logger.F.Duration("duration", time.Since(start)),
+				logger.F.String("remote_addr", r.RemoteAddr),
+				logger.F.String("user_agent", r.UserAgent()),
+			}
+			route := r.Pattern
+			if opts.Route != nil {
+				route = opts.Route(r)
+			}
+			if route != "" {
+				fields = append(fields, logger.F.String("route", route))
+			}
+			if rid := requestID(r, idKey, opts.RequestIDHeader); rid != "" {
+				fields = append(fields, logger.F.String("request_id", rid))
+			}
+			if rw.hijacked {
+				fields = append(fields, logger.F.Bool("hijacked", true))
+			}
+			fields = append(fields, ExtractTraceFields(r.Context(), opts.Context)...)
+
+			log.Log(statusLevel(status), "http request", fields...)
+		})
+	}
+}
+
+// requestIDKey is the context key of the "request_id" field in keys, or nil
+func requestIDKey(keys logger.ContextKeys) any {
+	for _, m := range keys.Mappings() {
+		if m.Field == "request_id" {
+			return m.Key
+		}
+	}
+	return nil
+}
+
+// requestID is the request ID stored in the request context under key, or else
+// the value of header
+func requestID(r *http.Request, key any, header string) string {
+	if key != nil {
+		if id, ok := r.Context().Value(key).(string); ok && id != "" {
+			return id
+		}
+	}
+	return r.Header.Get(header)
+}
+
+// statusLevel is error for 5xx, warn for 4xx and info otherwise
+func statusLevel(status int) logger.Level {
+	switch {
+	case status >= 500:
+		return logger.ErrorLevel
+	case status >= 400:
+		return logger.WarnLevel
+	default:
+		return logger.InfoLevel
+	}
+}
+
+// accessLogWriter records the status and body size written by a handler
+type accessLogWriter struct {
+	http.ResponseWriter
+	status   int
+	bytes    int64
+	hijacked bool
+}
+
+func (w *accessLogWriter) WriteHeader(code int) {
+	// Informational headers (e.g. 103 Early Hints) are followed by the real status
+	if w.status == 0 && code >= 200 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *accessLogWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *accessLogWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *accessLogWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *accessLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
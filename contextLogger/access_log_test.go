@@ -0,0 +1,148 @@
+package contextLogger_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/contextLogger"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+func get(t *testing.T, url string, header http.Header) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	for k := range header {
+		req.Header.Set(k, header.Get(k))
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "item") // Never calls WriteHeader
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/boom", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("Hijack failed: %v", err)
+			return
+		}
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 0\r\nConnection: close\r\n\r\n")
+		buf.Flush()
+		conn.Close()
+	})
+	srv := httptest.NewServer(contextLogger.AccessLogMiddleware(log, contextLogger.AccessLogOptions{
+		SkipPaths: []string{"/healthz"},
+	})(mux))
+	defer srv.Close()
+
+	get(t, srv.URL+"/items/42", http.Header{"X-Request-Id": {"req-7"}, "User-Agent": {"probe/1.0"}})
+	get(t, srv.URL+"/empty", nil)
+	get(t, srv.URL+"/missing", nil)
+	get(t, srv.URL+"/boom", nil)
+	get(t, srv.URL+"/healthz", nil)
+	get(t, srv.URL+"/ws", nil)
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	testCases := []struct {
+		path   string
+		level  string
+		status float64
+		bytes  float64
+	}{
+		{"/items/42", "info", 200, 4},
+		{"/empty", "info", 200, 0},
+		{"/missing", "warn", 404, 19},
+		{"/boom", "error", 500, 5},
+		{"/ws", "info", 0, 0},
+	}
+	if len(entries) != len(testCases) {
+		t.Fatalf("Expected %d entries (health check skipped), got %d: %v", len(testCases), len(entries), entries)
+	}
+	for i, tc := range testCases {
+		e := entries[i]
+		if e["msg"] != "http request" || e["method"] != "GET" || e["path"] != tc.path || e["level"] != tc.level ||
+			e["status"] != tc.status || e["bytes"] != tc.bytes {
+			t.Errorf("Unexpected entry for %s: %v", tc.path, e)
+		}
+		if _, ok := e["duration"].(float64); !ok || e["remote_addr"] == "" {
+			t.Errorf("Expected duration and remote_addr for %s: %v", tc.path, e)
+		}
+	}
+	if e := entries[0]; e["route"] != "GET /items/{id}" || e["request_id"] != "req-7" || e["user_agent"] != "probe/1.0" {
+		t.Errorf("Expected route, request_id and user_agent: %v", e)
+	}
+	if entries[4]["hijacked"] != true {
+		t.Errorf("Expected the hijacked connection to be marked: %v", entries[4])
+	}
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+	handler := contextLogger.AccessLogMiddleware(log, contextLogger.AccessLogOptions{
+		SampleSuccess: 3,
+		Route:         func(r *http.Request) string { return "/orders/:id" },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+
+	for i := 0; i < 6; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/1?fail=1", nil))
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 2 sampled successes and the failure, got %d: %v", len(entries), entries)
+	}
+	if entries[2]["status"] != float64(502) || entries[0]["route"] != "/orders/:id" {
+		t.Errorf("Unexpected entries: %v", entries)
+	}
+}
+
+func TestAccessLogGeneratedRequestID(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+	keys := logger.ContextKeys{RequestIDKey: "request_id", RequestIDHeader: "X-Request-ID"}
+	handler := contextLogger.HTTPMiddlewareWithOptions(keys, contextLogger.HTTPMiddlewareOptions{
+		GenerateRequestID: func() string { return "generated-1" },
+	})(contextLogger.AccessLogMiddleware(log, contextLogger.AccessLogOptions{Context: keys})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", "from-header")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 2 || entries[0]["request_id"] != "generated-1" || entries[1]["request_id"] != "from-header" {
+		t.Errorf("Expected the generated and the header request IDs, got %v", entries)
+	}
+}
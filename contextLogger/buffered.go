@@ -0,0 +1,212 @@
+package contextLogger
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+)
+
+// BufferOptions configures BufferedMiddleware
+type BufferOptions struct {
+	// Capacity is the number of entries kept per request; older ones are dropped (default 1000)
+	Capacity int
+	// FlushLevel is the lowest level that flushes the buffer (default error)
+	FlushLevel logger.Level
+	// AccessLog logs one "http request" line per request, also when the buffer is discarded
+	AccessLog bool
+	// RequestIDHeader is read for the request_id field of the access log line
+	// when the request context carries no request ID (default "X-Request-ID")
+	RequestIDHeader string
+	// Context names the context key of the request ID, as in AccessLogOptions
+	Context logger.ContextKeys
+	// Clock stamps buffered entries with the buffered_at field (default logger.SystemClock)
+	Clock logger.Clock
+}
+
+// BufferedMiddleware holds every entry logged through the request context in
+// memory and only writes them to log's sinks when an entry at FlushLevel is
+// logged, the handler responds with a 5xx status or panics; otherwise they are
+// discarded. Entries are replayed in order, after the fact: their timestamps
+// and callers are those of the flush, and a buffered_at field tells when each
+// was logged. The level of log still applies, so build it at debug level to get
+// debug entries of failed requests.
+func BufferedMiddleware(log logger.Logger, opts BufferOptions) func(http.Handler) http.Handler {
+	if opts.Capacity <= 0 {
+		opts.Capacity = 1000
+	}
+	if opts.FlushLevel == "" {
+		opts.FlushLevel = logger.ErrorLevel
+	}
+	if opts.Clock == nil {
+		opts.Clock = logger.SystemClock
+	}
+	if opts.RequestIDHeader == "" {
+		opts.RequestIDHeader = "X-Request-ID"
+	}
+	idKey := requestIDKey(opts.Context)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			buf := &requestBuffer{capacity: opts.Capacity, flushAt: levelRank(opts.FlushLevel), clock: opts.Clock}
+			bl := &bufferedLogger{base: log, buf: buf}
+
+			// A panicking request is the one whose entries are needed most
+			defer func() {
+				if p := recover(); p != nil {
+					buf.flush()
+					panic(p)
+				}
+			}()
+
+			start := time.Now()
+			rw := &accessLogWriter{ResponseWriter: w}
+			next.ServeHTTP(rw, r.WithContext(WithLogger(r.Context(), bl)))
+
+			status := rw.status
+			if status == 0 && !rw.hijacked {
+				status = http.StatusOK
+			}
+			if status >= 500 {
+				buf.flush()
+			}
+			if opts.AccessLog {
+				fields := []logger.Field{
+					logger.F.String("method", r.Method),
+					logger.F.String("path", r.URL.Path),
+					logger.F.Int("status", status),
+					logger.F.Duration("duration", time.Since(start)),
+				}
+				if rid := requestID(r, idKey, opts.RequestIDHeader); rid != "" {
+					fields = append(fields, logger.F.String("request_id", rid))
+				}
+				log.WithContext(r.Context()).Log(statusLevel(status), "http request", fields...)
+			}
+		})
+	}
+}
+
+// bufferedEntry is an entry held until the buffer is flushed
+type bufferedEntry struct {
+	log    logger.Logger // Logger the entry was logged with, carrying its With fields
+	level  logger.Level
+	msg    string
+	fields []logger.Field
+	at     time.Time // When the entry was buffered
+}
+
+// requestBuffer is shared by the loggers derived for one request
+type requestBuffer struct {
+	mu       sync.Mutex
+	entries  []bufferedEntry
+	capacity int
+	flushAt  int
+	clock    logger.Clock
+	dropped  int
+	flushed  bool
+}
+
+// add holds e, or writes it when the buffer is already flushed or e flushes it
+func (b *requestBuffer) add(e bufferedEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.flushed && levelRank(e.level) < b.flushAt {
+		if len(b.entries) == b.capacity {
+			b.entries = b.entries[1:]
+			b.dropped++
+		}
+		e.at = b.clock.Now()
+		b.entries = append(b.entries, e)
+		return
+	}
+	b.flushLocked()
+	e.log.Log(e.level, e.msg, e.fields...)
+}
+
+func (b *requestBuffer) flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flushLocked()
+}
+
+func (b *requestBuffer) flushLocked() {
+	if b.flushed {
+		return
+	}
+	b.flushed = true
+	if b.dropped > 0 && len(b.entries) > 0 {
+		b.entries[0].log.Warn("buffered log entries dropped", logger.F.Int("dropped", b.dropped))
+	}
+	for _, e := range b.entries {
+		e.log.Log(e.level, e.msg, append(e.fields, logger.F.Time("buffered_at", e.at))...)
+	}
+	b.entries = nil
+}
+
+// bufferedLogger logs into a requestBuffer; base is the logger entries are
+// eventually written with
+type bufferedLogger struct {
+	base logger.Logger
+	buf  *requestBuffer
+}
+
+func (l *bufferedLogger) Debug(msg string, fields ...logger.Field) {
+	l.Log(logger.DebugLevel, msg, fields...)
+}
+
+func (l *bufferedLogger) Info(msg string, fields ...logger.Field) {
+	l.Log(logger.InfoLevel, msg, fields...)
+}
+
+func (l *bufferedLogger) Warn(msg string, fields ...logger.Field) {
+	l.Log(logger.WarnLevel, msg, fields...)
+}
+
+func (l *bufferedLogger) Error(msg string, fields ...logger.Field) {
+	l.Log(logger.ErrorLevel, msg, fields...)
+}
+
+func (l *bufferedLogger) DPanic(msg string, fields ...logger.Field) {
+	l.Log(logger.DPanicLevel, msg, fields...)
+}
+
+func (l *bufferedLogger) Fatal(msg string, fields ...logger.Field) {
+	l.Log(logger.FatalLevel, msg, fields...)
+}
+
+func (l *bufferedLogger) Log(level logger.Level, msg string, fields ...logger.Field) {
+	// Copy: the caller may reuse its slice before the entry is flushed
+	l.buf.add(bufferedEntry{log: l.base, level: level, msg: msg, fields: append([]logger.Field(nil), fields...)})
+}
+
+func (l *bufferedLogger) With(fields ...logger.Field) logger.Logger {
+	return &bufferedLogger{base: l.base.With(fields...), buf: l.buf}
+}
+
+func (l *bufferedLogger) WithContext(ctx context.Context) logger.Logger {
+	return &bufferedLogger{base: l.base.WithContext(ctx), buf: l.buf}
+}
+
+func (l *bufferedLogger) Named(name string) logger.Logger {
+	return &bufferedLogger{base: l.base.Named(name), buf: l.buf}
+}
+
+// Close leaves the sinks to the logger passed to BufferedMiddleware
+func (l *bufferedLogger) Close(ctx context.Context) error {
+	return nil
+}
+
+func levelRank(l logger.Level) int {
+	switch l {
+	case logger.DebugLevel:
+		return 0
+	case logger.InfoLevel:
+		return 1
+	case logger.WarnLevel:
+		return 2
+	default:
+		return 3
+	}
+}
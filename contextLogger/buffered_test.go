@@ -0,0 +1,170 @@
+package contextLogger_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/contextLogger"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+func TestBufferedMiddleware(t *testing.T) {
+	testCases := []struct {
+		name     string
+		path     string
+		wantMsgs []string
+	}{
+		{"Success", "/ok", []string{"http request"}},
+		{"ErrorEntry", "/error", []string{"start", "step", "failed", "after", "http request"}},
+		{"ServerError", "/5xx", []string{"start", "step", "http request"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logPath := filepath.Join(t.TempDir(), "app.log")
+			log, err := logger.NewProduction(
+				logger.WithConsoleDisabled(),
+				logger.WithFile(logger.FileSink{Path: logPath}),
+				logger.WithLevel(logger.DebugLevel),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+
+			handler := contextLogger.BufferedMiddleware(log, contextLogger.BufferOptions{AccessLog: true})(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					reqLog := contextLogger.FromContext(r.Context()).With(logger.F.String("handler", "test"))
+					reqLog.Debug("start")
+					contextLogger.Info(r.Context(), "step")
+					switch r.URL.Path {
+					case "/error":
+						reqLog.Error("failed")
+						reqLog.Info("after") // Written directly once flushed
+					case "/5xx":
+						w.WriteHeader(http.StatusServiceUnavailable)
+					}
+				}))
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if err := log.Close(context.Background()); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			entries := testutil.ReadJSONLines(t, logPath)
+			if len(entries) != len(tc.wantMsgs) {
+				t.Fatalf("Expected %v, got %v", tc.wantMsgs, entries)
+			}
+			for i, msg := range tc.wantMsgs {
+				if entries[i]["msg"] != msg {
+					t.Errorf("Entry %d: expected %q, got %v", i, msg, entries[i])
+				}
+			}
+			if len(entries) > 1 && entries[0]["handler"] != "test" {
+				t.Errorf("Expected With fields on replayed entries: %v", entries[0])
+			}
+		})
+	}
+}
+
+func TestBufferedMiddlewareCapacity(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+	handler := contextLogger.BufferedMiddleware(log, contextLogger.BufferOptions{Capacity: 2})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, msg := range []string{"one", "two", "three"} {
+				contextLogger.Warn(r.Context(), msg)
+			}
+			contextLogger.Error(r.Context(), "boom")
+		}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	want := []string{"buffered log entries dropped", "two", "three", "boom"}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, entries)
+	}
+	for i, msg := range want {
+		if entries[i]["msg"] != msg {
+			t.Errorf("Entry %d: expected %q, got %v", i, msg, entries[i])
+		}
+	}
+	if entries[0]["dropped"] != float64(1) {
+		t.Errorf("Expected one dropped entry: %v", entries[0])
+	}
+}
+
+func TestBufferedMiddlewareBufferedAt(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := testutil.NewFakeClock(start)
+	handler := contextLogger.BufferedMiddleware(log, contextLogger.BufferOptions{Clock: clock})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contextLogger.Warn(r.Context(), "buffered")
+			clock.Advance(time.Second)
+			contextLogger.Error(r.Context(), "boom")
+			contextLogger.Warn(r.Context(), "after")
+		}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %v", entries)
+	}
+	if got, want := entries[0]["buffered_at"], start.Format(time.RFC3339Nano); got != want {
+		t.Errorf("Expected the replayed entry buffered at %v, got %v", want, entries[0])
+	}
+	for _, e := range entries[1:] {
+		if _, ok := e["buffered_at"]; ok {
+			t.Errorf("Expected no buffered_at on entries written directly: %v", e)
+		}
+	}
+}
+
+func TestBufferedMiddlewarePanic(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+	handler := contextLogger.BufferedMiddleware(log, contextLogger.BufferOptions{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contextLogger.Info(r.Context(), "before panic")
+			panic("boom")
+		}))
+
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Errorf("Expected the panic to propagate, got %v", p)
+			}
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if entries := testutil.ReadJSONLines(t, logPath); len(entries) != 1 || entries[0]["msg"] != "before panic" {
+		t.Errorf("Expected the buffered entry flushed on panic, got %v", entries)
+	}
+}
+
+func TestBufferedMiddlewareAccessLogRequestID(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+	handler := contextLogger.BufferedMiddleware(log, contextLogger.BufferOptions{AccessLog: true})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", "req-9")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if entries := testutil.ReadJSONLines(t, logPath); len(entries) != 1 || entries[0]["request_id"] != "req-9" {
+		t.Errorf("Expected request_id on the access log line, got %v", entries)
+	}
+}
@@ -3,11 +3,11 @@ package contextLogger
 import (
 	"context"
 	"net/http"
-	"sync"
+	"sync/atomic"
 
 	logger "github.com/HoangAnhNguyen269/loggerkit"
 	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
-	"go.opentelemetry.io/otel/trace"
+	"github.com/google/uuid"
 )
 
 type ctxKey struct{}
@@ -22,73 +22,157 @@ func ContextWithLogger(ctx context.Context, log logger.Logger) context.Context {
 	return WithLogger(ctx, log)
 }
 
+// attachedLogger is a logger stored by Attach, already enriched with its context
+type attachedLogger struct {
+	logger.Logger
+	helper logger.Logger // Logger with the caller skip of the package-level helpers
+}
+
+// Attach stores log enriched with the fields of ctx, so FromContext returns it
+// without extracting them again on every call. Values or spans added to the
+// context afterwards are not picked up; use WithLogger for that. A nil log
+// attaches the fallback logger.
+func Attach(ctx context.Context, log logger.Logger) context.Context {
+	if log == nil {
+		log = getFallback()
+	}
+	enriched := log.WithContext(ctx)
+	return context.WithValue(ctx, ctxKey{}, attachedLogger{Logger: enriched, helper: skipCaller(enriched)})
+}
+
 // FromContext retrieves Logger from context, falls back to default if none found
 func FromContext(ctx context.Context) logger.Logger {
-	if l, ok := ctx.Value(ctxKey{}).(logger.Logger); ok && l != nil {
-		return l.WithContext(ctx)
+	switch l := ctx.Value(ctxKey{}).(type) {
+	case attachedLogger:
+		return l.Logger
+	case logger.Logger:
+		if l != nil {
+			return l.WithContext(ctx)
+		}
 	}
 	return getFallback().WithContext(ctx)
 }
 
 // --- Fallback (zapx) as lazy singleton ---
-var (
-	fbOnce   sync.Once
-	fbMu     sync.RWMutex
-	fbLogger logger.Logger // may be set by SetFallbackLogger; else lazy zapx default
-)
 
-// Allow app/tests to override fallback (e.g., nop logger in tests)
+// fallbackLogger is the logger FromContext uses for contexts without one
+type fallbackLogger struct {
+	logger.Logger
+	owned bool // Created lazily by this package, which closes it
+}
+
+var fallback atomic.Pointer[fallbackLogger]
+
+// SetFallbackLogger makes FromContext use l for contexts without a logger,
+// e.g. a nop logger in tests; nil goes back to the lazily created zapx
+// default. A default created before is closed; l is never closed by this
+// package.
 func SetFallbackLogger(l logger.Logger) {
-	fbMu.Lock()
-	fbLogger = l
-	fbMu.Unlock()
+	var next *fallbackLogger
+	if l != nil {
+		next = &fallbackLogger{Logger: l}
+	}
+	closeOwned(context.Background(), fallback.Swap(next))
+}
+
+// ResetFallback drops the fallback logger, closing it if this package created
+// it, so the next FromContext creates a fresh default. For tests.
+func ResetFallback() {
+	closeOwned(context.Background(), fallback.Swap(nil))
 }
 
 func getFallback() logger.Logger {
-	fbMu.RLock()
-	l := fbLogger
-	fbMu.RUnlock()
-	if l != nil {
-		return l
+	for {
+		if fb := fallback.Load(); fb != nil {
+			return fb.Logger
+		}
+		created := &fallbackLogger{Logger: zapx.NewDefaultLogger(), owned: true}
+		if fallback.CompareAndSwap(nil, created) {
+			return created.Logger
+		}
+		// Lost to a concurrent getFallback or SetFallbackLogger
+		closeOwned(context.Background(), created)
 	}
-	fbOnce.Do(func() {
-		// create exactly once
-		fbMu.Lock()
-		fbLogger = zapx.NewDefaultLogger()
-		fbMu.Unlock()
-	})
-	fbMu.RLock()
-	l = fbLogger
-	fbMu.RUnlock()
-	return l
-}
-
-// Optional: let app close fallback on shutdown
+}
+
+// CloseFallback closes the default fallback logger if one was created; the next
+// FromContext without a logger creates another. Loggers set through
+// SetFallbackLogger are left to their owner.
 func CloseFallback(ctx context.Context) error {
-	fbMu.RLock()
-	l := fbLogger
-	fbMu.RUnlock()
-	if l != nil {
-		return l.Close(ctx)
+	fb := fallback.Load()
+	if fb == nil || !fb.owned || !fallback.CompareAndSwap(fb, nil) {
+		return nil
 	}
-	return nil
+	return fb.Close(ctx)
 }
 
-// HTTPMiddleware creates middleware that extracts request/user IDs from headers and traces
+// closeOwned closes fb when this package created it
+func closeOwned(ctx context.Context, fb *fallbackLogger) {
+	if fb != nil && fb.owned {
+		_ = fb.Close(ctx)
+	}
+}
+
+// HTTPMiddlewareOptions configures HTTPMiddlewareWithOptions
+type HTTPMiddlewareOptions struct {
+	// GenerateRequestID creates the request ID when the request carries none,
+	// e.g. NewRequestID. Nil leaves such requests without one.
+	GenerateRequestID func() string
+	// EchoResponseHeader sets the request ID header on the response
+	EchoResponseHeader bool
+}
+
+// NewRequestID returns a random UUIDv4, for HTTPMiddlewareOptions.GenerateRequestID
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// HTTPMiddleware creates middleware that stores the headers named by
+// contextKeys.Headers (and the request/user ID headers) under their fields'
+// context keys. It also reads the W3C traceparent/tracestate headers, so
+// WithContext adds trace_id and span_id even without the OTel SDK middleware.
 func HTTPMiddleware(contextKeys logger.ContextKeys) func(http.Handler) http.Handler {
+	return HTTPMiddlewareWithOptions(contextKeys, HTTPMiddlewareOptions{})
+}
+
+// HTTPMiddlewareWithOptions is HTTPMiddleware with request ID generation and
+// echoing. Both apply to the "request_id" field and need a context key for it;
+// the header defaults to "X-Request-ID".
+func HTTPMiddlewareWithOptions(contextKeys logger.ContextKeys, opts HTTPMiddlewareOptions) func(http.Handler) http.Handler {
+	var mappings []logger.ContextMapping
+	var requestID *logger.ContextMapping
+	for _, m := range contextKeys.Mappings() {
+		if m.Field == "request_id" {
+			if m.Header == "" {
+				m.Header = "X-Request-ID"
+			}
+			requestID = &m
+			continue
+		}
+		if m.Header != "" {
+			mappings = append(mappings, m)
+		}
+	}
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx := r.Context()
+			ctx := withRemoteSpan(r.Context(), r.Header)
 
-			if contextKeys.RequestIDKey != nil && contextKeys.RequestIDHeader != "" {
-				if requestID := r.Header.Get(contextKeys.RequestIDHeader); requestID != "" {
-					ctx = context.WithValue(ctx, contextKeys.RequestIDKey, requestID)
+			if requestID != nil {
+				id := r.Header.Get(requestID.Header)
+				if id == "" && opts.GenerateRequestID != nil {
+					id = opts.GenerateRequestID()
+				}
+				if id != "" {
+					ctx = context.WithValue(ctx, requestID.Key, id)
+					if opts.EchoResponseHeader {
+						w.Header().Set(requestID.Header, id)
+					}
 				}
 			}
 
-			if contextKeys.UserIDKey != nil && contextKeys.UserIDHeader != "" {
-				if userID := r.Header.Get(contextKeys.UserIDHeader); userID != "" {
-					ctx = context.WithValue(ctx, contextKeys.UserIDKey, userID)
+			for _, m := range mappings {
+				if v := r.Header.Get(m.Header); v != "" {
+					ctx = context.WithValue(ctx, m.Key, v)
 				}
 			}
 
@@ -108,35 +192,40 @@ func DefaultHTTPMiddleware() func(http.Handler) http.Handler {
 	return HTTPMiddleware(contextKeys)
 }
 
-// ExtractTraceFields extracts trace information from context as fields
-func ExtractTraceFields(ctx context.Context) []logger.Field {
-	var fields []logger.Field
-
-	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
-		fields = append(fields,
-			logger.F.String("trace_id", sc.TraceID().String()),
-			logger.F.String("span_id", sc.SpanID().String()),
-		)
+// ExtractTraceFields extracts trace_id, span_id and trace_sampled from context as
+// fields, under the names configured in contextKeys when given
+func ExtractTraceFields(ctx context.Context, contextKeys ...logger.ContextKeys) []logger.Field {
+	var keys logger.ContextKeys
+	if len(contextKeys) > 0 {
+		keys = contextKeys[0]
 	}
-
-	return fields
+	return keys.AppendTraceFields(nil, ctx)
 }
 
-// ExtractRequestFields extracts request/user ID fields from context
+// ExtractRequestFields extracts the fields mapped by contextKeys, sorted by field
+// name, followed by the baggage fields and those of contextKeys.Extractors.
+// Extractor panics are counted in the metrics of the logger stored in ctx, and
+// not at all without one or when its metrics are disabled.
 func ExtractRequestFields(ctx context.Context, contextKeys logger.ContextKeys) []logger.Field {
 	var fields []logger.Field
 
-	if contextKeys.RequestIDKey != nil {
-		if rid := ctx.Value(contextKeys.RequestIDKey); rid != nil {
-			fields = append(fields, logger.F.Any("request_id", rid))
+	for _, m := range contextKeys.Mappings() {
+		if v := ctx.Value(m.Key); v != nil {
+			fields = append(fields, logger.F.Any(m.Field, v))
 		}
 	}
 
-	if contextKeys.UserIDKey != nil {
-		if uid := ctx.Value(contextKeys.UserIDKey); uid != nil {
-			fields = append(fields, logger.F.Any("user_id", uid))
-		}
-	}
+	fields = append(fields, contextKeys.BaggageFields(ctx)...)
+	return append(fields, contextKeys.RunExtractors(ctx, contextMetrics(ctx))...)
+}
 
-	return fields
+// contextMetrics is the metrics of the logger stored in ctx, nil without one
+func contextMetrics(ctx context.Context) *logger.Metrics {
+	switch l := ctx.Value(ctxKey{}).(type) {
+	case attachedLogger:
+		return logger.MetricsOf(l.Logger)
+	case logger.Logger:
+		return logger.MetricsOf(l)
+	}
+	return nil
 }
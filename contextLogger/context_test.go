@@ -4,11 +4,15 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
 
 	logger "github.com/HoangAnhNguyen269/loggerkit"
 	"github.com/HoangAnhNguyen269/loggerkit/contextLogger"
 	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx" // Import to register the builder
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestWithLogger(t *testing.T) {
@@ -45,6 +49,33 @@ func TestContextWithLogger(t *testing.T) {
 	}
 }
 
+func TestAttach(t *testing.T) {
+	keys := logger.ContextKeys{RequestIDKey: "request_id"}
+	log, logPath := testutil.FileLogger(t, logger.WithContext(keys))
+
+	ctx := contextLogger.Attach(context.WithValue(context.Background(), "request_id", "req-1"), log)
+	ctx = context.WithValue(ctx, "other", "value") // Derived contexts keep the attached logger
+	contextLogger.FromContext(ctx).Info("first")
+	contextLogger.FromContext(ctx).Info("second")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	raw, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", raw)
+	}
+	for _, line := range lines {
+		if strings.Count(line, `"request_id":"req-1"`) != 1 {
+			t.Errorf("Expected request_id exactly once: %s", line)
+		}
+	}
+}
+
 func TestFromContextFallback(t *testing.T) {
 	// Test fallback behavior when no logger in context
 	ctx := context.Background()
@@ -133,6 +164,139 @@ func TestDefaultHTTPMiddleware(t *testing.T) {
 	}
 }
 
+func TestHTTPMiddlewareTraceparent(t *testing.T) {
+	testCases := []struct {
+		name        string
+		traceparent string
+		traceID     string
+		spanID      string
+	}{
+		{"Valid", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"},
+		{"FutureVersion", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"},
+		{"Uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01", "", ""},
+		{"ZeroTraceID", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", "", ""},
+		{"ForbiddenVersion", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "", ""},
+		{"TrailingData", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-xx", "", ""},
+		{"Garbage", "not-a-traceparent", "", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			log, logPath := testutil.FileLogger(t)
+			handler := contextLogger.DefaultHTTPMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				log.WithContext(r.Context()).Info("handled")
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("traceparent", tc.traceparent)
+			req.Header.Set("tracestate", "vendor=value")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			if err := log.Close(context.Background()); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			entries := testutil.ReadJSONLines(t, logPath)
+			if len(entries) != 1 {
+				t.Fatalf("Expected one entry, got %v", entries)
+			}
+			traceID, _ := entries[0]["trace_id"].(string)
+			spanID, _ := entries[0]["span_id"].(string)
+			if traceID != tc.traceID || spanID != tc.spanID {
+				t.Errorf("Expected trace_id=%q span_id=%q, got %v", tc.traceID, tc.spanID, entries[0])
+			}
+		})
+	}
+}
+
+func TestHTTPMiddlewareCustomKeys(t *testing.T) {
+	type ctxKey string
+	keys := logger.ContextKeys{
+		RequestIDKey:    ctxKey("rid"),
+		RequestIDHeader: "X-Request-ID",
+		Keys: map[string]any{
+			"tenant_id":  ctxKey("tenant"),
+			"session_id": ctxKey("session"),
+			"client":     ctxKey("client"),
+		},
+		Headers: map[string]string{
+			"tenant_id":  "X-Tenant-ID",
+			"session_id": "X-Session-ID",
+			"client":     "X-Client",
+		},
+	}
+	log, logPath := testutil.FileLogger(t, logger.WithContext(keys))
+	handler := contextLogger.HTTPMiddleware(keys)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.WithContext(r.Context()).Info("handled")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	req.Header.Set("X-Tenant-ID", "acme")
+	req.Header.Set("X-Session-ID", "sess-2")
+	req.Header.Set("X-Client", "cli/1.0")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	raw, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	want := `"client":"cli/1.0","request_id":"req-1","session_id":"sess-2","tenant_id":"acme"`
+	if !strings.Contains(string(raw), want) {
+		t.Errorf("Expected mapped fields sorted by name (%s), got %s", want, raw)
+	}
+
+	ctx := context.WithValue(context.Background(), ctxKey("tenant"), "acme")
+	ctx = context.WithValue(ctx, ctxKey("client"), "cli/1.0")
+	fields := contextLogger.ExtractRequestFields(ctx, keys)
+	if len(fields) != 2 || fields[0].Key != "client" || fields[1].Key != "tenant_id" {
+		t.Errorf("Expected client and tenant_id in order, got %v", fields)
+	}
+}
+
+func TestHTTPMiddlewareRequestID(t *testing.T) {
+	keys := logger.ContextKeys{RequestIDKey: "request_id", RequestIDHeader: "X-Request-ID"}
+	testCases := []struct {
+		name     string
+		incoming string
+		opts     contextLogger.HTTPMiddlewareOptions
+		wantID   string
+		wantEcho string
+	}{
+		{"Generated", "", contextLogger.HTTPMiddlewareOptions{GenerateRequestID: func() string { return "gen-1" }, EchoResponseHeader: true}, "gen-1", "gen-1"},
+		{"PassThrough", "req-1", contextLogger.HTTPMiddlewareOptions{GenerateRequestID: func() string { return "gen-1" }, EchoResponseHeader: true}, "req-1", "req-1"},
+		{"NoEcho", "", contextLogger.HTTPMiddlewareOptions{GenerateRequestID: func() string { return "gen-1" }}, "gen-1", ""},
+		{"NoGenerator", "", contextLogger.HTTPMiddlewareOptions{EchoResponseHeader: true}, "", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got any
+			handler := contextLogger.HTTPMiddlewareWithOptions(keys, tc.opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value("request_id")
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.incoming != "" {
+				req.Header.Set("X-Request-ID", tc.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if id, _ := got.(string); id != tc.wantID {
+				t.Errorf("Expected request ID %q in context, got %v", tc.wantID, got)
+			}
+			if echo := rec.Header().Get("X-Request-ID"); echo != tc.wantEcho {
+				t.Errorf("Expected response header %q, got %q", tc.wantEcho, echo)
+			}
+		})
+	}
+
+	if a, b := contextLogger.NewRequestID(), contextLogger.NewRequestID(); len(a) != 36 || a == b {
+		t.Errorf("Expected distinct UUIDs, got %q and %q", a, b)
+	}
+}
+
 func TestExtractTraceFields(t *testing.T) {
 	ctx := context.Background()
 
@@ -142,8 +306,20 @@ func TestExtractTraceFields(t *testing.T) {
 		t.Errorf("Expected no fields for empty context, got %d", len(fields))
 	}
 
-	// Note: Testing with actual OpenTelemetry traces would require more setup
-	// This test verifies the function doesn't panic with empty context
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	fields = contextLogger.ExtractTraceFields(ctx)
+	if len(fields) != 3 || fields[2].Key != "trace_sampled" || fields[2].Val != true {
+		t.Errorf("Expected trace_id, span_id and trace_sampled, got %v", fields)
+	}
+
+	fields = contextLogger.ExtractTraceFields(ctx, logger.ContextKeys{TraceIDField: "traceId", TraceFlagsField: "traceSampled"})
+	if len(fields) != 3 || fields[0].Key != "traceId" || fields[1].Key != "span_id" || fields[2].Key != "traceSampled" {
+		t.Errorf("Expected traceId, span_id and traceSampled, got %v", fields)
+	}
 }
 
 func TestExtractRequestFields(t *testing.T) {
@@ -168,3 +344,26 @@ func TestExtractRequestFields(t *testing.T) {
 		t.Errorf("Expected 2 fields, got %d", len(fields))
 	}
 }
+
+func BenchmarkFromContext(b *testing.B) {
+	keys := logger.ContextKeys{RequestIDKey: "request_id", UserIDKey: "user_id"}
+	log, _ := testutil.FileLogger(b, logger.WithContext(keys))
+
+	ctx := context.WithValue(context.Background(), "request_id", "req-123")
+	ctx = context.WithValue(ctx, "user_id", "user-456")
+
+	b.Run("WithLogger", func(b *testing.B) {
+		ctx := contextLogger.WithLogger(ctx, log)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			contextLogger.FromContext(ctx).Info("message")
+		}
+	})
+	b.Run("Attach", func(b *testing.B) {
+		ctx := contextLogger.Attach(ctx, log)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			contextLogger.FromContext(ctx).Info("message")
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package contextLogger_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/contextLogger"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+// closeTracker is a nop logger that counts its Close calls
+type closeTracker struct {
+	logger.Logger
+	closes atomic.Int32
+}
+
+func newCloseTracker(t *testing.T) *closeTracker {
+	t.Helper()
+	log, err := logger.NewProduction(logger.WithProvider("nop"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	return &closeTracker{Logger: log}
+}
+
+func (c *closeTracker) WithContext(context.Context) logger.Logger { return c }
+
+func (c *closeTracker) Close(context.Context) error {
+	c.closes.Add(1)
+	return nil
+}
+
+func TestSetFallbackLogger(t *testing.T) {
+	contextLogger.ResetFallback()
+	t.Cleanup(contextLogger.ResetFallback)
+	ctx := context.Background()
+
+	// The lazily created default is closed once replaced
+	created := contextLogger.FromContext(ctx)
+	first := newCloseTracker(t)
+	contextLogger.SetFallbackLogger(first)
+	output, err := testutil.CaptureStdout(func() { created.Info("After replacement") })
+	if err != nil {
+		t.Fatalf("Failed to capture stdout: %v", err)
+	}
+	if output != "" {
+		t.Errorf("Expected the replaced default fallback to be closed, got %q", output)
+	}
+	if got := contextLogger.FromContext(ctx); got != first {
+		t.Fatalf("Expected the logger set as fallback, got %T", got)
+	}
+
+	// Loggers set by the application are never closed by the package
+	second := newCloseTracker(t)
+	contextLogger.SetFallbackLogger(second)
+	if err := contextLogger.CloseFallback(ctx); err != nil {
+		t.Fatalf("CloseFallback failed: %v", err)
+	}
+	contextLogger.SetFallbackLogger(nil)
+	if first.closes.Load() != 0 || second.closes.Load() != 0 {
+		t.Errorf("Expected set fallbacks to stay open, got %d and %d closes", first.closes.Load(), second.closes.Load())
+	}
+	if contextLogger.FromContext(ctx) == nil {
+		t.Error("Expected a default fallback after setting nil")
+	}
+}
+
+func TestCloseFallbackRecreatesDefault(t *testing.T) {
+	contextLogger.ResetFallback()
+	t.Cleanup(contextLogger.ResetFallback)
+	ctx := context.Background()
+
+	closed := contextLogger.FromContext(ctx)
+	if err := contextLogger.CloseFallback(ctx); err != nil {
+		t.Fatalf("CloseFallback failed: %v", err)
+	}
+	output, err := testutil.CaptureStdout(func() {
+		closed.Info("Through the closed fallback")
+		contextLogger.FromContext(ctx).Info("Through a new fallback")
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture stdout: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(output), "\n"); len(lines) != 1 || !strings.Contains(output, "new fallback") {
+		t.Errorf("Expected only the new fallback to write, got %q", output)
+	}
+}
+
+func TestFallbackConcurrentSet(t *testing.T) {
+	contextLogger.ResetFallback()
+	t.Cleanup(contextLogger.ResetFallback)
+	ctx := context.Background()
+
+	trackers := make([]*closeTracker, 8)
+	for i := range trackers {
+		trackers[i] = newCloseTracker(t)
+	}
+	contextLogger.SetFallbackLogger(trackers[0])
+
+	var wg sync.WaitGroup
+	for i := range trackers {
+		wg.Add(2)
+		go func(tracker *closeTracker) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				contextLogger.SetFallbackLogger(tracker)
+			}
+		}(trackers[i])
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if log := contextLogger.FromContext(ctx); log == nil {
+					t.Error("Expected a fallback logger")
+					return
+				}
+				_ = contextLogger.CloseFallback(ctx)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// With only set loggers in play the lazy default never takes over
+	last := contextLogger.FromContext(ctx)
+	found := false
+	for _, tracker := range trackers {
+		if last == tracker {
+			found = true
+		}
+		if n := tracker.closes.Load(); n != 0 {
+			t.Errorf("Expected set fallbacks to stay open, got %d closes", n)
+		}
+	}
+	if !found {
+		t.Errorf("Expected one of the set loggers as fallback, got %T", last)
+	}
+}
@@ -0,0 +1,114 @@
+package contextLogger
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCOption configures the gRPC server interceptors
+type GRPCOption func(*grpcConfig)
+
+type grpcConfig struct {
+	log       logger.Logger
+	accessLog bool
+}
+
+// WithGRPCLogger attaches log to every RPC context, so FromContext returns it in handlers
+func WithGRPCLogger(log logger.Logger) GRPCOption {
+	return func(c *grpcConfig) {
+		c.log = log
+	}
+}
+
+// WithGRPCAccessLog logs one entry per RPC with its method, status code and
+// duration; failed RPCs are logged at error level
+func WithGRPCAccessLog() GRPCOption {
+	return func(c *grpcConfig) {
+		c.accessLog = true
+	}
+}
+
+// UnaryServerInterceptor is the gRPC counterpart of HTTPMiddleware: it reads the
+// incoming metadata keys named by the ContextKeys headers and stores them in the
+// RPC context
+func UnaryServerInterceptor(contextKeys logger.ContextKeys, opts ...GRPCOption) grpc.UnaryServerInterceptor {
+	config := newGRPCConfig(opts)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx = config.rpcContext(ctx, contextKeys)
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		config.logRPC(ctx, "unary", info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming RPCs
+func StreamServerInterceptor(contextKeys logger.ContextKeys, opts ...GRPCOption) grpc.StreamServerInterceptor {
+	config := newGRPCConfig(opts)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := config.rpcContext(ss.Context(), contextKeys)
+		start := time.Now()
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		config.logRPC(ctx, "stream", info.FullMethod, start, err)
+		return err
+	}
+}
+
+func newGRPCConfig(opts []GRPCOption) grpcConfig {
+	var config grpcConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+func (c grpcConfig) rpcContext(ctx context.Context, contextKeys logger.ContextKeys) context.Context {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, m := range contextKeys.Mappings() {
+			if m.Header == "" {
+				continue
+			}
+			if vals := md.Get(m.Header); len(vals) > 0 && vals[0] != "" {
+				ctx = context.WithValue(ctx, m.Key, vals[0])
+			}
+		}
+	}
+	if c.log != nil {
+		ctx = WithLogger(ctx, c.log)
+	}
+	return ctx
+}
+
+func (c grpcConfig) logRPC(ctx context.Context, kind, method string, start time.Time, err error) {
+	if !c.accessLog {
+		return
+	}
+	code := status.Code(err)
+	fields := []logger.Field{
+		logger.F.String("method", method),
+		logger.F.String("code", code.String()),
+		logger.F.Duration("duration", time.Since(start)),
+	}
+	log := FromContext(ctx)
+	if code != codes.OK {
+		log.Error("grpc "+kind+" call failed", append(fields, logger.F.Err(err))...)
+		return
+	}
+	log.Info("grpc "+kind+" call", fields...)
+}
+
+// contextStream serves the interceptor's context to stream handlers
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
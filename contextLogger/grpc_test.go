@@ -0,0 +1,126 @@
+package contextLogger_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/contextLogger"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testHealthServer logs through the context logger in every handler
+type testHealthServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (s *testHealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	contextLogger.FromContext(ctx).Info("checking", logger.F.String("service", req.Service))
+	if req.Service == "missing" {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *testHealthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	contextLogger.FromContext(stream.Context()).Info("watching")
+	return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+}
+
+func TestGRPCInterceptors(t *testing.T) {
+	keys := logger.ContextKeys{
+		RequestIDKey:    "request_id",
+		UserIDKey:       "user_id",
+		RequestIDHeader: "X-Request-ID",
+		UserIDHeader:    "X-User-ID",
+	}
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithContext(keys),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(contextLogger.UnaryServerInterceptor(keys, contextLogger.WithGRPCLogger(log), contextLogger.WithGRPCAccessLog())),
+		grpc.StreamInterceptor(contextLogger.StreamServerInterceptor(keys, contextLogger.WithGRPCLogger(log), contextLogger.WithGRPCAccessLog())),
+	)
+	healthpb.RegisterHealthServer(srv, &testHealthServer{})
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-1", "x-user-id", "user-9")
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "missing"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected NotFound, got %v", err)
+	}
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	stream.Recv() // Wait for the stream to end
+	srv.GracefulStop()
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 6 {
+		t.Fatalf("Expected a handler and an access entry per RPC, got %d: %v", len(entries), entries)
+	}
+	for _, entry := range entries {
+		if entry["request_id"] != "req-1" || entry["user_id"] != "user-9" {
+			t.Errorf("Expected request and user IDs from metadata: %v", entry)
+		}
+	}
+
+	testCases := []struct {
+		entry  map[string]any
+		level  string
+		method string
+		code   string
+	}{
+		{entries[1], "info", "/grpc.health.v1.Health/Check", "OK"},
+		{entries[3], "error", "/grpc.health.v1.Health/Check", "NotFound"},
+		{entries[5], "info", "/grpc.health.v1.Health/Watch", "OK"},
+	}
+	for _, tc := range testCases {
+		if tc.entry["level"] != tc.level || tc.entry["method"] != tc.method || tc.entry["code"] != tc.code {
+			t.Errorf("Unexpected access entry: %v", tc.entry)
+		}
+		if _, ok := tc.entry["duration"].(float64); !ok {
+			t.Errorf("Expected a duration: %v", tc.entry)
+		}
+	}
+	if entries[3]["error"] != "rpc error: code = NotFound desc = unknown service" {
+		t.Errorf("Expected the RPC error on the failed call: %v", entries[3])
+	}
+}
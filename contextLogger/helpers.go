@@ -0,0 +1,55 @@
+package contextLogger
+
+import (
+	"context"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+)
+
+// callerSkipper is implemented by loggers that can attribute entries to the
+// caller of a wrapper function
+type callerSkipper interface {
+	WithCallerSkip(delta int) logger.Logger
+}
+
+// skipCaller makes log report the caller of the package-level helpers
+func skipCaller(log logger.Logger) logger.Logger {
+	if cs, ok := log.(callerSkipper); ok {
+		return cs.WithCallerSkip(1)
+	}
+	return log
+}
+
+// helperLogger resolves the logger of ctx like FromContext, reusing the one
+// prepared by Attach when there is one
+func helperLogger(ctx context.Context) logger.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(attachedLogger); ok {
+		return l.helper
+	}
+	return skipCaller(FromContext(ctx))
+}
+
+// Debug logs at debug level with the logger and fields of ctx
+func Debug(ctx context.Context, msg string, fields ...logger.Field) {
+	helperLogger(ctx).Debug(msg, fields...)
+}
+
+// Info logs at info level with the logger and fields of ctx
+func Info(ctx context.Context, msg string, fields ...logger.Field) {
+	helperLogger(ctx).Info(msg, fields...)
+}
+
+// Warn logs at warn level with the logger and fields of ctx
+func Warn(ctx context.Context, msg string, fields ...logger.Field) {
+	helperLogger(ctx).Warn(msg, fields...)
+}
+
+// Error logs at error level with the logger and fields of ctx
+func Error(ctx context.Context, msg string, fields ...logger.Field) {
+	helperLogger(ctx).Error(msg, fields...)
+}
+
+// Err logs err at error level with the logger and fields of ctx
+func Err(ctx context.Context, err error, msg string, fields ...logger.Field) {
+	helperLogger(ctx).Error(msg, append(fields[:len(fields):len(fields)], logger.F.Err(err))...)
+}
@@ -0,0 +1,105 @@
+package contextLogger_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/contextLogger"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+func TestPackageLevelHelpers(t *testing.T) {
+	keys := logger.ContextKeys{RequestIDKey: "request_id"}
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithContext(keys),
+		logger.WithLevel(logger.DebugLevel),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), "request_id", "req-1")
+	stored := contextLogger.WithLogger(ctx, log)
+	attached := contextLogger.Attach(ctx, log)
+	for _, ctx := range []context.Context{stored, attached} {
+		contextLogger.Debug(ctx, "debug")
+		contextLogger.Info(ctx, "info", logger.F.String("k", "v"))
+		contextLogger.Warn(ctx, "warn")
+		contextLogger.Error(ctx, "error")
+		contextLogger.Err(ctx, errors.New("boom"), "failed")
+	}
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 10 {
+		t.Fatalf("Expected 10 entries, got %d: %v", len(entries), entries)
+	}
+	levels := []string{"debug", "info", "warn", "error", "error"}
+	for i, entry := range entries {
+		if entry["request_id"] != "req-1" || entry["level"] != levels[i%5] {
+			t.Errorf("Expected request_id at %s level: %v", levels[i%5], entry)
+		}
+		if caller, _ := entry["caller"].(string); !strings.HasPrefix(caller, "contextLogger/helpers_test.go:") {
+			t.Errorf("Expected the test as caller, got %q", caller)
+		}
+	}
+	if entries[4]["error"] != "boom" || entries[9]["error"] != "boom" || entries[1]["k"] != "v" {
+		t.Errorf("Expected the error and extra fields: %v", entries)
+	}
+}
+
+func TestErrKeepsCallerFields(t *testing.T) {
+	log, _ := testutil.FileLogger(t)
+	defer log.Close(context.Background())
+	ctx := contextLogger.WithLogger(context.Background(), log)
+
+	fields := make([]logger.Field, 1, 2)
+	fields[0] = logger.F.String("k", "v")
+	spare := fields[:2]
+	contextLogger.Err(ctx, errors.New("boom"), "failed", fields...)
+	if spare[1] != (logger.Field{}) {
+		t.Errorf("Expected the caller's spare capacity untouched, got %v", spare[1])
+	}
+}
+
+// logThroughFromContext is application code logging through whatever logger
+// ctx carries
+func logThroughFromContext(ctx context.Context, msg string) {
+	contextLogger.FromContext(ctx).Info(msg)
+}
+
+func TestFallbackCaller(t *testing.T) {
+	output, err := testutil.CaptureStdout(func() {
+		ctx := context.Background() // No logger attached: the fallback logs
+		logThroughFromContext(ctx, "from helper")
+		contextLogger.FromContext(ctx).With(logger.F.String("k", "v")).Info("derived")
+		contextLogger.Info(ctx, "package-level helper")
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture stdout: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 fallback entries, got %q", output)
+	}
+	for _, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to parse %q: %v", line, err)
+		}
+		if caller, _ := entry["caller"].(string); !strings.HasPrefix(caller, "contextLogger/helpers_test.go:") {
+			t.Errorf("Expected the call site in the test file for %q, got %q", entry["msg"], caller)
+		}
+	}
+}
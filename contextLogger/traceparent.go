@@ -0,0 +1,66 @@
+package contextLogger
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// withRemoteSpan attaches the span described by the W3C traceparent and
+// tracestate headers, unless ctx already carries a span (e.g. from OTel
+// middleware). Invalid headers are ignored.
+func withRemoteSpan(ctx context.Context, h http.Header) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	sc, ok := parseTraceparent(h.Get("traceparent"))
+	if !ok {
+		return ctx
+	}
+	if ts, err := trace.ParseTraceState(h.Get("tracestate")); err == nil {
+		sc = sc.WithTraceState(ts)
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// parseTraceparent parses version-traceid-parentid-flags as specified by W3C
+// Trace Context. Later versions may append fields, which are skipped.
+func parseTraceparent(v string) (trace.SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 {
+		return trace.SpanContext{}, false
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if len(version) != 2 || !isLowerHex(version) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return trace.SpanContext{}, false
+	}
+	if len(traceID) != 32 || len(spanID) != 16 || len(flags) != 2 ||
+		!isLowerHex(traceID) || !isLowerHex(spanID) || !isLowerHex(flags) {
+		return trace.SpanContext{}, false
+	}
+
+	var cfg trace.SpanContextConfig
+	hex.Decode(cfg.TraceID[:], []byte(traceID))
+	hex.Decode(cfg.SpanID[:], []byte(spanID))
+	var f [1]byte
+	hex.Decode(f[:], []byte(flags))
+	cfg.TraceFlags = trace.TraceFlags(f[0]) & trace.FlagsSampled
+	cfg.Remote = true
+
+	// All-zero IDs are invalid
+	sc := trace.NewSpanContext(cfg)
+	return sc, sc.IsValid()
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"context"
+	"sort"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Default names of the trace context fields added by WithContext
+const (
+	DefaultTraceIDField    = "trace_id"
+	DefaultSpanIDField     = "span_id"
+	DefaultTraceFlagsField = "trace_sampled"
+)
+
+// ContextMapping wires one field: the middleware copies Header into the context
+// under Key, and WithContext logs the value found under Key as Field
+type ContextMapping struct {
+	Field  string
+	Key    any
+	Header string // Empty when the value is not read from a header
+}
+
+// Mappings returns the field table of Keys and Headers, including the
+// RequestIDKey and UserIDKey shorthand, sorted by field name. Fields without a
+// context key are left out.
+func (k ContextKeys) Mappings() []ContextMapping {
+	keys := make(map[string]any, len(k.Keys)+2)
+	headers := make(map[string]string, len(k.Headers)+2)
+	if k.RequestIDKey != nil {
+		keys["request_id"], headers["request_id"] = k.RequestIDKey, k.RequestIDHeader
+	}
+	if k.UserIDKey != nil {
+		keys["user_id"], headers["user_id"] = k.UserIDKey, k.UserIDHeader
+	}
+	for field, key := range k.Keys {
+		keys[field] = key
+	}
+	for field, header := range k.Headers {
+		headers[field] = header
+	}
+
+	mappings := make([]ContextMapping, 0, len(keys))
+	for field, key := range keys {
+		if key != nil {
+			mappings = append(mappings, ContextMapping{Field: field, Key: key, Header: headers[field]})
+		}
+	}
+	sort.Slice(mappings, func(i, j int) bool { return mappings[i].Field < mappings[j].Field })
+	return mappings
+}
+
+// BaggageFields returns the Baggage members present in the baggage of ctx
+func (k ContextKeys) BaggageFields(ctx context.Context) []Field {
+	if len(k.Baggage) == 0 {
+		return nil
+	}
+	b := baggage.FromContext(ctx)
+	var fields []Field
+	for _, key := range k.Baggage {
+		if m := b.Member(key); m.Key() != "" {
+			fields = append(fields, F.String(key, m.Value()))
+		}
+	}
+	return fields
+}
+
+// TraceFieldNames returns the names of the trace ID, span ID and sampled flag
+// fields, defaulting the ones left empty
+func (k ContextKeys) TraceFieldNames() (traceID, spanID, flags string) {
+	traceID, spanID, flags = k.TraceIDField, k.SpanIDField, k.TraceFlagsField
+	if traceID == "" {
+		traceID = DefaultTraceIDField
+	}
+	if spanID == "" {
+		spanID = DefaultSpanIDField
+	}
+	if flags == "" {
+		flags = DefaultTraceFlagsField
+	}
+	return traceID, spanID, flags
+}
+
+// AppendTraceFields appends the trace ID, span ID and sampled flag of the span
+// context in ctx to dst. Nothing is added without a valid span context.
+func (k ContextKeys) AppendTraceFields(dst []Field, ctx context.Context) []Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return dst
+	}
+	traceID, spanID, flags := k.TraceFieldNames()
+	return append(dst,
+		F.String(traceID, sc.TraceID().String()),
+		F.String(spanID, sc.SpanID().String()),
+		F.Bool(flags, sc.IsSampled()),
+	)
+}
@@ -3,16 +3,27 @@ package logger_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/HoangAnhNguyen269/loggerkit/testutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	logger "github.com/HoangAnhNguyen269/loggerkit"
 	"github.com/HoangAnhNguyen269/loggerkit/contextLogger"
 	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // C) Context & Middleware
@@ -126,9 +137,13 @@ func TestOpenTelemetryTraceCorrelation(t *testing.T) {
 func TestFieldMergingPriority(t *testing.T) {
 	output, err := testutil.CaptureStdout(func() {
 		// Create logger with context keys configured
-		log, err := logger.NewProduction(logger.WithContext(logger.ContextKeys{
-			RequestIDKey: "request_id",
-		}))
+		log, err := logger.NewProduction(
+			logger.WithContext(logger.ContextKeys{
+				RequestIDKey: "request_id",
+				UserIDKey:    "user_id",
+			}),
+			logger.WithDedupFields(),
+		)
 		if err != nil {
 			t.Fatalf("Failed to create logger: %v", err)
 		}
@@ -136,10 +151,13 @@ func TestFieldMergingPriority(t *testing.T) {
 
 		// Create context with values
 		ctx := context.WithValue(context.Background(), "request_id", "from-context")
+		ctx = context.WithValue(ctx, "user_id", "from-context")
 
 		// Chain operations: With() -> WithContext() -> log fields
-		chainedLog := log.With(logger.F.String("service", "test-service")).
-			WithContext(ctx)
+		chainedLog := log.With(
+			logger.F.String("service", "test-service"),
+			logger.F.String("user_id", "from-with"), // Should override the context field
+		).WithContext(ctx)
 
 		chainedLog.Info("Test message",
 			logger.F.String("final", "field"),
@@ -150,8 +168,9 @@ func TestFieldMergingPriority(t *testing.T) {
 		t.Fatalf("Failed to capture stdout: %v", err)
 	}
 
+	line := strings.TrimSpace(output)
 	var logEntry map[string]interface{}
-	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &logEntry); err != nil {
+	if err := json.Unmarshal([]byte(line), &logEntry); err != nil {
 		t.Fatalf("Failed to parse log JSON: %v", err)
 	}
 
@@ -162,6 +181,292 @@ func TestFieldMergingPriority(t *testing.T) {
 	if logEntry["final"] != "field" {
 		t.Error("Expected final field from log call")
 	}
-	// Note: The exact priority of field overriding depends on zap's implementation
-	// This test documents the current behavior
+	// Call site over With over WithContext, each key written once
+	for key, want := range map[string]string{"service": "overridden", "user_id": "from-with"} {
+		if n := strings.Count(line, `"`+key+`":`); n != 1 {
+			t.Errorf("Expected a single %s key, got %d in %s", key, n, line)
+		}
+		if logEntry[key] != want {
+			t.Errorf("Expected %s=%s, got %v", key, want, logEntry[key])
+		}
+	}
+}
+
+func TestDedupFieldsManyKeys(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithDedupFields(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	bound := make([]logger.Field, 40)
+	for i := range bound {
+		bound[i] = logger.F.Int(fmt.Sprintf("k%d", i), i)
+	}
+	log.With(bound...).With(logger.F.Int("k1", -1)).Info("Many fields", logger.F.Int("k0", -1))
+	closeLogger(t, log)
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	for _, key := range []string{"k0", "k1", "k39"} {
+		if n := strings.Count(string(data), `"`+key+`":`); n != 1 {
+			t.Errorf("Expected a single %s key, got %d", key, n)
+		}
+	}
+	entries := testutil.ReadJSONLines(t, logPath)
+	if e := entries[0]; e["k0"] != float64(-1) || e["k1"] != float64(-1) || e["k39"] != float64(39) {
+		t.Errorf("Expected the latest value of each key, got %v", e)
+	}
+}
+
+type tenantKey struct{}
+
+func TestContextExtractors(t *testing.T) {
+	metrics := logger.GetMetrics()
+	before := promtestutil.ToFloat64(metrics.ExtractorPanics)
+
+	output, err := testutil.CaptureStdout(func() {
+		log, err := logger.NewProduction(
+			logger.WithContext(logger.ContextKeys{RequestIDKey: "request_id"}),
+			logger.WithContextExtractor(func(ctx context.Context) []logger.Field {
+				if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+					return []logger.Field{logger.F.String("tenant_id", tenant)}
+				}
+				return nil
+			}),
+			logger.WithContextExtractor(func(ctx context.Context) []logger.Field {
+				panic("broken extractor")
+			}),
+			logger.WithContextExtractor(func(ctx context.Context) []logger.Field {
+				return []logger.Field{logger.F.Bool("beta", true)}
+			}),
+			logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		defer log.Close(context.Background())
+
+		ctx := context.WithValue(context.Background(), "request_id", "req-1")
+		ctx = context.WithValue(ctx, tenantKey{}, "acme")
+		log.WithContext(ctx).Info("with tenant")
+		log.WithContext(context.Background()).Info("without tenant")
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture stdout: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", output)
+	}
+	if !strings.Contains(lines[0], `"request_id":"req-1","tenant_id":"acme","beta":true`) {
+		t.Errorf("Expected extractor fields in order after the built-in ones: %s", lines[0])
+	}
+	if strings.Contains(lines[1], "tenant_id") || !strings.Contains(lines[1], `"beta":true`) {
+		t.Errorf("Expected only the unconditional extractor field: %s", lines[1])
+	}
+
+	if got := promtestutil.ToFloat64(metrics.ExtractorPanics) - before; got != 2 {
+		t.Errorf("Expected 2 recovered extractor panics, got %v", got)
+	}
+
+	// Middleware sees the same fields
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	keys := logger.ContextKeys{Extractors: []logger.ContextExtractor{func(ctx context.Context) []logger.Field {
+		return []logger.Field{logger.F.Any("tenant_id", ctx.Value(tenantKey{}))}
+	}}}
+	if fields := contextLogger.ExtractRequestFields(ctx, keys); len(fields) != 1 || fields[0].Val != "acme" {
+		t.Errorf("Expected ExtractRequestFields to run the extractors, got %v", fields)
+	}
+}
+
+func TestExtractRequestFieldsMetrics(t *testing.T) {
+	keys := logger.ContextKeys{Extractors: []logger.ContextExtractor{func(ctx context.Context) []logger.Field {
+		panic("broken extractor")
+	}}}
+	panics := logger.GetMetrics().ExtractorPanics
+	testCases := []struct {
+		name    string
+		metrics bool
+		want    float64
+	}{
+		{"Enabled", true, 1},
+		{"Disabled", false, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			log, err := logger.NewProduction(logger.WithConsoleDisabled(), logger.WithRing(logger.RingSink{Capacity: 1}),
+				logger.WithMetrics(logger.MetricsOptions{Enabled: tc.metrics}))
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			defer log.Close(context.Background())
+
+			before := promtestutil.ToFloat64(panics)
+			contextLogger.ExtractRequestFields(contextLogger.WithLogger(context.Background(), log), keys)
+			if got := promtestutil.ToFloat64(panics) - before; got != tc.want {
+				t.Errorf("Expected %v recorded extractor panics, got %v", tc.want, got)
+			}
+		})
+	}
+
+	// Without a logger in the context there is nothing to count against
+	before := promtestutil.ToFloat64(panics)
+	contextLogger.ExtractRequestFields(context.Background(), keys)
+	if got := promtestutil.ToFloat64(panics) - before; got != 0 {
+		t.Errorf("Expected no recorded extractor panics without a logger, got %v", got)
+	}
+}
+
+func TestSpanEvents(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	log, err := logger.NewProduction(logger.WithConsoleDisabled(), logger.WithRing(logger.RingSink{Capacity: 10}), logger.WithSpanEvents(logger.WarnLevel))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+
+	ctx, span := tracer.Start(context.Background(), "handler")
+	spanLog := log.WithContext(ctx)
+	spanLog.Info("below threshold")
+	spanLog.Warn("slow query", logger.F.Duration("elapsed", 2*time.Second), logger.F.Int("rows", 3))
+	spanLog.With(logger.F.String("component", "db")).Error("query failed", logger.F.Err(errors.New("timeout")))
+	log.Warn("no span") // The root logger has no span
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected one span, got %d", len(spans))
+	}
+	var names []string
+	for _, e := range spans[0].Events() {
+		names = append(names, e.Name)
+	}
+	// RecordError adds an "exception" event after the log event
+	if want := []string{"slow query", "query failed", "exception"}; strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("Expected events %v, got %v", want, names)
+	}
+
+	attrs := map[string]string{}
+	for _, kv := range spans[0].Events()[0].Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs["level"] != "warn" || attrs["elapsed"] != "2s" || attrs["rows"] != "3" {
+		t.Errorf("Unexpected warn event attributes: %v", attrs)
+	}
+	if status := spans[0].Status(); status.Code != codes.Error || status.Description != "query failed" {
+		t.Errorf("Expected error status, got %+v", status)
+	}
+	for _, kv := range spans[0].Events()[2].Attributes {
+		if kv.Key == "exception.message" && kv.Value.AsString() != "timeout" {
+			t.Errorf("Expected the logged error to be recorded, got %v", kv.Value.AsString())
+		}
+	}
+}
+
+func TestTraceSampledAndBaggage(t *testing.T) {
+	tier, _ := baggage.NewMember("customer_tier", "gold")
+	region, _ := baggage.NewMember("region", "eu")
+	bag, _ := baggage.New(tier, region)
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithBaggageFields("customer_tier", "missing"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	log.WithContext(ctx).Info("sampled")
+	log.WithContext(trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{3},
+		SpanID:  trace.SpanID{4},
+	}))).Info("unsampled")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %v", entries)
+	}
+	if e := entries[0]; e["trace_sampled"] != true || e["customer_tier"] != "gold" || e["region"] != nil || e["missing"] != nil {
+		t.Errorf("Expected trace_sampled and the selected baggage member: %v", e)
+	}
+	if e := entries[1]; e["trace_sampled"] != false || e["customer_tier"] != nil {
+		t.Errorf("Expected an unsampled trace without baggage: %v", e)
+	}
+}
+
+func TestWithoutTraceExtraction(t *testing.T) {
+	type ctxKey string
+	ctx := context.WithValue(context.Background(), ctxKey("req"), "r-1")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	}))
+
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithoutTraceExtraction(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	// Nothing to extract: a derived logger comes back, and deriving it again is free
+	scoped := log.WithContext(ctx)
+	if scoped == log {
+		t.Error("Expected WithContext not to return the root logger")
+	}
+	if got := scoped.WithContext(ctx); got != scoped {
+		t.Error("Expected WithContext on a derived logger to return the receiver")
+	}
+	scoped.Info("untraced")
+
+	// Context keys are still extracted
+	withKeys, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithContext(logger.ContextKeys{Keys: map[string]any{"request_id": ctxKey("req")}}),
+		logger.WithoutTraceExtraction(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	withKeys.WithContext(ctx).Info("keyed")
+	for _, l := range []logger.Logger{log, withKeys} {
+		if err := l.Close(context.Background()); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %v", entries)
+	}
+	for _, e := range entries {
+		if _, ok := e["trace_id"]; ok {
+			t.Errorf("Expected no trace fields: %v", e)
+		}
+	}
+	if entries[1]["request_id"] != "r-1" {
+		t.Errorf("Expected context keys to be extracted: %v", entries[1])
+	}
 }
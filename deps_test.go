@@ -0,0 +1,86 @@
+package logger_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Heavy sinks live in their own packages so they only reach binaries that import them
+
+const esModule = "github.com/elastic/go-elasticsearch"
+
+func TestSinkDependencies(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping go toolchain test in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+	root, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatalf("Failed to resolve the module root: %v", err)
+	}
+
+	for _, tc := range []struct {
+		program string
+		want    bool
+	}{
+		{"console", false},
+		{"elastic", true},
+	} {
+		t.Run(tc.program, func(t *testing.T) {
+			dir := copyProgram(t, filepath.Join("testdata", "deps", tc.program), root)
+
+			runGo(t, dir, goBin, "mod", "tidy")
+			gomod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+			if err != nil {
+				t.Fatalf("Failed to read go.mod: %v", err)
+			}
+			if got := strings.Contains(string(gomod), esModule); got != tc.want {
+				t.Errorf("Expected %s in go.mod: %v, got:\n%s", esModule, tc.want, gomod)
+			}
+
+			deps := runGo(t, dir, goBin, "list", "-deps", ".")
+			if got := strings.Contains(deps, esModule); got != tc.want {
+				t.Errorf("Expected %s among the linked packages: %v", esModule, tc.want)
+			}
+		})
+	}
+}
+
+// copyProgram copies the program in src to a temporary directory, pointing its
+// replace directive at root, so tidying it leaves testdata untouched
+func copyProgram(t *testing.T, src, root string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"go.mod", "main.go"} {
+		data, err := os.ReadFile(filepath.Join(src, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if name == "go.mod" {
+			data = []byte(strings.Replace(string(data), "=> ../../..", "=> "+root, 1))
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+// runGo runs the go command in dir and returns its output
+func runGo(t *testing.T, dir, goBin string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command(goBin, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
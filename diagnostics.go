@@ -0,0 +1,17 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+)
+
+// Diagnosticf reports an internal loggerkit problem to the configured diagnostics
+// writer. Diagnostics never go through the logger's own sinks, so they stay visible
+// when those sinks are the thing that is failing.
+func (o Options) Diagnosticf(format string, args ...any) {
+	w := o.Diagnostics
+	if w == nil {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, "loggerkit: "+format+"\n", args...)
+}
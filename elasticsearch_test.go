@@ -2,13 +2,22 @@ package logger_test
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	"math"
+	"net/http"
+	"os"
 	"strings"
 	"testing"
 	"time"
 
 	logger "github.com/HoangAnhNguyen269/loggerkit"
 	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/elasticx"
+	"github.com/elastic/go-elasticsearch/v8"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // G) Elasticsearch Provider
@@ -22,11 +31,12 @@ func TestESBasicFunctionality(t *testing.T) {
 	defer cleanup()
 
 	log, err := logger.NewProduction(
+		logger.WithClock(testutil.NewFakeClock(time.Date(2026, 5, 14, 9, 30, 0, 0, time.UTC))),
 		logger.WithElastic(logger.ElasticSink{
 			Addresses:     []string{mockES.URL},
 			Index:         "test-logs-%Y.%m.%d",
 			FlushInterval: 100 * time.Millisecond, // Quick flush for testing
-			BulkActions:   1,                      // Should be ignored per spec
+			BulkActions:   1,                      // Flush after every document
 			BulkSizeBytes: 0,
 			Retry: logger.Retry{
 				Max:        2,
@@ -55,12 +65,12 @@ func TestESBasicFunctionality(t *testing.T) {
 	if len(docs) != 2 {
 		t.Fatalf("Expected 2 documents, got %d", len(docs))
 	}
+	mockES.AssertReceivedMeta(t, "Test message 1", map[string]interface{}{
+		"_index": "test-logs-2026.05.14",
+	})
 
 	// Verify document content
-	doc1 := docs[0]
-	if doc1["msg"] != "Test message 1" {
-		t.Errorf("Expected first message, got %v", doc1["msg"])
-	}
+	doc1 := mockES.AssertReceivedMessage(t, "Test message 1")
 	if doc1["field1"] != "value1" {
 		t.Errorf("Expected field1=value1, got %v", doc1["field1"])
 	}
@@ -68,19 +78,198 @@ func TestESBasicFunctionality(t *testing.T) {
 		t.Error("Expected service field to be populated")
 	}
 
-	doc2 := docs[1]
-	if doc2["msg"] != "Test message 2" {
-		t.Errorf("Expected second message, got %v", doc2["msg"])
-	}
+	doc2 := mockES.AssertReceivedMessage(t, "Test message 2")
 	if doc2["level"] != "error" {
 		t.Errorf("Expected error level, got %v", doc2["level"])
 	}
 }
 
+func TestESCustomTraceFieldNames(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithContext(logger.ContextKeys{
+			TraceIDField:    "traceId",
+			SpanIDField:     "spanId",
+			TraceFlagsField: "traceSampled",
+		}),
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			FlushInterval: 50 * time.Millisecond,
+			BulkActions:   1,
+		}),
+		logger.WithConsoleDisabled(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0xab},
+		SpanID:     trace.SpanID{0xcd},
+		TraceFlags: trace.FlagsSampled,
+	})
+	log.WithContext(trace.ContextWithSpanContext(context.Background(), sc)).Info("traced")
+	log.WithContext(context.Background()).Info("untraced")
+
+	if !mockES.WaitForDocs(2, 5*time.Second) {
+		t.Fatal("Expected 2 documents to be received by mock ES")
+	}
+
+	doc := mockES.AssertReceivedMessage(t, "traced")
+	if doc["traceId"] != sc.TraceID().String() || doc["spanId"] != sc.SpanID().String() || doc["traceSampled"] != true {
+		t.Errorf("Expected traceId, spanId and traceSampled, got %v", doc)
+	}
+	for _, key := range []string{"trace_id", "span_id", "trace_sampled"} {
+		if _, ok := doc[key]; ok {
+			t.Errorf("Expected no %s with custom names, got %v", key, doc)
+		}
+	}
+
+	doc = mockES.AssertReceivedMessage(t, "untraced")
+	for _, key := range []string{"traceId", "spanId", "traceSampled"} {
+		if _, ok := doc[key]; ok {
+			t.Errorf("Expected no %s without a span context, got %v", key, doc)
+		}
+	}
+}
+
 func TestESOnFailureDLQ(t *testing.T) {
-	// Skip this test for now as it requires a more complex setup to reliably trigger DLQ
-	// The DLQ functionality works but the test is flaky due to bulk indexer timing
-	t.Skip("DLQ test skipped - functionality works but test is unreliable due to timing issues")
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+	mockES.FailNextDocs(1, http.StatusBadRequest)
+
+	tempDLQ, cleanup := testutil.TempFile(t, "test-dlq", ".log")
+	defer cleanup()
+
+	log, err := logger.NewProduction(
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			FlushInterval: time.Minute, // Rely on Close() to flush
+			DLQPath:       tempDLQ,
+		}),
+		logger.WithConsoleDisabled(),
+		logger.WithDiagnostics(&testutil.SafeBuffer{}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	log.Info("Rejected document")
+	log.Info("Accepted document")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	mockES.AssertReceivedMessage(t, "Accepted document")
+	mockES.AssertNotReceivedMessage(t, "Rejected document")
+
+	data, err := os.ReadFile(tempDLQ)
+	if err != nil {
+		t.Fatalf("Failed to read DLQ: %v", err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("Expected one DLQ entry, got %q: %v", data, err)
+	}
+	if entry["reason"] != "index_error_400" {
+		t.Errorf("Expected reason index_error_400, got %v", entry["reason"])
+	}
+	if original, _ := entry["original_log"].(string); !strings.Contains(original, "Rejected document") {
+		t.Errorf("Expected the rejected document in the DLQ, got %v", entry["original_log"])
+	}
+}
+
+func TestESConnectionReset(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			FlushInterval: time.Minute,
+			BulkActions:   1, // Flush after every document
+		}),
+		logger.WithConsoleDisabled(),
+		logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+
+	bulkErrors := logger.GetMetrics().LogsDropped.WithLabelValues("elasticsearch", "bulk_error")
+	before := promtestutil.ToFloat64(bulkErrors)
+
+	// A batch sent over a reset connection is lost and counted
+	mockES.CloseConnections()
+	log.Info("Lost to a reset")
+	deadline := time.Now().Add(5 * time.Second)
+	for promtestutil.ToFloat64(bulkErrors) == before {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the reset to be counted as a bulk error")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mockES.RestoreConnections()
+	log.Info("Delivered after recovery")
+	if !mockES.WaitForDocs(1, 2*time.Second, testutil.MessageIs("Delivered after recovery")) {
+		t.Fatal("Expected delivery once connections are restored")
+	}
+	mockES.AssertNotReceivedMessage(t, "Lost to a reset")
+}
+
+func TestESCloseAbortsInFlightBulk(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+	mockES.SetLatency(time.Hour) // The bulk request hangs until it is cancelled
+
+	log, err := logger.NewProduction(
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			FlushInterval: time.Minute,
+			BulkActions:   1,
+		}),
+		logger.WithConsoleDisabled(),
+		logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	bulkErrors := logger.GetMetrics().LogsDropped.WithLabelValues("elasticsearch", "bulk_error")
+	before := promtestutil.ToFloat64(bulkErrors)
+
+	log.Info("Stuck in flight")
+	deadline := time.Now().Add(5 * time.Second)
+	for mockES.CountRequests(http.MethodPost, "/_bulk") == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the bulk request to reach the server")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := log.Close(ctx); err == nil {
+		t.Error("Expected Close to report the unflushed bulk request")
+	}
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("Expected Close to return shortly after its deadline, took %v", elapsed)
+	}
+
+	// The request itself is aborted rather than left running to its timeout
+	deadline = time.Now().Add(time.Second)
+	for promtestutil.ToFloat64(bulkErrors) == before {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the in-flight bulk request to be aborted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
 }
 
 func TestESAuthAndTLSConfigPaths(t *testing.T) {
@@ -146,14 +335,14 @@ func TestESAuthAndTLSConfigPaths(t *testing.T) {
 	}
 }
 
-func TestESBulkActionsIgnored(t *testing.T) {
+func TestESCloseFlushesBelowBulkActions(t *testing.T) {
 	mockES := testutil.NewElasticsearchMock()
 	defer mockES.Close()
 
 	log, err := logger.NewProduction(
 		logger.WithElastic(logger.ElasticSink{
 			Addresses:     []string{mockES.URL},
-			BulkActions:   1000, // Should be ignored
+			BulkActions:   1000, // Never reached
 			FlushInterval: 0,    // Rely on Close() to flush
 			BulkSizeBytes: 0,    // Disabled
 		}),
@@ -164,7 +353,7 @@ func TestESBulkActionsIgnored(t *testing.T) {
 	}
 
 	// Send a message
-	log.Info("Test bulk actions ignored")
+	log.Info("Test below bulk actions threshold")
 
 	// Close should flush even with BulkActions set
 	if err := log.Close(context.Background()); err != nil {
@@ -177,6 +366,40 @@ func TestESBulkActionsIgnored(t *testing.T) {
 	}
 }
 
+func TestESBulkActionsCountFlush(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			BulkActions:   3,
+			FlushInterval: time.Minute, // Only the count can trigger a flush
+			NumWorkers:    2,
+		}),
+		logger.WithConsoleDisabled(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+
+	log.Info("Count flush 1")
+	log.Info("Count flush 2")
+
+	// Below the threshold nothing may be sent
+	time.Sleep(200 * time.Millisecond)
+	if got := len(mockES.GetReceivedDocs()); got != 0 {
+		t.Fatalf("Expected no documents before %d adds, got %d", 3, got)
+	}
+
+	log.Info("Count flush 3")
+
+	if !mockES.WaitForDocs(3, 2*time.Second) {
+		t.Fatalf("Expected a flush after exactly 3 adds, got %d docs", len(mockES.GetReceivedDocs()))
+	}
+}
+
 func TestESSyncNoopCanStillWrite(t *testing.T) {
 	mockES := testutil.NewElasticsearchMock()
 	defer mockES.Close()
@@ -261,3 +484,1006 @@ func TestESCustomTransport(t *testing.T) {
 		log.Close(context.Background())
 	}
 }
+
+func TestESBorrowedClient(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses: []string{mockES.URL},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create elasticsearch client: %v", err)
+	}
+
+	tempDLQ, cleanup := testutil.TempFile(t, "test-dlq", ".log")
+	defer cleanup()
+
+	log, err := logger.NewProduction(
+		logger.WithElastic(logger.ElasticSink{
+			Client:        client,
+			Addresses:     []string{"http://unreachable.invalid:9200"}, // Ignored when Client is set
+			Index:         "borrowed-%Y.%m.%d",
+			FlushInterval: 50 * time.Millisecond,
+			Retry:         logger.DefaultElasticRetry(),
+			DLQPath:       tempDLQ,
+		}),
+		logger.WithConsoleDisabled(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger with borrowed client: %v", err)
+	}
+
+	log.Info("Via borrowed client", logger.F.String("k", "v"))
+
+	if !mockES.WaitForDocs(1, 2*time.Second) {
+		t.Fatal("Expected document to be indexed through the borrowed client")
+	}
+
+	if err := log.Close(context.Background()); err != nil {
+		t.Errorf("Failed to close logger: %v", err)
+	}
+
+	// The borrowed client must remain usable after the logger is closed
+	res, err := client.Info()
+	if err != nil {
+		t.Fatalf("Borrowed client unusable after logger Close: %v", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		t.Errorf("Expected successful response from borrowed client, got %s", res.Status())
+	}
+}
+
+func TestESGzipCompression(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:           []string{mockES.URL},
+			FlushInterval:       50 * time.Millisecond,
+			CompressRequestBody: true,
+		}),
+		logger.WithConsoleDisabled(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+
+	log.Info("Compressed message", logger.F.String("payload", strings.Repeat("abc", 100)))
+
+	if !mockES.WaitForDocs(1, 2*time.Second) {
+		t.Fatal("Expected compressed document to be received and decoded")
+	}
+
+	if mockES.GetGzipRequestCount() == 0 {
+		t.Error("Expected at least one request with Content-Encoding: gzip")
+	}
+
+	docs := mockES.GetReceivedDocs()
+	if docs[0]["msg"] != "Compressed message" {
+		t.Errorf("Expected decoded message, got %v", docs[0]["msg"])
+	}
+}
+
+func TestESBootstrapTemplate(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	newLogger := func() logger.Logger {
+		log, err := logger.NewProduction(
+			logger.WithService("billing"),
+			logger.WithElastic(logger.ElasticSink{
+				Addresses:     []string{mockES.URL},
+				FlushInterval: 50 * time.Millisecond,
+				Bootstrap: logger.ElasticBootstrap{
+					EnsureTemplate: true,
+					ILMPolicy:      json.RawMessage(`{"policy":{"phases":{"delete":{"min_age":"7d","actions":{"delete":{}}}}}}`),
+				},
+			}),
+			logger.WithConsoleDisabled(),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		return log
+	}
+
+	log := newLogger()
+	defer log.Close(context.Background())
+
+	if got := mockES.CountRequests(http.MethodPut, "/_index_template/billing-logs"); got != 1 {
+		t.Errorf("Expected exactly one template PUT, got %d", got)
+	}
+	if got := mockES.CountRequests(http.MethodPut, "/_ilm/policy/billing-logs-policy"); got != 1 {
+		t.Errorf("Expected exactly one ILM policy PUT, got %d", got)
+	}
+
+	// Logging must not trigger further bootstrap requests
+	log.Info("After bootstrap")
+	if !mockES.WaitForDocs(1, 2*time.Second) {
+		t.Fatal("Expected document after bootstrap")
+	}
+
+	log2 := newLogger()
+	defer log2.Close(context.Background())
+
+	if got := mockES.CountRequests(http.MethodPut, "/_index_template/billing-logs"); got != 2 {
+		t.Errorf("Expected one template PUT per logger (2 total), got %d", got)
+	}
+}
+
+func TestESBootstrapRejected(t *testing.T) {
+	newMock := func() *testutil.ElasticsearchMockServer {
+		mockES := testutil.NewElasticsearchMock()
+		mockES.SetResponse(http.StatusBadRequest, `{"error":{"type":"illegal_argument_exception"}}`)
+		return mockES
+	}
+
+	t.Run("WarnAndContinue", func(t *testing.T) {
+		mockES := newMock()
+		defer mockES.Close()
+
+		diag := &testutil.SafeBuffer{}
+		log, err := logger.NewProduction(
+			logger.WithElastic(logger.ElasticSink{
+				Addresses: []string{mockES.URL},
+				Bootstrap: logger.ElasticBootstrap{EnsureTemplate: true},
+			}),
+			logger.WithConsoleDisabled(),
+			logger.WithDiagnostics(diag),
+		)
+		if err != nil {
+			t.Fatalf("Expected rejected bootstrap to be non-fatal, got: %v", err)
+		}
+		defer log.Close(context.Background())
+
+		if !strings.Contains(diag.String(), "illegal_argument_exception") {
+			t.Errorf("Expected diagnostics to report the rejection, got %q", diag.String())
+		}
+	})
+
+	t.Run("FailOnBootstrapError", func(t *testing.T) {
+		mockES := newMock()
+		defer mockES.Close()
+
+		_, err := logger.NewProduction(
+			logger.WithElastic(logger.ElasticSink{
+				Addresses: []string{mockES.URL},
+				Bootstrap: logger.ElasticBootstrap{
+					EnsureTemplate:       true,
+					FailOnBootstrapError: true,
+				},
+			}),
+			logger.WithConsoleDisabled(),
+		)
+		if err == nil {
+			t.Fatal("Expected logger creation to fail when bootstrap is rejected")
+		}
+		if !strings.Contains(err.Error(), "index template") {
+			t.Errorf("Expected error to name the index template, got: %v", err)
+		}
+	})
+}
+
+func TestESBootstrapNoIndexPrefix(t *testing.T) {
+	for _, index := range []string{"%Y-logs", "%{tenant}-app"} {
+		t.Run(index, func(t *testing.T) {
+			mockES := testutil.NewElasticsearchMock()
+			defer mockES.Close()
+
+			diag := &testutil.SafeBuffer{}
+			log, err := logger.NewProduction(
+				logger.WithElastic(logger.ElasticSink{
+					Addresses: []string{mockES.URL},
+					Index:     index,
+					Bootstrap: logger.ElasticBootstrap{EnsureTemplate: true, TemplateName: "app-logs"},
+				}),
+				logger.WithConsoleDisabled(),
+				logger.WithDiagnostics(diag),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			defer log.Close(context.Background())
+
+			// A template for "*" would override the mappings of every index
+			if got := mockES.CountRequests(http.MethodPut, "/_index_template/app-logs"); got != 0 {
+				t.Errorf("Expected no template PUT, got %d", got)
+			}
+			if !strings.Contains(diag.String(), "literal prefix") {
+				t.Errorf("Expected diagnostics to report the missing prefix, got %q", diag.String())
+			}
+
+			_, err = logger.NewProduction(
+				logger.WithElastic(logger.ElasticSink{
+					Addresses: []string{mockES.URL},
+					Index:     index,
+					Bootstrap: logger.ElasticBootstrap{EnsureTemplate: true, FailOnBootstrapError: true},
+				}),
+				logger.WithConsoleDisabled(),
+			)
+			if err == nil || !strings.Contains(err.Error(), "literal prefix") {
+				t.Errorf("Expected logger creation to fail on the missing prefix, got %v", err)
+			}
+		})
+	}
+}
+
+func TestESDocumentIDRoutingPipeline(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			FlushInterval: time.Minute, // Rely on Close() to flush
+			Pipeline:      "enrich-logs",
+			DocumentID: func(doc map[string]any) string {
+				id, _ := doc["event_id"].(string)
+				return id
+			},
+			Routing: func(doc map[string]any) string {
+				tenant, _ := doc["tenant"].(string)
+				return tenant
+			},
+		}),
+		logger.WithConsoleDisabled(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	log.Info("Order placed", logger.F.String("event_id", "evt-1"), logger.F.String("tenant", "acme"))
+	log.Info("Order shipped", logger.F.String("event_id", "evt-2"), logger.F.String("tenant", "globex"))
+	// Replay of evt-1 must overwrite rather than duplicate
+	log.Info("Order placed", logger.F.String("event_id", "evt-1"), logger.F.String("tenant", "acme"))
+
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	docs := mockES.GetReceivedDocs()
+	if len(docs) != 2 {
+		t.Fatalf("Expected replayed duplicate to be deduplicated by _id (2 docs), got %d", len(docs))
+	}
+
+	meta := mockES.GetReceivedMeta()
+	expected := map[string]string{"evt-1": "acme", "evt-2": "globex"}
+	for i, m := range meta {
+		id, _ := m["_id"].(string)
+		want, ok := expected[id]
+		if !ok {
+			t.Errorf("Doc %d: unexpected _id %v", i, m["_id"])
+			continue
+		}
+		if m["routing"] != want {
+			t.Errorf("Doc %d: expected routing %q, got %v", i, want, m["routing"])
+		}
+		if docs[i]["event_id"] != id {
+			t.Errorf("Doc %d: _id %q does not match event_id %v", i, id, docs[i]["event_id"])
+		}
+	}
+
+	sawPipeline := false
+	for _, r := range mockES.GetRequests() {
+		if r.Path == "/_bulk" && strings.Contains(r.Query, "pipeline=enrich-logs") {
+			sawPipeline = true
+		}
+	}
+	if !sawPipeline {
+		t.Error("Expected bulk request to carry pipeline=enrich-logs")
+	}
+}
+
+func TestESIndexByLevel(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+	clock := testutil.NewFakeClock(time.Date(2026, 5, 14, 9, 30, 0, 0, time.UTC))
+
+	log, err := logger.NewProduction(
+		logger.WithClock(clock),
+		logger.WithService("checkout"),
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			Index:         "<service>-%Y.%m.%d",
+			IndexByLevel:  map[logger.Level]string{logger.ErrorLevel: "<service>-errors-%Y.%m"},
+			FlushInterval: time.Minute, // Rely on Close() to flush
+		}),
+		logger.WithConsoleDisabled(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	log.Info("Cart updated")
+	log.Error("Payment declined")
+
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	docs := mockES.GetReceivedDocs()
+	meta := mockES.GetReceivedMeta()
+	if len(meta) != 2 {
+		t.Fatalf("Expected 2 documents, got %d", len(meta))
+	}
+
+	expected := map[string]string{
+		"info":  "checkout-2026.05.14",
+		"error": "checkout-errors-2026.05",
+	}
+	for i, m := range meta {
+		level, _ := docs[i]["level"].(string)
+		if m["_index"] != expected[level] {
+			t.Errorf("Level %q: expected index %q, got %v", level, expected[level], m["_index"])
+		}
+	}
+	if meta[0]["_index"] == meta[1]["_index"] {
+		t.Errorf("Expected info and error in different indices, both went to %v", meta[0]["_index"])
+	}
+}
+
+// The daily index rolls over with the clock, without waiting for midnight
+func TestESDailyIndexRollover(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+	clock := testutil.NewFakeClock(time.Date(2026, 3, 31, 23, 59, 59, 0, time.UTC))
+
+	log, err := logger.NewProduction(
+		logger.WithClock(clock),
+		logger.WithService("checkout"),
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			FlushInterval: time.Minute, // Rely on Close() to flush
+		}),
+		logger.WithConsoleDisabled(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	log.Info("Before midnight")
+	clock.Advance(time.Second)
+	log.Info("At midnight")
+	clock.Advance(24 * time.Hour)
+	log.Info("A day later")
+
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	meta := mockES.GetReceivedMeta()
+	expected := []string{"checkout-2026.03.31", "checkout-2026.04.01", "checkout-2026.04.02"}
+	if len(meta) != len(expected) {
+		t.Fatalf("Expected %d documents, got %d", len(expected), len(meta))
+	}
+	for i, want := range expected {
+		if meta[i]["_index"] != want {
+			t.Errorf("Doc %d: expected index %q, got %v", i, want, meta[i]["_index"])
+		}
+	}
+	docs := mockES.GetReceivedDocs()
+	if ts, _ := docs[1]["ts"].(string); !strings.HasPrefix(ts, "2026-04-01T00:00:00") {
+		t.Errorf("Expected the entry timestamp from the clock, got %v", docs[1]["ts"])
+	}
+}
+
+func TestESIndexByLevelInvalid(t *testing.T) {
+	_, err := logger.NewProduction(
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:    []string{"http://localhost:9200"},
+			IndexByLevel: map[logger.Level]string{"critical": "logs-critical-%Y"},
+		}),
+		logger.WithConsoleDisabled(),
+	)
+	if err == nil {
+		t.Error("Expected error for unknown IndexByLevel level")
+	}
+}
+
+func TestESStaticFields(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithService("checkout"),
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			FlushInterval: time.Minute, // Rely on Close() to flush
+			StaticFields:  map[string]string{"region": "eu-west-1", "cluster": "blue"},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	output, err := testutil.CaptureStdout(func() {
+		log.Info("Order placed")
+		// The entry's own region wins over the static label
+		log.Info("Order replicated", logger.F.String("region", "us-east-1"))
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture stdout: %v", err)
+	}
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	docs := mockES.GetReceivedDocs()
+	if len(docs) != 2 {
+		t.Fatalf("Expected 2 documents, got %d", len(docs))
+	}
+	for i, doc := range docs {
+		if doc["env"] != "prod" {
+			t.Errorf("Doc %d: expected env=prod, got %v", i, doc["env"])
+		}
+		if doc["cluster"] != "blue" {
+			t.Errorf("Doc %d: expected cluster=blue, got %v", i, doc["cluster"])
+		}
+	}
+	if docs[0]["region"] != "eu-west-1" {
+		t.Errorf("Expected static region, got %v", docs[0]["region"])
+	}
+	if docs[1]["region"] != "us-east-1" {
+		t.Errorf("Expected entry region to be kept, got %v", docs[1]["region"])
+	}
+	for i, raw := range mockES.GetRawDocs() {
+		if n := strings.Count(raw, `"region":`); n != 1 {
+			t.Errorf("Doc %d: expected one region key, got %d", i, n)
+		}
+	}
+
+	// Enrichment is ES-only
+	if !strings.Contains(output, "Order placed") {
+		t.Fatalf("Expected console output, got %q", output)
+	}
+	for _, key := range []string{`"env"`, `"cluster"`, `"eu-west-1"`} {
+		if strings.Contains(output, key) {
+			t.Errorf("Console output must not contain %s: %s", key, output)
+		}
+	}
+}
+
+func TestESDeDotKeys(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			FlushInterval: time.Minute, // Rely on Close() to flush
+			DeDotKeys:     true,
+		}),
+		logger.WithConsoleDisabled(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	log.With(logger.F.String("k8s.pod", "api-1")).Info("Dotted keys",
+		logger.F.String("http.method", "GET"),
+		logger.F.Any("labels", map[string]any{"app.kubernetes.io/name": "api", "plain": 1}),
+	)
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	docs := mockES.GetReceivedDocs()
+	if len(docs) != 1 {
+		t.Fatalf("Expected 1 document, got %d", len(docs))
+	}
+	doc := docs[0]
+	if doc["k8s_pod"] != "api-1" || doc["http_method"] != "GET" {
+		t.Errorf("Expected de-dotted top-level keys, got %v", doc)
+	}
+	labels, ok := doc["labels"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected labels object, got %T", doc["labels"])
+	}
+	if labels["app_kubernetes_io/name"] != "api" || labels["plain"] != float64(1) {
+		t.Errorf("Expected de-dotted map keys, got %v", labels)
+	}
+}
+
+func TestESMaxDocFields(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			FlushInterval: time.Minute, // Rely on Close() to flush
+			MaxDocFields:  100,
+		}),
+		logger.WithConsoleDisabled(),
+		logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	overflowCount := logger.GetMetrics().ESFieldsOverflow
+	before := promtestutil.ToFloat64(overflowCount)
+
+	fields := make([]logger.Field, 2000)
+	for i := range fields {
+		fields[i] = logger.F.Int(fmt.Sprintf("user_%d", i), i)
+	}
+	log.Info("Field explosion", fields...)
+
+	perUser := make(map[string]any, 500)
+	for i := 0; i < 500; i++ {
+		perUser[fmt.Sprintf("u%03d", i)] = i
+	}
+	log.Info("Map explosion", logger.F.Any("by_user", perUser))
+
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	docs := mockES.GetReceivedDocs()
+	if len(docs) != 2 {
+		t.Fatalf("Expected 2 documents, got %d", len(docs))
+	}
+
+	// Top level: 100 fields including the service/env enrichment, the rest folded
+	doc := docs[0]
+	overflow, ok := doc["fields_overflow"].(string)
+	if !ok {
+		t.Fatalf("Expected fields_overflow string, got %T", doc["fields_overflow"])
+	}
+	var folded map[string]any
+	if err := json.Unmarshal([]byte(overflow), &folded); err != nil {
+		t.Fatalf("fields_overflow is not JSON: %v", err)
+	}
+	kept := 0
+	for k := range doc {
+		if strings.HasPrefix(k, "user_") {
+			kept++
+		}
+	}
+	if kept+len(folded) != 2000 {
+		t.Errorf("Expected all 2000 fields kept or folded, got %d + %d", kept, len(folded))
+	}
+	if kept != 98 {
+		t.Errorf("Expected 98 user fields next to service and env, got %d", kept)
+	}
+	if doc["user_0"] != float64(0) || folded["user_1999"] != float64(1999) {
+		t.Errorf("Expected first fields kept and last folded")
+	}
+
+	// One level into F.Any maps
+	byUser, ok := docs[1]["by_user"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected by_user object, got %T", docs[1]["by_user"])
+	}
+	if len(byUser) != 101 || byUser["fields_overflow"] == nil {
+		t.Errorf("Expected 100 map keys plus fields_overflow, got %d keys", len(byUser))
+	}
+
+	if got := promtestutil.ToFloat64(overflowCount) - before; got != 2 {
+		t.Errorf("Expected overflow metric to increase by 2, got %v", got)
+	}
+}
+
+func TestESPingOnStartup(t *testing.T) {
+	newLogger := func(url string) (logger.Logger, error) {
+		return logger.NewProduction(
+			logger.WithElastic(logger.ElasticSink{
+				Addresses:      []string{url},
+				PingOnStartup:  true,
+				StartupTimeout: time.Second,
+			}),
+			logger.WithConsoleDisabled(),
+		)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockES := testutil.NewElasticsearchMock()
+		defer mockES.Close()
+
+		log, err := newLogger(mockES.URL)
+		if err != nil {
+			t.Fatalf("Expected ping to succeed, got %v", err)
+		}
+		defer log.Close(context.Background())
+
+		if got := mockES.CountRequests(http.MethodGet, "/"); got != 1 {
+			t.Errorf("Expected one info request, got %d", got)
+		}
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		mockES := testutil.NewElasticsearchMock()
+		defer mockES.Close()
+		mockES.SetResponse(http.StatusUnauthorized, `{"error":{"type":"security_exception"}}`)
+
+		_, err := newLogger(mockES.URL)
+		if err == nil || !strings.Contains(err.Error(), "rejected credentials") {
+			t.Errorf("Expected credentials error, got %v", err)
+		}
+	})
+
+	t.Run("ConnectionRefused", func(t *testing.T) {
+		mockES := testutil.NewElasticsearchMock()
+		url := mockES.URL
+		mockES.Close()
+
+		_, err := newLogger(url)
+		if err == nil || !strings.Contains(err.Error(), "unreachable") {
+			t.Errorf("Expected unreachable error, got %v", err)
+		}
+	})
+
+	t.Run("LazyByDefault", func(t *testing.T) {
+		mockES := testutil.NewElasticsearchMock()
+		url := mockES.URL
+		mockES.Close()
+
+		log, err := logger.NewProduction(
+			logger.WithElastic(logger.ElasticSink{Addresses: []string{url}}),
+			logger.WithConsoleDisabled(),
+		)
+		if err != nil {
+			t.Fatalf("Expected lazy connect to ignore a down cluster, got %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = log.Close(ctx)
+	})
+}
+
+func TestESMaxDocBytes(t *testing.T) {
+	t.Run("TruncatesLargestStrings", func(t *testing.T) {
+		mockES := testutil.NewElasticsearchMock()
+		defer mockES.Close()
+
+		log, err := logger.NewProduction(
+			logger.WithElastic(logger.ElasticSink{
+				Addresses:     []string{mockES.URL},
+				FlushInterval: time.Minute, // Rely on Close() to flush
+			}),
+			logger.WithConsoleDisabled(),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		log.Info("Huge payload",
+			logger.F.String("payload", strings.Repeat("x", 2<<20)),
+			logger.F.String("request_id", "req-1"),
+		)
+		if err := log.Close(context.Background()); err != nil {
+			t.Fatalf("Failed to close logger: %v", err)
+		}
+
+		raw := mockES.GetRawDocs()
+		if len(raw) != 1 {
+			t.Fatalf("Expected the truncated document to be indexed, got %d docs", len(raw))
+		}
+		if len(raw[0]) > 1<<20 {
+			t.Errorf("Expected document within the 1MB default, got %d bytes", len(raw[0]))
+		}
+		doc := mockES.GetReceivedDocs()[0]
+		payload, _ := doc["payload"].(string)
+		if !strings.HasSuffix(payload, "...(truncated)") {
+			t.Errorf("Expected payload to be marked truncated")
+		}
+		if doc["request_id"] != "req-1" || doc["msg"] != "Huge payload" {
+			t.Errorf("Expected small fields to be kept, got %v / %v", doc["request_id"], doc["msg"])
+		}
+	})
+
+	t.Run("DLQWhenTruncationIsNotEnough", func(t *testing.T) {
+		mockES := testutil.NewElasticsearchMock()
+		defer mockES.Close()
+
+		tempDLQ, cleanup := testutil.TempFile(t, "test-dlq", ".log")
+		defer cleanup()
+
+		log, err := logger.NewProduction(
+			logger.WithElastic(logger.ElasticSink{
+				Addresses:     []string{mockES.URL},
+				FlushInterval: time.Minute, // Rely on Close() to flush
+				DLQPath:       tempDLQ,
+			}),
+			logger.WithConsoleDisabled(),
+			logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		dropped := logger.GetMetrics().LogsDropped.WithLabelValues("elasticsearch", "doc_too_large")
+		before := promtestutil.ToFloat64(dropped)
+
+		// Numbers cannot be truncated
+		ids := make([]int, 300000)
+		for i := range ids {
+			ids[i] = 100000 + i
+		}
+		log.Info("Huge id list", logger.F.Any("ids", ids))
+		if err := log.Close(context.Background()); err != nil {
+			t.Fatalf("Failed to close logger: %v", err)
+		}
+
+		if got := mockES.CountRequests(http.MethodPost, "/_bulk"); got != 0 {
+			t.Errorf("Expected no bulk request, got %d", got)
+		}
+		dlq, err := os.ReadFile(tempDLQ)
+		if err != nil {
+			t.Fatalf("Failed to read DLQ: %v", err)
+		}
+		if !strings.Contains(string(dlq), `"reason":"doc_too_large"`) {
+			t.Errorf("Expected doc_too_large DLQ entry, got %.200s", dlq)
+		}
+		if got := promtestutil.ToFloat64(dropped) - before; got != 1 {
+			t.Errorf("Expected LogsDropped to increase by 1, got %v", got)
+		}
+	})
+}
+
+func TestESFlushOnLevel(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:          []string{mockES.URL},
+			FlushInterval:      time.Minute, // Only the level can trigger a flush
+			FlushOnLevel:       logger.ErrorLevel,
+			FlushOnLevelWindow: time.Second,
+		}),
+		logger.WithConsoleDisabled(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+
+	log.Warn("Below the flush level")
+	time.Sleep(100 * time.Millisecond)
+	if got := len(mockES.GetReceivedDocs()); got != 0 {
+		t.Fatalf("Expected warn to stay buffered, got %d docs", got)
+	}
+
+	log.Error("Right before a crash")
+	if !mockES.WaitForDocs(2, time.Second) {
+		t.Fatalf("Expected error to be flushed immediately, got %d docs", len(mockES.GetReceivedDocs()))
+	}
+
+	// A burst is debounced into a single trailing flush
+	for i := 0; i < 20; i++ {
+		log.Error("Burst", logger.F.Int("n", i))
+	}
+	if !mockES.WaitForDocs(22, 3*time.Second) {
+		t.Fatalf("Expected burst to be flushed within the window, got %d docs", len(mockES.GetReceivedDocs()))
+	}
+	if got := mockES.CountRequests(http.MethodPost, "/_bulk"); got != 2 {
+		t.Errorf("Expected 2 bulk requests (immediate + debounced), got %d", got)
+	}
+}
+
+func TestESBulkItemFailureDetails(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+	mockES.SetBulkResponse(http.StatusOK, []testutil.MockBulkItem{
+		{Index: testutil.MockBulkItemResult{
+			Status: http.StatusBadRequest,
+			Error: &testutil.MockBulkItemError{
+				Type:   "mapper_parsing_exception",
+				Reason: "failed to parse field [count] of type [long]",
+			},
+		}},
+	})
+
+	tempDLQ, cleanup := testutil.TempFile(t, "test-dlq", ".log")
+	defer cleanup()
+
+	diag := &testutil.SafeBuffer{}
+	log, err := logger.NewProduction(
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			FlushInterval: time.Minute, // Rely on Close() to flush
+			DLQPath:       tempDLQ,
+		}),
+		logger.WithConsoleDisabled(),
+		logger.WithDiagnostics(diag),
+		logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	failures := logger.GetMetrics().ESBulkItemFailures.WithLabelValues("4xx")
+	before := promtestutil.ToFloat64(failures)
+
+	log.Info("Mapping conflict", logger.F.String("count", "not-a-number"))
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	data, err := os.ReadFile(tempDLQ)
+	if err != nil {
+		t.Fatalf("Failed to read DLQ: %v", err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("Expected one DLQ entry, got %q: %v", data, err)
+	}
+	if entry["reason"] != "index_error_400" {
+		t.Errorf("Expected reason index_error_400, got %v", entry["reason"])
+	}
+	if !strings.Contains(fmt.Sprint(entry["error"]), "mapper_parsing_exception: failed to parse field [count]") {
+		t.Errorf("Expected Elasticsearch reason in DLQ entry, got %v", entry["error"])
+	}
+
+	if got := promtestutil.ToFloat64(failures) - before; got != 1 {
+		t.Errorf("Expected es_bulk_item_failures_total{status_class=\"4xx\"} to increase by 1, got %v", got)
+	}
+	if !strings.Contains(diag.String(), "mapper_parsing_exception") {
+		t.Errorf("Expected sampled diagnostic with the rejection reason, got %q", diag.String())
+	}
+}
+
+func TestESServiceNameInIndex(t *testing.T) {
+	testCases := []struct {
+		name        string
+		service     string
+		indexPrefix string
+		field       string
+	}{
+		{"Empty", "", "app-", "app"},
+		{"Uppercase", "Checkout", "checkout-", "Checkout"},
+		{"Exotic", "_Team/Payments EU#2", "team-payments-eu-2-", "_Team/Payments EU#2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockES := testutil.NewElasticsearchMock()
+			defer mockES.Close()
+
+			diagnostics := &testutil.SafeBuffer{}
+			log, err := logger.NewProduction(
+				logger.WithService(tc.service),
+				logger.WithElastic(logger.ElasticSink{Addresses: []string{mockES.URL}}),
+				logger.WithConsoleDisabled(),
+				logger.WithDiagnostics(diagnostics),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			log.Info("Service name test")
+			closeLogger(t, log)
+
+			doc := mockES.AssertReceivedMessage(t, "Service name test")
+			if doc["service"] != tc.field {
+				t.Errorf("Expected the service field as configured (%q), got %v", tc.field, doc["service"])
+			}
+			meta := mockES.GetReceivedMeta()
+			if len(meta) != 1 {
+				t.Fatalf("Expected one bulk action, got %v", meta)
+			}
+			if index, _ := meta[0]["_index"].(string); !strings.HasPrefix(index, tc.indexPrefix) {
+				t.Errorf("Expected an index starting with %q, got %q", tc.indexPrefix, index)
+			}
+			warned := strings.Contains(diagnostics.String(), "no service name configured")
+			if warned != (tc.service == "") {
+				t.Errorf("Expected a diagnostics warning only without a service, got %q", diagnostics.String())
+			}
+		})
+	}
+}
+
+func TestESIndexFieldSubstitution(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithService("billing"),
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:          []string{mockES.URL},
+			Index:              "logs-<service>-%{tenant}-%Y",
+			IndexFieldFallback: "shared",
+		}),
+		logger.WithConsoleDisabled(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	log.With(logger.F.String("tenant", "acme")).Info("Bound tenant")
+	log.Info("Entry tenant", logger.F.String("tenant", "globex"))
+	log.Info("No tenant")
+	log.Info("Exotic tenant", logger.F.String("tenant", "_Wayne Enterprises/EU"))
+	closeLogger(t, log)
+
+	if !mockES.WaitForDocs(4, time.Second) {
+		t.Fatalf("Expected 4 documents, got %d", len(mockES.GetReceivedDocs()))
+	}
+	year := time.Now().UTC().Format("2006")
+	want := []string{
+		"logs-billing-acme-" + year,
+		"logs-billing-globex-" + year,
+		"logs-billing-shared-" + year,
+		"logs-billing-wayne-enterprises-eu-" + year,
+	}
+	for i, meta := range mockES.GetReceivedMeta() {
+		if meta["_index"] != want[i] {
+			t.Errorf("Doc %d: expected index %q, got %v", i, want[i], meta["_index"])
+		}
+	}
+	// Only the index name is sanitized
+	doc := mockES.AssertReceivedMessage(t, "Exotic tenant")
+	if doc["tenant"] != "_Wayne Enterprises/EU" {
+		t.Errorf("Expected the tenant field as logged, got %v", doc["tenant"])
+	}
+}
+
+func TestESNormalizesValues(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			FlushInterval: time.Minute, // Rely on Close() to flush
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	var nilInt *int
+	var nilErr *os.PathError
+	cases := []struct {
+		name  string
+		field logger.Field
+		key   string
+		want  any
+	}{
+		{"nil", logger.F.Any("v", nil), "v", nil},
+		{"nan", logger.F.Any("v", math.NaN()), "v", "NaN"},
+		{"pos inf", logger.F.Any("v", math.Inf(1)), "v", "+Inf"},
+		{"neg inf", logger.F.Any("v", math.Inf(-1)), "v", "-Inf"},
+		{"nil pointer", logger.F.Any("v", nilInt), "v", nil},
+		{"nil pointer error", logger.F.NamedErr("v", nilErr), "v", nil},
+		{"map with nan", logger.F.Any("v", map[string]any{"x": math.NaN()}), "v", map[string]any{"x": "NaN"}},
+		{"valid bytes", logger.F.Any("v", []byte("hello")), "v", "hello"},
+		{"invalid bytes", logger.F.Any("v", []byte{0xff, 0xfe}), "v_b64", "//4="},
+	}
+	for _, tc := range cases {
+		log.Info(tc.name, tc.field)
+	}
+	// Bound fields are normalized too
+	log.With(logger.F.Any("bound", math.NaN())).Info("bound nan")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	for i, raw := range mockES.GetRawDocs() {
+		if !json.Valid([]byte(raw)) {
+			t.Errorf("Doc %d is not valid JSON: %s", i, raw)
+		}
+	}
+	for _, tc := range cases {
+		doc := mockES.AssertReceivedMessage(t, tc.name)
+		got, ok := doc[tc.key]
+		if !ok {
+			t.Errorf("%s: expected key %q, got %v", tc.name, tc.key, doc)
+			continue
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("%s: expected %s=%v, got %v", tc.name, tc.key, tc.want, got)
+		}
+		if _, ok := doc[tc.key+"Error"]; ok {
+			t.Errorf("%s: unexpected encoding error %v", tc.name, doc[tc.key+"Error"])
+		}
+	}
+	if doc := mockES.AssertReceivedMessage(t, "bound nan"); doc["bound"] != "NaN" {
+		t.Errorf("Expected bound=NaN, got %v", doc["bound"])
+	}
+}
@@ -0,0 +1,60 @@
+package logger
+
+// EntryOptions are the overrides of a logger derived with WithOptions
+type EntryOptions struct {
+	Caller       *bool  // Overrides Options.EnableCaller
+	StacktraceAt *Level // Overrides Options.StacktraceAt; an empty level turns stacktraces off
+	CallerSkip   int    // Added to the frames skipped when reporting the caller
+}
+
+// EntryOption configures EntryOptions
+type EntryOption func(*EntryOptions)
+
+// EntryCaller turns the caller field of the derived logger on or off
+func EntryCaller(enabled bool) EntryOption {
+	return func(o *EntryOptions) {
+		o.Caller = &enabled
+	}
+}
+
+// EntryStacktraceAt sets the level from which the derived logger adds
+// stacktraces. An empty level turns them off.
+func EntryStacktraceAt(level Level) EntryOption {
+	return func(o *EntryOptions) {
+		o.StacktraceAt = &level
+	}
+}
+
+// EntryCallerSkip skips n more frames when reporting the caller, for
+// loggers used from a wrapper function
+func EntryCallerSkip(n int) EntryOption {
+	return func(o *EntryOptions) {
+		o.CallerSkip += n
+	}
+}
+
+// EntryConfigurer is implemented by loggers that can derive a logger with
+// other caller and stacktrace settings. The derived logger shares the sinks.
+type EntryConfigurer interface {
+	WithOptions(opts ...EntryOption) Logger
+}
+
+// WithOptions returns a logger like log with opts applied to its entries, for
+// components that need other caller or stacktrace settings than the rest of
+// the application. A logger that can't change them is returned as is.
+func WithOptions(log Logger, opts ...EntryOption) Logger {
+	c, ok := log.(EntryConfigurer)
+	if !ok {
+		return log
+	}
+	return c.WithOptions(opts...)
+}
+
+// ApplyEntryOptions collects opts, for Logger implementations
+func ApplyEntryOptions(opts ...EntryOption) EntryOptions {
+	var o EntryOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
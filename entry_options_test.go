@@ -0,0 +1,117 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/slogx"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+func TestWithOptionsCallerAndStacktrace(t *testing.T) {
+	for _, provider := range []string{"zapx", "slogx"} {
+		t.Run(provider, func(t *testing.T) {
+			logPath := filepath.Join(t.TempDir(), "app.log")
+			log, err := logger.NewProduction(
+				logger.WithProvider(provider),
+				logger.WithConsoleDisabled(),
+				logger.WithFile(logger.FileSink{Path: logPath}),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+
+			access := logger.WithOptions(log.Named("http"), logger.EntryCaller(false), logger.EntryStacktraceAt(""))
+			access.Info("GET /health")
+			access.Error("GET /fail", logger.F.Err(errors.New("boom")))
+			log.Info("parent")
+			log.Error("parent failed")
+			// Closing the derived logger leaves the sinks open
+			if err := access.Close(context.Background()); err != nil {
+				t.Fatalf("Close of derived logger failed: %v", err)
+			}
+			log.Info("after child close")
+			closeLogger(t, log)
+
+			entries := testutil.ReadJSONLines(t, logPath)
+			if len(entries) != 5 {
+				t.Fatalf("Expected 5 entries, got %d", len(entries))
+			}
+			for _, e := range entries[:2] {
+				if _, ok := e["caller"]; ok {
+					t.Errorf("Expected no caller on the derived logger: %v", e)
+				}
+				if _, ok := e["stacktrace"]; ok {
+					t.Errorf("Expected no stacktrace on the derived logger: %v", e)
+				}
+			}
+			if entries[0]["logger"] != "http" {
+				t.Errorf("Expected the derived logger to keep its name: %v", entries[0])
+			}
+			for _, e := range entries[2:] {
+				if caller, _ := e["caller"].(string); !strings.Contains(caller, "entry_options_test.go") {
+					t.Errorf("Expected the parent to keep its caller, got %v", e["caller"])
+				}
+			}
+			if provider == "zapx" && entries[3]["stacktrace"] == nil {
+				t.Errorf("Expected the parent to keep its stacktraces: %v", entries[3])
+			}
+		})
+	}
+}
+
+func TestWithOptionsCallerSkip(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithCaller(false),
+		logger.WithStacktraceAt(logger.FatalLevel),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	wrapped := logger.WithOptions(log, logger.EntryCaller(true), logger.EntryCallerSkip(1), logger.EntryStacktraceAt(logger.WarnLevel))
+	logThroughHelper(wrapped, "from helper")
+	log.Warn("plain")
+	closeLogger(t, log)
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if caller, _ := entries[0]["caller"].(string); !strings.Contains(caller, "entry_options_test.go") {
+		t.Errorf("Expected the helper's caller to be reported, got %v", entries[0]["caller"])
+	}
+	if entries[0]["stacktrace"] == nil {
+		t.Errorf("Expected a stacktrace from the overridden level: %v", entries[0])
+	}
+	if _, ok := entries[1]["caller"]; ok {
+		t.Errorf("Expected the parent to keep caller disabled: %v", entries[1])
+	}
+	if _, ok := entries[1]["stacktrace"]; ok {
+		t.Errorf("Expected the parent to keep its stacktrace level: %v", entries[1])
+	}
+}
+
+func logThroughHelper(log logger.Logger, msg string) {
+	log.Warn(msg)
+}
+
+func TestWithOptionsUnsupported(t *testing.T) {
+	log, err := logger.NewProduction(logger.WithProvider("nop"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	got := logger.WithOptions(log, logger.EntryCaller(false))
+	if fmt.Sprintf("%T", got) != fmt.Sprintf("%T", log) {
+		t.Errorf("Expected the logger to be returned as is, got %T", got)
+	}
+	got.Info("still usable")
+}
@@ -9,21 +9,36 @@ import (
 type Env string
 
 const (
-	EnvDev  Env = "dev"
-	EnvProd Env = "prod"
+	EnvDev     Env = "dev"
+	EnvProd    Env = "prod"
+	EnvStaging Env = "staging" // Production behavior, reported as staging
 )
 
+// ParseEnv accepts the Env values and "development" and "production" in any case
 func ParseEnv(s string) (Env, error) {
-	switch strings.ToLower(s) {
-	case "dev":
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "dev", "development":
 		return EnvDev, nil
-	case "prod":
+	case "prod", "production":
 		return EnvProd, nil
+	case "staging":
+		return EnvStaging, nil
 	default:
 		return "", fmt.Errorf("unknown env %q", s)
 	}
 }
 
+// IsDev reports whether e gets development behavior: text console, DPanic
+// panics, duplicate field warnings and no sanitizing by default
+func (e Env) IsDev() bool {
+	return e == EnvDev
+}
+
+// IsProd reports whether e gets production defaults; staging does
+func (e Env) IsProd() bool {
+	return e == EnvProd || e == EnvStaging
+}
+
 // Text/JSON compatibility
 func (e *Env) UnmarshalText(b []byte) error {
 	v, err := ParseEnv(string(b))
@@ -0,0 +1,94 @@
+package logger_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/slogx"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+func TestEnvAliases(t *testing.T) {
+	testCases := []struct {
+		env  logger.Env
+		want logger.Env
+	}{
+		{"dev", logger.EnvDev},
+		{"Dev", logger.EnvDev},
+		{"development", logger.EnvDev},
+		{"DEVELOPMENT", logger.EnvDev},
+		{"prod", logger.EnvProd},
+		{"Prod", logger.EnvProd},
+		{"Production", logger.EnvProd},
+		{"staging", logger.EnvStaging},
+		{" Staging ", logger.EnvStaging},
+	}
+
+	for _, provider := range []string{"zapx", "slogx"} {
+		for _, tc := range testCases {
+			t.Run(provider+"/"+string(tc.env), func(t *testing.T) {
+				var log logger.Logger
+				var panicked bool
+				output, err := testutil.CaptureStdout(func() {
+					var err error
+					// Start from the defaults of the other env, so only Env decides
+					build := logger.NewProduction
+					if tc.want.IsProd() {
+						build = logger.NewDevelopment
+					}
+					log, err = build(logger.WithEnv(tc.env), logger.WithProvider(provider))
+					if err != nil {
+						t.Errorf("Failed to create logger: %v", err)
+						return
+					}
+					log.Info("Env alias test")
+					// DPanic panics only in development
+					func() {
+						defer func() { panicked = recover() != nil }()
+						log.DPanic("Env alias dpanic")
+					}()
+					closeLogger(t, log)
+				})
+				if err != nil {
+					t.Fatalf("Failed to capture stdout: %v", err)
+				}
+				if log == nil {
+					return
+				}
+				if got := logger.Report(log).Env; got != tc.want {
+					t.Errorf("Expected env %q, got %q", tc.want, got)
+				}
+
+				var entry map[string]any
+				firstLine, _, _ := strings.Cut(output, "\n")
+				jsonConsole := json.Unmarshal([]byte(firstLine), &entry) == nil
+				if jsonConsole != tc.want.IsProd() {
+					t.Errorf("Expected a JSON console only for production envs, got %q", output)
+				}
+
+				if panicked != tc.want.IsDev() {
+					t.Errorf("Expected DPanic to panic only in development, panicked: %v", panicked)
+				}
+			})
+		}
+	}
+}
+
+func TestEnvUnknown(t *testing.T) {
+	if _, err := logger.NewProduction(logger.WithEnv("qa")); err == nil || !strings.Contains(err.Error(), `unknown env "qa"`) {
+		t.Errorf("Expected an unknown env rejected, got %v", err)
+	}
+
+	opts := logger.DefaultProductionOptions()
+	opts.Env = "Production"
+	if err := opts.Validate(); err != nil || opts.Env != logger.EnvProd {
+		t.Errorf("Expected Validate to normalize the env, got %q, %v", opts.Env, err)
+	}
+	opts.Env = ""
+	if err := opts.Validate(); err != nil || opts.Env != "" {
+		t.Errorf("Expected an empty env left as is, got %q, %v", opts.Env, err)
+	}
+}
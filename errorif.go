@@ -0,0 +1,24 @@
+package logger
+
+// ErrorIf logs msg at error level with err as the "error" field, after fields,
+// when err is not nil. It reports whether err was logged, so callers can write
+// `if logger.ErrorIf(log, err, "save failed") { return }`.
+func ErrorIf(log Logger, err error, msg string, fields ...Field) bool {
+	return logIf(log, ErrorLevel, err, msg, fields)
+}
+
+// WarnIf is ErrorIf at warn level
+func WarnIf(log Logger, err error, msg string, fields ...Field) bool {
+	return logIf(log, WarnLevel, err, msg, fields)
+}
+
+func logIf(log Logger, level Level, err error, msg string, fields []Field) bool {
+	if err == nil {
+		return false
+	}
+	if cs, ok := log.(callerSkipper); ok {
+		log = cs.WithCallerSkip(2) // Report the caller of ErrorIf or WarnIf
+	}
+	log.Log(level, msg, append(fields[:len(fields):len(fields)], F.Err(err))...)
+	return true
+}
@@ -0,0 +1,57 @@
+package logger_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+func TestErrorIfWarnIf(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+
+	if logger.ErrorIf(log, nil, "Never logged") || logger.WarnIf(log, nil, "Never logged either") {
+		t.Error("Expected nil errors not to be logged")
+	}
+	if !logger.ErrorIf(log, errors.New("disk full"), "Save failed", logger.F.String("file", "a.txt")) {
+		t.Error("Expected ErrorIf to report the logged error")
+	}
+	if !logger.WarnIf(log.With(logger.F.String("region", "eu")), errors.New("slow disk"), "Save slow") {
+		t.Error("Expected WarnIf to report the logged error")
+	}
+	closeLogger(t, log)
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %v", entries)
+	}
+	saved, slow := entries[0], entries[1]
+	if saved["level"] != "error" || saved["msg"] != "Save failed" || saved["error"] != "disk full" || saved["file"] != "a.txt" {
+		t.Errorf("Unexpected ErrorIf entry: %v", saved)
+	}
+	if slow["level"] != "warn" || slow["error"] != "slow disk" || slow["region"] != "eu" {
+		t.Errorf("Unexpected WarnIf entry: %v", slow)
+	}
+	for _, e := range entries {
+		if caller, _ := e["caller"].(string); !strings.Contains(caller, "errorif_test.go") {
+			t.Errorf("Expected the call site as caller, got %q", caller)
+		}
+	}
+}
+
+func TestNamedErr(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+	log.Error("Rollback failed",
+		logger.F.Err(errors.New("insert failed")),
+		logger.F.NamedErr("rollback_error", errors.New("connection lost")),
+	)
+	closeLogger(t, log)
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 1 || entries[0]["error"] != "insert failed" || entries[0]["rollback_error"] != "connection lost" {
+		t.Errorf("Expected both errors under their keys, got %v", entries)
+	}
+}
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Event is a message logged at a fixed level with a fixed set of field keys,
+// defined once with DefineEvent and emitted from any number of call sites
+type Event struct {
+	name   string
+	level  Level
+	fields []string
+}
+
+var (
+	eventsMu sync.RWMutex
+	events   = map[string]*Event{}
+)
+
+// DefineEvent registers an event logged as name at level, taking one value per
+// field name. It panics if an event with the same name is already defined, so
+// events are best defined in package-level variables.
+func DefineEvent(name string, level Level, fieldNames ...string) *Event {
+	e := &Event{name: name, level: level, fields: append([]string(nil), fieldNames...)}
+
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	if _, ok := events[name]; ok {
+		panic(fmt.Sprintf("loggerkit: event %q already defined", name))
+	}
+	events[name] = e
+	return e
+}
+
+// Events returns every defined event sorted by name, e.g. to document them
+func Events() []*Event {
+	eventsMu.RLock()
+	defer eventsMu.RUnlock()
+	all := make([]*Event, 0, len(events))
+	for _, e := range events {
+		all = append(all, e)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].name < all[j].name })
+	return all
+}
+
+// Name returns the message the event is logged with
+func (e *Event) Name() string { return e.name }
+
+// Level returns the level the event is logged at
+func (e *Event) Level() Level { return e.level }
+
+// FieldNames returns the keys of the event's values, in order
+func (e *Event) FieldNames() []string { return append([]string(nil), e.fields...) }
+
+// callerSkipper is implemented by loggers that can attribute entries to the
+// caller of a wrapper function
+type callerSkipper interface {
+	WithCallerSkip(delta int) Logger
+}
+
+// Emit logs the event with values paired with its field names in order. When
+// the number of values is wrong, Emit panics if log was built for EnvDev;
+// otherwise it logs the values it can pair and an "event_error" field.
+func (e *Event) Emit(log Logger, values ...any) {
+	if cs, ok := log.(callerSkipper); ok {
+		log = cs.WithCallerSkip(1) // Report the caller of Emit
+	}
+	n := len(e.fields)
+	if len(values) == n {
+		fields := make([]Field, n)
+		for i, v := range values {
+			fields[i] = Field{e.fields[i], v}
+		}
+		log.Log(e.level, e.name, fields...)
+		return
+	}
+
+	mismatch := fmt.Sprintf("event %q takes %d values %v, got %d", e.name, n, e.fields, len(values))
+	if Report(log).Env.IsDev() {
+		panic("loggerkit: " + mismatch)
+	}
+	n = min(n, len(values))
+	fields := make([]Field, n, n+1)
+	for i := range fields {
+		fields[i] = Field{e.fields[i], values[i]}
+	}
+	log.Log(e.level, e.name, append(fields, F.String("event_error", mismatch))...)
+}
@@ -0,0 +1,91 @@
+package logger_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+// Typed events
+
+var paymentProcessed = logger.DefineEvent("payment processed", logger.InfoLevel, "amount", "currency", "customer")
+
+func TestEventEmit(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+
+	paymentProcessed.Emit(log, 12.5, "EUR", "c-1")
+	paymentProcessed.Emit(log.With(logger.F.String("region", "eu")), 99.0, "USD", "c-2")
+	paymentProcessed.Emit(log, 1.0, "EUR") // Missing customer
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	for i, e := range entries[:2] {
+		if e["msg"] != "payment processed" || e["level"] != "info" {
+			t.Errorf("Entry %d: unexpected message or level: %v", i, e)
+		}
+		for _, key := range paymentProcessed.FieldNames() {
+			if _, ok := e[key]; !ok {
+				t.Errorf("Entry %d: missing %q: %v", i, key, e)
+			}
+		}
+		if caller, _ := e["caller"].(string); !strings.Contains(caller, "event_test.go") {
+			t.Errorf("Entry %d: expected the Emit call site as caller, got %q", i, caller)
+		}
+	}
+	if entries[0]["amount"] != 12.5 || entries[1]["customer"] != "c-2" || entries[1]["region"] != "eu" {
+		t.Errorf("Unexpected values: %v", entries[:2])
+	}
+
+	mismatch := entries[2]
+	if mismatch["currency"] != "EUR" || mismatch["customer"] != nil {
+		t.Errorf("Expected the values that could be paired: %v", mismatch)
+	}
+	if msg, _ := mismatch["event_error"].(string); !strings.Contains(msg, "takes 3 values") {
+		t.Errorf("Expected an event_error field, got %v", mismatch)
+	}
+}
+
+func TestEventArityPanicsInDev(t *testing.T) {
+	log, err := logger.NewDevelopment(logger.WithConsoleDisabled(), logger.WithFile(logger.FileSink{Path: filepath.Join(t.TempDir(), "app.log")}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+
+	defer func() {
+		r := recover()
+		if msg, _ := r.(string); !strings.Contains(msg, `event "payment processed" takes 3 values [amount currency customer], got 4`) {
+			t.Errorf("Expected an arity panic, got %v", r)
+		}
+	}()
+	paymentProcessed.Emit(log, 1.0, "EUR", "c-1", "extra")
+}
+
+func TestEventRegistry(t *testing.T) {
+	found := false
+	for _, e := range logger.Events() {
+		if e == paymentProcessed {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected the defined event in Events()")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected defining an event twice to panic")
+		}
+	}()
+	logger.DefineEvent("payment processed", logger.WarnLevel)
+}
@@ -0,0 +1,23 @@
+package logger
+
+// SwapBuilderForTest replaces the builder registered under name, removing it
+// when builder is nil, until the returned func restores it
+func SwapBuilderForTest(name string, builder NewBuilder, pkg string) (restore func()) {
+	buildersMu.Lock()
+	defer buildersMu.Unlock()
+	prev, ok := builders[name]
+	if builder == nil {
+		delete(builders, name)
+	} else {
+		builders[name] = registeredBuilder{builder: builder, pkg: pkg}
+	}
+	return func() {
+		buildersMu.Lock()
+		defer buildersMu.Unlock()
+		if ok {
+			builders[name] = prev
+		} else {
+			delete(builders, name)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package logger
+
+import "context"
+
+// ContextExtractor returns fields to log for ctx, e.g. a tenant ID; nil adds none
+type ContextExtractor func(ctx context.Context) []Field
+
+// RunExtractors runs the Extractors in order and returns their fields. An
+// extractor that panics is skipped and counted in context_extractor_panics_total.
+func (k ContextKeys) RunExtractors(ctx context.Context, m *Metrics) []Field {
+	var fields []Field
+	for _, extract := range k.Extractors {
+		fields = append(fields, runExtractor(ctx, extract, m)...)
+	}
+	return fields
+}
+
+func runExtractor(ctx context.Context, extract ContextExtractor, m *Metrics) (fields []Field) {
+	defer func() {
+		if recover() != nil {
+			m.RecordExtractorPanic()
+			fields = nil
+		}
+	}()
+	return extract(ctx)
+}
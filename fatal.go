@@ -0,0 +1,22 @@
+package logger
+
+import "time"
+
+// FatalEntry is the entry passed to a FatalHook, already written to the sinks
+type FatalEntry struct {
+	Time    time.Time
+	Message string
+	Caller  string         // file:line, empty without EnableCaller
+	Fields  map[string]any // Call-site fields
+}
+
+// FatalHook decides what happens after a Fatal entry is written. When it
+// returns, so does Fatal.
+type FatalHook func(entry FatalEntry)
+
+var (
+	// FatalPanic panics with the message instead of exiting
+	FatalPanic FatalHook = func(entry FatalEntry) { panic(entry.Message) }
+	// FatalReturn returns control to the caller of Fatal, e.g. in tests
+	FatalReturn FatalHook = func(FatalEntry) {}
+)
@@ -8,18 +8,24 @@ type Field struct {
 	Val any
 }
 
+// FV builds a typed field. string, int, int64, float64, bool, time.Time,
+// time.Duration and []string values are encoded without reflection.
+func FV[T any](key string, val T) Field {
+	return Field{key, val}
+}
+
 // Legacy field helpers for backward compatibility
-func String(key, val string) Field    { return Field{key, val} }
-func Int(key string, val int) Field   { return Field{key, val} }
-func Bool(key string, val bool) Field { return Field{key, val} }
+func String(key, val string) Field    { return FV(key, val) }
+func Int(key string, val int) Field   { return FV(key, val) }
+func Bool(key string, val bool) Field { return FV(key, val) }
 func Any(key string, val any) Field {
 	return Field{key, val}
 }
 func Duration(key string, val time.Duration) Field {
-	return Field{key, val}
+	return FV(key, val)
 }
 func Time(key string, val time.Time) Field {
-	return Field{key, val}
+	return FV(key, val)
 }
 func Error(err error) Field {
 	return Field{Key: "error", Val: err.Error()}
@@ -31,13 +37,17 @@ var F = struct {
 	Int      func(k string, v int) Field
 	Bool     func(k string, v bool) Field
 	Err      func(err error) Field
+	NamedErr func(k string, err error) Field
 	Duration func(k string, v time.Duration) Field
+	Time     func(k string, v time.Time) Field
 	Any      func(k string, v any) Field
 }{
-	String:   func(k, v string) Field { return Field{k, v} },
-	Int:      func(k string, v int) Field { return Field{k, v} },
-	Bool:     func(k string, v bool) Field { return Field{k, v} },
+	String:   func(k, v string) Field { return FV(k, v) },
+	Int:      func(k string, v int) Field { return FV(k, v) },
+	Bool:     func(k string, v bool) Field { return FV(k, v) },
 	Err:      func(err error) Field { return Field{"error", err} },
-	Duration: func(k string, v time.Duration) Field { return Field{k, v} },
+	NamedErr: func(k string, err error) Field { return Field{k, err} },
+	Duration: func(k string, v time.Duration) Field { return FV(k, v) },
+	Time:     func(k string, v time.Time) Field { return FV(k, v) },
 	Any:      func(k string, v any) Field { return Field{k, v} },
 }
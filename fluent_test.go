@@ -0,0 +1,203 @@
+package logger_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+// Fluentd/Fluent Bit forward sink
+
+func newFluentLogger(t *testing.T, sink logger.FluentSink, opts ...logger.Option) logger.Logger {
+	t.Helper()
+	return testutil.NewLogger(t, append([]logger.Option{logger.WithService("checkout"), logger.WithFluent(sink)}, opts...)...)
+}
+
+func closeLogger(t *testing.T, log logger.Logger) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := log.Close(ctx); err != nil {
+		t.Errorf("Failed to close logger: %v", err)
+	}
+}
+
+func TestFluentForward(t *testing.T) {
+	server, err := testutil.NewFluentForwardServer(testutil.FluentForwardOptions{})
+	if err != nil {
+		t.Fatalf("Failed to start forward server: %v", err)
+	}
+	defer server.Close()
+
+	log := newFluentLogger(t, logger.FluentSink{Address: server.Addr()})
+
+	before := time.Now()
+	log.With(logger.F.String("tenant", "acme")).Info("Order placed",
+		logger.F.String("order_id", "o-1"),
+		logger.F.Int("items", 3),
+		logger.F.Any("labels", map[string]any{"region": "eu"}),
+	)
+	log.Error("Payment failed", logger.F.Bool("retryable", false))
+	closeLogger(t, log)
+	server.WaitForEvents(2, 5*time.Second) // Without acks delivery is only visible server-side
+
+	events := server.GetEvents()
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+
+	info, errEv := events[0], events[1]
+	if info.Tag != "checkout.info" || errEv.Tag != "checkout.error" {
+		t.Errorf("Expected per-level tags, got %q and %q", info.Tag, errEv.Tag)
+	}
+	// A seconds-only timestamp would fall before the time taken just ahead of the call
+	if info.Time.Before(before) || info.Time.After(time.Now()) {
+		t.Errorf("Expected event time with nanosecond precision around %v, got %v", before, info.Time)
+	}
+
+	rec := info.Record
+	if rec["msg"] != "Order placed" || rec["level"] != "info" {
+		t.Errorf("Expected msg and level in record, got %v", rec)
+	}
+	if rec["tenant"] != "acme" || rec["order_id"] != "o-1" {
+		t.Errorf("Expected bound and call-site fields, got %v", rec)
+	}
+	if rec["items"] != int64(3) {
+		t.Errorf("Expected integer field to stay an integer, got %#v", rec["items"])
+	}
+	if labels, ok := rec["labels"].(map[string]interface{}); !ok || labels["region"] != "eu" {
+		t.Errorf("Expected nested map field, got %#v", rec["labels"])
+	}
+	if _, ok := rec["ts"]; ok {
+		t.Error("Timestamp belongs in the event time, not the record")
+	}
+	if errEv.Record["retryable"] != false {
+		t.Errorf("Expected bool field, got %#v", errEv.Record["retryable"])
+	}
+}
+
+func TestFluentTagTemplate(t *testing.T) {
+	server, err := testutil.NewFluentForwardServer(testutil.FluentForwardOptions{})
+	if err != nil {
+		t.Fatalf("Failed to start forward server: %v", err)
+	}
+	defer server.Close()
+
+	log := newFluentLogger(t, logger.FluentSink{Address: server.Addr(), Tag: "app.<service>.<level>.v1"})
+	log.Warn("Disk almost full")
+	closeLogger(t, log)
+	server.WaitForEvents(1, 5*time.Second)
+
+	events := server.GetEvents()
+	if len(events) != 1 || events[0].Tag != "app.checkout.warn.v1" {
+		t.Errorf("Expected templated tag, got %+v", events)
+	}
+}
+
+func TestFluentRequireAck(t *testing.T) {
+	server, err := testutil.NewFluentForwardServer(testutil.FluentForwardOptions{})
+	if err != nil {
+		t.Fatalf("Failed to start forward server: %v", err)
+	}
+	defer server.Close()
+
+	log := newFluentLogger(t, logger.FluentSink{Address: server.Addr(), RequireAck: true})
+	for i := 0; i < 10; i++ {
+		log.Info("Acked entry", logger.F.Int("i", i))
+	}
+	closeLogger(t, log)
+
+	if got := len(server.GetEvents()); got != 10 {
+		t.Errorf("Expected 10 events, got %d", got)
+	}
+	if acks, msgs := server.GetAckCount(), server.GetMessageCount(); acks == 0 || acks != msgs {
+		t.Errorf("Expected every message to be acknowledged, got %d acks for %d messages", acks, msgs)
+	}
+}
+
+func TestFluentSharedKey(t *testing.T) {
+	t.Run("Accepted", func(t *testing.T) {
+		server, err := testutil.NewFluentForwardServer(testutil.FluentForwardOptions{SharedKey: "s3cret"})
+		if err != nil {
+			t.Fatalf("Failed to start forward server: %v", err)
+		}
+		defer server.Close()
+
+		log := newFluentLogger(t, logger.FluentSink{Address: server.Addr(), SharedKey: "s3cret"})
+		log.Info("Authenticated entry")
+		closeLogger(t, log)
+		server.WaitForEvents(1, 5*time.Second)
+
+		if ok, _ := server.GetHandshakeCount(); ok != 1 {
+			t.Errorf("Expected 1 successful handshake, got %d", ok)
+		}
+		if got := len(server.GetEvents()); got != 1 {
+			t.Errorf("Expected 1 event, got %d", got)
+		}
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		server, err := testutil.NewFluentForwardServer(testutil.FluentForwardOptions{SharedKey: "s3cret"})
+		if err != nil {
+			t.Fatalf("Failed to start forward server: %v", err)
+		}
+		defer server.Close()
+
+		diag := &testutil.SafeBuffer{}
+		log := newFluentLogger(t, logger.FluentSink{Address: server.Addr(), SharedKey: "wrong"}, logger.WithDiagnostics(diag))
+		log.Info("Should not be delivered")
+		closeLogger(t, log)
+
+		if _, rejected := server.GetHandshakeCount(); rejected == 0 {
+			t.Error("Expected the handshake to be rejected")
+		}
+		if got := len(server.GetEvents()); got != 0 {
+			t.Errorf("Expected no events, got %d", got)
+		}
+		if !strings.Contains(diag.String(), "handshake rejected") {
+			t.Errorf("Expected handshake failure in diagnostics, got %q", diag.String())
+		}
+	})
+}
+
+func TestFluentReconnect(t *testing.T) {
+	server, err := testutil.NewFluentForwardServer(testutil.FluentForwardOptions{})
+	if err != nil {
+		t.Fatalf("Failed to start forward server: %v", err)
+	}
+	defer server.Close()
+
+	diag := &testutil.SafeBuffer{}
+	log := newFluentLogger(t, logger.FluentSink{Address: server.Addr(), RequireAck: true}, logger.WithDiagnostics(diag))
+
+	log.Info("Before outage")
+	if !server.WaitForEvents(1, 5*time.Second) {
+		t.Fatal("Expected the first event before the outage")
+	}
+
+	server.Stop()
+	for i := 0; i < 5; i++ {
+		log.Info("During outage", logger.F.Int("i", i))
+	}
+	time.Sleep(300 * time.Millisecond) // Let the sender hit the outage
+
+	if err := server.Restart(); err != nil {
+		t.Fatalf("Failed to restart forward server: %v", err)
+	}
+	if !server.WaitForEvents(6, 10*time.Second) {
+		t.Fatalf("Expected buffered events after reconnect, got %d", len(server.GetEvents()))
+	}
+	closeLogger(t, log)
+
+	if server.GetConnectionCount() < 2 {
+		t.Errorf("Expected a new connection after the restart, got %d", server.GetConnectionCount())
+	}
+	if out := diag.String(); !strings.Contains(out, "failed, retrying") || !strings.Contains(out, "resumed") {
+		t.Errorf("Expected outage and recovery in diagnostics, got %q", out)
+	}
+}
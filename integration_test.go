@@ -111,8 +111,8 @@ func TestFullIntegration(t *testing.T) {
 
 	// Verify metrics were created
 	collectors := logger.MetricsCollectors()
-	if len(collectors) != 5 {
-		t.Errorf("Expected 5 metric collectors, got %d", len(collectors))
+	if len(collectors) != 14 {
+		t.Errorf("Expected 14 metric collectors, got %d", len(collectors))
 	}
 }
 
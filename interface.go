@@ -10,8 +10,21 @@ type Logger interface {
 	Info(msg string, fields ...Field)
 	Warn(msg string, fields ...Field)
 	Error(msg string, fields ...Field)
+	// DPanic logs at DPanicLevel: it panics after logging in EnvDev, so bugs
+	// surface during development, and logs an error entry elsewhere
+	DPanic(msg string, fields ...Field)
+	// Fatal logs at FatalLevel, then runs the FatalHook: by default it closes
+	// the sinks and exits with status 1
+	Fatal(msg string, fields ...Field)
 	Log(level Level, msg string, fields ...Field)
 	With(fields ...Field) Logger
 	WithContext(ctx context.Context) Logger
+	// Named returns a child logger whose entries carry name in the "logger"
+	// field. Nested names join with dots: log.Named("api").Named("user") logs
+	// as "api.user".
+	Named(name string) Logger
+	// Close flushes and releases the sinks. Only the logger returned by the
+	// constructor owns them: Close on a child from With or WithContext only
+	// syncs and leaves the sinks open.
 	Close(ctx context.Context) error
 }
@@ -0,0 +1,161 @@
+// Package dlqfile reads the dead letter queue files of the Elasticsearch sink
+package dlqfile
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap/zapcore"
+)
+
+// dlqMaxLineSize bounds one DLQ line. It matches Elasticsearch's default
+// http.max_content_length, beyond which a document could never be indexed.
+const dlqMaxLineSize = 100 << 20
+
+// Entry is one entry of a DLQ file
+type Entry struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Reason      string    `json:"reason"`          // Why the document was dead-lettered, e.g. index_error_400
+	Error       string    `json:"error,omitempty"` // Elasticsearch's explanation, if any
+	OriginalLog string    `json:"original_log"`    // The document as it would have been indexed
+}
+
+// Level returns the level of the original log, or "" when it has none
+func (e Entry) Level() logger.Level {
+	var doc struct {
+		Level logger.Level `json:"level"`
+	}
+	if err := json.Unmarshal([]byte(e.OriginalLog), &doc); err != nil {
+		return ""
+	}
+	return doc.Level
+}
+
+// String formats the entry on one line, for printing
+func (e Entry) String() string {
+	var b strings.Builder
+	b.WriteString(e.Timestamp.UTC().Format(time.RFC3339Nano))
+	b.WriteByte(' ')
+	b.WriteString(e.Reason)
+	if e.Error != "" {
+		b.WriteString(" (")
+		b.WriteString(e.Error)
+		b.WriteByte(')')
+	}
+	b.WriteByte(' ')
+	b.WriteString(e.OriginalLog)
+	return b.String()
+}
+
+// Scan calls fn with each entry of the DLQ file at path, in order. The
+// file may be gzip-compressed, as rotated DLQ files often are. Scanning stops
+// at the first error fn returns, which Scan returns as is.
+func Scan(path string, fn func(Entry) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open DLQ file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	r, err := dlqReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to read DLQ file %s: %w", path, err)
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), dlqMaxLineSize)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return fmt.Errorf("failed to parse DLQ line %d of %s: %w", n, path, err)
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read DLQ file %s: %w", path, err)
+	}
+	return nil
+}
+
+// dlqReader reads r, decompressing it when it starts with the gzip header
+func dlqReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return br, nil // Plain text, or too short to be gzip
+	}
+	return gzip.NewReader(br)
+}
+
+// Filter selects DLQ entries. Zero fields match every entry.
+type Filter struct {
+	Reason string       // Prefix of the reason: "index_error" matches every status
+	Since  time.Time    // Entries at or after
+	Until  time.Time    // Entries before
+	Level  logger.Level // Entries whose original log is at this level or above
+}
+
+// Match reports whether entry passes the filter
+func (f Filter) Match(entry Entry) bool {
+	if f.Reason != "" && !strings.HasPrefix(entry.Reason, f.Reason) {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.Timestamp.Before(f.Until) {
+		return false
+	}
+	if f.Level != "" {
+		want, ok := dlqLevel(f.Level)
+		if !ok {
+			return false
+		}
+		got, ok := dlqLevel(entry.Level())
+		if !ok || got < want {
+			return false
+		}
+	}
+	return true
+}
+
+func dlqLevel(level logger.Level) (zapcore.Level, bool) {
+	parsed, err := logger.ParseLevel(string(level))
+	if err != nil {
+		return 0, false
+	}
+	lvl, err := zapcore.ParseLevel(string(parsed))
+	return lvl, err == nil
+}
+
+// Collect returns the entries of the DLQ file at path that match opts
+func Collect(path string, opts Filter) ([]Entry, error) {
+	if opts.Level != "" {
+		if _, err := logger.ParseLevel(string(opts.Level)); err != nil {
+			return nil, fmt.Errorf("invalid DLQ filter level: %w", err)
+		}
+	}
+	var entries []Entry
+	err := Scan(path, func(e Entry) error {
+		if opts.Match(e) {
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,394 @@
+// Package msgpack is the small subset of MessagePack needed to speak the Fluent
+// forward protocol: appending values to a buffer and decoding generic values.
+package msgpack
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"time"
+)
+
+// EventTimeExt is the extension type Fluentd uses for nanosecond timestamps
+const EventTimeExt = 0
+
+// Ext is an extension value returned by Decode
+type Ext struct {
+	Type int8
+	Data []byte
+}
+
+// AppendNil appends nil
+func AppendNil(b []byte) []byte {
+	return append(b, 0xc0)
+}
+
+// AppendBool appends a boolean
+func AppendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xc3)
+	}
+	return append(b, 0xc2)
+}
+
+// AppendInt appends a signed integer using the shortest encoding
+func AppendInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return AppendUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v))
+	case v >= math.MinInt8:
+		return append(b, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+	}
+}
+
+// AppendUint appends an unsigned integer using the shortest encoding
+func AppendUint(b []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(b, byte(v))
+	case v <= math.MaxUint8:
+		return append(b, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), v)
+	}
+}
+
+// AppendFloat64 appends a 64-bit float
+func AppendFloat64(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v))
+}
+
+// AppendString appends a UTF-8 string
+func AppendString(b []byte, s string) []byte {
+	n := len(s)
+	switch {
+	case n <= 31:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+// AppendBytes appends binary data
+func AppendBytes(b []byte, v []byte) []byte {
+	n := len(v)
+	switch {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+	return append(b, v...)
+}
+
+// AppendArrayHeader appends the header of an array with n elements
+func AppendArrayHeader(b []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+	}
+}
+
+// AppendMapHeader appends the header of a map with n key/value pairs
+func AppendMapHeader(b []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+	}
+}
+
+// AppendEventTime appends t as a Fluent EventTime (ext type 0, seconds and nanoseconds)
+func AppendEventTime(b []byte, t time.Time) []byte {
+	b = append(b, 0xd7, EventTimeExt)
+	b = binary.BigEndian.AppendUint32(b, uint32(t.Unix()))
+	return binary.BigEndian.AppendUint32(b, uint32(t.Nanosecond()))
+}
+
+// ParseEventTime converts a decoded EventTime extension back into a time
+func ParseEventTime(e Ext) (time.Time, bool) {
+	if e.Type != EventTimeExt || len(e.Data) != 8 {
+		return time.Time{}, false
+	}
+	sec := binary.BigEndian.Uint32(e.Data[:4])
+	nsec := binary.BigEndian.Uint32(e.Data[4:])
+	return time.Unix(int64(sec), int64(nsec)), true
+}
+
+// AppendAny appends v. Durations are written as nanoseconds, times as RFC 3339
+// strings, and types without a native representation go through encoding/json.
+func AppendAny(b []byte, v any) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return AppendNil(b), nil
+	case bool:
+		return AppendBool(b, v), nil
+	case int:
+		return AppendInt(b, int64(v)), nil
+	case int8:
+		return AppendInt(b, int64(v)), nil
+	case int16:
+		return AppendInt(b, int64(v)), nil
+	case int32:
+		return AppendInt(b, int64(v)), nil
+	case int64:
+		return AppendInt(b, v), nil
+	case uint:
+		return AppendUint(b, uint64(v)), nil
+	case uint8:
+		return AppendUint(b, uint64(v)), nil
+	case uint16:
+		return AppendUint(b, uint64(v)), nil
+	case uint32:
+		return AppendUint(b, uint64(v)), nil
+	case uint64:
+		return AppendUint(b, v), nil
+	case uintptr:
+		return AppendUint(b, uint64(v)), nil
+	case float32:
+		return AppendFloat64(b, float64(v)), nil
+	case float64:
+		return AppendFloat64(b, v), nil
+	case string:
+		return AppendString(b, v), nil
+	case []byte:
+		return AppendBytes(b, v), nil
+	case time.Duration:
+		return AppendInt(b, int64(v)), nil
+	case time.Time:
+		return AppendString(b, v.Format(time.RFC3339Nano)), nil
+	case error:
+		return AppendString(b, v.Error()), nil
+	case fmt.Stringer:
+		return AppendString(b, v.String()), nil
+	case []any:
+		b = AppendArrayHeader(b, len(v))
+		for _, e := range v {
+			var err error
+			if b, err = AppendAny(b, e); err != nil {
+				return b, err
+			}
+		}
+		return b, nil
+	case map[string]any:
+		b = AppendMapHeader(b, len(v))
+		for k, e := range v {
+			b = AppendString(b, k)
+			var err error
+			if b, err = AppendAny(b, e); err != nil {
+				return b, err
+			}
+		}
+		return b, nil
+	}
+
+	// Arbitrary structs, slices and maps: take the JSON view of the value
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return AppendNil(b), nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return b, fmt.Errorf("failed to encode %T: %w", v, err)
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return b, fmt.Errorf("failed to encode %T: %w", v, err)
+	}
+	return AppendAny(b, generic)
+}
+
+// ErrInvalid is returned by Decode for bytes that are not valid MessagePack
+var ErrInvalid = errors.New("msgpack: invalid data")
+
+// Decode reads one value from r. Integers decode as int64 (uint64 only beyond
+// math.MaxInt64), floats as float64, maps as map[string]any (non-string keys are
+// formatted with %v) and extensions as Ext.
+func Decode(r *bufio.Reader) (any, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return decodeMap(r, int(c&0x0f))
+	case c&0xf0 == 0x90:
+		return decodeArray(r, int(c&0x0f))
+	case c&0xe0 == 0xa0:
+		return decodeString(r, int(c&0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readLen(r, c-0xc4)
+		if err != nil {
+			return nil, err
+		}
+		return readN(r, n)
+	case 0xc7, 0xc8, 0xc9:
+		n, err := readLen(r, c-0xc7)
+		if err != nil {
+			return nil, err
+		}
+		return decodeExt(r, n)
+	case 0xca:
+		v, err := readUint(r, 4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case 0xcb:
+		v, err := readUint(r, 8)
+		return math.Float64frombits(v), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := readUint(r, 1<<(c-0xcc))
+		if v > math.MaxInt64 {
+			return v, err
+		}
+		return int64(v), err
+	case 0xd0:
+		v, err := readUint(r, 1)
+		return int64(int8(v)), err
+	case 0xd1:
+		v, err := readUint(r, 2)
+		return int64(int16(v)), err
+	case 0xd2:
+		v, err := readUint(r, 4)
+		return int64(int32(v)), err
+	case 0xd3:
+		v, err := readUint(r, 8)
+		return int64(v), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return decodeExt(r, 1<<(c-0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := readLen(r, c-0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return decodeString(r, n)
+	case 0xdc, 0xdd:
+		n, err := readLen(r, c-0xdc+1)
+		if err != nil {
+			return nil, err
+		}
+		return decodeArray(r, n)
+	case 0xde, 0xdf:
+		n, err := readLen(r, c-0xde+1)
+		if err != nil {
+			return nil, err
+		}
+		return decodeMap(r, n)
+	}
+	return nil, ErrInvalid
+}
+
+// readLen reads a 1, 2 or 4 byte length for size class 0, 1 or 2
+func readLen(r *bufio.Reader, class byte) (int, error) {
+	v, err := readUint(r, 1<<class)
+	return int(v), err
+}
+
+func readUint(r *bufio.Reader, size int) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:size]); err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range buf[:size] {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+func readN(r *bufio.Reader, n int) ([]byte, error) {
+	buf := make([]byte, n)
+	_, err := io.ReadFull(r, buf)
+	return buf, err
+}
+
+func decodeString(r *bufio.Reader, n int) (any, error) {
+	buf, err := readN(r, n)
+	return string(buf), err
+}
+
+func decodeExt(r *bufio.Reader, n int) (any, error) {
+	typ, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	data, err := readN(r, n)
+	return Ext{Type: int8(typ), Data: data}, err
+}
+
+func decodeArray(r *bufio.Reader, n int) (any, error) {
+	arr := make([]any, n)
+	for i := range arr {
+		v, err := Decode(r)
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = v
+	}
+	return arr, nil
+}
+
+func decodeMap(r *bufio.Reader, n int) (any, error) {
+	m := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		k, err := Decode(r)
+		if err != nil {
+			return nil, err
+		}
+		v, err := Decode(r)
+		if err != nil {
+			return nil, err
+		}
+		switch k := k.(type) {
+		case string:
+			m[k] = v
+		case []byte:
+			m[string(k)] = v
+		default:
+			m[fmt.Sprint(k)] = v
+		}
+	}
+	return m, nil
+}
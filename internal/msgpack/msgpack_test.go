@@ -0,0 +1,103 @@
+package msgpack
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeAll(t *testing.T, b []byte) any {
+	t.Helper()
+	v, err := Decode(bufio.NewReader(bytes.NewReader(b)))
+	if err != nil {
+		t.Fatalf("Failed to decode % x: %v", b, err)
+	}
+	return v
+}
+
+func TestRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    any
+		expected any
+	}{
+		{"nil", nil, nil},
+		{"true", true, true},
+		{"fixint", 7, int64(7)},
+		{"negative fixint", -5, int64(-5)},
+		{"int8", -100, int64(-100)},
+		{"int16", -1000, int64(-1000)},
+		{"int32", -100000, int64(-100000)},
+		{"int64", int64(math.MinInt64), int64(math.MinInt64)},
+		{"uint8", 200, int64(200)},
+		{"uint16", 60000, int64(60000)},
+		{"uint32", 4000000000, int64(4000000000)},
+		{"uint64", uint64(math.MaxUint64), uint64(math.MaxUint64)},
+		{"float", 1.5, 1.5},
+		{"fixstr", "hello", "hello"},
+		{"str8", strings.Repeat("a", 200), strings.Repeat("a", 200)},
+		{"str16", strings.Repeat("b", 70000), strings.Repeat("b", 70000)},
+		{"bin", []byte{1, 2, 3}, []byte{1, 2, 3}},
+		{"duration", 1500 * time.Millisecond, int64(1500 * time.Millisecond)},
+		{"array", []any{1, "x", nil}, []any{int64(1), "x", nil}},
+		{"map", map[string]any{"k": map[string]any{"n": 1}}, map[string]any{"k": map[string]any{"n": int64(1)}}},
+		{"struct", struct {
+			Name string `json:"name"`
+		}{"x"}, map[string]any{"name": "x"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := AppendAny(nil, tc.value)
+			if err != nil {
+				t.Fatalf("Failed to encode: %v", err)
+			}
+			if got := decodeAll(t, b); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected %#v, got %#v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestLargeCollections(t *testing.T) {
+	arr := make([]any, 20)
+	m := make(map[string]any, 20)
+	for i := range arr {
+		arr[i] = i
+		m[strings.Repeat("k", i+1)] = i
+	}
+
+	b, _ := AppendAny(nil, arr)
+	if got := decodeAll(t, b).([]any); len(got) != 20 || got[19] != int64(19) {
+		t.Errorf("Unexpected array: %v", got)
+	}
+	b, _ = AppendAny(nil, m)
+	if got := decodeAll(t, b).(map[string]any); len(got) != 20 {
+		t.Errorf("Unexpected map: %v", got)
+	}
+}
+
+func TestEventTime(t *testing.T) {
+	ts := time.Date(2026, 3, 9, 14, 30, 0, 123456789, time.UTC)
+	ext, ok := decodeAll(t, AppendEventTime(nil, ts)).(Ext)
+	if !ok {
+		t.Fatal("Expected an extension value")
+	}
+	got, ok := ParseEventTime(ext)
+	if !ok || !got.Equal(ts) {
+		t.Errorf("Expected %v, got %v", ts, got)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	if _, err := Decode(bufio.NewReader(bytes.NewReader([]byte{0xc1}))); err != ErrInvalid {
+		t.Errorf("Expected ErrInvalid for reserved byte, got %v", err)
+	}
+	if _, err := Decode(bufio.NewReader(bytes.NewReader([]byte{0xa5, 'a'}))); err == nil {
+		t.Error("Expected error for truncated string")
+	}
+}
@@ -0,0 +1,129 @@
+package logger_test
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+// legacyOutput logs msg through a logger built with MustNew(cfg) and returns
+// what reached stdout
+func legacyOutput(t *testing.T, cfg *logger.Config, msg string) string {
+	t.Helper()
+	output, err := testutil.CaptureStdout(func() {
+		log := logger.MustNew(cfg)
+		log.Debug(msg+" debug", logger.String("legacy", "true"))
+		log.Info(msg, logger.String("legacy", "true"))
+		_ = log.Close(context.Background())
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture stdout: %v", err)
+	}
+	return output
+}
+
+func TestLegacyConfigJSONConsole(t *testing.T) {
+	output := legacyOutput(t, logger.DefaultConfig(), "Legacy JSON")
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected only the info entry at the default level, got %q", output)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Expected a JSON console line, got %q: %v", lines[0], err)
+	}
+	if entry["msg"] != "Legacy JSON" || entry["legacy"] != "true" {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+}
+
+func TestLegacyConfigTextConsole(t *testing.T) {
+	output := legacyOutput(t, &logger.Config{
+		Level:          logger.DebugLevel,
+		JSON:           false,
+		ConsoleEnabled: true,
+	}, "Legacy text")
+
+	if !strings.Contains(output, "Legacy text debug") {
+		t.Errorf("Expected the debug entry at DebugLevel, got %q", output)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if json.Valid([]byte(line)) {
+			t.Errorf("Expected text console output with JSON:false, got %q", line)
+		}
+		if !strings.Contains(line, `{"legacy": "true"}`) {
+			t.Errorf("Expected the fields after the message, got %q", line)
+		}
+	}
+}
+
+func TestLegacyConfigConsoleDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.log")
+	output := legacyOutput(t, &logger.Config{
+		Level:      logger.InfoLevel,
+		JSON:       true,
+		FileConfig: &logger.FileConfig{Filename: path, MaxSize: 10},
+	}, "Legacy file only")
+
+	if output != "" {
+		t.Errorf("Expected nothing on the console with ConsoleEnabled:false, got %q", output)
+	}
+	entries := testutil.ReadJSONLines(t, path)
+	if len(entries) != 1 || entries[0]["msg"] != "Legacy file only" {
+		t.Errorf("Expected the info entry in the file, got %v", entries)
+	}
+}
+
+func TestLegacyConfigLevel(t *testing.T) {
+	opts, err := (&logger.Config{}).Options()
+	if err != nil {
+		t.Fatalf("Options failed: %v", err)
+	}
+	if opts.Level != logger.InfoLevel || !opts.DisableConsole || opts.Env != logger.EnvProd {
+		t.Errorf("Expected a production InfoLevel logger without console, got %+v", opts)
+	}
+
+	opts, err = (&logger.Config{Level: "WARNING"}).Options()
+	if err != nil || opts.Level != logger.WarnLevel {
+		t.Errorf("Expected a case-insensitive level, got %q, %v", opts.Level, err)
+	}
+
+	if _, err := (&logger.Config{Level: "verbose"}).Options(); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected MustNew to panic for an unknown level")
+		}
+	}()
+	logger.MustNew(&logger.Config{Level: "verbose"})
+}
+
+func TestConsoleEncodingOverride(t *testing.T) {
+	output, err := testutil.CaptureStdout(func() {
+		log, err := logger.NewDevelopment(logger.WithConsoleEncoding(logger.ConsoleJSON))
+		if err != nil {
+			t.Errorf("Failed to create logger: %v", err)
+			return
+		}
+		log.Info("Dev JSON")
+		_ = log.Close(context.Background())
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture stdout: %v", err)
+	}
+	if !json.Valid([]byte(strings.TrimSpace(output))) {
+		t.Errorf("Expected JSON console output in dev, got %q", output)
+	}
+
+	if _, err := logger.NewProduction(logger.WithConsoleEncoding("yaml")); err == nil {
+		t.Error("Expected an error for an unknown console encoding")
+	}
+}
@@ -14,6 +14,12 @@ const (
 	InfoLevel  Level = "info"
 	WarnLevel  Level = "warn"
 	ErrorLevel Level = "error"
+	// DPanicLevel panics after logging in EnvDev; elsewhere it logs at error
+	// level with a "dpanic" field
+	DPanicLevel Level = "dpanic"
+	// FatalLevel runs the FatalHook after logging; by default the sinks are
+	// closed and the process exits
+	FatalLevel Level = "fatal"
 )
 
 func ParseLevel(s string) (Level, error) {
@@ -26,6 +32,10 @@ func ParseLevel(s string) (Level, error) {
 		return WarnLevel, nil
 	case "error":
 		return ErrorLevel, nil
+	case "dpanic":
+		return DPanicLevel, nil
+	case "fatal":
+		return FatalLevel, nil
 	default:
 		return "", fmt.Errorf("unknown level %q", s)
 	}
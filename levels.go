@@ -0,0 +1,35 @@
+package logger
+
+import "errors"
+
+// ErrLevelsNotSupported is returned by SetLevel and SetLevelFor for a logger
+// whose levels can't change at runtime
+var ErrLevelsNotSupported = errors.New("runtime level changes not supported")
+
+// LevelController is implemented by loggers whose levels can change at runtime.
+// Changes apply to the root logger and every logger derived from it.
+type LevelController interface {
+	SetLevel(level Level) error
+	SetLevelFor(name string, level Level) error
+}
+
+// SetLevel changes the minimum level of log's logger tree. Names with an
+// override keep theirs.
+func SetLevel(log Logger, level Level) error {
+	c, ok := log.(LevelController)
+	if !ok {
+		return ErrLevelsNotSupported
+	}
+	return c.SetLevel(level)
+}
+
+// SetLevelFor overrides the minimum level of the loggers named name and their
+// children, like an entry of Options.LevelOverrides. An empty level removes the
+// override.
+func SetLevelFor(log Logger, name string, level Level) error {
+	c, ok := log.(LevelController)
+	if !ok {
+		return ErrLevelsNotSupported
+	}
+	return c.SetLevelFor(name, level)
+}
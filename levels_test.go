@@ -0,0 +1,103 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+// Per-name level overrides
+
+func TestLevelOverrides(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []logger.Option
+	}{
+		{"tee", nil},
+		{"parallel", []logger.Option{logger.WithParallelSinks()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			logPath := filepath.Join(t.TempDir(), "app.log")
+			opts := append([]logger.Option{
+				logger.WithConsoleDisabled(),
+				logger.WithFile(logger.FileSink{Path: logPath}),
+				logger.WithLevel(logger.InfoLevel),
+				logger.WithLevelOverrides(map[string]logger.Level{"billing": logger.DebugLevel}),
+			}, tc.opts...)
+			log, err := logger.NewProduction(opts...)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+
+			billing := log.Named("billing")
+			api := log.Named("api")
+			billing.Debug("billing debug")
+			billing.Named("invoices").Debug("invoices debug")
+			log.Named("billingx").Debug("billingx debug")
+			api.Debug("api debug")
+			api.Info("api info")
+
+			// Runtime changes reach loggers created before them
+			if err := logger.SetLevelFor(log, "api", logger.DebugLevel); err != nil {
+				t.Fatalf("SetLevelFor failed: %v", err)
+			}
+			if err := logger.SetLevelFor(log, "billing", ""); err != nil {
+				t.Fatalf("SetLevelFor failed: %v", err)
+			}
+			api.Debug("api debug after override")
+			billing.Debug("billing debug after removal")
+			if err := logger.SetLevel(log, logger.WarnLevel); err != nil {
+				t.Fatalf("SetLevel failed: %v", err)
+			}
+			billing.Info("billing info after warn")
+			api.Debug("api debug after warn")
+
+			if err := log.Close(context.Background()); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+			var got []string
+			for _, e := range testutil.ReadJSONLines(t, logPath) {
+				got = append(got, e["logger"].(string)+": "+e["msg"].(string))
+			}
+			want := []string{
+				"billing: billing debug",
+				"billing.invoices: invoices debug",
+				"api: api info",
+				"api: api debug after override",
+				"api: api debug after warn",
+			}
+			if len(got) != len(want) {
+				t.Fatalf("Expected %q, got %q", want, got)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("Entry %d: expected %q, got %q", i, want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLevelOverridesValidation(t *testing.T) {
+	_, err := logger.NewProduction(logger.WithLevelOverrides(map[string]logger.Level{"billing": "verbose"}))
+	if err == nil {
+		t.Fatal("Expected an invalid override to fail the build")
+	}
+
+	log, err := logger.NewProduction(logger.WithConsoleDisabled(), logger.WithRing(logger.RingSink{Capacity: 8}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+	if err := logger.SetLevel(log, ""); err == nil {
+		t.Error("Expected SetLevel to reject an empty level")
+	}
+	if err := logger.SetLevel(struct{ logger.Logger }{log}, logger.DebugLevel); !errors.Is(err, logger.ErrLevelsNotSupported) {
+		t.Errorf("Expected ErrLevelsNotSupported, got %v", err)
+	}
+}
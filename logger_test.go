@@ -1,13 +1,23 @@
 package logger_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	logger "github.com/HoangAnhNguyen269/loggerkit"
 	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx" // Import to register the builder
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
 )
 
 func TestNewDevelopment(t *testing.T) {
@@ -69,6 +79,43 @@ func TestWithFields(t *testing.T) {
 	contextLog.Info("Message with context")
 }
 
+func TestNamed(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithContext(logger.ContextKeys{RequestIDKey: "request_id"}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	api := log.Named("api")
+	api.Info("api entry")
+	api.Named("handlers").Named("user").Info("nested entry")
+	ctx := context.WithValue(context.Background(), "request_id", "req-1")
+	api.With(logger.F.String("route", "/users")).WithContext(ctx).Info("derived entry")
+	log.Info("root entry")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	want := []string{"api", "api.handlers.user", "api", ""}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %d", len(want), len(entries))
+	}
+	for i, name := range want {
+		got, _ := entries[i]["logger"].(string)
+		if got != name {
+			t.Errorf("Entry %q: expected logger %q, got %q", entries[i]["msg"], name, got)
+		}
+	}
+	if d := entries[2]; d["route"] != "/users" || d["request_id"] != "req-1" {
+		t.Errorf("Expected derived fields on the named entry, got %v", d)
+	}
+}
+
 func TestContextLogger(t *testing.T) {
 	log, err := logger.NewDevelopment()
 	if err != nil {
@@ -100,8 +147,8 @@ func TestMetrics(t *testing.T) {
 
 	// Get metrics collectors
 	collectors := logger.MetricsCollectors()
-	if len(collectors) != 5 {
-		t.Errorf("Expected 5 metric collectors, got %d", len(collectors))
+	if len(collectors) != 14 {
+		t.Errorf("Expected 14 metric collectors, got %d", len(collectors))
 	}
 
 	// Log some messages to generate metrics
@@ -168,6 +215,38 @@ func TestRaceCondition(t *testing.T) {
 	wg.Wait()
 }
 
+func TestConcurrentFieldReuse(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+
+	// Pooled field slices must never leak values between concurrent calls
+	var wg sync.WaitGroup
+	for g := 0; g < 20; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				id := fmt.Sprintf("%d-%d", g, i)
+				log.Info(id, logger.F.String("id", id), logger.F.Int("g", g), logger.F.Int("i", i), logger.F.Bool("even", i%2 == 0))
+			}
+		}()
+	}
+	wg.Wait()
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 4000 {
+		t.Fatalf("Expected 4000 entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		want := fmt.Sprintf("%v-%v", e["g"], e["i"])
+		if e["msg"] != want || e["id"] != want || e["even"] != (int(e["i"].(float64))%2 == 0) {
+			t.Fatalf("Fields of another entry leaked into %v", e)
+		}
+	}
+}
+
 func TestFileLogging(t *testing.T) {
 	tempFile := "/tmp/test_logger.log"
 
@@ -211,6 +290,249 @@ func TestFieldHelpers(t *testing.T) {
 	log.Error("Error with custom error", logger.F.Err(testErr))
 }
 
+func TestFVRendering(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	log.Info("typed",
+		logger.FV("string", "value"),
+		logger.FV("int", 42),
+		logger.FV("int64", int64(1)<<40),
+		logger.FV("float64", 2.5),
+		logger.FV("bool", true),
+		logger.FV("time", at),
+		logger.FV("duration", 1500*time.Millisecond),
+		logger.FV("strings", []string{"a", "b"}),
+		logger.FV("struct", struct{ N int }{7}), // Falls back to zap.Any
+	)
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 1 {
+		t.Fatalf("Expected one entry, got %v", entries)
+	}
+	e := entries[0]
+	testCases := []struct {
+		key  string
+		want any
+	}{
+		{"string", "value"},
+		{"int", float64(42)},
+		{"int64", float64(int64(1) << 40)},
+		{"float64", 2.5},
+		{"bool", true},
+		{"duration", 1.5}, // Seconds, like F.Duration
+		{"strings", []any{"a", "b"}},
+		{"struct", map[string]any{"N": float64(7)}},
+	}
+	for _, tc := range testCases {
+		if !reflect.DeepEqual(e[tc.key], tc.want) {
+			t.Errorf("%s: expected %v, got %v (%T)", tc.key, tc.want, e[tc.key], e[tc.key])
+		}
+	}
+	if ts, _ := e["time"].(string); !strings.HasPrefix(ts, "2024-01-02T03:04:05") {
+		t.Errorf("Expected the time encoded like the entry timestamp, got %v", e["time"])
+	}
+}
+
+func TestDuplicateFieldKeys(t *testing.T) {
+	var diag bytes.Buffer
+	dev, err := logger.NewDevelopment(logger.WithConsoleDisabled(), logger.WithRing(logger.RingSink{}), logger.WithDiagnostics(&diag))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer dev.Close(context.Background())
+
+	userLog := dev.With(logger.F.String("user", "a"))
+	userLog.Info("login", logger.F.String("user", "b"))
+	userLog.Info("login", logger.F.String("user", "b")) // Reported once
+	dev.Info("twice", logger.F.Int("n", 1), logger.F.Int("n", 2))
+	dev.Info("distinct", logger.F.Int("n", 1), logger.F.Int("m", 2))
+	want := "loggerkit: duplicate field key \"user\" in entry \"login\"\n" +
+		"loggerkit: duplicate field key \"n\" in entry \"twice\"\n"
+	if diag.String() != want {
+		t.Errorf("Expected diagnostics %q, got %q", want, diag.String())
+	}
+
+	diag.Reset()
+	prod, err := logger.NewProduction(logger.WithConsoleDisabled(), logger.WithRing(logger.RingSink{}), logger.WithDiagnostics(&diag))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer prod.Close(context.Background())
+	prod.With(logger.F.String("user", "a")).Info("login", logger.F.String("user", "b"))
+	if diag.Len() != 0 {
+		t.Errorf("Expected production to skip the check, got %q", diag.String())
+	}
+
+	strict, err := logger.NewProduction(logger.WithConsoleDisabled(), logger.WithRing(logger.RingSink{}), logger.WithStrictFields())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer strict.Close(context.Background())
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), `duplicate field key "user"`) {
+			t.Errorf("Expected a duplicate key panic, got %v", r)
+		}
+	}()
+	strict.With(logger.F.String("user", "a")).Info("login", logger.F.String("user", "b"))
+}
+
+func TestDPanic(t *testing.T) {
+	if lvl, err := logger.ParseLevel("DPANIC"); err != nil || lvl != logger.DPanicLevel {
+		t.Fatalf("Expected dpanic to parse, got %q, %v", lvl, err)
+	}
+
+	prod, prodPath := testutil.FileLogger(t)
+	fields := make([]logger.Field, 1, 4)
+	fields[0] = logger.F.String("order", "o-1")
+	prod.DPanic("impossible state", fields...)
+	prod.Log(logger.DPanicLevel, "impossible state")
+	if err := prod.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if fields[:2][1] != (logger.Field{}) {
+		t.Error("DPanic wrote into the caller's field slice")
+	}
+	entries := testutil.ReadJSONLines(t, prodPath)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if e["level"] != "error" || e["dpanic"] != true {
+			t.Errorf("Expected an error entry flagged dpanic in production, got %v", e)
+		}
+	}
+
+	devPath := filepath.Join(t.TempDir(), "dev.log")
+	dev, err := logger.NewDevelopment(logger.WithConsoleDisabled(), logger.WithFile(logger.FileSink{Path: devPath}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != "impossible state" {
+				t.Errorf("Expected DPanic to panic in development, got %v", r)
+			}
+		}()
+		dev.With(logger.F.String("order", "o-2")).DPanic("impossible state")
+	}()
+	if err := dev.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	entries = testutil.ReadJSONLines(t, devPath)
+	if len(entries) != 1 || entries[0]["level"] != "dpanic" || entries[0]["order"] != "o-2" {
+		t.Errorf("Expected the entry written before the panic, got %v", entries)
+	}
+}
+
+func TestFatalBehavior(t *testing.T) {
+	var got []logger.FatalEntry
+	record := func(e logger.FatalEntry) { got = append(got, e) }
+
+	for _, hook := range []logger.FatalHook{logger.FatalReturn, record} {
+		log, err := logger.NewProduction(
+			logger.WithConsoleDisabled(),
+			logger.WithRing(logger.RingSink{}),
+			logger.WithFatalBehavior(hook),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		log.With(logger.F.String("component", "db")).Fatal("cannot connect", logger.F.Int("attempts", 3))
+		log.Info("still running") // Control returned
+
+		_, docs := ringDump(t, log, "")
+		if len(docs) != 2 || docs[0]["level"] != "fatal" || docs[0]["component"] != "db" || docs[1]["msg"] != "still running" {
+			t.Errorf("Expected the fatal entry in the sink and logging to go on, got %v", docs)
+		}
+		log.Close(context.Background())
+	}
+
+	if len(got) != 1 || got[0].Message != "cannot connect" || got[0].Fields["attempts"] != int64(3) ||
+		!strings.Contains(got[0].Caller, "logger_test.go") || got[0].Time.IsZero() {
+		t.Errorf("Expected the hook to receive the entry, got %+v", got)
+	}
+
+	log, err := logger.NewProduction(logger.WithConsoleDisabled(), logger.WithRing(logger.RingSink{}), logger.WithFatalBehavior(logger.FatalPanic))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+	defer func() {
+		if r := recover(); r != "cannot connect" {
+			t.Errorf("Expected FatalPanic to panic, got %v", r)
+		}
+	}()
+	log.Fatal("cannot connect")
+}
+
+// TestFatalDefaultExit runs itself in a subprocess, which must exit with status 1
+// after Fatal closed the sinks
+func TestFatalDefaultExit(t *testing.T) {
+	if path := os.Getenv("LOGGERKIT_FATAL_LOG"); path != "" {
+		log, err := logger.NewProduction(
+			logger.WithConsoleDisabled(),
+			logger.WithFile(logger.FileSink{Path: path}),
+			logger.WithBufferedWrites(logger.BufferOptions{FlushInterval: time.Hour}), // Only Close flushes
+		)
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		log.Fatal("cannot connect")
+		return
+	}
+
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatalDefaultExit$")
+	cmd.Env = append(os.Environ(), "LOGGERKIT_FATAL_LOG="+logPath)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Fatalf("Expected exit status 1, got %v", err)
+	}
+	if entries := testutil.ReadJSONLines(t, logPath); len(entries) != 1 || entries[0]["level"] != "fatal" {
+		t.Errorf("Expected the fatal entry flushed before exiting, got %v", entries)
+	}
+}
+
+func TestLineBufferedConsole(t *testing.T) {
+	output, err := testutil.CaptureStdout(func() {
+		log, err := logger.NewProduction(logger.WithLineBufferedConsole(0))
+		if err != nil {
+			t.Errorf("Failed to create logger: %v", err)
+			return
+		}
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 25; i++ {
+					log.Info(fmt.Sprintf("Line %d from %d", i, g))
+				}
+			}(g)
+		}
+		wg.Wait()
+		closeLogger(t, log) // Writes what is still queued
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture stdout: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 200 {
+		t.Fatalf("Expected 200 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Expected whole JSON lines, got %q: %v", line, err)
+		}
+	}
+}
+
 type customError struct {
 	message string
 }
@@ -0,0 +1,259 @@
+// Package loggertest checks that a provider behaves like loggerkit's reference
+// provider, zapx. Providers run the suite from a test:
+//
+//	func TestConformance(t *testing.T) {
+//		loggertest.RunConformance(t, mybackend.NewWithOptions)
+//	}
+package loggertest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Capture is the sink the suite reads entries back from: a file the provider
+// writes one JSON object per line to, as configured by Options.File
+type Capture struct {
+	Path string
+}
+
+// NewCapture returns a capture in a temporary directory of t
+func NewCapture(t testing.TB) *Capture {
+	return &Capture{Path: filepath.Join(t.TempDir(), "conformance.log")}
+}
+
+// Options returns production options writing only to c, without sampling
+func (c *Capture) Options(opts ...logger.Option) logger.Options {
+	o := logger.DefaultProductionOptions()
+	o.Sampling = nil
+	o.DisableConsole = true
+	o.File = &logger.FileSink{Path: c.Path}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Entries decodes the entries written so far
+func (c *Capture) Entries(t testing.TB) []map[string]any {
+	t.Helper()
+	f, err := os.Open(c.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", c.Path, err)
+	}
+	defer f.Close()
+
+	var entries []map[string]any
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Failed to read %s: %v", c.Path, err)
+	}
+	return entries
+}
+
+// RunConformance checks the semantics every provider must share: level
+// filtering, core keys, With inheritance and override order, Named, WithContext
+// trace extraction, Close idempotency and concurrent use. newLogger must
+// support the file sink used by Capture.
+func RunConformance(t *testing.T, newLogger func(opts logger.Options) (logger.Logger, error)) {
+	t.Helper()
+	build := func(t *testing.T, opts ...logger.Option) (logger.Logger, *Capture) {
+		t.Helper()
+		c := NewCapture(t)
+		log, err := newLogger(c.Options(opts...))
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		return log, c
+	}
+	closeLogger := func(t *testing.T, log logger.Logger) {
+		t.Helper()
+		if err := log.Close(context.Background()); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+
+	t.Run("LevelFiltering", func(t *testing.T) {
+		log, c := build(t, logger.WithLevel(logger.WarnLevel))
+		log.Debug("debug")
+		log.Info("info")
+		log.Warn("warn")
+		log.Error("error")
+		log.Log(logger.InfoLevel, "dynamic info")
+		log.Log(logger.ErrorLevel, "dynamic error")
+		closeLogger(t, log)
+
+		want := []string{"warn", "error", "error"}
+		entries := c.Entries(t)
+		if len(entries) != len(want) {
+			t.Fatalf("Expected %d entries at warn and above, got %d", len(want), len(entries))
+		}
+		for i, level := range want {
+			if entries[i]["level"] != level {
+				t.Errorf("Entry %d: expected level %q, got %v", i, level, entries[i]["level"])
+			}
+		}
+	})
+
+	t.Run("CoreKeys", func(t *testing.T) {
+		log, c := build(t)
+		log.Info("hello", logger.F.String("user", "u-1"), logger.F.Int("attempt", 2), logger.F.Bool("ok", true))
+		closeLogger(t, log)
+
+		entries := c.Entries(t)
+		if len(entries) != 1 {
+			t.Fatalf("Expected 1 entry, got %d", len(entries))
+		}
+		e := entries[0]
+		for _, key := range []string{"ts", "level", "msg"} {
+			if _, ok := e[key]; !ok {
+				t.Errorf("Expected key %q in %v", key, e)
+			}
+		}
+		if e["msg"] != "hello" || e["level"] != "info" || e["user"] != "u-1" || e["attempt"] != float64(2) || e["ok"] != true {
+			t.Errorf("Unexpected entry %v", e)
+		}
+	})
+
+	t.Run("WithInheritance", func(t *testing.T) {
+		log, c := build(t)
+		parent := log.With(logger.F.String("service", "billing"))
+		child := parent.With(logger.F.String("request", "r-1"))
+		child.Info("child")
+		parent.Info("parent")
+		log.Info("root")
+		closeLogger(t, log)
+
+		entries := c.Entries(t)
+		if len(entries) != 3 {
+			t.Fatalf("Expected 3 entries, got %d", len(entries))
+		}
+		if e := entries[0]; e["service"] != "billing" || e["request"] != "r-1" {
+			t.Errorf("Expected the child to inherit the parent's fields, got %v", e)
+		}
+		if e := entries[1]; e["service"] != "billing" || e["request"] != nil {
+			t.Errorf("Expected the parent to keep only its own fields, got %v", e)
+		}
+		if e := entries[2]; e["service"] != nil {
+			t.Errorf("Expected the root without bound fields, got %v", e)
+		}
+	})
+
+	t.Run("FieldOverrideOrder", func(t *testing.T) {
+		// A key bound by With and passed again at the call site: readers that
+		// keep the last occurrence see the call-site value
+		log, c := build(t)
+		log.With(logger.F.String("k", "bound")).Info("override", logger.F.String("k", "call site"))
+		closeLogger(t, log)
+
+		entries := c.Entries(t)
+		if len(entries) != 1 || entries[0]["k"] != "call site" {
+			t.Errorf("Expected the call-site value to win, got %v", entries)
+		}
+	})
+
+	t.Run("Named", func(t *testing.T) {
+		log, c := build(t)
+		log.Named("api").Named("users").With(logger.F.Int("n", 1)).Info("named")
+		closeLogger(t, log)
+
+		entries := c.Entries(t)
+		if len(entries) != 1 || entries[0]["logger"] != "api.users" || entries[0]["n"] != float64(1) {
+			t.Errorf("Expected logger \"api.users\", got %v", entries)
+		}
+	})
+
+	t.Run("WithContextTrace", func(t *testing.T) {
+		log, c := build(t)
+		sc := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{0x0a, 0x0b},
+			SpanID:     trace.SpanID{0x0c},
+			TraceFlags: trace.FlagsSampled,
+		})
+		log.WithContext(trace.ContextWithSpanContext(context.Background(), sc)).Info("traced")
+		log.WithContext(context.Background()).Info("untraced")
+		closeLogger(t, log)
+
+		entries := c.Entries(t)
+		if len(entries) != 2 {
+			t.Fatalf("Expected 2 entries, got %d", len(entries))
+		}
+		e := entries[0]
+		if e["trace_id"] != sc.TraceID().String() || e["span_id"] != sc.SpanID().String() || e["trace_sampled"] != true {
+			t.Errorf("Expected trace_id, span_id and trace_sampled, got %v", e)
+		}
+		if _, ok := entries[1]["trace_id"]; ok {
+			t.Errorf("Expected no trace_id without a span, got %v", entries[1])
+		}
+	})
+
+	t.Run("CloseIdempotent", func(t *testing.T) {
+		log, c := build(t)
+		child := log.With(logger.F.Int("n", 1))
+		log.Info("before close")
+		if err := child.Close(context.Background()); err != nil {
+			t.Errorf("Close on a child failed: %v", err)
+		}
+		log.Info("after child close")
+		closeLogger(t, log)
+		if err := log.Close(context.Background()); err != nil {
+			t.Errorf("Expected a second Close to return nil, got %v", err)
+		}
+		// Logging after Close must not panic; the entry may be dropped
+		log.Info("after close")
+		child.Info("child after close")
+
+		entries := c.Entries(t)
+		if len(entries) < 2 || entries[0]["msg"] != "before close" || entries[1]["msg"] != "after child close" {
+			t.Errorf("Expected the sinks to stay open until the root closes, got %v", entries)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		log, c := build(t)
+		const goroutines, perGoroutine = 8, 50
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				child := log.With(logger.F.Int("g", g))
+				for i := 0; i < perGoroutine; i++ {
+					child.Info(fmt.Sprintf("entry %d-%d", g, i), logger.F.Int("i", i))
+				}
+			}()
+		}
+		wg.Wait()
+		closeLogger(t, log)
+
+		entries := c.Entries(t)
+		if len(entries) != goroutines*perGoroutine {
+			t.Fatalf("Expected %d entries, got %d", goroutines*perGoroutine, len(entries))
+		}
+		for _, e := range entries {
+			if want := fmt.Sprintf("entry %v-%v", e["g"], e["i"]); e["msg"] != want {
+				t.Fatalf("Fields of another entry leaked into %v", e)
+			}
+		}
+	})
+}
@@ -0,0 +1,18 @@
+package loggertest_test
+
+import (
+	"testing"
+
+	"github.com/HoangAnhNguyen269/loggerkit/loggertest"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/slogx"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+)
+
+// zapx is the reference provider
+func TestZapxConformance(t *testing.T) {
+	loggertest.RunConformance(t, zapx.NewWithOptions)
+}
+
+func TestSlogxConformance(t *testing.T) {
+	loggertest.RunConformance(t, slogx.NewWithOptions)
+}
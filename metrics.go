@@ -7,35 +7,68 @@ import (
 
 // Metrics holds all the Prometheus metrics for the logger
 type Metrics struct {
-	LogsWritten   *prometheus.CounterVec
-	LogsDropped   *prometheus.CounterVec
-	ESBulkRetries *prometheus.CounterVec
-	ESQueueDepth  *prometheus.GaugeVec
-	ESBulkLatency *prometheus.HistogramVec
+	LogsWritten        *prometheus.CounterVec // By level and sink, curried with the service label
+	LogsDropped        *prometheus.CounterVec // By sink and reason, curried with the service label
+	ESBulkRetries      *prometheus.CounterVec
+	ESQueueDepth       *prometheus.GaugeVec
+	ESBulkLatency      *prometheus.HistogramVec
+	ESFieldsOverflow   prometheus.Counter
+	ESBulkItemFailures *prometheus.CounterVec
+	ESDLQWriteFailures prometheus.Counter
+	AuditEvents        *prometheus.CounterVec
+	AuditFailures      *prometheus.CounterVec
+	ShadowFailures     *prometheus.CounterVec
+	ExtractorPanics    prometheus.Counter
+	FieldViolations    *prometheus.CounterVec
+
+	shadowSink string // Set on the copy returned by Shadow
+	esIndexer  *indexerStatsCollector
+
+	// Vectors behind LogsWritten and LogsDropped, with the "service" label
+	logsWritten *prometheus.CounterVec
+	logsDropped *prometheus.CounterVec
 }
 
+// serviceLabel is the label ForService sets on logs_written_total and
+// logs_dropped_total. It is empty otherwise, which Prometheus treats as absent.
+const serviceLabel = "service"
+
 var (
 	metricsOnce sync.Once
 	metrics     *Metrics
 )
 
+// MetricsReporter is implemented by loggers that can tell which metrics they record into
+type MetricsReporter interface {
+	Metrics() *Metrics
+}
+
+// MetricsOf returns the metrics log records into, or nil when its metrics are
+// disabled or it can't tell
+func MetricsOf(log Logger) *Metrics {
+	if r, ok := log.(MetricsReporter); ok {
+		return r.Metrics()
+	}
+	return nil
+}
+
 // GetMetrics returns the singleton metrics instance
 func GetMetrics() *Metrics {
 	metricsOnce.Do(func() {
 		metrics = &Metrics{
-			LogsWritten: prometheus.NewCounterVec(
+			logsWritten: prometheus.NewCounterVec(
 				prometheus.CounterOpts{
 					Name: "logs_written_total",
 					Help: "Total number of log messages written",
 				},
-				[]string{"level", "sink"},
+				[]string{"level", "sink", serviceLabel},
 			),
-			LogsDropped: prometheus.NewCounterVec(
+			logsDropped: prometheus.NewCounterVec(
 				prometheus.CounterOpts{
 					Name: "logs_dropped_total",
 					Help: "Total number of log messages dropped",
 				},
-				[]string{"sink", "reason"},
+				[]string{"sink", "reason", serviceLabel},
 			),
 			ESBulkRetries: prometheus.NewCounterVec(
 				prometheus.CounterOpts{
@@ -59,20 +92,106 @@ func GetMetrics() *Metrics {
 				},
 				[]string{"operation", "status"},
 			),
+			ESFieldsOverflow: prometheus.NewCounter(
+				prometheus.CounterOpts{
+					Name: "es_fields_overflow_total",
+					Help: "Total number of Elasticsearch documents with fields folded into fields_overflow",
+				},
+			),
+			ESBulkItemFailures: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "es_bulk_item_failures_total",
+					Help: "Total number of documents rejected by Elasticsearch in bulk responses",
+				},
+				[]string{"status_class"},
+			),
+			ESDLQWriteFailures: prometheus.NewCounter(
+				prometheus.CounterOpts{
+					Name: "es_dlq_write_failures_total",
+					Help: "Total number of Elasticsearch DLQ entries that could not be written",
+				},
+			),
+			AuditEvents: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "audit_events_total",
+					Help: "Total number of audit events stored",
+				},
+				[]string{"sink"},
+			),
+			AuditFailures: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "audit_failures_total",
+					Help: "Total number of audit events that could not be stored",
+				},
+				[]string{"sink"},
+			),
+			ShadowFailures: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "shadow_failures_total",
+					Help: "Total number of entries a shadow sink failed to deliver",
+				},
+				[]string{"sink", "reason"},
+			),
+			ExtractorPanics: prometheus.NewCounter(
+				prometheus.CounterOpts{
+					Name: "context_extractor_panics_total",
+					Help: "Total number of context extractors that panicked",
+				},
+			),
+			FieldViolations: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "logs_field_violations_total",
+					Help: "Total number of fields whose key is not in the field allowlist",
+				},
+				[]string{"key"},
+			),
 		}
+		metrics.esIndexer = newIndexerStatsCollector()
+		metrics.curryService("")
 	})
 	return metrics
 }
 
+// ForService returns metrics whose logs_written_total and logs_dropped_total
+// series carry service as their "service" label, for processes running
+// loggers of several services. The label is curried once here, so recording
+// costs the same as without it.
+func (m *Metrics) ForService(service string) *Metrics {
+	if m == nil {
+		return nil
+	}
+	scoped := *m
+	scoped.curryService(service)
+	return &scoped
+}
+
+func (m *Metrics) curryService(service string) {
+	if m.logsWritten == nil || m.logsDropped == nil {
+		return
+	}
+	labels := prometheus.Labels{serviceLabel: service}
+	m.LogsWritten = m.logsWritten.MustCurryWith(labels)
+	m.LogsDropped = m.logsDropped.MustCurryWith(labels)
+}
+
 // MetricsCollectors returns all metric collectors for manual registration
 func MetricsCollectors() []prometheus.Collector {
 	m := GetMetrics()
 	return []prometheus.Collector{
-		m.LogsWritten,
-		m.LogsDropped,
+		m.logsWritten,
+		m.logsDropped,
 		m.ESBulkRetries,
 		m.ESQueueDepth,
 		m.ESBulkLatency,
+		m.ESFieldsOverflow,
+		m.ESBulkItemFailures,
+		m.ESDLQWriteFailures,
+		m.AuditEvents,
+		m.AuditFailures,
+		m.ShadowFailures,
+		m.ExtractorPanics,
+		m.FieldViolations,
+		m.esIndexer,
 	}
 }
 
@@ -96,8 +215,13 @@ func (m *Metrics) RecordLogWritten(level, sink string) {
 	}
 }
 
-// RecordLogDropped records a log message being dropped
+// RecordLogDropped records a log message being dropped. On metrics returned by
+// Shadow, it records a shadow failure instead.
 func (m *Metrics) RecordLogDropped(sink, reason string) {
+	if m != nil && m.shadowSink != "" {
+		m.RecordShadowFailure(m.shadowSink, reason)
+		return
+	}
 	if m != nil && m.LogsDropped != nil {
 		m.LogsDropped.WithLabelValues(sink, reason).Inc()
 	}
@@ -123,3 +247,163 @@ func (m *Metrics) RecordESBulkLatency(operation, status string, latency float64)
 		m.ESBulkLatency.WithLabelValues(operation, status).Observe(latency)
 	}
 }
+
+// RecordESFieldsOverflow records a document whose extra fields were folded into fields_overflow
+func (m *Metrics) RecordESFieldsOverflow() {
+	if m != nil && m.ESFieldsOverflow != nil {
+		m.ESFieldsOverflow.Inc()
+	}
+}
+
+// RecordESBulkItemFailure records a document rejected in a bulk response ("4xx", "5xx" or "other")
+func (m *Metrics) RecordESBulkItemFailure(statusClass string) {
+	if m != nil && m.ESBulkItemFailures != nil {
+		m.ESBulkItemFailures.WithLabelValues(statusClass).Inc()
+	}
+}
+
+// RecordESDLQWriteFailure records a DLQ entry lost because the DLQ file
+// couldn't be written
+func (m *Metrics) RecordESDLQWriteFailure() {
+	if m != nil && m.ESDLQWriteFailures != nil {
+		m.ESDLQWriteFailures.Inc()
+	}
+}
+
+// RecordAuditEvent records an audit event stored by sink ("file" or "elasticsearch")
+func (m *Metrics) RecordAuditEvent(sink string) {
+	if m != nil && m.AuditEvents != nil {
+		m.AuditEvents.WithLabelValues(sink).Inc()
+	}
+}
+
+// RecordAuditFailure records an audit event that sink failed to store
+func (m *Metrics) RecordAuditFailure(sink string) {
+	if m != nil && m.AuditFailures != nil {
+		m.AuditFailures.WithLabelValues(sink).Inc()
+	}
+}
+
+// Shadow returns metrics for a shadow sink: entries it drops are counted in
+// shadow_failures_total under sink, so they neither mix with nor inflate the drops
+// of a primary sink of the same kind
+func (m *Metrics) Shadow(sink string) *Metrics {
+	if m == nil {
+		return nil
+	}
+	shadow := *m
+	shadow.shadowSink = sink
+	return &shadow
+}
+
+// RecordShadowFailure records an entry a shadow sink failed to deliver
+func (m *Metrics) RecordShadowFailure(sink, reason string) {
+	if m != nil && m.ShadowFailures != nil {
+		m.ShadowFailures.WithLabelValues(sink, reason).Inc()
+	}
+}
+
+// RecordExtractorPanic records a context extractor that panicked
+func (m *Metrics) RecordExtractorPanic() {
+	if m != nil && m.ExtractorPanics != nil {
+		m.ExtractorPanics.Inc()
+	}
+}
+
+// RecordFieldViolation records a field whose key is not allowlisted. key must
+// come from a bounded set; see FieldAllowlist.
+func (m *Metrics) RecordFieldViolation(key string) {
+	if m != nil && m.FieldViolations != nil {
+		m.FieldViolations.WithLabelValues(key).Inc()
+	}
+}
+
+// TrackESIndexer reports the bulk indexer counters stats returns in the
+// es_indexer_* series of service, read on each scrape. Call the returned func
+// once the indexer is closed: its final counts stay in the totals.
+func (m *Metrics) TrackESIndexer(service string, stats func() IndexerStats) (untrack func()) {
+	if m == nil || m.esIndexer == nil || m.shadowSink != "" {
+		return func() {}
+	}
+	return m.esIndexer.track(service, stats)
+}
+
+// indexerStatsCollector exports the counters of the tracked bulk indexers,
+// summed by service
+type indexerStatsCollector struct {
+	mu      sync.Mutex
+	nextID  uint64
+	sources map[uint64]indexerStatsSource
+	retired map[string]IndexerStats // Final counts of untracked indexers, so totals never go down
+
+	added, flushed, failed, indexed, created, requests *prometheus.Desc
+}
+
+type indexerStatsSource struct {
+	service string
+	stats   func() IndexerStats
+}
+
+func newIndexerStatsCollector() *indexerStatsCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(name, help, []string{serviceLabel}, nil)
+	}
+	return &indexerStatsCollector{
+		sources:  make(map[uint64]indexerStatsSource),
+		retired:  make(map[string]IndexerStats),
+		added:    desc("es_indexer_added_total", "Total number of documents queued in Elasticsearch bulk indexers"),
+		flushed:  desc("es_indexer_flushed_total", "Total number of documents Elasticsearch accepted from bulk indexers"),
+		failed:   desc("es_indexer_failed_total", "Total number of documents rejected by Elasticsearch or lost with a failed bulk request"),
+		indexed:  desc("es_indexer_indexed_total", "Total number of documents Elasticsearch indexed"),
+		created:  desc("es_indexer_created_total", "Total number of documents Elasticsearch created"),
+		requests: desc("es_indexer_requests_total", "Total number of Elasticsearch bulk requests"),
+	}
+}
+
+func (c *indexerStatsCollector) track(service string, stats func() IndexerStats) func() {
+	c.mu.Lock()
+	id := c.nextID
+	c.nextID++
+	c.sources[id] = indexerStatsSource{service: service, stats: stats}
+	c.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.retired[service] = c.retired[service].add(stats())
+			delete(c.sources, id)
+		})
+	}
+}
+
+func (c *indexerStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.added, c.flushed, c.failed, c.indexed, c.created, c.requests} {
+		ch <- d
+	}
+}
+
+func (c *indexerStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	totals := make(map[string]IndexerStats, len(c.retired))
+	for service, s := range c.retired {
+		totals[service] = s
+	}
+	for _, src := range c.sources {
+		totals[src.service] = totals[src.service].add(src.stats())
+	}
+	c.mu.Unlock()
+
+	for service, s := range totals {
+		for _, v := range []struct {
+			desc  *prometheus.Desc
+			value uint64
+		}{
+			{c.added, s.Added}, {c.flushed, s.Flushed}, {c.failed, s.Failed},
+			{c.indexed, s.Indexed}, {c.created, s.Created}, {c.requests, s.Requests},
+		} {
+			ch <- prometheus.MustNewConstMetric(v.desc, prometheus.CounterValue, float64(v.value), service)
+		}
+	}
+}
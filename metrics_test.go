@@ -2,6 +2,7 @@ package logger_test
 
 import (
 	"context"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +11,7 @@ import (
 	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
 	"github.com/HoangAnhNguyen269/loggerkit/testutil"
 	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	dto "github.com/prometheus/client_model/go"
 )
 
@@ -90,9 +92,9 @@ func TestMetricsWrittenByLevelAndSink(t *testing.T) {
 
 	// Verify metrics by calculating the difference from baseline
 	expectedIncrements := map[string]float64{
-		"info_zap":  1,
-		"error_zap": 1,
-		"warn_zap":  1,
+		"info_console":  1,
+		"error_console": 1,
+		"warn_console":  1,
 	}
 
 	actualIncrements := make(map[string]float64)
@@ -121,6 +123,48 @@ func TestMetricsWrittenByLevelAndSink(t *testing.T) {
 	}
 }
 
+func TestMetricsNoAdapterSinkSeries(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	for _, collector := range logger.MetricsCollectors() {
+		registry.MustRegister(collector)
+	}
+
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewProduction(
+		logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithSampling(logger.Sampling{Initial: 1, Thereafter: 1000}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	// Written, level-filtered and sampled-away entries
+	log.Info("Counted once per sink")
+	log.Debug("Filtered by level")
+	log.Warn("Sampled")
+	log.Warn("Sampled")
+	closeLogger(t, log)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	for _, mf := range families {
+		if mf.GetName() != "logs_written_total" {
+			continue
+		}
+		for _, metric := range mf.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "sink" && label.GetValue() == "zap" {
+					t.Errorf("Expected no logs_written_total series with sink=\"zap\", got %v", metric)
+				}
+			}
+		}
+	}
+}
+
 func TestMetricsDroppedOnWriteError(t *testing.T) {
 	registry := prometheus.NewRegistry()
 
@@ -233,3 +277,84 @@ func TestMetricsAutoRegistration(t *testing.T) {
 		t.Error("Expected logger metrics to be auto-registered in default registry")
 	}
 }
+
+// seriesValue returns the counter of the family name whose labels include want
+func seriesValue(families []*dto.MetricFamily, name string, want map[string]string) (float64, bool) {
+	for _, mf := range families {
+		if mf.GetName() != name {
+			continue
+		}
+	series:
+		for _, metric := range mf.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			for k, v := range want {
+				if labels[k] != v {
+					continue series
+				}
+			}
+			return metric.GetCounter().GetValue(), true
+		}
+	}
+	return 0, false
+}
+
+func TestMetricsServiceLabel(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	for _, collector := range logger.MetricsCollectors() {
+		registry.MustRegister(collector)
+	}
+
+	newLogger := func(service string, opts ...logger.Option) logger.Logger {
+		log, err := logger.NewProduction(append([]logger.Option{
+			logger.WithService(service),
+			logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+			logger.WithConsoleDisabled(),
+			logger.WithFile(logger.FileSink{Path: filepath.Join(t.TempDir(), service+".log")}),
+		}, opts...)...)
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		return log
+	}
+	billing := newLogger("svc-label-billing", logger.WithServiceLabel())
+	search := newLogger("svc-label-search", logger.WithServiceLabel())
+	unlabeled := newLogger("svc-label-unlabeled")
+
+	billing.Info("Billing one")
+	billing.Info("Billing two")
+	search.Warn("Search one")
+	unlabeled.Info("Unlabeled one")
+	closeLogger(t, billing)
+	closeLogger(t, search)
+	closeLogger(t, unlabeled)
+	billing.Info("After close") // Dropped as logger_closed
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	for _, tc := range []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"logs_written_total", map[string]string{"service": "svc-label-billing", "level": "info", "sink": "file"}, 2},
+		{"logs_written_total", map[string]string{"service": "svc-label-search", "level": "warn", "sink": "file"}, 1},
+		{"logs_dropped_total", map[string]string{"service": "svc-label-billing", "sink": "logger", "reason": "logger_closed"}, 1},
+	} {
+		if got, ok := seriesValue(families, tc.name, tc.labels); !ok || got != tc.want {
+			t.Errorf("Expected %s%v = %v, got %v (found %v)", tc.name, tc.labels, tc.want, got, ok)
+		}
+	}
+	if _, ok := seriesValue(families, "logs_written_total", map[string]string{"service": "svc-label-unlabeled"}); ok {
+		t.Error("Expected no service label without WithServiceLabel")
+	}
+
+	// The curried vectors record into the same series
+	if got := promtestutil.ToFloat64(logger.GetMetrics().ForService("svc-label-billing").LogsWritten.WithLabelValues("info", "file")); got != 2 {
+		t.Errorf("Expected the curried counter to read 2, got %v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Msgf logs at level a message rendered from template, with each {key}
+// replaced by the value paired with key in kv, and attaches every pair as a
+// field, so the entry reads like a printf message and stays queryable:
+//
+//	logger.Msgf(log, logger.InfoLevel, "user {user} bought {item}", "user", id, "item", sku)
+//
+// A placeholder without a pair stays as written and a value without a key is
+// attached as "!BADKEY". Such mistakes are noted in a "msgf_error" field and,
+// for loggers built for EnvDev, in a warn entry of their own.
+func Msgf(log Logger, level Level, template string, kv ...any) {
+	if cs, ok := log.(callerSkipper); ok {
+		log = cs.WithCallerSkip(1) // Report the caller of Msgf
+	}
+
+	var problems []string
+	fields := make([]Field, 0, len(kv)/2+2)
+	values := make(map[string]any, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 == len(kv) {
+			fields = append(fields, Field{"!BADKEY", kv[i]})
+			problems = append(problems, fmt.Sprintf("value %v has no key", kv[i]))
+			break
+		}
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprint(kv[i])
+		}
+		fields = append(fields, Field{key, kv[i+1]})
+		values[key] = kv[i+1]
+	}
+
+	msg, unknown := renderTemplate(template, values)
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("no value for {%s}", key))
+	}
+	if len(problems) > 0 {
+		problem := fmt.Sprintf("msgf %q: %s", template, strings.Join(problems, "; "))
+		fields = append(fields, F.String("msgf_error", problem))
+		if Report(log).Env.IsDev() {
+			log.Warn("loggerkit: "+problem, F.String("template", template))
+		}
+	}
+	log.Log(level, msg, fields...)
+}
+
+// renderTemplate substitutes the {key} placeholders of template found in
+// values and returns the keys it found none for. Braces around anything but a
+// key of letters, digits, '_', '.' or '-' are plain text.
+func renderTemplate(template string, values map[string]any) (string, []string) {
+	var b strings.Builder
+	var unknown []string
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		key := template[start+1 : start+end]
+		if !isTemplateKey(key) {
+			b.WriteString(template[:start+1])
+			template = template[start+1:]
+			continue
+		}
+		b.WriteString(template[:start])
+		if v, ok := values[key]; ok {
+			fmt.Fprint(&b, v)
+		} else {
+			b.WriteString(template[start : start+end+1])
+			unknown = append(unknown, key)
+		}
+		template = template[start+end+1:]
+	}
+	b.WriteString(template)
+	return b.String(), unknown
+}
+
+func isTemplateKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
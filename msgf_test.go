@@ -0,0 +1,75 @@
+package logger_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+func TestMsgf(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+	logger.Msgf(log, logger.InfoLevel, "user {user} bought {count} x {item} (json: {\"a\": 1})", "user", "u-1", "count", 3, "item", "book")
+	logger.Msgf(log, logger.WarnLevel, "order {order} for {customer} is late", "order", "o-7")
+	logger.Msgf(log, logger.InfoLevel, "odd {a}", "a", 1, "dangling")
+	closeLogger(t, log)
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %v", entries)
+	}
+
+	bought := entries[0]
+	if bought["msg"] != `user u-1 bought 3 x book (json: {"a": 1})` || bought["level"] != "info" {
+		t.Errorf("Expected the placeholders substituted, got %v", bought)
+	}
+	if bought["user"] != "u-1" || bought["count"] != float64(3) || bought["item"] != "book" {
+		t.Errorf("Expected every pair as a field, got %v", bought)
+	}
+	if _, ok := bought["msgf_error"]; ok {
+		t.Errorf("Expected no msgf_error, got %v", bought)
+	}
+	if caller, _ := bought["caller"].(string); !strings.Contains(caller, "msgf_test.go") {
+		t.Errorf("Expected the call site as caller, got %q", caller)
+	}
+
+	late := entries[1]
+	if late["msg"] != "order o-7 for {customer} is late" || late["level"] != "warn" || late["order"] != "o-7" {
+		t.Errorf("Expected the missing key left as written, got %v", late)
+	}
+	if problem, _ := late["msgf_error"].(string); !strings.Contains(problem, "no value for {customer}") {
+		t.Errorf("Expected the missing key noted, got %v", late)
+	}
+
+	odd := entries[2]
+	if odd["msg"] != "odd 1" || odd["!BADKEY"] != "dangling" {
+		t.Errorf("Expected the dangling value kept as !BADKEY, got %v", odd)
+	}
+	if problem, _ := odd["msgf_error"].(string); !strings.Contains(problem, "has no key") {
+		t.Errorf("Expected the odd kv noted, got %v", odd)
+	}
+}
+
+func TestMsgfDevWarning(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewDevelopment(logger.WithConsoleDisabled(), logger.WithFile(logger.FileSink{Path: logPath}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Msgf(log, logger.InfoLevel, "hello {name}")
+	closeLogger(t, log)
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 2 {
+		t.Fatalf("Expected a warning and the entry, got %v", entries)
+	}
+	if warning, _ := entries[0]["msg"].(string); entries[0]["level"] != "warn" || !strings.Contains(warning, "no value for {name}") {
+		t.Errorf("Expected a dev-mode warning first, got %v", entries[0])
+	}
+	if entries[1]["msg"] != "hello {name}" {
+		t.Errorf("Expected the entry still logged, got %v", entries[1])
+	}
+}
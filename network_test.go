@@ -0,0 +1,210 @@
+package logger_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TCP/UDP JSON-lines network sink
+
+func newNetworkLogger(t *testing.T, sink logger.NetworkSink, opts ...logger.Option) logger.Logger {
+	t.Helper()
+	return testutil.NewLogger(t, append([]logger.Option{logger.WithService("shipping"), logger.WithNetwork(sink)}, opts...)...)
+}
+
+func decodeLines(t *testing.T, lines []string) []map[string]any {
+	t.Helper()
+	docs := make([]map[string]any, 0, len(lines))
+	for _, line := range lines {
+		var doc map[string]any
+		if err := json.Unmarshal([]byte(line), &doc); err != nil {
+			t.Fatalf("Line is not a JSON document: %q: %v", line, err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func TestNetworkJSONLines(t *testing.T) {
+	for _, network := range []string{"tcp", "udp", "unix"} {
+		t.Run(network, func(t *testing.T) {
+			addr := ""
+			if network == "unix" {
+				addr = filepath.Join(t.TempDir(), "logs.sock")
+			}
+			server, err := testutil.NewLineServer(network, addr)
+			if err != nil {
+				t.Fatalf("Failed to start line server: %v", err)
+			}
+			defer server.Close()
+
+			// Without sanitizing, raw newlines reach the sink's framing
+			log := newNetworkLogger(t, logger.NetworkSink{Network: network, Address: server.Addr()}, logger.WithSanitize(false))
+			log.Info("first line\nsecond line", logger.F.String("payload", "a\nb"))
+			log.Warn("Plain entry", logger.F.Int("n", 2))
+			closeLogger(t, log)
+
+			if !server.WaitForLines(2, 5*time.Second) {
+				t.Fatalf("Expected 2 lines, got %d", len(server.GetLines()))
+			}
+			time.Sleep(50 * time.Millisecond) // Catch any extra fragments
+			docs := decodeLines(t, server.GetLines())
+			if len(docs) != 2 {
+				t.Fatalf("Expected exactly 2 lines, got %d", len(docs))
+			}
+			if docs[0]["msg"] != "first line\nsecond line" || docs[0]["payload"] != "a\nb" {
+				t.Errorf("Expected embedded newlines to survive framing, got %v", docs[0])
+			}
+			if docs[1]["level"] != "warn" || docs[1]["n"] != float64(2) {
+				t.Errorf("Unexpected second document: %v", docs[1])
+			}
+		})
+	}
+}
+
+func TestNetworkReconnect(t *testing.T) {
+	server, err := testutil.NewLineServer("tcp", "")
+	if err != nil {
+		t.Fatalf("Failed to start line server: %v", err)
+	}
+	defer server.Close()
+
+	diag := &testutil.SafeBuffer{}
+	log := newNetworkLogger(t, logger.NetworkSink{
+		Address:      server.Addr(),
+		ReconnectMin: 20 * time.Millisecond,
+		ReconnectMax: 200 * time.Millisecond,
+	}, logger.WithDiagnostics(diag))
+
+	for i := 0; i < 3; i++ {
+		log.Info("Before outage", logger.F.Int("i", i))
+	}
+	if !server.WaitForLines(3, 5*time.Second) {
+		t.Fatalf("Expected 3 lines before the outage, got %d", len(server.GetLines()))
+	}
+
+	server.Stop()
+	time.Sleep(100 * time.Millisecond) // Let the writer notice the hang-up
+	for i := 0; i < 5; i++ {
+		log.Info("During outage", logger.F.Int("i", i))
+	}
+	time.Sleep(300 * time.Millisecond) // Entries sit in the buffer while reconnects fail
+
+	if err := server.Restart(); err != nil {
+		t.Fatalf("Failed to restart line server: %v", err)
+	}
+	if !server.WaitForLines(8, 5*time.Second) {
+		t.Fatalf("Expected buffered lines after reconnect, got %d", len(server.GetLines()))
+	}
+	closeLogger(t, log)
+
+	outage := 0
+	for _, doc := range decodeLines(t, server.GetLines()) {
+		if doc["msg"] == "During outage" {
+			outage++
+		}
+	}
+	if outage != 5 {
+		t.Errorf("Expected all 5 buffered entries, got %d", outage)
+	}
+	if server.GetConnectionCount() < 2 {
+		t.Errorf("Expected a new connection after the restart, got %d", server.GetConnectionCount())
+	}
+	if out := diag.String(); !strings.Contains(out, "reconnecting") || !strings.Contains(out, "resumed") {
+		t.Errorf("Expected outage and recovery in diagnostics, got %q", out)
+	}
+}
+
+func TestNetworkBufferFull(t *testing.T) {
+	// A port nobody listens on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to reserve port: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	dropped := logger.GetMetrics().LogsDropped.WithLabelValues("network", "buffer_full")
+	before := promtestutil.ToFloat64(dropped)
+
+	log := newNetworkLogger(t, logger.NetworkSink{
+		Address:      addr,
+		BufferSize:   2,
+		ReconnectMin: time.Hour, // Keep the sender stuck on its first batch
+	}, logger.WithMetrics(logger.MetricsOptions{Enabled: true}), logger.WithDiagnostics(&testutil.SafeBuffer{}))
+
+	log.Info("Taken by the sender")
+	time.Sleep(100 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		log.Info(fmt.Sprintf("Queued %d", i))
+	}
+	closeLogger(t, log)
+
+	if got := promtestutil.ToFloat64(dropped) - before; got != 3 {
+		t.Errorf("Expected 3 entries dropped with a buffer of 2, got %v", got)
+	}
+}
+
+func TestNetworkCloseAbortsBlockedWrite(t *testing.T) {
+	// A peer that accepts but never reads, so writes block once the socket buffers fill
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer l.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := l.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	log := newNetworkLogger(t, logger.NetworkSink{
+		Address:      l.Addr().String(),
+		WriteTimeout: time.Hour,
+	}, logger.WithDiagnostics(&testutil.SafeBuffer{}))
+	payload := strings.Repeat("x", 1<<20)
+	for i := 0; i < 32; i++ {
+		log.Info(fmt.Sprintf("Large entry %d", i), logger.F.String("payload", payload))
+	}
+	select {
+	case conn := <-accepted:
+		defer conn.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the sink to connect")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := log.Close(ctx); err == nil {
+		t.Error("Expected Close to report the undelivered entries")
+	}
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("Expected Close to return shortly after its deadline, took %v", elapsed)
+	}
+}
+
+func TestNetworkInvalidConfig(t *testing.T) {
+	testCases := []logger.NetworkSink{
+		{Network: "sctp", Address: "127.0.0.1:5000"},
+		{Network: "tcp"},
+	}
+	for _, sink := range testCases {
+		_, err := logger.NewProduction(logger.WithConsoleDisabled(), logger.WithNetwork(sink))
+		if err == nil {
+			t.Errorf("Expected error for %+v", sink)
+		}
+	}
+}
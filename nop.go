@@ -0,0 +1,31 @@
+package logger
+
+import "context"
+
+var _ Logger = nopLogger{}
+
+// nopBuilder is the built-in "nop" provider: its loggers discard every entry,
+// for tests and for binaries that must build without a real provider
+type nopBuilder struct{}
+
+func (nopBuilder) NewWithOptions(opts Options) (Logger, error) {
+	return nopLogger{report: BuildReport{Env: opts.Env}}, nil
+}
+
+// nopLogger discards entries; Fatal returns and DPanic never panics
+type nopLogger struct {
+	report BuildReport
+}
+
+func (nopLogger) Debug(string, ...Field)               {}
+func (nopLogger) Info(string, ...Field)                {}
+func (nopLogger) Warn(string, ...Field)                {}
+func (nopLogger) Error(string, ...Field)               {}
+func (nopLogger) DPanic(string, ...Field)              {}
+func (nopLogger) Fatal(string, ...Field)               {}
+func (nopLogger) Log(Level, string, ...Field)          {}
+func (l nopLogger) With(...Field) Logger               { return l }
+func (l nopLogger) WithContext(context.Context) Logger { return l }
+func (l nopLogger) Named(string) Logger                { return l }
+func (nopLogger) Close(context.Context) error          { return nil }
+func (l nopLogger) BuildReport() BuildReport           { return l.report }
@@ -1,6 +1,12 @@
 package logger
 
 import (
+	"crypto/tls"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
 	"time"
 )
 
@@ -10,6 +16,88 @@ type Sampling struct {
 	Thereafter int // Sample every Nth message after initial
 }
 
+// BufferOptions configures buffered console and file writes
+type BufferOptions struct {
+	Size          int           // Buffer size in bytes (default 256 KiB)
+	FlushInterval time.Duration // Longest time an entry waits in the buffer (default 30s)
+}
+
+// SinkErrorPolicy decides what happens when a sink fails to build
+type SinkErrorPolicy string
+
+const (
+	SinkErrorFail SinkErrorPolicy = "fail" // Logger construction fails (default)
+	SinkErrorSkip SinkErrorPolicy = "skip" // The sink is left out, reported via diagnostics and BuildReport
+)
+
+// DurationUnit is how time.Duration fields are encoded
+type DurationUnit string
+
+const (
+	DurationSeconds DurationUnit = "s"      // Float seconds, e.g. 1.5
+	DurationMillis  DurationUnit = "ms"     // Integer milliseconds, e.g. 1500
+	DurationNanos   DurationUnit = "ns"     // Integer nanoseconds
+	DurationString  DurationUnit = "string" // Go duration string, e.g. "1.5s"
+)
+
+// ConsoleEncoding is the format of console output
+type ConsoleEncoding string
+
+const (
+	ConsoleJSON ConsoleEncoding = "json" // One JSON object per line
+	ConsoleText ConsoleEncoding = "text" // Human-readable columns
+)
+
+// EncoderOptions configures how field values are rendered
+type EncoderOptions struct {
+	Console             ConsoleEncoding // Console format (default: text in dev, JSON otherwise)
+	DurationUnit        DurationUnit    // JSON outputs: JSON console, file, Elasticsearch... (default "s")
+	ConsoleDurationUnit DurationUnit    // Text console; WithPrettyDev always uses "string" (default "string")
+}
+
+// TextConsole reports whether the console writes text rather than JSON
+func (o Options) TextConsole() bool {
+	switch o.Encoder.Console {
+	case ConsoleText:
+		return true
+	case ConsoleJSON:
+		return false
+	default:
+		return o.Env.IsDev()
+	}
+}
+
+// Validate normalizes Env, e.g. "Production" to EnvProd, and rejects an
+// unknown one; an empty Env is left empty. The builders call it first.
+func (o *Options) Validate() error {
+	if o.Env == "" {
+		return nil
+	}
+	env, err := ParseEnv(string(o.Env))
+	if err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+	o.Env = env
+	return nil
+}
+
+// FieldViolation decides what happens to a field whose key is not allowlisted
+type FieldViolation string
+
+const (
+	FieldViolationDropField FieldViolation = "drop_field" // The field is left out (default)
+	FieldViolationDropEntry FieldViolation = "drop_entry" // The whole entry is dropped
+	FieldViolationRedact    FieldViolation = "redact"     // The value is replaced with "REDACTED"
+)
+
+// FieldAllowlist restricts the field keys that leave the process. Keys loggerkit
+// writes itself (ts, level, msg, logger, caller, stacktrace, trace_id, span_id,
+// trace_sampled, dpanic) are always allowed.
+type FieldAllowlist struct {
+	Keys        []string
+	OnViolation FieldViolation
+}
+
 // Retry configuration for failed operations
 type Retry struct {
 	Max        int           // Maximum number of retries
@@ -24,17 +112,59 @@ type FileSink struct {
 	MaxBackups int    // Maximum number of backup files to keep
 	MaxAgeDays int    // Maximum age in days before deletion
 	Compress   bool   // Compress rotated files
+
+	// Sampling overrides Options.Sampling for this sink; nil inherits it and a
+	// zero Sampling writes every entry
+	Sampling *Sampling
+}
+
+// ElasticClient is the transport used to talk to Elasticsearch.
+// It is satisfied by *elasticsearch.Client from go-elasticsearch v8.
+type ElasticClient interface {
+	Perform(req *http.Request) (*http.Response, error)
 }
 
-// ElasticSink configuration for Elasticsearch logging
+// ElasticSink configuration for Elasticsearch logging.
+// Requires importing github.com/HoangAnhNguyen269/loggerkit/provider/zapx/elasticx.
 type ElasticSink struct {
-	Addresses     []string      // List of Elasticsearch addresses
-	CloudID       string        // Cloud ID for Elastic Cloud
-	Index         string        // Index pattern (default "<service>-%Y.%m.%d")
-	FlushInterval time.Duration // How often to flush batches (default 2s)
-	BulkActions   int           // DEPRECATED/IGNORED with go-elasticsearch; use FlushInterval/FlushBytes
-	BulkSizeBytes int           // Size in bytes before flush (0 = disabled)
-	Retry         Retry         // Retry configuration
+	// Client is a pre-built client to reuse (e.g. *elasticsearch.Client). When set,
+	// Addresses, CloudID, authentication and TLS settings are ignored. The client is
+	// borrowed: the logger never closes it or its connections.
+	Client ElasticClient
+
+	Addresses     []string         // List of Elasticsearch addresses
+	CloudID       string           // Cloud ID for Elastic Cloud
+	Index         string           // Index pattern (default "<service>-%Y.%m.%d"), may hold %{field} tokens
+	IndexByLevel  map[Level]string // Per-level index pattern overrides (falls back to Index)
+	FlushInterval time.Duration    // How often to flush batches (default 2s)
+	FlushBytes    int              // Size in bytes before flush (0 = BulkSizeBytes, then the 5MB indexer default)
+	BulkActions   int              // Flush after this many buffered documents (0 = disabled)
+	BulkSizeBytes int              // Deprecated: use FlushBytes
+	NumWorkers    int              // Bulk indexer workers (default 1)
+	Retry         Retry            // Retry configuration
+
+	// Sampling overrides Options.Sampling for this sink, e.g. to sample what is
+	// indexed while the file keeps everything; nil inherits it and a zero
+	// Sampling sends every entry
+	Sampling *Sampling
+
+	// IndexFieldFallback replaces a %{field} token for entries without the field,
+	// or whose value is not a string, number or bool (default "unknown"). Each
+	// distinct value creates an index: only use low-cardinality fields.
+	IndexFieldFallback string
+
+	// Level-triggered flushing: entries at or above FlushOnLevel are sent right away
+	// instead of waiting for FlushInterval, at most once per FlushOnLevelWindow
+	FlushOnLevel       Level         // e.g. ErrorLevel (empty = disabled)
+	FlushOnLevelWindow time.Duration // Debounce window for level-triggered flushes (default 1s)
+
+	// Startup check (default lazy: the cluster is first contacted by the bulk indexer)
+	PingOnStartup  bool          // Fail logger construction if the cluster is unreachable or rejects auth
+	StartupTimeout time.Duration // Timeout for the startup ping (default 5s)
+
+	// Compression of bulk request bodies (ignored when Client is set)
+	CompressRequestBody      bool // Gzip request bodies (default false)
+	CompressRequestBodyLevel int  // Gzip level (0 = gzip.DefaultCompression)
 
 	// Authentication
 	Username     string // Basic auth username
@@ -50,6 +180,130 @@ type ElasticSink struct {
 
 	// Dead Letter Queue
 	DLQPath string // Path for DLQ file (empty = disabled)
+
+	// Index template / ILM bootstrap
+	Bootstrap ElasticBootstrap
+
+	// StaticFields are added to every Elasticsearch document (not to other sinks),
+	// e.g. region or cluster labels. "env" defaults to Options.Env; "service" always
+	// comes from Options.Service. Keys already present in an entry are never overwritten.
+	StaticFields map[string]string
+
+	// MaxDocBytes caps the encoded document size (0 = 1MB, negative = unlimited).
+	// Larger entries have their biggest strings truncated, or go to the DLQ as
+	// "doc_too_large" if that is not enough.
+	MaxDocBytes int
+
+	// Key sanitization, applied to top-level fields and one level into F.Any maps
+	DeDotKeys    bool // Replace "." with "_" in field names to avoid nested mappings
+	MaxDocFields int  // Fold fields beyond this count into a "fields_overflow" JSON string (0 = unlimited)
+
+	// Per-document bulk metadata; nil funcs leave _id and routing to Elasticsearch.
+	// Setting either func makes the writer decode each document to call it.
+	Pipeline   string                          // Ingest pipeline applied to every document
+	DocumentID func(doc map[string]any) string // Derives the document _id (idempotent indexing)
+	Routing    func(doc map[string]any) string // Derives the shard routing value
+}
+
+// ElasticBootstrap configuration for creating the index template and ILM policy at startup
+type ElasticBootstrap struct {
+	EnsureTemplate       bool            // PUT the index template (and ILM policy) when the writer starts
+	TemplateName         string          // Index template name (default "<service>-logs")
+	Mappings             json.RawMessage // Template mappings (default: built-in ECS-style mapping)
+	ILMPolicy            json.RawMessage // ILM policy body, e.g. {"policy":{...}} (empty = no policy)
+	FailOnBootstrapError bool            // Fail logger construction when the bootstrap is rejected
+}
+
+// OTLPSink configuration for exporting logs over OTLP to an OpenTelemetry collector.
+// Requires importing github.com/HoangAnhNguyen269/loggerkit/provider/zapx/otlpx.
+type OTLPSink struct {
+	Endpoint string            // Collector host:port (default localhost:4317 for grpc, localhost:4318 for http)
+	Insecure bool              // Use plaintext instead of TLS
+	Headers  map[string]string // Extra request headers, e.g. authentication
+	Timeout  time.Duration     // Export timeout (default 10s)
+	Protocol string            // "grpc" (default) or "http"
+}
+
+// FluentSink configuration for shipping logs to Fluentd/Fluent Bit over the forward protocol
+type FluentSink struct {
+	Address    string // Forward input host:port (default "localhost:24224")
+	Tag        string // Tag template; <service> and <level> are substituted (default "<service>.<level>")
+	SharedKey  string // Shared key for the forward handshake (empty = no handshake)
+	RequireAck bool   // Wait for the server to acknowledge each chunk, resending on failure
+	BufferSize int    // Entries queued while the server is slow or unreachable (default 8192)
+}
+
+// NetworkSink configuration for streaming JSON lines over a socket (e.g. a Logstash tcp input)
+type NetworkSink struct {
+	Network      string        // "tcp" (default), "udp" or "unix"
+	Address      string        // host:port, or socket path for "unix"
+	TLS          *tls.Config   // Wrap tcp connections in TLS (nil = plaintext)
+	ReconnectMin time.Duration // Initial delay between reconnect attempts (default 100ms)
+	ReconnectMax time.Duration // Maximum delay between reconnect attempts (default 10s)
+	WriteTimeout time.Duration // Deadline for each write (default 5s)
+	BufferSize   int           // Entries queued while disconnected (default 8192)
+}
+
+// AlertSink configuration for posting a summary to a Slack-compatible webhook when
+// error volume spikes, instead of one message per error
+type AlertSink struct {
+	WebhookURL string        // Incoming webhook URL; receives {"text": "..."}
+	MinLevel   Level         // Lowest level that counts towards the threshold (default error)
+	Window     time.Duration // Counting window (default 1m)
+	Threshold  int           // Entries per window that trigger an alert (default 10)
+	Cooldown   time.Duration // Minimum time between two alerts (default 5 × Window)
+	Template   string        // text/template for the message (default: count, service and top messages)
+}
+
+// RingSink configuration for keeping the most recent entries in memory, served by RingHandler
+type RingSink struct {
+	Capacity      int   // Entries kept; older ones are evicted (default 1000)
+	Level         Level // Lowest level kept, may be below Options.Level (default Options.Level)
+	MaxEntryBytes int   // Larger entries are replaced by a truncated stub (default 16KB)
+}
+
+// SQLiteSink configuration for storing entries in a local SQLite database. Requires
+// importing github.com/HoangAnhNguyen269/loggerkit/provider/zapx/sqlitex and a
+// database/sql SQLite driver such as github.com/mattn/go-sqlite3 or modernc.org/sqlite.
+type SQLiteSink struct {
+	Path          string        // Database file (ignored when DB is set)
+	Driver        string        // database/sql driver name (default "sqlite3"; use "sqlite" for modernc.org/sqlite)
+	DB            *sql.DB       // Pre-opened database to reuse; borrowed, never closed by the logger
+	Table         string        // Table name (default "logs")
+	BatchSize     int           // Rows per insert transaction (default 100)
+	FlushInterval time.Duration // Longest time a row waits for its batch (default 1s)
+	RetentionDays int           // Delete rows older than this many days, checked hourly (0 = keep forever)
+}
+
+// AuditSink configuration for compliance events written with Audit. Audit events
+// bypass sampling and go only to these outputs, separate from regular logs.
+type AuditSink struct {
+	File         *FileSink     // Audit file, separate from Options.File
+	ElasticIndex string        // Index pattern for audit documents, sent over the Options.Elastic connection
+	Timeout      time.Duration // Bound for an Audit call whose context has no deadline (default 10s)
+}
+
+// SinkRef selects a registered sink and the options it is built from, for WithShadow
+type SinkRef struct {
+	Factory string   // Registered factory name, e.g. "elasticsearch" or "file"
+	Options []Option // Applied to a copy of the logger's options to configure the sink
+}
+
+// ElasticRef refers to an Elasticsearch sink built from elastic
+func ElasticRef(elastic ElasticSink) SinkRef {
+	return SinkRef{Factory: "elasticsearch", Options: []Option{WithElastic(elastic)}}
+}
+
+// FileRef refers to a file sink built from file
+func FileRef(file FileSink) SinkRef {
+	return SinkRef{Factory: "file", Options: []Option{WithFile(file)}}
+}
+
+// ShadowSink configuration for writing every entry to a primary and a shadow sink,
+// e.g. while migrating between index layouts. Shadow failures are only counted.
+type ShadowSink struct {
+	Primary SinkRef
+	Shadow  SinkRef
 }
 
 // ContextKeys configuration for extracting values from context
@@ -61,28 +315,77 @@ type ContextKeys struct {
 	// HTTP header names for middleware extraction
 	RequestIDHeader string // Header name for request ID (default "X-Request-ID")
 	UserIDHeader    string // Header name for user ID (default "X-User-ID")
+
+	// Keys maps output field names to context keys; RequestIDKey and UserIDKey are
+	// shorthand for the "request_id" and "user_id" entries
+	Keys map[string]any
+	// Headers maps output field names to the header the middleware stores under
+	// the field's context key
+	Headers map[string]string
+
+	// Baggage lists OTel baggage members copied into fields of the same name
+	Baggage []string
+
+	// Extractors add fields of their own, after the mapped keys, trace and baggage fields
+	Extractors []ContextExtractor
+
+	// DisableTraceExtraction skips the trace_id, span_id and trace_sampled fields
+	DisableTraceExtraction bool
+	// Names of the trace fields (default "trace_id", "span_id" and "trace_sampled")
+	TraceIDField    string
+	SpanIDField     string
+	TraceFlagsField string
 }
 
 // MetricsOptions configuration for Prometheus metrics
 type MetricsOptions struct {
 	Enabled      bool // Enable metrics collection
 	AutoRegister bool // Auto-register with prometheus.DefaultRegisterer
+	ServiceLabel bool // Label logs_written_total and logs_dropped_total with Options.Service
 }
 
 // Options represents the complete logger configuration
 type Options struct {
-	Env            Env            // Environment: dev or prod
-	Service        string         // Service name
-	Level          Level          // Log level: debug, info, warn, error
-	TimeFormat     string         // Time format (default RFC3339Nano)
-	EnableCaller   bool           // Include caller information
-	StacktraceAt   Level          // Level at which to include stacktrace
-	Sampling       *Sampling      // Sampling configuration
-	DisableConsole bool           // default: false (console bật mặc định)
-	File           *FileSink      // File sink configuration
-	Elastic        *ElasticSink   // Elasticsearch sink configuration
-	Context        ContextKeys    // Context extraction configuration
-	Metrics        MetricsOptions // Metrics configuration
+	Env             Env              // Environment: dev or prod
+	Provider        string           // Registered builder to use (default DefaultProvider, "zapx")
+	Service         string           // Service name
+	Level           Level            // Log level: debug, info, warn, error
+	LevelOverrides  map[string]Level // Levels of named loggers, matched by longest name prefix
+	Encoder         EncoderOptions   // Duration units per output
+	TimeFormat      string           // Time format (default RFC3339Nano)
+	EnableCaller    bool             // Include caller information
+	StacktraceAt    Level            // Level at which to include stacktrace
+	SpanEvents      Level            // Lowest level also added as an event to the WithContext span (empty: off)
+	StrictFields    bool             // Panic on duplicate field keys; dev mode only warns through Diagnostics
+	DedupFields     bool             // Write each key once: call site over With over WithContext
+	FieldAllowlist  *FieldAllowlist  // Only these field keys are written (default: any)
+	Sanitize        *bool            // Escape control characters and invalid UTF-8 (default: on outside dev)
+	NormalizeValues bool             // NaN/Inf as strings, nil pointers as null, raw bytes as key_b64 (always on for Elasticsearch)
+	FatalHook       FatalHook        // Runs after a Fatal entry (nil: close the sinks and exit with status 1)
+	Sampling        *Sampling        // Sampling configuration
+	SamplingMarker  bool             // Entries emitted after sampled-away ones carry sampled_dropped
+	Buffer          *BufferOptions   // Buffer console and file writes (default: unbuffered)
+	ConsoleLines    int              // Queue up to this many console lines for one writer goroutine (0 = direct writes)
+	DisableConsole  bool             // default: false (console bật mặc định)
+	PrettyDev       bool             // Dev console output renders one field per line
+	File            *FileSink        // File sink configuration
+	Elastic         *ElasticSink     // Elasticsearch sink configuration (requires provider/zapx/elasticx)
+	OTLP            *OTLPSink        // OTLP sink configuration (requires provider/zapx/otlpx)
+	Fluent          *FluentSink      // Fluentd/Fluent Bit forward sink configuration
+	Network         *NetworkSink     // TCP/UDP/unix JSON-lines sink configuration
+	Alert           *AlertSink       // Webhook alerts on error bursts
+	Ring            *RingSink        // In-memory buffer of recent entries
+	SQLite          *SQLiteSink      // SQLite sink configuration (requires provider/zapx/sqlitex)
+	Audit           *AuditSink       // Outputs for Audit events
+	Shadow          *ShadowSink      // Primary sink mirrored to a shadow sink
+	ParallelSinks   bool             // Write each sink from its own goroutine
+	SinkErrorPolicy SinkErrorPolicy  // What to do when a sink fails to build (default SinkErrorFail)
+	Extensions      map[string]any   // Configuration of custom factories, keyed by factory Name()
+	Context         ContextKeys      // Context extraction configuration
+	Metrics         MetricsOptions   // Metrics configuration
+	Diagnostics     io.Writer        // Destination for loggerkit's own diagnostics (default os.Stderr)
+	StartupBanner   bool             // Log the effective configuration once the logger is built
+	Clock           Clock            // Time source (default SystemClock)
 }
 
 // Option is a functional option for configuring the logger
@@ -95,6 +398,13 @@ func WithEnv(env Env) Option {
 	}
 }
 
+// WithProvider selects the builder registered under name, e.g. "nop"
+func WithProvider(name string) Option {
+	return func(o *Options) {
+		o.Provider = name
+	}
+}
+
 // WithService sets the service name
 func WithService(service string) Option {
 	return func(o *Options) {
@@ -109,6 +419,22 @@ func WithLevel(level Level) Option {
 	}
 }
 
+// WithLevelOverrides sets the level of loggers by name, e.g. "billing": DebugLevel.
+// An override applies to the named logger and its children ("billing.invoices"),
+// the longest matching name wins, and other loggers keep Options.Level.
+func WithLevelOverrides(overrides map[string]Level) Option {
+	return func(o *Options) {
+		merged := make(map[string]Level, len(o.LevelOverrides)+len(overrides))
+		for name, l := range o.LevelOverrides {
+			merged[name] = l
+		}
+		for name, l := range overrides {
+			merged[name] = l
+		}
+		o.LevelOverrides = merged
+	}
+}
+
 // WithLevelString sets the log level from string (deprecated: use WithLevel with typed enum)
 func WithLevelString(level string) Option {
 	return func(o *Options) {
@@ -118,6 +444,20 @@ func WithLevelString(level string) Option {
 	}
 }
 
+// WithEncoderOptions sets how field values such as durations are rendered
+func WithEncoderOptions(enc EncoderOptions) Option {
+	return func(o *Options) {
+		o.Encoder = enc
+	}
+}
+
+// WithConsoleEncoding overrides the console format of the environment
+func WithConsoleEncoding(enc ConsoleEncoding) Option {
+	return func(o *Options) {
+		o.Encoder.Console = enc
+	}
+}
+
 // WithTimeFormat sets the time format
 func WithTimeFormat(format string) Option {
 	return func(o *Options) {
@@ -155,6 +495,47 @@ func WithSampling(sampling Sampling) Option {
 	}
 }
 
+// WithSamplingMarker adds "sampled_dropped" to the first entry sampling lets
+// through after dropping similar ones (same level and message), counting the
+// entries dropped since the previous one emitted
+func WithSamplingMarker() Option {
+	return func(o *Options) {
+		o.SamplingMarker = true
+	}
+}
+
+// WithBufferedWrites buffers console and file output, trading a delay of up to
+// FlushInterval for far fewer write syscalls. Close and Sync flush the buffer.
+func WithBufferedWrites(buffer BufferOptions) Option {
+	return func(o *Options) {
+		o.Buffer = &buffer
+	}
+}
+
+// WithLineBufferedConsole hands whole console lines to a single writer
+// goroutine through a queue of up to queue lines (default 4096), so concurrent
+// log calls don't contend on stdout and lines never interleave. The lines queued
+// together go out in one write. While the queue is full new lines are dropped
+// and counted in logs_dropped as "buffer_full". Close writes what is queued.
+// For the console it takes precedence over WithBufferedWrites.
+func WithLineBufferedConsole(queue int) Option {
+	if queue <= 0 {
+		queue = 4096
+	}
+	return func(o *Options) {
+		o.ConsoleLines = queue
+	}
+}
+
+// WithPrettyDev renders development console output with each field on its own
+// line under the message, nested values as indented JSON and the stacktrace
+// last. Production and non-console output is unaffected.
+func WithPrettyDev() Option {
+	return func(o *Options) {
+		o.PrettyDev = true
+	}
+}
+
 func WithConsoleDisabled() Option {
 	return func(o *Options) { o.DisableConsole = true }
 }
@@ -173,6 +554,131 @@ func WithElastic(elastic ElasticSink) Option {
 	}
 }
 
+// WithOTLP sets the OTLP sink configuration
+func WithOTLP(otlp OTLPSink) Option {
+	return func(o *Options) {
+		o.OTLP = &otlp
+	}
+}
+
+// WithFluent sets the Fluentd/Fluent Bit forward sink configuration
+func WithFluent(fluent FluentSink) Option {
+	return func(o *Options) {
+		o.Fluent = &fluent
+	}
+}
+
+// WithNetwork sets the JSON-lines network sink configuration
+func WithNetwork(network NetworkSink) Option {
+	return func(o *Options) {
+		o.Network = &network
+	}
+}
+
+// WithAlert sets the webhook alert sink configuration
+func WithAlert(alert AlertSink) Option {
+	return func(o *Options) {
+		o.Alert = &alert
+	}
+}
+
+// WithRing sets the in-memory ring buffer sink configuration
+func WithRing(ring RingSink) Option {
+	return func(o *Options) {
+		o.Ring = &ring
+	}
+}
+
+// WithSQLite sets the SQLite sink configuration
+func WithSQLite(sqlite SQLiteSink) Option {
+	return func(o *Options) {
+		o.SQLite = &sqlite
+	}
+}
+
+// WithAudit sets the audit sink configuration
+func WithAudit(audit AuditSink) Option {
+	return func(o *Options) {
+		o.Audit = &audit
+	}
+}
+
+// WithShadow writes every entry to primary and to shadow. The shadow is written
+// from its own goroutine through a bounded queue, so it never slows the caller.
+// Shadow failures and entries dropped from a full queue never reach the caller
+// or the primary; they are counted in shadow_failures_total.
+func WithShadow(primary, shadow SinkRef) Option {
+	return func(o *Options) {
+		o.Shadow = &ShadowSink{Primary: primary, Shadow: shadow}
+	}
+}
+
+// WithSpanEvents also records entries at or above minLevel as events on the
+// recording span of the context passed to WithContext. Error entries set the
+// span status to error.
+func WithSpanEvents(minLevel Level) Option {
+	return func(o *Options) {
+		o.SpanEvents = minLevel
+	}
+}
+
+// WithStrictFields panics when an entry has the same key twice among its With
+// and call-site fields, in any environment. Without it only dev mode checks,
+// and reports duplicates through Diagnostics.
+func WithStrictFields() Option {
+	return func(o *Options) {
+		o.StrictFields = true
+	}
+}
+
+// WithDedupFields writes every field key once per entry. Call-site fields win
+// over fields bound with With, which win over fields from WithContext; within
+// each of those the last one wins. Bound fields are then encoded on every entry
+// instead of once in With.
+func WithDedupFields() Option {
+	return func(o *Options) {
+		o.DedupFields = true
+	}
+}
+
+// WithFieldAllowlist only lets fields with the given keys through, bound with
+// With or WithContext or passed at the call site. Other fields are handled per
+// onViolation and counted in logs_field_violations_total.
+func WithFieldAllowlist(keys []string, onViolation FieldViolation) Option {
+	return func(o *Options) {
+		o.FieldAllowlist = &FieldAllowlist{Keys: append([]string(nil), keys...), OnViolation: onViolation}
+	}
+}
+
+// WithSanitize turns sanitization of messages and string field values on or
+// off. Control characters such as "\n" are escaped so user input can't forge
+// entries, and invalid UTF-8 is replaced with U+FFFD. It is on by default
+// except in dev.
+func WithSanitize(enabled bool) Option {
+	return func(o *Options) {
+		o.Sanitize = &enabled
+	}
+}
+
+// WithNormalizeValues writes values every JSON consumer accepts in all sinks:
+// NaN and ±Inf as "NaN", "+Inf" and "-Inf", nil pointers as null, and []byte
+// that isn't valid UTF-8 base64-encoded under the key plus "_b64". The
+// Elasticsearch sink always does this.
+func WithNormalizeValues() Option {
+	return func(o *Options) {
+		o.NormalizeValues = true
+	}
+}
+
+// WithFatalBehavior replaces what Fatal does once its entry is written, e.g.
+// FatalPanic or FatalReturn in tests and in libraries embedded in long-running
+// hosts. The default closes the sinks, within 5 seconds, and exits with status 1.
+func WithFatalBehavior(hook FatalHook) Option {
+	return func(o *Options) {
+		o.FatalHook = hook
+	}
+}
+
 // WithContext sets the context configuration
 func WithContext(ctx ContextKeys) Option {
 	return func(o *Options) {
@@ -180,6 +686,33 @@ func WithContext(ctx ContextKeys) Option {
 	}
 }
 
+// WithContextExtractor appends an extractor run by WithContext
+func WithContextExtractor(fn ContextExtractor) Option {
+	return func(o *Options) {
+		// Copy so Options values sharing the slice don't see each other's extractors
+		extractors := make([]ContextExtractor, 0, len(o.Context.Extractors)+1)
+		o.Context.Extractors = append(append(extractors, o.Context.Extractors...), fn)
+	}
+}
+
+// WithBaggageFields copies the named OTel baggage members of the WithContext
+// context into fields; members missing from the baggage are skipped
+func WithBaggageFields(keys ...string) Option {
+	return func(o *Options) {
+		baggage := make([]string, 0, len(o.Context.Baggage)+len(keys))
+		o.Context.Baggage = append(append(baggage, o.Context.Baggage...), keys...)
+	}
+}
+
+// WithoutTraceExtraction stops WithContext from adding trace_id, span_id and
+// trace_sampled, for services without tracing. With no context keys, baggage or
+// extractors either, WithContext then returns the logger without looking at ctx.
+func WithoutTraceExtraction() Option {
+	return func(o *Options) {
+		o.Context.DisableTraceExtraction = true
+	}
+}
+
 // WithMetrics sets the metrics configuration
 func WithMetrics(metrics MetricsOptions) Option {
 	return func(o *Options) {
@@ -187,6 +720,68 @@ func WithMetrics(metrics MetricsOptions) Option {
 	}
 }
 
+// WithServiceLabel labels logs_written_total and logs_dropped_total with the
+// service, so loggers of several services in one process can be told apart
+func WithServiceLabel() Option {
+	return func(o *Options) {
+		o.Metrics.ServiceLabel = true
+	}
+}
+
+// WithStartupBanner logs one info entry with the effective configuration, secrets
+// masked, once the logger is built. It goes through every sink like any entry.
+func WithStartupBanner() Option {
+	return func(o *Options) {
+		o.StartupBanner = true
+	}
+}
+
+// WithParallelSinks writes every sink from its own goroutine, so a slow sink
+// such as Elasticsearch no longer delays the log call or the other sinks. Each
+// sink still receives entries in order. A sink more than 1024 entries behind
+// drops new ones; write errors and drops are returned by the next Sync or Close.
+func WithParallelSinks() Option {
+	return func(o *Options) {
+		o.ParallelSinks = true
+	}
+}
+
+// WithSinkErrorPolicy sets what happens when a sink fails to build
+func WithSinkErrorPolicy(policy SinkErrorPolicy) Option {
+	return func(o *Options) {
+		o.SinkErrorPolicy = policy
+	}
+}
+
+// WithExtension sets the configuration of the custom factory named name. The
+// factory reads it back with GetExtension.
+func WithExtension(name string, cfg any) Option {
+	return func(o *Options) {
+		// Copy so Options values sharing the map are not affected
+		ext := make(map[string]any, len(o.Extensions)+1)
+		for k, v := range o.Extensions {
+			ext[k] = v
+		}
+		ext[name] = cfg
+		o.Extensions = ext
+	}
+}
+
+// GetExtension returns the configuration set with WithExtension for name, if it
+// is present and of type T
+func GetExtension[T any](opts Options, name string) (T, bool) {
+	cfg, ok := opts.Extensions[name].(T)
+	return cfg, ok
+}
+
+// WithDiagnostics sets where loggerkit reports its own operational problems
+// (sink bootstrap failures, dropped DLQ writes, ...)
+func WithDiagnostics(w io.Writer) Option {
+	return func(o *Options) {
+		o.Diagnostics = w
+	}
+}
+
 // DefaultDevelopmentOptions returns default options for development
 func DefaultDevelopmentOptions() Options {
 	return Options{
@@ -247,7 +842,7 @@ func DefaultElasticSink(addresses []string, index string) ElasticSink {
 		Addresses:     addresses,
 		Index:         index,
 		FlushInterval: 2 * time.Second,
-		BulkActions:   5000,
+		BulkActions:   0, // Count-based flushing disabled by default
 		BulkSizeBytes: 0, // Disabled by default
 		Retry:         DefaultElasticRetry(),
 	}
@@ -0,0 +1,190 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx/corefactories"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	"go.uber.org/zap/zapcore"
+)
+
+// Parallel sink writes
+
+// writeFuncCore is a sink core calling write for every entry
+type writeFuncCore struct {
+	zapcore.LevelEnabler
+	write func(zapcore.Entry) error
+}
+
+func (c *writeFuncCore) With([]zapcore.Field) zapcore.Core { return c }
+func (c *writeFuncCore) Sync() error                       { return nil }
+
+func (c *writeFuncCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *writeFuncCore) Write(ent zapcore.Entry, _ []zapcore.Field) error {
+	return c.write(ent)
+}
+
+// writeFuncFactory is enabled by an extension named after it
+type writeFuncFactory struct {
+	name  string
+	write func(zapcore.Entry) error
+}
+
+func (f *writeFuncFactory) Name() string { return f.name }
+
+func (f *writeFuncFactory) Enabled(opts logger.Options) bool {
+	_, ok := logger.GetExtension[bool](opts, f.name)
+	return ok
+}
+
+func (f *writeFuncFactory) Build(_ zapcore.EncoderConfig, lvl zapcore.Level, _ *logger.Metrics, _ logger.Options) (zapcore.Core, func() error, error) {
+	return &writeFuncCore{LevelEnabler: lvl, write: f.write}, nil, nil
+}
+
+// registerWriteFuncFactory registers a sink calling write until tb ends
+func registerWriteFuncFactory(tb testing.TB, name string, write func(zapcore.Entry) error) logger.Option {
+	tb.Helper()
+	corefactories.RegisterFactory(&writeFuncFactory{name: name, write: write})
+	tb.Cleanup(func() { corefactories.UnregisterFactory(name) })
+	return logger.WithExtension(name, true)
+}
+
+func TestParallelSinksIsolateSlowSink(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var slowGot []string
+	slow := registerWriteFuncFactory(t, "slow", func(ent zapcore.Entry) error {
+		<-release
+		mu.Lock()
+		slowGot = append(slowGot, ent.Message)
+		mu.Unlock()
+		return nil
+	})
+
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithParallelSinks(),
+		slow,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	// The slow sink holds its first entry until released; neither the caller
+	// nor the file sink wait for it
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		log.Info(fmt.Sprintf("entry %d", i))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Log calls waited for the slow sink: %v", elapsed)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if data, _ := os.ReadFile(logPath); bytes.Count(data, []byte("\n")) == 10 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("File sink is blocked by the slow sink")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Close drains every queue before closing the sinks
+	close(release)
+	log.Info("last words")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(slowGot) != 11 || slowGot[0] != "entry 0" || slowGot[9] != "entry 9" || slowGot[10] != "last words" {
+		t.Errorf("Expected the slow sink to get every entry in order, got %v", slowGot)
+	}
+	if entries := testutil.ReadJSONLines(t, logPath); len(entries) != 11 || entries[10]["msg"] != "last words" {
+		t.Errorf("Expected 11 file entries ending with the last one, got %v", entries)
+	}
+}
+
+func TestParallelSinksReportErrorsOnClose(t *testing.T) {
+	broken := registerWriteFuncFactory(t, "broken", func(zapcore.Entry) error {
+		return errors.New("disk on fire")
+	})
+
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithParallelSinks(),
+		broken,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		log.Error("failed request")
+	}
+	err = log.Close(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "broken: 3 writes failed, last: disk on fire") {
+		t.Errorf("Expected the broken sink's failures from Close, got %v", err)
+	}
+	if entries := testutil.ReadJSONLines(t, logPath); len(entries) != 3 {
+		t.Errorf("Expected the file sink to write despite the broken one, got %d entries", len(entries))
+	}
+}
+
+// BenchmarkParallelSinks logs to a file and a sink taking 50µs per entry, like a
+// slow Elasticsearch
+func BenchmarkParallelSinks(b *testing.B) {
+	for _, mode := range []struct {
+		name string
+		opts []logger.Option
+	}{
+		{"Sequential", nil},
+		{"Parallel", []logger.Option{logger.WithParallelSinks()}},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			slow := registerWriteFuncFactory(b, "slow", func(zapcore.Entry) error {
+				time.Sleep(50 * time.Microsecond)
+				return nil
+			})
+			log, err := logger.NewProduction(append([]logger.Option{
+				func(o *logger.Options) { o.Sampling = nil }, // Every entry reaches the sinks
+				logger.WithConsoleDisabled(),
+				logger.WithFile(logger.FileSink{Path: filepath.Join(b.TempDir(), "app.log")}),
+				slow,
+			}, mode.opts...)...)
+			if err != nil {
+				b.Fatalf("Failed to create logger: %v", err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				log.Info("Benchmark message", logger.F.Int("iteration", i))
+			}
+			b.StopTimer()
+			log.Close(context.Background()) // Reports the entries the parallel sink dropped
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package slogx
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+)
+
+// Levels beyond slog's own, spaced like them
+const (
+	levelDPanic = slog.LevelError + 4
+	levelFatal  = slog.LevelError + 8
+)
+
+func toSlogLevel(l logger.Level) slog.Level {
+	switch l {
+	case logger.DebugLevel:
+		return slog.LevelDebug
+	case logger.WarnLevel:
+		return slog.LevelWarn
+	case logger.ErrorLevel:
+		return slog.LevelError
+	case logger.DPanicLevel:
+		return levelDPanic
+	case logger.FatalLevel:
+		return levelFatal
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func levelName(l slog.Level) string {
+	switch {
+	case l >= levelFatal:
+		return "fatal"
+	case l >= levelDPanic:
+		return "dpanic"
+	case l >= slog.LevelError:
+		return "error"
+	case l >= slog.LevelWarn:
+		return "warn"
+	case l >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// replaceAttr renames slog's built-in attributes to zapx's keys (ts, level, msg,
+// caller) and encodes times and durations the way zapx does
+func replaceAttr(opts logger.Options) func(groups []string, a slog.Attr) slog.Attr {
+	layout := opts.TimeFormat
+	if layout == "" {
+		layout = "2006-01-02T15:04:05.000Z0700" // zapcore.ISO8601TimeEncoder
+	}
+	unit := opts.Encoder.DurationUnit
+	return func(groups []string, a slog.Attr) slog.Attr {
+		// Fields may use the same keys; the value types tell the built-ins apart
+		if len(groups) == 0 {
+			switch a.Key {
+			case slog.TimeKey:
+				if a.Value.Kind() == slog.KindTime {
+					return slog.String(logger.TimeKey, a.Value.Time().Format(layout))
+				}
+			case slog.LevelKey:
+				if lvl, ok := a.Value.Any().(slog.Level); ok {
+					return slog.String(logger.LevelKey, levelName(lvl))
+				}
+			case slog.MessageKey:
+				return slog.Attr{Key: logger.MessageKey, Value: a.Value}
+			case slog.SourceKey:
+				src, ok := a.Value.Any().(*slog.Source)
+				if !ok {
+					break
+				}
+				if src.File == "" {
+					return slog.Attr{}
+				}
+				// Like zapcore.ShortCallerEncoder: the file's directory and name
+				file := filepath.Join(filepath.Base(filepath.Dir(src.File)), filepath.Base(src.File))
+				return slog.String(logger.CallerKey, fmt.Sprintf("%s:%d", filepath.ToSlash(file), src.Line))
+			}
+		}
+		switch a.Value.Kind() {
+		case slog.KindTime:
+			return slog.String(a.Key, a.Value.Time().Format(layout))
+		case slog.KindDuration:
+			return slog.Attr{Key: a.Key, Value: durationValue(a.Value.Duration(), unit)}
+		}
+		return a
+	}
+}
+
+// durationValue matches zapx's EncoderOptions.DurationUnit
+func durationValue(d time.Duration, unit logger.DurationUnit) slog.Value {
+	switch unit {
+	case logger.DurationMillis:
+		return slog.Int64Value(d.Milliseconds())
+	case logger.DurationNanos:
+		return slog.Int64Value(int64(d))
+	case logger.DurationString:
+		return slog.StringValue(d.String())
+	default:
+		return slog.Float64Value(d.Seconds())
+	}
+}
+
+func toAttrs(fields []logger.Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, f := range fields {
+		attrs = append(attrs, toAttr(f))
+	}
+	return attrs
+}
+
+// toAttr encodes the common value types directly; errors are written as their
+// message, like zapx
+func toAttr(f logger.Field) slog.Attr {
+	switch v := f.Val.(type) {
+	case string:
+		return slog.String(f.Key, v)
+	case int:
+		return slog.Int(f.Key, v)
+	case int64:
+		return slog.Int64(f.Key, v)
+	case float64:
+		return slog.Float64(f.Key, v)
+	case bool:
+		return slog.Bool(f.Key, v)
+	case time.Time:
+		return slog.Time(f.Key, v)
+	case time.Duration:
+		return slog.Duration(f.Key, v)
+	case error:
+		return slog.String(f.Key, v.Error())
+	default:
+		return slog.Any(f.Key, v)
+	}
+}
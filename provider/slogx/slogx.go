@@ -0,0 +1,363 @@
+// Package slogx is a loggerkit provider built on log/slog, for programs that
+// don't want zap and the sink dependencies of zapx. It writes to the console and
+// to a file only. Import it for its side effect and select it by name:
+//
+//	import _ "github.com/HoangAnhNguyen269/loggerkit/provider/slogx"
+//
+//	log, err := logger.NewProduction(logger.WithProvider("slogx"))
+package slogx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+)
+
+// ProviderName is the name slogx registers under
+const ProviderName = "slogx"
+
+var _ logger.Logger = (*slogAdapter)(nil)
+
+type slogBuilder struct{}
+
+func init() {
+	logger.RegisterBuilder(ProviderName, slogBuilder{})
+}
+
+func (slogBuilder) NewWithOptions(opts logger.Options) (logger.Logger, error) {
+	return NewWithOptions(opts)
+}
+
+type slogAdapter struct {
+	handler       slog.Handler
+	name          string // Dot-joined Named names
+	contextKeys   logger.ContextKeys
+	contextFields []logger.ContextMapping // contextKeys.Mappings(), computed once
+	enableCaller  bool
+	callerSkip    int // Frames skipped on top of the adapter's own
+	development   bool
+	fatalHook     logger.FatalHook
+	clock         logger.Clock
+	report        logger.BuildReport
+	files         []*os.File // Closed by the root only
+	root          bool
+	closed        *atomic.Bool // Shared with derived loggers
+	closeOnce     *sync.Once
+}
+
+// NewWithOptions creates a slog-backed logger. Sampling is not supported and
+// ignored; other sinks than the console and a file fail the build.
+func NewWithOptions(opts logger.Options) (logger.Logger, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Level == "" {
+		return nil, fmt.Errorf("invalid log level %q", opts.Level)
+	}
+	if _, err := logger.ParseLevel(string(opts.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", opts.Level, err)
+	}
+	if sink := unsupportedSink(opts); sink != "" {
+		return nil, fmt.Errorf("slogx: %s sink not supported", sink)
+	}
+
+	handlerOpts := &slog.HandlerOptions{
+		AddSource:   opts.EnableCaller,
+		Level:       toSlogLevel(opts.Level),
+		ReplaceAttr: replaceAttr(opts),
+	}
+
+	var handlers []slog.Handler
+	report := logger.BuildReport{Env: opts.Env}
+	if !opts.DisableConsole {
+		var h slog.Handler
+		if opts.TextConsole() {
+			h = slog.NewTextHandler(os.Stdout, handlerOpts)
+		} else {
+			h = slog.NewJSONHandler(os.Stdout, handlerOpts)
+		}
+		handlers = append(handlers, h)
+		report.Sinks = append(report.Sinks, "console")
+	}
+
+	var files []*os.File
+	if opts.File != nil {
+		f, err := os.OpenFile(opts.File.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		files = append(files, f)
+		handlers = append(handlers, slog.NewJSONHandler(f, handlerOpts))
+		report.Sinks = append(report.Sinks, "file")
+	}
+
+	if len(handlers) == 0 {
+		return nil, fmt.Errorf("%w: console disabled and no other sinks enabled", logger.ErrNoSinks)
+	}
+
+	var handler slog.Handler = handlers[0]
+	if len(handlers) > 1 {
+		handler = multiHandler(handlers)
+	}
+
+	l := &slogAdapter{
+		handler:       handler,
+		contextKeys:   opts.Context,
+		contextFields: opts.Context.Mappings(),
+		enableCaller:  opts.EnableCaller,
+		development:   opts.Env.IsDev(),
+		fatalHook:     opts.FatalHook,
+		clock:         opts.ClockOrDefault(),
+		report:        report,
+		files:         files,
+		root:          true,
+		closed:        new(atomic.Bool),
+		closeOnce:     new(sync.Once),
+	}
+	if opts.StartupBanner {
+		l.Info(logger.StartupBannerMessage, opts.BannerFields(report)...)
+	}
+	return l, nil
+}
+
+// unsupportedSink names the first configured sink slogx can't write to
+func unsupportedSink(opts logger.Options) string {
+	switch {
+	case opts.Elastic != nil:
+		return "elasticsearch"
+	case opts.OTLP != nil:
+		return "otlp"
+	case opts.Fluent != nil:
+		return "fluent"
+	case opts.Network != nil:
+		return "network"
+	case opts.Alert != nil:
+		return "alert"
+	case opts.Ring != nil:
+		return "ring"
+	case opts.SQLite != nil:
+		return "sqlite"
+	case opts.Audit != nil:
+		return "audit"
+	case opts.Shadow != nil:
+		return "shadow"
+	}
+	return ""
+}
+
+func (l *slogAdapter) Debug(msg string, fields ...logger.Field) {
+	l.log(logger.DebugLevel, msg, fields)
+}
+
+func (l *slogAdapter) Info(msg string, fields ...logger.Field) {
+	l.log(logger.InfoLevel, msg, fields)
+}
+
+func (l *slogAdapter) Warn(msg string, fields ...logger.Field) {
+	l.log(logger.WarnLevel, msg, fields)
+}
+
+func (l *slogAdapter) Error(msg string, fields ...logger.Field) {
+	l.log(logger.ErrorLevel, msg, fields)
+}
+
+func (l *slogAdapter) DPanic(msg string, fields ...logger.Field) {
+	l.log(logger.DPanicLevel, msg, fields)
+}
+
+func (l *slogAdapter) Fatal(msg string, fields ...logger.Field) {
+	l.log(logger.FatalLevel, msg, fields)
+}
+
+func (l *slogAdapter) Log(level logger.Level, msg string, fields ...logger.Field) {
+	l.log(level, msg, fields)
+}
+
+func (l *slogAdapter) log(level logger.Level, msg string, fields []logger.Field) {
+	if l.closed.Load() {
+		return
+	}
+
+	// Outside dev, DPanic is an error entry flagged as such
+	dpanic := level == logger.DPanicLevel
+	if dpanic && !l.development {
+		level = logger.ErrorLevel
+		fields = append(fields[:len(fields):len(fields)], logger.F.Bool(logger.DPanicKey, true))
+	}
+
+	lvl := toSlogLevel(level)
+	ctx := context.Background()
+	if !l.handler.Enabled(ctx, lvl) {
+		return
+	}
+
+	var pc uintptr
+	if l.enableCaller {
+		var pcs [1]uintptr
+		runtime.Callers(3+l.callerSkip, pcs[:]) // Skip Callers, log and Info (or its siblings)
+		pc = pcs[0]
+	}
+	r := slog.NewRecord(l.clock.Now(), lvl, msg, pc)
+	if l.name != "" {
+		r.AddAttrs(slog.String("logger", l.name))
+	}
+	r.AddAttrs(toAttrs(fields)...)
+	_ = l.handler.Handle(ctx, r)
+
+	switch {
+	case dpanic && l.development:
+		panic(msg)
+	case level == logger.FatalLevel:
+		l.fatal(r.Time, msg, fields)
+	}
+}
+
+// fatal runs the FatalHook, or closes the sinks and exits like zapx
+func (l *slogAdapter) fatal(t time.Time, msg string, fields []logger.Field) {
+	if l.fatalHook != nil {
+		entry := logger.FatalEntry{Time: t, Message: msg, Fields: make(map[string]any, len(fields))}
+		for _, f := range fields {
+			entry.Fields[f.Key] = f.Val
+		}
+		l.fatalHook(entry)
+		return
+	}
+	l.closeSinks()
+	os.Exit(1)
+}
+
+func (l *slogAdapter) With(fields ...logger.Field) logger.Logger {
+	child := l.derive()
+	child.handler = l.handler.WithAttrs(toAttrs(fields))
+	return child
+}
+
+func (l *slogAdapter) WithContext(ctx context.Context) logger.Logger {
+	var fs []logger.Field
+	for _, m := range l.contextFields {
+		if v := ctx.Value(m.Key); v != nil {
+			fs = append(fs, logger.F.Any(m.Field, v))
+		}
+	}
+	if !l.contextKeys.DisableTraceExtraction {
+		fs = l.contextKeys.AppendTraceFields(fs, ctx)
+	}
+	if len(l.contextKeys.Baggage) > 0 {
+		fs = append(fs, l.contextKeys.BaggageFields(ctx)...)
+	}
+	if len(l.contextKeys.Extractors) > 0 {
+		fs = append(fs, l.contextKeys.RunExtractors(ctx, nil)...)
+	}
+	if len(fs) == 0 {
+		return l
+	}
+	return l.With(fs...)
+}
+
+// WithOptions derives a logger with other caller settings. slog records
+// carry no stacktraces, so StacktraceAt is ignored.
+func (l *slogAdapter) WithOptions(opts ...logger.EntryOption) logger.Logger {
+	o := logger.ApplyEntryOptions(opts...)
+	child := l.derive()
+	if o.Caller != nil {
+		child.enableCaller = *o.Caller
+	}
+	child.callerSkip += o.CallerSkip
+	return child
+}
+
+func (l *slogAdapter) Named(name string) logger.Logger {
+	child := l.derive()
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	child.name = name
+	return child
+}
+
+func (l *slogAdapter) BuildReport() logger.BuildReport {
+	return l.report
+}
+
+// Close flushes and closes the file sink. Only the root closes it, once;
+// entries logged afterwards are dropped.
+func (l *slogAdapter) Close(ctx context.Context) error {
+	if !l.root {
+		return nil
+	}
+	return l.closeSinks()
+}
+
+func (l *slogAdapter) closeSinks() error {
+	var err error
+	l.closeOnce.Do(func() {
+		l.closed.Store(true)
+		var errs []error
+		for _, f := range l.files {
+			if syncErr := f.Sync(); syncErr != nil {
+				errs = append(errs, fmt.Errorf("file: failed to sync: %w", syncErr))
+			}
+			if closeErr := f.Close(); closeErr != nil {
+				errs = append(errs, fmt.Errorf("file: %w", closeErr))
+			}
+		}
+		err = errors.Join(errs...)
+	})
+	return err
+}
+
+// derive copies l for a child logger, which shares everything but the sinks
+func (l *slogAdapter) derive() *slogAdapter {
+	child := *l
+	child.files = nil
+	child.root = false
+	return &child
+}
+
+// multiHandler writes each record to every handler enabled for it
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range m {
+		if h.Enabled(ctx, r.Level) {
+			if err := h.Handle(ctx, r.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(multiHandler, len(m))
+	for i, h := range m {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	out := make(multiHandler, len(m))
+	for i, h := range m {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
@@ -2,11 +2,15 @@ package zapx
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx/corefactories"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -14,6 +18,8 @@ import (
 
 // Ensure zapAdapter implements Logger
 var _ logger.Logger = (*zapAdapter)(nil)
+var _ logger.Auditor = (*zapAdapter)(nil)
+var _ logger.LevelController = (*zapAdapter)(nil)
 
 // zapBuilder implements NewBuilder interface
 type zapBuilder struct{}
@@ -21,8 +27,8 @@ type zapBuilder struct{}
 var _ logger.NewBuilder = (*zapBuilder)(nil)
 
 func init() {
-	// Register the zapx builder as the default
-	logger.SetBuilder(&zapBuilder{})
+	// Register the zapx builder, the default provider
+	logger.RegisterBuilder(logger.DefaultProvider, &zapBuilder{})
 }
 
 func (b *zapBuilder) NewWithOptions(opts logger.Options) (logger.Logger, error) {
@@ -31,15 +37,48 @@ func (b *zapBuilder) NewWithOptions(opts logger.Options) (logger.Logger, error)
 
 type zapAdapter struct {
 	zl             *zap.Logger
-	closers        []func() error
+	closers        []sinkCloser
 	metrics        *logger.Metrics
 	metricsEnabled bool
 	contextKeys    logger.ContextKeys
+	contextFields  []logger.ContextMapping // contextKeys.Mappings(), computed once
 	service        string
+	ring           *logger.RingBuffer
+	audit          *corefactories.AuditWriter // nil without an AuditSink
+	report         logger.BuildReport
+	sinks          []builtSink     // Shared with derived loggers; nil for NewFromZap
+	levels         *levelTable     // Shared with derived loggers; nil for NewFromZap
+	spanEventsAt   zapcore.Level   // zapcore.InvalidLevel when span events are off
+	span           trace.Span      // Recording span of the WithContext context, if span events are on
+	fieldCheck     *fieldCheck     // nil unless in dev mode or with StrictFields
+	allowlist      *fieldAllowlist // nil without a FieldAllowlist
+	violating      bool            // With bound a field outside a drop_entry allowlist
+	sanitize       bool            // Escape control characters and invalid UTF-8
+	normalize      bool            // NormalizeValues: NaN, nil pointers and raw bytes made JSON-safe
+	development    bool            // EnvDev: DPanic panics instead of logging an error
+	boundKeys      []string        // Keys bound by With, tracked for fieldCheck only
+	dedup          bool            // DedupFields: bound fields are kept here, not in zl
+	boundFields    []logger.Field  // With fields when dedup is on, one per key
+	contextBound   []logger.Field  // WithContext fields when dedup is on, one per key
+	root           bool            // Built by NewWithOptions; only the root closes the sinks
+	closed         *atomic.Bool    // Shared with derived loggers
 }
 
+// defaultService is the service of loggers built without one, as in the
+// default options
+const defaultService = "app"
+
 // NewWithOptions creates a new logger with the provided options
 func NewWithOptions(opts logger.Options) (logger.Logger, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Service == "" {
+		// A zero-value Options would otherwise name indices "-2025.01.02"
+		opts.Diagnosticf("no service name configured, using %q", defaultService)
+		opts.Service = defaultService
+	}
+
 	// Parse log level
 	lvl, err := ToZapLevel(opts.Level)
 	if err != nil {
@@ -55,13 +94,33 @@ func NewWithOptions(opts logger.Options) (logger.Logger, error) {
 		}
 	}
 
+	// Parse span events level
+	spanLvl := zapcore.InvalidLevel
+	if opts.SpanEvents != "" {
+		spanLvl, err = ToZapLevel(opts.SpanEvents)
+		if err != nil {
+			return nil, fmt.Errorf("invalid span events level %q: %w", opts.SpanEvents, err)
+		}
+	}
+
+	levels, err := newLevelTable(lvl, opts.LevelOverrides)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create encoder config
-	encCfg := createEncoderConfig(opts)
+	encCfg, err := createEncoderConfig(opts)
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize metrics if enabled
 	var metrics *logger.Metrics
 	if opts.Metrics.Enabled {
 		metrics = logger.GetMetrics()
+		if opts.Metrics.ServiceLabel {
+			metrics = metrics.ForService(opts.Service)
+		}
 		if opts.Metrics.AutoRegister {
 			if err := logger.AutoRegisterMetrics(); err != nil {
 				return nil, fmt.Errorf("failed to auto-register metrics: %w", err)
@@ -69,23 +128,61 @@ func NewWithOptions(opts logger.Options) (logger.Logger, error) {
 		}
 	}
 
+	allowlist, err := newFieldAllowlist(opts, metrics)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build cores
 	coreBuilder := &coreBuilder{
 		opts:    opts,
 		encCfg:  encCfg,
-		lvl:     lvl,
+		levels:  levels,
 		metrics: metrics,
+		report:  logger.BuildReport{Env: opts.Env},
+
+		sampleSinks: perSinkSampling(opts),
+	}
+
+	// Audit outputs live outside the core tree so sampling never applies to them
+	var audit *corefactories.AuditWriter
+	if opts.Audit != nil {
+		audit, err = corefactories.NewAuditWriter(encCfg, metrics, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build audit sink: %w", err)
+		}
 	}
 
 	cores, closers, err := coreBuilder.buildCores()
 	if err != nil {
+		if audit != nil {
+			audit.Close()
+		}
 		return nil, fmt.Errorf("failed to build cores: %w", err)
 	}
+	if audit != nil {
+		closers = append(closers, sinkCloser{name: "audit", close: func(context.Context) error { return audit.Close() }})
+	}
 
 	// Create the core
 	var core zapcore.Core
 	if len(cores) == 0 {
-		return nil, fmt.Errorf("no log sinks configured: console disabled and no other sinks enabled")
+		if audit != nil {
+			audit.Close()
+		}
+		if skipped := coreBuilder.report.Skipped; len(skipped) > 0 {
+			errs := make([]error, len(skipped))
+			for i := range skipped {
+				errs[i] = skipped[i]
+			}
+			return nil, fmt.Errorf("no log sinks left: every enabled sink failed to build: %w", errors.Join(errs...))
+		}
+		if opts.DisableConsole {
+			return nil, fmt.Errorf("%w: console disabled and no other sinks enabled", logger.ErrNoSinks)
+		}
+		return nil, fmt.Errorf("%w: no registered sink factory is enabled", logger.ErrNoSinks)
+	} else if opts.ParallelSinks {
+		core, closers = newParallelTee(cores, coreBuilder.report.Sinks, closers, metrics)
 	} else if len(cores) == 1 {
 		core = cores[0]
 	} else {
@@ -93,7 +190,12 @@ func NewWithOptions(opts logger.Options) (logger.Logger, error) {
 	}
 
 	// Apply sampling if configured
-	if opts.Sampling != nil {
+	switch {
+	case coreBuilder.sampleSinks:
+		// Every sink got its own sampler
+	case opts.Sampling != nil && opts.SamplingMarker:
+		core = newMarkerSampler(core, time.Second, opts.Sampling.Initial, opts.Sampling.Thereafter)
+	case opts.Sampling != nil:
 		core = zapcore.NewSamplerWithOptions(
 			core, time.Second,
 			opts.Sampling.Initial,
@@ -104,30 +206,79 @@ func NewWithOptions(opts logger.Options) (logger.Logger, error) {
 	// Create zap logger options
 	zapOpts := []zap.Option{
 		zap.AddCallerSkip(2),
+		zap.WithClock(opts.ClockOrDefault()),
 	}
 
 	if opts.EnableCaller {
 		zapOpts = append(zapOpts, zap.AddCaller())
 	}
 
+	if opts.Env.IsDev() {
+		zapOpts = append(zapOpts, zap.Development()) // DPanic panics after writing
+	}
+
 	if stackLvl != zapcore.InvalidLevel {
 		zapOpts = append(zapOpts, zap.AddStacktrace(stackLvl))
 	}
 
+	// What Fatal does after writing; the default needs the root to close it
+	var exitHook *exitOnFatal
+	if opts.FatalHook != nil {
+		zapOpts = append(zapOpts, zap.WithFatalHook(ToCheckWriteHook(opts.FatalHook)))
+	} else {
+		exitHook = &exitOnFatal{}
+		zapOpts = append(zapOpts, zap.WithFatalHook(exitHook))
+	}
+
 	// Create the underlying zap logger
 	zl := zap.New(core, zapOpts...)
 
-	return &zapAdapter{
+	root := &zapAdapter{
 		zl:             zl,
 		closers:        closers,
+		root:           true,
+		closed:         new(atomic.Bool),
 		metrics:        metrics,
 		metricsEnabled: opts.Metrics.Enabled,
 		contextKeys:    opts.Context,
+		contextFields:  opts.Context.Mappings(),
+		spanEventsAt:   spanLvl,
+		fieldCheck:     newFieldCheck(opts),
+		dedup:          opts.DedupFields,
+		allowlist:      allowlist,
+		sanitize:       sanitizeEnabled(opts),
+		normalize:      opts.NormalizeValues,
+		development:    opts.Env.IsDev(),
 		service:        opts.Service,
-	}, nil
+		ring:           coreBuilder.ring,
+		audit:          audit,
+		report:         coreBuilder.report,
+		sinks:          coreBuilder.sinks,
+		levels:         levels,
+	}
+	if exitHook != nil {
+		exitHook.root = root
+	}
+	if opts.StartupBanner {
+		root.Info(logger.StartupBannerMessage, opts.BannerFields(root.report)...)
+	}
+	return root, nil
 }
 
-func createEncoderConfig(opts logger.Options) zapcore.EncoderConfig {
+func createEncoderConfig(opts logger.Options) (zapcore.EncoderConfig, error) {
+	durationEncoder, err := corefactories.DurationEncoder(opts.Encoder.DurationUnit)
+	if err != nil {
+		return zapcore.EncoderConfig{}, err
+	}
+	if _, err := corefactories.DurationEncoder(opts.Encoder.ConsoleDurationUnit); err != nil {
+		return zapcore.EncoderConfig{}, fmt.Errorf("console: %w", err)
+	}
+	switch opts.Encoder.Console {
+	case "", logger.ConsoleJSON, logger.ConsoleText:
+	default:
+		return zapcore.EncoderConfig{}, fmt.Errorf("unknown console encoding %q", opts.Encoder.Console)
+	}
+
 	timeEncoder := zapcore.ISO8601TimeEncoder
 	if opts.TimeFormat != "" {
 		if opts.TimeFormat == time.RFC3339Nano {
@@ -138,26 +289,19 @@ func createEncoderConfig(opts logger.Options) zapcore.EncoderConfig {
 	}
 
 	return zapcore.EncoderConfig{
-		TimeKey:        "ts",
-		LevelKey:       "level",
-		NameKey:        "logger",
-		CallerKey:      "caller",
+		TimeKey:        logger.TimeKey,
+		LevelKey:       logger.LevelKey,
+		NameKey:        logger.LoggerKey,
+		CallerKey:      logger.CallerKey,
 		FunctionKey:    zapcore.OmitKey,
-		MessageKey:     "msg",
-		StacktraceKey:  "stacktrace",
+		MessageKey:     logger.MessageKey,
+		StacktraceKey:  logger.StacktraceKey,
 		LineEnding:     zapcore.DefaultLineEnding,
 		EncodeLevel:    zapcore.LowercaseLevelEncoder,
 		EncodeTime:     timeEncoder,
-		EncodeDuration: zapcore.SecondsDurationEncoder,
+		EncodeDuration: durationEncoder,
 		EncodeCaller:   zapcore.ShortCallerEncoder,
-	}
-}
-
-func createEncoder(encCfg zapcore.EncoderConfig, isProduction bool) zapcore.Encoder {
-	if isProduction {
-		return zapcore.NewJSONEncoder(encCfg)
-	}
-	return zapcore.NewConsoleEncoder(encCfg)
+	}, nil
 }
 
 func (l *zapAdapter) Debug(msg string, fields ...logger.Field) {
@@ -176,115 +320,409 @@ func (l *zapAdapter) Error(msg string, fields ...logger.Field) {
 	l.log(zapcore.ErrorLevel, msg, fields...)
 }
 
+func (l *zapAdapter) DPanic(msg string, fields ...logger.Field) {
+	l.log(zapcore.DPanicLevel, msg, fields...)
+}
+
+func (l *zapAdapter) Fatal(msg string, fields ...logger.Field) {
+	l.log(zapcore.FatalLevel, msg, fields...)
+}
+
 func (l *zapAdapter) Log(level logger.Level, msg string, fields ...logger.Field) {
 	l.log(toZapLevel(level), msg, fields...)
 }
 
 func (l *zapAdapter) With(fields ...logger.Field) logger.Logger {
-	return &zapAdapter{
-		zl:             l.zl.With(toZapFields(fields...)...),
-		closers:        l.closers, // Share closers
-		metrics:        l.metrics,
-		metricsEnabled: l.metricsEnabled,
-		contextKeys:    l.contextKeys,
-		service:        l.service,
+	return l.with(fields, false)
+}
+
+// with binds fields to a child logger; fromContext marks those of WithContext,
+// which rank below With fields when dedup is on
+func (l *zapAdapter) with(fields []logger.Field, fromContext bool) *zapAdapter {
+	if l.sanitize {
+		fields = sanitizeFields(fields)
+	}
+	child := l.derive()
+	if l.allowlist != nil {
+		var violated bool
+		fields, violated = l.allowlist.apply(fields)
+		child.violating = l.violating || violated
+	}
+	zf := toZapFields(fields...)
+	if l.normalize {
+		zf = corefactories.NormalizeFields(zf)
 	}
+	if !l.dedup {
+		child.zl = l.zl.With(zf...)
+	} else if fromContext {
+		child.contextBound = mergeFields(l.contextBound, fields)
+	} else {
+		child.boundFields = mergeFields(l.boundFields, fields)
+	}
+	child.audit = l.audit.With(zf)
+	if l.fieldCheck != nil {
+		l.fieldCheck.check("With", l.boundKeys, fields)
+		child.boundKeys = make([]string, 0, len(l.boundKeys)+len(fields))
+		child.boundKeys = append(child.boundKeys, l.boundKeys...)
+		for _, f := range fields {
+			child.boundKeys = append(child.boundKeys, f.Key)
+		}
+	}
+	return child
 }
 
 func (l *zapAdapter) WithContext(ctx context.Context) logger.Logger {
+	// Nothing to look up: skip the context walks entirely
+	keys := &l.contextKeys
+	if len(l.contextFields) == 0 && keys.DisableTraceExtraction && len(keys.Baggage) == 0 &&
+		len(keys.Extractors) == 0 && l.spanEventsAt == zapcore.InvalidLevel {
+		return l.scoped()
+	}
+
+	// fs stays nil when ctx adds nothing
 	var fs []logger.Field
 
-	// Extract request ID
-	if l.contextKeys.RequestIDKey != nil {
-		if rid := ctx.Value(l.contextKeys.RequestIDKey); rid != nil {
-			fs = append(fs, logger.F.Any("request_id", rid))
+	for _, m := range l.contextFields {
+		if v := ctx.Value(m.Key); v != nil {
+			if fs == nil {
+				fs = make([]logger.Field, 0, len(l.contextFields)+3)
+			}
+			fs = append(fs, logger.F.Any(m.Field, v))
 		}
 	}
 
-	// Extract user ID
-	if l.contextKeys.UserIDKey != nil {
-		if uid := ctx.Value(l.contextKeys.UserIDKey); uid != nil {
-			fs = append(fs, logger.F.Any("user_id", uid))
-		}
+	// Extract OpenTelemetry trace information
+	if !keys.DisableTraceExtraction {
+		fs = keys.AppendTraceFields(fs, ctx)
 	}
 
-	// Extract OpenTelemetry trace information
-	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
-		fs = append(fs,
-			logger.F.String("trace_id", sc.TraceID().String()),
-			logger.F.String("span_id", sc.SpanID().String()),
-		)
+	if len(keys.Baggage) > 0 {
+		fs = append(fs, keys.BaggageFields(ctx)...)
 	}
 
-	if len(fs) == 0 {
-		return l
+	if len(keys.Extractors) > 0 {
+		fs = append(fs, keys.RunExtractors(ctx, l.metrics)...)
 	}
 
-	return l.With(fs...)
+	span := l.recordingSpan(ctx)
+	if len(fs) == 0 && span == nil {
+		return l.scoped()
+	}
+
+	child := l.with(fs, true)
+	if span != nil {
+		child.span = span
+	}
+	return child
+}
+
+// RingBuffer returns the RingSink buffer served by logger.RingHandler (nil without a RingSink)
+func (l *zapAdapter) RingBuffer() *logger.RingBuffer {
+	return l.ring
+}
+
+// Metrics returns the metrics the logger records into, nil when they are disabled
+func (l *zapAdapter) Metrics() *logger.Metrics {
+	return l.metrics
+}
+
+// BuildReport returns which sinks the logger was built with and which were skipped
+func (l *zapAdapter) BuildReport() logger.BuildReport {
+	return l.report
+}
+
+// Audit writes msg to the AuditSink outputs, bypassing sampling; see logger.Audit
+func (l *zapAdapter) Audit(ctx context.Context, msg string, fields ...logger.Field) error {
+	if l.audit == nil {
+		return logger.ErrAuditNotConfigured
+	}
+	if l.sanitize {
+		msg = sanitizeString(msg)
+		fields = sanitizeFields(fields)
+	}
+	if l.allowlist != nil {
+		// Audit events are never dropped; the offending fields still are
+		fields, _ = l.allowlist.apply(fields)
+	}
+	zf := toZapFields(fields...)
+	if l.normalize {
+		zf = corefactories.NormalizeFields(zf)
+	}
+	return l.audit.Write(ctx, msg, zf)
 }
 
 func (l *zapAdapter) Close(ctx context.Context) error {
+	_, err := l.CloseWithReport(ctx)
+	return err
+}
+
+// CloseWithReport closes like Close and reports how each sink closed
+func (l *zapAdapter) CloseWithReport(ctx context.Context) (logger.CloseReport, error) {
+	// Only the first Close of the root does anything; later calls return nil
+	if l.root {
+		if !l.closed.CompareAndSwap(false, true) {
+			return logger.CloseReport{}, nil
+		}
+	} else if l.closed.Load() {
+		return logger.CloseReport{}, nil
+	}
+	start := time.Now()
+
+	// Every failure is kept: the sync error and each sink's, prefixed with its name
+	var errs []error
+
 	// First, sync the zap logger
 	if err := l.zl.Sync(); err != nil {
 		// Ignore known sync errors on non-seekable files
 		if err.Error() != "sync /dev/stdout: invalid argument" &&
 			err.Error() != "sync /dev/stderr: invalid argument" {
-			return fmt.Errorf("failed to sync logger: %w", err)
+			errs = append(errs, fmt.Errorf("failed to sync logger: %w", err))
 		}
 	}
 
-	// Close all registered closers
-	var lastErr error
-	for _, closer := range l.closers {
-		if err := closer(); err != nil {
-			lastErr = err
+	// Closers run concurrently so one slow sink can't use up the others' share of
+	// the deadline; sinks still running when ctx is done are named in the error
+	type result struct {
+		i       int
+		err     error
+		elapsed time.Duration
+	}
+	results := make(chan result, len(l.closers))
+	for i, c := range l.closers {
+		go func() {
+			begin := time.Now()
+			err := c.close(ctx)
+			results <- result{i, err, time.Since(begin)}
+		}()
+	}
+
+	sinks := make([]logger.SinkCloseReport, len(l.closers))
+	record := func(r result) {
+		s := &sinks[r.i]
+		s.Duration = r.elapsed
+		s.Err = r.err
+		// A sink that gave up because of ctx did not finish either
+		s.Finished = r.err == nil || ctx.Err() == nil || !errors.Is(r.err, ctx.Err())
+	}
+wait:
+	for n := 0; n < len(l.closers); n++ {
+		select {
+		case r := <-results:
+			record(r)
+		case <-ctx.Done():
+			// Keep closers that finished at the same moment
+			for len(results) > 0 {
+				record(<-results)
+			}
+			break wait
 		}
 	}
 
-	return lastErr
+	var pending []string
+	for i, c := range l.closers {
+		s := &sinks[i]
+		s.Name = c.name
+		if c.counts != nil {
+			counts := c.counts()
+			s.Counts = &counts
+		}
+		if c.indexer != nil {
+			stats := c.indexer()
+			s.Indexer = &stats
+		}
+		if !s.Finished {
+			if s.Duration == 0 { // Still running
+				s.Duration = time.Since(start)
+			}
+			if s.Err == nil {
+				s.Err = ctx.Err()
+			}
+			pending = append(pending, c.name)
+		} else if s.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, s.Err))
+		}
+	}
+	if len(pending) > 0 {
+		errs = append(errs, fmt.Errorf("failed to close sinks %s: %w", strings.Join(pending, ", "), ctx.Err()))
+	}
+	return logger.CloseReport{Duration: time.Since(start), Sinks: sinks}, errors.Join(errs...)
 }
 
 func (l *zapAdapter) log(level zapcore.Level, msg string, fields ...logger.Field) {
-	zf := toZapFields(fields...)
+	// The sinks are closed: drop instead of writing to them
+	if l.closed.Load() {
+		if l.metricsEnabled {
+			l.metrics.RecordLogDropped("logger", "logger_closed")
+		}
+		return
+	}
+
+	// Outside dev, DPanic is an error entry flagged as such
+	if level == zapcore.DPanicLevel && !l.development {
+		level = zapcore.ErrorLevel
+		fields = append(fields[:len(fields):len(fields)], logger.F.Bool(logger.DPanicKey, true))
+	}
+
+	// Disabled or sampled-away entries cost nothing beyond this check
+	ce := l.zl.Check(level, msg)
+	if ce == nil {
+		return
+	}
+
+	if l.sanitize {
+		ce.Message = sanitizeString(ce.Message)
+		fields = sanitizeFields(fields)
+	}
+
+	if l.allowlist != nil {
+		var violated bool
+		fields, violated = l.allowlist.apply(fields)
+		if (violated || l.violating) && l.allowlist.dropsEntry(level) {
+			if l.metricsEnabled {
+				l.metrics.RecordLogDropped("logger", "field_violation")
+			}
+			return
+		}
+	}
+
+	if l.fieldCheck != nil {
+		l.fieldCheck.check(msg, l.boundKeys, fields)
+	}
+
+	if l.span != nil && level >= l.spanEventsAt {
+		l.addSpanEvent(level, msg, fields)
+	}
 
-	// Record metrics if enabled
-	if l.metricsEnabled && l.metrics != nil {
-		l.metrics.RecordLogWritten(level.String(), "zap")
+	if len(l.boundFields)+len(l.contextBound) > 0 {
+		fields = mergeFields(l.contextBound, l.boundFields, fields)
 	}
 
-	switch level {
-	case zapcore.DebugLevel:
-		l.zl.Debug(msg, zf...)
-	case zapcore.InfoLevel:
-		l.zl.Info(msg, zf...)
-	case zapcore.WarnLevel:
-		l.zl.Warn(msg, zf...)
-	case zapcore.ErrorLevel:
-		l.zl.Error(msg, zf...)
-	case zapcore.FatalLevel:
-		l.zl.Fatal(msg, zf...)
+	// logs_written_total is recorded per sink by metricsCore
+	zf := getZapFields(fields)
+	if l.normalize {
+		ce.Write(corefactories.NormalizeFields(*zf)...)
+	} else {
+		ce.Write(*zf...)
 	}
+	putZapFields(zf)
 }
 
 func (a *zapAdapter) WithCallerSkip(delta int) logger.Logger {
-	return &zapAdapter{
-		zl:             a.zl.WithOptions(zap.AddCallerSkip(delta)),
-		closers:        a.closers,
-		metrics:        a.metrics,
-		metricsEnabled: a.metricsEnabled,
-		contextKeys:    a.contextKeys,
-		service:        a.service,
+	child := a.derive()
+	child.zl = a.zl.WithOptions(zap.AddCallerSkip(delta))
+	return child
+}
+
+// WithOptions derives a logger with other caller and stacktrace settings. It
+// writes to the same cores; an unknown stacktrace level is ignored.
+func (l *zapAdapter) WithOptions(opts ...logger.EntryOption) logger.Logger {
+	o := logger.ApplyEntryOptions(opts...)
+	var zapOpts []zap.Option
+	if o.Caller != nil {
+		zapOpts = append(zapOpts, zap.WithCaller(*o.Caller))
+	}
+	if o.StacktraceAt != nil {
+		if *o.StacktraceAt == "" {
+			zapOpts = append(zapOpts, zap.AddStacktrace(zap.LevelEnablerFunc(func(zapcore.Level) bool { return false })))
+		} else if lvl, err := ToZapLevel(*o.StacktraceAt); err == nil {
+			zapOpts = append(zapOpts, zap.AddStacktrace(lvl))
+		}
+	}
+	if o.CallerSkip != 0 {
+		zapOpts = append(zapOpts, zap.AddCallerSkip(o.CallerSkip))
+	}
+
+	child := l.derive()
+	child.zl = l.zl.WithOptions(zapOpts...)
+	return child
+}
+
+func (l *zapAdapter) Named(name string) logger.Logger {
+	child := l.derive()
+	child.zl = l.zl.Named(name)
+	return child
+}
+
+// derive copies l for a child logger, which shares everything but the sinks
+func (l *zapAdapter) derive() *zapAdapter {
+	child := *l
+	child.closers = nil // Sinks belong to the root logger; a derived Close only syncs
+	child.root = false
+	return &child
+}
+
+// scoped returns l when it is already derived, or else a derived copy, so Close
+// on a request-scoped logger never closes the sinks of the root
+func (l *zapAdapter) scoped() *zapAdapter {
+	if !l.root {
+		return l
 	}
+	return l.derive()
 }
 
 func toZapFields(fields ...logger.Field) []zap.Field {
 	out := make([]zap.Field, 0, len(fields))
 	for _, f := range fields {
-		out = append(out, zap.Any(f.Key, f.Val))
+		out = append(out, toZapField(f))
 	}
 	return out
 }
 
+// fieldPool recycles the field slices of log calls. zap's cores and every core
+// in corefactories encode (or copy) the fields before Write returns, so a slice
+// is free again once the zl call returns. With keeps allocating: cores may hold
+// on to the fields bound there.
+var fieldPool = sync.Pool{
+	New: func() any {
+		zf := make([]zap.Field, 0, 8)
+		return &zf
+	},
+}
+
+// maxPooledFields keeps unusually large slices out of the pool
+const maxPooledFields = 64
+
+func getZapFields(fields []logger.Field) *[]zap.Field {
+	zf := fieldPool.Get().(*[]zap.Field)
+	for _, f := range fields {
+		*zf = append(*zf, toZapField(f))
+	}
+	return zf
+}
+
+func putZapFields(zf *[]zap.Field) {
+	if cap(*zf) > maxPooledFields {
+		return
+	}
+	clear(*zf) // Drop references to the logged values
+	*zf = (*zf)[:0]
+	fieldPool.Put(zf)
+}
+
+// toZapField encodes the common value types directly; zap.Any handles the rest
+func toZapField(f logger.Field) zap.Field {
+	switch v := f.Val.(type) {
+	case string:
+		return zap.String(f.Key, v)
+	case int:
+		return zap.Int(f.Key, v)
+	case int64:
+		return zap.Int64(f.Key, v)
+	case float64:
+		return zap.Float64(f.Key, v)
+	case bool:
+		return zap.Bool(f.Key, v)
+	case time.Time:
+		return zap.Time(f.Key, v)
+	case time.Duration:
+		return zap.Duration(f.Key, v)
+	case []string:
+		return zap.Strings(f.Key, v)
+	default:
+		return zap.Any(f.Key, f.Val)
+	}
+}
+
 // Map logger.Level -> zapcore.Level (fallback: info)
 func toZapLevel(lvl logger.Level) zapcore.Level {
 	switch lvl {
@@ -296,14 +734,11 @@ func toZapLevel(lvl logger.Level) zapcore.Level {
 		return zapcore.WarnLevel
 	case logger.ErrorLevel:
 		return zapcore.ErrorLevel
+	case logger.DPanicLevel:
+		return zapcore.DPanicLevel
+	case logger.FatalLevel:
+		return zapcore.FatalLevel
 	default:
 		return zapcore.InfoLevel
 	}
 }
-
-// fallbackConsoleWriter is used as a fallback when no cores are configured
-type fallbackConsoleWriter struct{}
-
-func (cw *fallbackConsoleWriter) Write(p []byte) (int, error) {
-	return os.Stdout.Write(p)
-}
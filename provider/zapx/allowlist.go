@@ -0,0 +1,134 @@
+package zapx
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap/zapcore"
+)
+
+// builtinFieldKeys are written by loggerkit itself and always allowed
+var builtinFieldKeys = []string{
+	logger.TimeKey, logger.LevelKey, logger.MessageKey, logger.LoggerKey, logger.CallerKey, logger.StacktraceKey,
+	logger.DefaultTraceIDField, logger.DefaultSpanIDField, logger.DefaultTraceFlagsField,
+	logger.DPanicKey, logger.SampledDroppedKey,
+}
+
+const (
+	// maxViolationLabels bounds the keys labeled as is in logs_field_violations_total
+	maxViolationLabels = 100
+	// maxViolationKeyLen sends longer keys to the hashed buckets right away
+	maxViolationKeyLen = 64
+	// violationBuckets is the number of hashed labels ("other_00".."other_3f")
+	violationBuckets = 64
+)
+
+// fieldAllowlist applies Options.FieldAllowlist in the adapter, to With and to
+// call-site fields
+type fieldAllowlist struct {
+	keys    map[string]struct{}
+	policy  logger.FieldViolation
+	metrics *logger.Metrics
+}
+
+// violationLabels holds the keys labeled as is so far, shared like the metric
+var violationLabels = struct {
+	sync.Mutex
+	keys map[string]struct{}
+}{keys: make(map[string]struct{})}
+
+// newFieldAllowlist returns nil, allowing every key, without a FieldAllowlist
+func newFieldAllowlist(opts logger.Options, metrics *logger.Metrics) (*fieldAllowlist, error) {
+	cfg := opts.FieldAllowlist
+	if cfg == nil {
+		return nil, nil
+	}
+	policy := cfg.OnViolation
+	switch policy {
+	case "":
+		policy = logger.FieldViolationDropField
+	case logger.FieldViolationDropField, logger.FieldViolationDropEntry, logger.FieldViolationRedact:
+	default:
+		return nil, fmt.Errorf("invalid field violation policy %q (want %q, %q or %q)", policy,
+			logger.FieldViolationDropField, logger.FieldViolationDropEntry, logger.FieldViolationRedact)
+	}
+	a := &fieldAllowlist{
+		keys:    make(map[string]struct{}, len(cfg.Keys)+len(builtinFieldKeys)),
+		policy:  policy,
+		metrics: metrics,
+	}
+	for _, k := range builtinFieldKeys {
+		a.keys[k] = struct{}{}
+	}
+	traceID, spanID, flags := opts.Context.TraceFieldNames()
+	for _, k := range []string{traceID, spanID, flags} {
+		a.keys[k] = struct{}{}
+	}
+	for _, k := range cfg.Keys {
+		a.keys[k] = struct{}{}
+	}
+	return a, nil
+}
+
+// apply returns fields with the violations dropped, or redacted under
+// FieldViolationRedact, and whether there were any. fields is only copied when
+// a field changes, since the caller owns it.
+func (a *fieldAllowlist) apply(fields []logger.Field) ([]logger.Field, bool) {
+	var out []logger.Field
+	for i, f := range fields {
+		if _, ok := a.keys[f.Key]; ok {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		a.record(f.Key)
+		if out == nil {
+			out = append(make([]logger.Field, 0, len(fields)), fields[:i]...)
+		}
+		if a.policy == logger.FieldViolationRedact {
+			out = append(out, logger.F.String(f.Key, "REDACTED"))
+		}
+	}
+	if out == nil {
+		return fields, false
+	}
+	return out, true
+}
+
+// dropsEntry reports whether a violation drops an entry at level. DPanic and
+// Fatal entries are written without the offending fields instead, so their
+// hooks still run.
+func (a *fieldAllowlist) dropsEntry(level zapcore.Level) bool {
+	return a.policy == logger.FieldViolationDropEntry && level < zapcore.DPanicLevel
+}
+
+func (a *fieldAllowlist) record(key string) {
+	if a.metrics == nil {
+		return
+	}
+	a.metrics.RecordFieldViolation(violationLabel(key))
+}
+
+// violationLabel keeps the metric's cardinality bounded: the first maxViolationLabels
+// keys are labeled as is, later (or very long) ones by one of violationBuckets
+// hashes
+func violationLabel(key string) string {
+	if len(key) <= maxViolationKeyLen {
+		violationLabels.Lock()
+		_, seen := violationLabels.keys[key]
+		if !seen && len(violationLabels.keys) < maxViolationLabels {
+			violationLabels.keys[key] = struct{}{}
+			seen = true
+		}
+		violationLabels.Unlock()
+		if seen {
+			return key
+		}
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return fmt.Sprintf("other_%02x", h.Sum32()%violationBuckets)
+}
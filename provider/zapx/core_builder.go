@@ -2,39 +2,156 @@ package zapx
 
 import (
 	"fmt"
+	"slices"
+	"strings"
+
 	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx/corefactories"
 	"go.uber.org/zap/zapcore"
 )
 
 type coreBuilder struct {
 	opts    logger.Options
 	encCfg  zapcore.EncoderConfig
-	lvl     zapcore.Level
+	levels  *levelTable
 	metrics *logger.Metrics
+	ring    *logger.RingBuffer // Set when a core exposes one (RingSink)
+	report  logger.BuildReport
+	sinks   []builtSink
+
+	sampleSinks bool // Sample each sink on its own instead of the whole tee
+}
+
+// sinkCloser is a sink's closer with the name reported when it fails to finish
+type sinkCloser struct {
+	name    string
+	close   corefactories.CloseFunc
+	counts  func() logger.CloseCounts  // Set for sinks that count what closing flushed
+	indexer func() logger.IndexerStats // Set for sinks with a bulk indexer
 }
 
 // provider/zapx/core_builder.go
-func (cb *coreBuilder) buildCores() ([]zapcore.Core, []func() error, error) {
+func (cb *coreBuilder) buildCores() ([]zapcore.Core, []sinkCloser, error) {
 	var cores []zapcore.Core
-	var closers []func() error
+	var closers []sinkCloser
 
-	reg := getRegistry() // default or injected by tests
+	skip := false
+	switch cb.opts.SinkErrorPolicy {
+	case "", logger.SinkErrorFail:
+	case logger.SinkErrorSkip:
+		skip = true
+	default:
+		return nil, nil, fmt.Errorf("invalid sink error policy %q (want %q or %q)", cb.opts.SinkErrorPolicy, logger.SinkErrorFail, logger.SinkErrorSkip)
+	}
+
+	reg := optionsRegistry(cb.opts) // default, injected by tests or set with WithFactoryRegistry
+	for _, err := range unlinkedSinks(reg, cb.opts) {
+		if !skip {
+			return nil, nil, err
+		}
+		cb.opts.Diagnosticf("skipping %s sink: %v", err.sink, err)
+		cb.report.Skipped = append(cb.report.Skipped, logger.SinkError{Sink: err.sink, Err: err})
+	}
 	for _, factory := range reg.All() {
 		if !factory.Enabled(cb.opts) {
 			continue
 		}
-		core, closer, err := factory.Build(cb.encCfg, cb.lvl, cb.metrics, cb.opts)
+		core, closer, err := corefactories.BuildCore(factory, cb.encCfg, followLevel, cb.metrics, cb.opts)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to build %s core: %w", factory.Name(), err)
+			if !skip {
+				return nil, nil, fmt.Errorf("failed to build %s core: %w", factory.Name(), err)
+			}
+			cb.opts.Diagnosticf("skipping %s sink: %v", factory.Name(), err)
+			cb.report.Skipped = append(cb.report.Skipped, logger.SinkError{Sink: factory.Name(), Err: err})
+			continue
+		}
+		if rc, ok := core.(interface{ RingBuffer() *logger.RingBuffer }); ok {
+			cb.ring = rc.RingBuffer()
+		}
+		var counts func() logger.CloseCounts
+		if cc, ok := core.(interface{ CloseCounts() logger.CloseCounts }); ok {
+			counts = cc.CloseCounts
+		}
+		var indexer func() logger.IndexerStats
+		if is, ok := core.(indexerStatsReporter); ok {
+			indexer = is.IndexerStats
 		}
 		if core != nil {
+			follows := core.Enabled(followLevel)
+			cb.sinks = append(cb.sinks, builtSink{
+				name:    factory.Name(),
+				typ:     strings.TrimPrefix(fmt.Sprintf("%T", factory), "*"),
+				core:    core,
+				follows: follows,
+			})
 			core = NewMetricsCore(core, factory.Name(), cb.metrics)
+			if follows {
+				core = &levelFilter{Core: core, levels: cb.levels}
+			}
+			if s := sinkSampling(cb.opts, factory.Name()); cb.sampleSinks && s != nil && *s != (logger.Sampling{}) {
+				core = newSinkSampler(core, *s, cb.opts.SamplingMarker)
+			}
 			cores = append(cores, core)
+			cb.report.Sinks = append(cb.report.Sinks, factory.Name())
 		}
 		if closer != nil {
-			closers = append(closers, closer)
+			closers = append(closers, sinkCloser{name: factory.Name(), close: closer, counts: counts, indexer: indexer})
 		}
 	}
 
 	return cores, closers, nil
 }
+
+// linkedSinks are the sinks whose factory registers itself from its own package
+var linkedSinks = []struct {
+	name       string
+	pkg        string
+	configured func(logger.Options) bool
+}{
+	{"elasticsearch", corefactories.ElasticPackage, func(o logger.Options) bool { return o.Elastic != nil }},
+	{"otlp", corefactories.OTLPPackage, func(o logger.Options) bool { return o.OTLP != nil }},
+	{"sqlite", corefactories.SQLitePackage, func(o logger.Options) bool { return o.SQLite != nil }},
+}
+
+// unlinkedSinkError reports a configured sink whose factory isn't registered
+type unlinkedSinkError struct {
+	sink string
+	pkg  string
+}
+
+func (e *unlinkedSinkError) Error() string {
+	return fmt.Sprintf("%s sink is configured but its factory is not registered: import _ %q", e.sink, e.pkg)
+}
+
+// unlinkedSinks returns an error for each sink configured in opts that reg has
+// no factory for, which usually means its package was never imported
+func unlinkedSinks(reg corefactories.Registry, opts logger.Options) []*unlinkedSinkError {
+	var errs []*unlinkedSinkError
+	for _, s := range linkedSinks {
+		if !s.configured(opts) || slices.ContainsFunc(reg.All(), func(f corefactories.CoreFactory) bool { return f.Name() == s.name }) {
+			continue
+		}
+		errs = append(errs, &unlinkedSinkError{sink: s.name, pkg: s.pkg})
+	}
+	return errs
+}
+
+// perSinkSampling reports whether a sink overrides Options.Sampling, in which
+// case every sink is sampled on its own rather than all of them together
+func perSinkSampling(opts logger.Options) bool {
+	return (opts.File != nil && opts.File.Sampling != nil) ||
+		(opts.Elastic != nil && opts.Elastic.Sampling != nil)
+}
+
+// sinkSampling is the sampling of the sink named name: its override, or else
+// Options.Sampling. Nil or a zero Sampling means every entry is written.
+func sinkSampling(opts logger.Options, name string) *logger.Sampling {
+	switch {
+	case name == "file" && opts.File != nil && opts.File.Sampling != nil:
+		return opts.File.Sampling
+	case name == "elasticsearch" && opts.Elastic != nil && opts.Elastic.Sampling != nil:
+		return opts.Elastic.Sampling
+	default:
+		return opts.Sampling
+	}
+}
@@ -0,0 +1,345 @@
+package corefactories
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultAlertWindow    = time.Minute
+	defaultAlertThreshold = 10
+
+	// A sustained burst re-alerts every 5 windows rather than every window
+	defaultAlertCooldownWindows = 5
+
+	alertPostTimeout  = 5 * time.Second
+	alertCloseTimeout = 10 * time.Second
+	alertTopMessages  = 5
+	alertMaxMessages  = 100 // Distinct messages tracked per window; the rest count as "other"
+)
+
+// defaultAlertTemplate renders a compact Slack message
+const defaultAlertTemplate = `:rotating_light: *{{.Service}}* ({{.Env}}): {{.Count}} {{.Level}}+ entries in the last {{.Window}}
+{{- range .TopMessages}}
+• {{.Count}}× {{.Message}}
+{{- end}}`
+
+// AlertData is what the alert template is rendered with
+type AlertData struct {
+	Service     string
+	Env         string
+	Level       string // MinLevel
+	Count       int    // Qualifying entries in the window so far
+	Window      time.Duration
+	Since       time.Time // Start of the window
+	TopMessages []AlertMessage
+}
+
+// AlertMessage is a message and how often it was logged in the window
+type AlertMessage struct {
+	Message string
+	Count   int
+}
+
+// AlertFactory creates cores that post a webhook summary when error volume spikes
+type AlertFactory struct{}
+
+func init() {
+	RegisterFactory(&AlertFactory{})
+}
+
+// Name returns the unique name of this factory
+func (af *AlertFactory) Name() string {
+	return "alert"
+}
+
+// Enabled determines if webhook alerts should be enabled based on options
+func (af *AlertFactory) Enabled(opts logger.Options) bool {
+	return opts.Alert != nil
+}
+
+// Build creates a counting core; it never writes entries anywhere itself
+func (af *AlertFactory) Build(encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, func() error, error) {
+	core, closer, err := af.BuildContext(encCfg, lvl, metrics, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return core, func() error { return closer(context.Background()) }, nil
+}
+
+// BuildContext is Build with a closer that waits for a pending alert until the
+// context is done
+func (af *AlertFactory) BuildContext(encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, CloseFunc, error) {
+	n, err := newAlertNotifier(opts.Alert, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if n.minLevel < lvl {
+		n.minLevel = lvl
+	}
+	return &alertCore{n: n}, n.CloseContext, nil
+}
+
+// alertNotifier counts qualifying entries in fixed windows and posts once per
+// window when the threshold is reached, then stays quiet for the cool-down
+type alertNotifier struct {
+	webhookURL string
+	minLevel   zapcore.Level
+	window     time.Duration
+	threshold  int
+	cooldown   time.Duration
+	tmpl       *template.Template
+	service    string
+	env        string
+	client     *http.Client
+	now        func() time.Time
+
+	diagnosticf func(format string, args ...any)
+
+	ctx    context.Context // Base of webhook requests; Close cancels it
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	windowStart time.Time
+	count       int
+	messages    map[string]int
+	alerted     bool      // Already posted for the current window
+	lastAlert   time.Time // Zero until the first post
+	closed      bool
+	inflight    sync.WaitGroup
+}
+
+func newAlertNotifier(config *logger.AlertSink, opts logger.Options) (*alertNotifier, error) {
+	if config.WebhookURL == "" {
+		return nil, fmt.Errorf("alert webhook URL is required")
+	}
+
+	minLevel := zapcore.ErrorLevel
+	if config.MinLevel != "" {
+		lvl, err := logger.ParseLevel(string(config.MinLevel))
+		if err != nil {
+			return nil, fmt.Errorf("invalid alert MinLevel: %w", err)
+		}
+		minLevel, _ = zapcore.ParseLevel(string(lvl))
+	}
+
+	text := config.Template
+	if text == "" {
+		text = defaultAlertTemplate
+	}
+	tmpl, err := template.New("alert").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse alert template: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &alertNotifier{
+		webhookURL:  config.WebhookURL,
+		minLevel:    minLevel,
+		window:      config.Window,
+		threshold:   config.Threshold,
+		cooldown:    config.Cooldown,
+		tmpl:        tmpl,
+		service:     opts.Service,
+		env:         string(opts.Env),
+		client:      &http.Client{Timeout: alertPostTimeout},
+		now:         opts.ClockOrDefault().Now,
+		diagnosticf: opts.Diagnosticf,
+		messages:    make(map[string]int),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	if n.window <= 0 {
+		n.window = defaultAlertWindow
+	}
+	if n.threshold <= 0 {
+		n.threshold = defaultAlertThreshold
+	}
+	if n.cooldown <= 0 {
+		n.cooldown = defaultAlertCooldownWindows * n.window
+	}
+	return n, nil
+}
+
+// observe counts one qualifying entry and fires the alert when it crosses the threshold
+func (n *alertNotifier) observe(msg string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return
+	}
+
+	now := n.now()
+	if n.windowStart.IsZero() || now.Sub(n.windowStart) >= n.window {
+		n.windowStart = now
+		n.count = 0
+		n.alerted = false
+		clear(n.messages)
+	}
+
+	n.count++
+	if _, ok := n.messages[msg]; ok || len(n.messages) < alertMaxMessages {
+		n.messages[msg]++
+	} else {
+		n.messages["(other messages)"]++
+	}
+
+	if n.alerted || n.count < n.threshold {
+		return
+	}
+	if !n.lastAlert.IsZero() && now.Sub(n.lastAlert) < n.cooldown {
+		return
+	}
+	n.alerted = true
+	n.lastAlert = now
+
+	text, err := n.render()
+	if err != nil {
+		n.diagnosticf("alert: failed to render template: %v", err)
+		return
+	}
+	n.inflight.Add(1)
+	go func() {
+		defer n.inflight.Done()
+		n.post(text)
+	}()
+}
+
+// render must be called with n.mu held
+func (n *alertNotifier) render() (string, error) {
+	top := make([]AlertMessage, 0, len(n.messages))
+	for msg, c := range n.messages {
+		top = append(top, AlertMessage{Message: msg, Count: c})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Message < top[j].Message
+	})
+	if len(top) > alertTopMessages {
+		top = top[:alertTopMessages]
+	}
+
+	var buf strings.Builder
+	err := n.tmpl.Execute(&buf, AlertData{
+		Service:     n.service,
+		Env:         n.env,
+		Level:       n.minLevel.String(),
+		Count:       n.count,
+		Window:      n.window,
+		Since:       n.windowStart,
+		TopMessages: top,
+	})
+	return buf.String(), err
+}
+
+// post delivers the alert, retrying once; failures only reach diagnostics
+func (n *alertNotifier) post(text string) {
+	body, _ := json.Marshal(map[string]string{"text": text})
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if err = n.send(body); err == nil || n.ctx.Err() != nil {
+			break
+		}
+	}
+	if err != nil {
+		n.diagnosticf("alert: failed to post to webhook: %v", err)
+	}
+}
+
+func (n *alertNotifier) send(body []byte) error {
+	ctx, cancel := context.WithTimeout(n.ctx, alertPostTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("%s: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Close stops counting and waits for an alert that is still being posted
+func (n *alertNotifier) Close() error {
+	return n.CloseContext(context.Background())
+}
+
+// CloseContext is Close bounded by ctx, or by alertCloseTimeout when ctx has no
+// deadline. A post still running then is aborted.
+func (n *alertNotifier) CloseContext(ctx context.Context) error {
+	n.mu.Lock()
+	n.closed = true
+	n.mu.Unlock()
+	defer n.cancel()
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, alertCloseTimeout)
+		defer cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		n.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to deliver pending alert: %w", ctx.Err())
+	}
+}
+
+// alertCore feeds qualifying entries to the notifier; fields are irrelevant to it
+type alertCore struct {
+	n *alertNotifier
+}
+
+func (c *alertCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= c.n.minLevel
+}
+
+func (c *alertCore) With([]zapcore.Field) zapcore.Core {
+	return c
+}
+
+func (c *alertCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *alertCore) Write(ent zapcore.Entry, _ []zapcore.Field) error {
+	c.n.observe(ent.Message)
+	return nil
+}
+
+func (c *alertCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,220 @@
+package corefactories
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	"go.uber.org/zap/zapcore"
+)
+
+// webhookRecorder is an httptest webhook that keeps the posted texts
+type webhookRecorder struct {
+	*httptest.Server
+	mu    sync.Mutex
+	texts []string
+	fail  int // Respond 500 to this many requests first
+	calls int
+}
+
+func newWebhookRecorder() *webhookRecorder {
+	rec := &webhookRecorder{}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.calls++
+		if rec.calls <= rec.fail {
+			http.Error(w, "unavailable", http.StatusInternalServerError)
+			return
+		}
+		rec.texts = append(rec.texts, body["text"])
+	}))
+	return rec
+}
+
+func (r *webhookRecorder) Texts() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.texts...)
+}
+
+func (r *webhookRecorder) Calls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
+
+func newTestAlertNotifier(t *testing.T, sink logger.AlertSink, diag *testutil.SafeBuffer) (*alertNotifier, *testutil.FakeClock) {
+	t.Helper()
+	opts := logger.DefaultProductionOptions()
+	opts.Service = "payments"
+	opts.Diagnostics = diag
+	// Advanced by hand so window boundaries are deterministic
+	clock := testutil.NewFakeClock(time.Date(2026, 3, 9, 14, 0, 0, 0, time.UTC))
+	opts.Clock = clock
+
+	n, err := newAlertNotifier(&sink, opts)
+	if err != nil {
+		t.Fatalf("Failed to create alert notifier: %v", err)
+	}
+	return n, clock
+}
+
+func writeErrors(t *testing.T, core zapcore.Core, msg string, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		if err := core.Write(zapcore.Entry{Level: zapcore.ErrorLevel, Message: msg}, nil); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+}
+
+func TestAlertOnePostPerBreachedWindow(t *testing.T) {
+	hook := newWebhookRecorder()
+	defer hook.Close()
+
+	n, clock := newTestAlertNotifier(t, logger.AlertSink{
+		WebhookURL: hook.URL,
+		Window:     time.Minute,
+		Threshold:  3,
+		Cooldown:   time.Minute,
+	}, &testutil.SafeBuffer{})
+	core := &alertCore{n: n}
+
+	// Window 1: breached, many more errors afterwards still give a single post
+	writeErrors(t, core, "db timeout", 10)
+	writeErrors(t, core, "cache miss storm", 2)
+
+	// Window 2: below threshold
+	clock.Advance(61 * time.Second)
+	writeErrors(t, core, "db timeout", 2)
+
+	// Window 3: breached again, past the cool-down
+	clock.Advance(61 * time.Second)
+	writeErrors(t, core, "upstream 502", 3)
+
+	if err := n.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	texts := hook.Texts()
+	if len(texts) != 2 {
+		t.Fatalf("Expected exactly 2 posts, got %d: %q", len(texts), texts)
+	}
+	// Posts are sent concurrently, so they may arrive in either order
+	first, second := texts[0], texts[1]
+	if strings.Contains(first, "upstream 502") {
+		first, second = second, first
+	}
+	if !strings.Contains(first, "payments") || !strings.Contains(first, "3 error+ entries in the last 1m0s") || !strings.Contains(first, "db timeout") {
+		t.Errorf("Unexpected first alert: %q", first)
+	}
+	if !strings.Contains(second, "upstream 502") || strings.Contains(second, "db timeout") {
+		t.Errorf("Expected second alert to only summarize its own window: %q", second)
+	}
+}
+
+func TestAlertCooldown(t *testing.T) {
+	hook := newWebhookRecorder()
+	defer hook.Close()
+
+	n, clock := newTestAlertNotifier(t, logger.AlertSink{
+		WebhookURL: hook.URL,
+		Window:     time.Minute,
+		Threshold:  2,
+		Cooldown:   5 * time.Minute,
+	}, &testutil.SafeBuffer{})
+	core := &alertCore{n: n}
+
+	writeErrors(t, core, "boom", 2)
+	for i := 0; i < 3; i++ { // Breaches inside the cool-down stay quiet
+		clock.Advance(61 * time.Second)
+		writeErrors(t, core, "boom", 2)
+	}
+	clock.Advance(2 * time.Minute)
+	writeErrors(t, core, "boom", 2)
+	n.Close()
+
+	if got := len(hook.Texts()); got != 2 {
+		t.Errorf("Expected 2 posts around the cool-down, got %d", got)
+	}
+}
+
+func TestAlertTopMessagesAndTemplate(t *testing.T) {
+	hook := newWebhookRecorder()
+	defer hook.Close()
+
+	n, _ := newTestAlertNotifier(t, logger.AlertSink{
+		WebhookURL: hook.URL,
+		Threshold:  6,
+		Template:   `{{.Service}} {{.Count}}{{range .TopMessages}} [{{.Count}} {{.Message}}]{{end}}`,
+	}, &testutil.SafeBuffer{})
+	core := &alertCore{n: n}
+
+	writeErrors(t, core, "b", 1)
+	writeErrors(t, core, "a", 2)
+	writeErrors(t, core, "c", 3)
+	n.Close()
+
+	texts := hook.Texts()
+	if len(texts) != 1 || texts[0] != "payments 6 [3 c] [2 a] [1 b]" {
+		t.Errorf("Unexpected alert text: %q", texts)
+	}
+}
+
+func TestAlertDeliveryRetriedOnce(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		fail      int
+		posted    int
+		diagnosed bool
+	}{
+		{"RecoversOnRetry", 1, 1, false},
+		{"GivesUpAfterRetry", 5, 0, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			hook := newWebhookRecorder()
+			defer hook.Close()
+			hook.fail = tc.fail
+
+			diag := &testutil.SafeBuffer{}
+			n, _ := newTestAlertNotifier(t, logger.AlertSink{WebhookURL: hook.URL, Threshold: 1}, diag)
+			writeErrors(t, &alertCore{n: n}, "boom", 1)
+			n.Close()
+
+			if got := len(hook.Texts()); got != tc.posted {
+				t.Errorf("Expected %d delivered posts, got %d", tc.posted, got)
+			}
+			if calls := hook.Calls(); calls > 2 {
+				t.Errorf("Expected at most 2 attempts, got %d", calls)
+			}
+			if got := strings.Contains(diag.String(), "failed to post to webhook"); got != tc.diagnosed {
+				t.Errorf("Expected diagnostic=%v, got %q", tc.diagnosed, diag.String())
+			}
+		})
+	}
+}
+
+func TestAlertMinLevel(t *testing.T) {
+	n, _ := newTestAlertNotifier(t, logger.AlertSink{WebhookURL: "http://127.0.0.1:0", MinLevel: logger.WarnLevel}, &testutil.SafeBuffer{})
+	core := &alertCore{n: n}
+	if core.Enabled(zapcore.InfoLevel) || !core.Enabled(zapcore.WarnLevel) {
+		t.Error("Expected only warn and above to count")
+	}
+
+	if _, err := newAlertNotifier(&logger.AlertSink{}, logger.Options{}); err == nil {
+		t.Error("Expected error without a webhook URL")
+	}
+	if _, err := newAlertNotifier(&logger.AlertSink{WebhookURL: "x", Template: "{{"}, logger.Options{}); err == nil {
+		t.Error("Expected error for an invalid template")
+	}
+}
@@ -0,0 +1,208 @@
+package corefactories
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const defaultAuditTimeout = 10 * time.Second
+
+// ErrAuditClosed is returned for audit events written after the logger was closed
+var ErrAuditClosed = errors.New("audit writer is closed")
+
+// AuditOutput stores one encoded audit event, blocking until it is stored or ctx is done
+type AuditOutput interface {
+	Name() string
+	Write(ctx context.Context, doc []byte, t time.Time) error
+	Close() error
+}
+
+// ElasticAuditFunc creates the output of AuditSink.ElasticIndex
+type ElasticAuditFunc func(pattern string, opts logger.Options) (AuditOutput, error)
+
+var elasticAudit atomic.Pointer[ElasticAuditFunc]
+
+// RegisterElasticAudit sets how audit events reach AuditSink.ElasticIndex. The
+// elasticx package registers it along with its factory.
+func RegisterElasticAudit(fn ElasticAuditFunc) {
+	elasticAudit.Store(&fn)
+}
+
+// auditOutputs is shared by an AuditWriter and the writers derived from it
+type auditOutputs struct {
+	mu      sync.RWMutex // Held for reading by in-flight writes, so Close waits for them
+	closed  bool
+	list    []AuditOutput
+	timeout time.Duration
+	metrics *logger.Metrics
+}
+
+// AuditWriter writes audit events synchronously to the AuditSink outputs. It is
+// used outside the zap core tree, so no sampler or level ever applies to it.
+type AuditWriter struct {
+	enc   zapcore.Encoder
+	outs  *auditOutputs
+	clock logger.Clock
+}
+
+// NewAuditWriter creates the outputs configured in opts.Audit
+func NewAuditWriter(encCfg zapcore.EncoderConfig, metrics *logger.Metrics, opts logger.Options) (*AuditWriter, error) {
+	config := opts.Audit
+	if config.File == nil && config.ElasticIndex == "" {
+		return nil, fmt.Errorf("audit sink needs a File or an ElasticIndex")
+	}
+
+	outs := &auditOutputs{timeout: config.Timeout, metrics: metrics}
+	if outs.timeout <= 0 {
+		outs.timeout = defaultAuditTimeout
+	}
+	if config.File != nil {
+		if config.File.Path == "" {
+			return nil, fmt.Errorf("audit file path is required")
+		}
+		outs.list = append(outs.list, newAuditFile(config.File))
+	}
+	if config.ElasticIndex != "" {
+		es, err := newAuditElastic(config.ElasticIndex, opts)
+		if err != nil {
+			for _, out := range outs.list {
+				out.Close()
+			}
+			return nil, err
+		}
+		outs.list = append(outs.list, es)
+	}
+
+	encCfg.LineEnding = ""
+	enc := zapcore.NewJSONEncoder(encCfg)
+	enc.AddBool("audit", true)
+	enc.AddString("service", opts.Service)
+	if opts.Env != "" {
+		enc.AddString("env", string(opts.Env))
+	}
+	return &AuditWriter{enc: enc, outs: outs, clock: opts.ClockOrDefault()}, nil
+}
+
+// With returns a writer that adds fields to every event
+func (w *AuditWriter) With(fields []zapcore.Field) *AuditWriter {
+	if w == nil || len(fields) == 0 {
+		return w
+	}
+	enc := w.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(enc)
+	}
+	return &AuditWriter{enc: enc, outs: w.outs, clock: w.clock}
+}
+
+// Write stores one audit event in every output. A context without a deadline is
+// bounded by AuditSink.Timeout.
+func (w *AuditWriter) Write(ctx context.Context, msg string, fields []zapcore.Field) error {
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: w.clock.Now(), Message: msg}
+	buf, err := w.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %w", err)
+	}
+	doc := bytes.TrimRight(buf.Bytes(), "\n")
+	doc = append(make([]byte, 0, len(doc)+1), doc...)
+	buf.Free()
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.outs.timeout)
+		defer cancel()
+	}
+
+	w.outs.mu.RLock()
+	defer w.outs.mu.RUnlock()
+	if w.outs.closed {
+		return ErrAuditClosed
+	}
+
+	var errs []error
+	for _, out := range w.outs.list {
+		if err := out.Write(ctx, doc, ent.Time); err != nil {
+			w.outs.metrics.RecordAuditFailure(out.Name())
+			errs = append(errs, fmt.Errorf("failed to write audit event to %s: %w", out.Name(), err))
+			continue
+		}
+		w.outs.metrics.RecordAuditEvent(out.Name())
+	}
+	return errors.Join(errs...)
+}
+
+// Close waits for in-flight events and closes the outputs
+func (w *AuditWriter) Close() error {
+	w.outs.mu.Lock()
+	defer w.outs.mu.Unlock()
+	if w.outs.closed {
+		return nil
+	}
+	w.outs.closed = true
+
+	var errs []error
+	for _, out := range w.outs.list {
+		if err := out.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// auditFile appends events to a rotated file
+type auditFile struct {
+	lj  *lumberjack.Logger
+	sem chan struct{} // Serializes writes; a channel so waiting honors ctx
+}
+
+func newAuditFile(config *logger.FileSink) *auditFile {
+	return &auditFile{
+		lj: &lumberjack.Logger{
+			Filename:   config.Path,
+			MaxSize:    config.MaxSizeMB,
+			MaxBackups: config.MaxBackups,
+			MaxAge:     config.MaxAgeDays,
+			Compress:   config.Compress,
+		},
+		sem: make(chan struct{}, 1),
+	}
+}
+
+func (f *auditFile) Name() string {
+	return "file"
+}
+
+func (f *auditFile) Write(ctx context.Context, doc []byte, _ time.Time) error {
+	select {
+	case f.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-f.sem }()
+
+	// doc has spare capacity for the newline, so the bytes other outputs read are untouched
+	_, err := f.lj.Write(append(doc, '\n'))
+	return err
+}
+
+func (f *auditFile) Close() error {
+	return f.lj.Close()
+}
+
+// newAuditElastic creates the output registered by the elasticx package
+func newAuditElastic(pattern string, opts logger.Options) (AuditOutput, error) {
+	fn := elasticAudit.Load()
+	if fn == nil {
+		return nil, fmt.Errorf("audit ElasticIndex requires importing %s", ElasticPackage)
+	}
+	return (*fn)(pattern, opts)
+}
@@ -0,0 +1,21 @@
+package corefactories
+
+import (
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap/zapcore"
+)
+
+// bufferWrites wraps ws in a BufferedWriteSyncer when opts.Buffer is set. The
+// returned stop flushes the buffer and ends its flush goroutine; it is nil
+// when writes are unbuffered.
+func bufferWrites(ws zapcore.WriteSyncer, opts logger.Options) (zapcore.WriteSyncer, func() error) {
+	if opts.Buffer == nil {
+		return ws, nil
+	}
+	buffered := &zapcore.BufferedWriteSyncer{
+		WS:            ws,
+		Size:          opts.Buffer.Size,
+		FlushInterval: opts.Buffer.FlushInterval,
+	}
+	return buffered, buffered.Stop
+}
@@ -1,12 +1,20 @@
 package corefactories_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 
 	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
 	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx/corefactories"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx/elasticx"
 	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestConsoleFactoryEnabled(t *testing.T) {
@@ -192,42 +200,6 @@ func TestFileFactoryBuild(t *testing.T) {
 	}
 }
 
-func TestElasticFactoryEnabled(t *testing.T) {
-	factory := &corefactories.ElasticFactory{}
-
-	testCases := []struct {
-		name     string
-		opts     logger.Options
-		expected bool
-	}{
-		{
-			name: "No elastic config",
-			opts: logger.Options{
-				Elastic: nil,
-			},
-			expected: false,
-		},
-		{
-			name: "With elastic config",
-			opts: logger.Options{
-				Elastic: &logger.ElasticSink{
-					Addresses: []string{"http://localhost:9200"},
-				},
-			},
-			expected: true,
-		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			enabled := factory.Enabled(tc.opts)
-			if enabled != tc.expected {
-				t.Errorf("Expected %v, got %v", tc.expected, enabled)
-			}
-		})
-	}
-}
-
 func TestFactoryRegistration(t *testing.T) {
 	// Clear factories first
 	corefactories.ClearFactories()
@@ -270,7 +242,7 @@ func TestDefaultRegistry(t *testing.T) {
 	corefactories.ClearFactories()
 	corefactories.RegisterFactory(&corefactories.ConsoleFactory{})
 	corefactories.RegisterFactory(&corefactories.FileFactory{})
-	corefactories.RegisterFactory(&corefactories.ElasticFactory{})
+	corefactories.RegisterFactory(&elasticx.Factory{})
 
 	registry := corefactories.DefaultRegistry()
 	factories := registry.All()
@@ -298,7 +270,7 @@ func TestFactoryNames(t *testing.T) {
 	factories := []corefactories.CoreFactory{
 		&corefactories.ConsoleFactory{},
 		&corefactories.FileFactory{},
-		&corefactories.ElasticFactory{},
+		&elasticx.Factory{},
 	}
 
 	names := make(map[string]bool)
@@ -334,7 +306,7 @@ func TestConcurrentRegistration(t *testing.T) {
 	}()
 
 	go func() {
-		corefactories.RegisterFactory(&corefactories.ElasticFactory{})
+		corefactories.RegisterFactory(&elasticx.Factory{})
 		done <- true
 	}()
 
@@ -368,3 +340,185 @@ func TestFactoriesCopy(t *testing.T) {
 		t.Error("Factory copies should have same content")
 	}
 }
+
+// kafkaConfig is the extension configuration of kafkaFactory
+type kafkaConfig struct {
+	Brokers []string
+	Topic   string
+}
+
+// kafkaFactory is a custom factory configured through Options.Extensions
+type kafkaFactory struct {
+	built *kafkaConfig
+	logs  *observer.ObservedLogs
+}
+
+func (f *kafkaFactory) Name() string {
+	return "kafka"
+}
+
+func (f *kafkaFactory) Enabled(opts logger.Options) bool {
+	_, ok := logger.GetExtension[kafkaConfig](opts, f.Name())
+	return ok
+}
+
+func (f *kafkaFactory) Build(encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, func() error, error) {
+	cfg, _ := logger.GetExtension[kafkaConfig](opts, f.Name())
+	if len(cfg.Brokers) == 0 {
+		return nil, nil, errors.New("kafka brokers are required")
+	}
+	f.built = &cfg
+	core, logs := observer.New(lvl)
+	f.logs = logs
+	return core, nil, nil
+}
+
+func TestExtensionConfiguredFactory(t *testing.T) {
+	kafka := &kafkaFactory{}
+	zapx.UseFactoryRegistry(staticRegistry{kafka})
+	defer zapx.UseFactoryRegistry(corefactories.DefaultRegistry())
+
+	if kafka.Enabled(logger.DefaultProductionOptions()) {
+		t.Error("Expected the factory to be disabled without its extension")
+	}
+
+	// The wrong type is treated as absent
+	opts := logger.DefaultProductionOptions()
+	logger.WithExtension("kafka", "brokers=localhost:9092")(&opts)
+	if kafka.Enabled(opts) {
+		t.Error("Expected an extension of the wrong type to be ignored")
+	}
+
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithExtension("kafka", kafkaConfig{Brokers: []string{"localhost:9092"}, Topic: "logs"}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+	log.Info("Through the custom sink")
+
+	if kafka.built == nil || kafka.built.Topic != "logs" {
+		t.Fatalf("Expected Build to receive the extension config, got %+v", kafka.built)
+	}
+	if kafka.logs.Len() != 1 {
+		t.Errorf("Expected the custom core to receive the entry, got %d", kafka.logs.Len())
+	}
+
+	_, err = logger.NewProduction(logger.WithExtension("kafka", kafkaConfig{Topic: "logs"}))
+	if err == nil || !strings.Contains(err.Error(), "kafka brokers are required") {
+		t.Errorf("Expected the custom factory's build error, got %v", err)
+	}
+	// Without the extension nothing in the injected registry is enabled
+	if _, err := logger.NewProduction(); !errors.Is(err, logger.ErrNoSinks) {
+		t.Errorf("Expected ErrNoSinks, got %v", err)
+	}
+}
+
+func TestWithExtensionCopiesMap(t *testing.T) {
+	base := logger.Options{}
+	logger.WithExtension("a", 1)(&base)
+	derived := base
+	logger.WithExtension("b", 2)(&derived)
+
+	if _, ok := logger.GetExtension[int](base, "b"); ok {
+		t.Error("WithExtension must not modify a map shared with another Options value")
+	}
+	if v, ok := logger.GetExtension[int](derived, "a"); !ok || v != 1 {
+		t.Error("Expected existing extensions to be kept")
+	}
+}
+
+// staticRegistry serves a fixed set of factories
+type staticRegistry []corefactories.CoreFactory
+
+func (r staticRegistry) All() []corefactories.CoreFactory {
+	return r
+}
+
+// observerFactory builds cores that record into one shared observer
+type observerFactory struct {
+	name string
+	core zapcore.Core
+	logs *observer.ObservedLogs
+}
+
+func newObserverFactory(name string) *observerFactory {
+	core, logs := observer.New(zapcore.DebugLevel - 1) // Follows Options.Level
+	return &observerFactory{name: name, core: core, logs: logs}
+}
+
+func (f *observerFactory) Name() string                { return f.name }
+func (f *observerFactory) Enabled(logger.Options) bool { return true }
+
+func (f *observerFactory) Build(zapcore.EncoderConfig, zapcore.Level, *logger.Metrics, logger.Options) (zapcore.Core, func() error, error) {
+	return f.core, nil, nil
+}
+
+func TestParallelLoggersWithSeparateRegistries(t *testing.T) {
+	built := newObserverFactory("observed")
+	buildReg := corefactories.NewRegistry()
+	buildReg.Register(built)
+	buildReg.Freeze()
+	otherReg := corefactories.NewRegistry()
+
+	const loggers = 20
+	var wg sync.WaitGroup
+	wg.Add(loggers + 1)
+	go func() {
+		// Changes to another registry don't affect the loggers being built
+		defer wg.Done()
+		for i := 0; i < loggers; i++ {
+			name := fmt.Sprintf("sink%d", i)
+			otherReg.Register(newObserverFactory(name))
+			otherReg.Replace(name, newObserverFactory(name))
+		}
+	}()
+	for i := 0; i < loggers; i++ {
+		go func() {
+			defer wg.Done()
+			log, err := logger.NewProduction(zapx.WithFactoryRegistry(buildReg), logger.WithConsoleDisabled())
+			if err != nil {
+				t.Errorf("Failed to create logger: %v", err)
+				return
+			}
+			log.Info("parallel")
+			if err := log.Close(context.Background()); err != nil {
+				t.Errorf("Close failed: %v", err)
+			}
+			if got := logger.Report(log).Sinks; len(got) != 1 || got[0] != "observed" {
+				t.Errorf("Expected only the registry's sink, got %v", got)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := built.logs.FilterMessage("parallel").Len(); got != loggers {
+		t.Errorf("Expected %d entries, got %d", loggers, got)
+	}
+	if got := len(otherReg.All()); got != loggers {
+		t.Errorf("Expected %d factories in the other registry, got %d", loggers, got)
+	}
+}
+
+func TestUnlinkedSinkFactory(t *testing.T) {
+	// A registry without the Elasticsearch factory, as when elasticx isn't imported
+	reg := corefactories.NewRegistry()
+	reg.Register(&corefactories.ConsoleFactory{})
+	elastic := logger.WithElastic(logger.ElasticSink{Addresses: []string{"http://localhost:9200"}})
+
+	_, err := logger.NewProduction(zapx.WithFactoryRegistry(reg), elastic)
+	if err == nil || !strings.Contains(err.Error(), corefactories.ElasticPackage) {
+		t.Fatalf("Expected an error naming %s, got %v", corefactories.ElasticPackage, err)
+	}
+
+	log, err := logger.NewProduction(zapx.WithFactoryRegistry(reg), elastic, logger.WithSinkErrorPolicy(logger.SinkErrorSkip))
+	if err != nil {
+		t.Fatalf("Expected the sink to be skipped, got %v", err)
+	}
+	defer log.Close(context.Background())
+	if skipped := logger.Report(log).Skipped; len(skipped) != 1 || skipped[0].Sink != "elasticsearch" {
+		t.Errorf("Expected the elasticsearch sink reported as skipped, got %v", skipped)
+	}
+}
@@ -34,19 +34,39 @@ func (cf *ConsoleFactory) Build(encCfg zapcore.EncoderConfig, lvl zapcore.Level,
 		metrics: metrics,
 	}
 
-	var encoder zapcore.Encoder
-	if opts.Env == logger.EnvDev {
-		// Development: use console encoder for human-readable output
-		encoder = zapcore.NewConsoleEncoder(encCfg)
-	} else {
-		// Production: use JSON encoder for structured output
-		encoder = zapcore.NewJSONEncoder(encCfg)
+	encoder := consoleEncoder(encCfg, opts)
+
+	// os.Stdout is safe for concurrent use and each entry is a single Write, so
+	// the core needs no Lock; a write buffer has its own
+	if opts.ConsoleLines > 0 {
+		lines := newLineWriter(writer, opts.ConsoleLines, metrics)
+		return zapcore.NewCore(encoder, lines, lvl), lines.Close, nil
 	}
+	ws, stop := bufferWrites(writer, opts)
+	core := zapcore.NewCore(encoder, ws, lvl)
 
-	core := zapcore.NewCore(encoder, zapcore.Lock(zapcore.AddSync(writer)), lvl)
+	// Console only needs a closer to flush its buffer
+	return core, stop, nil
+}
 
-	// Console doesn't need a closer
-	return core, nil, nil
+// consoleEncoder picks the console output format for opts.Env, unless
+// opts.Encoder.Console overrides it
+func consoleEncoder(encCfg zapcore.EncoderConfig, opts logger.Options) zapcore.Encoder {
+	text := opts.TextConsole()
+	if text {
+		encCfg.EncodeDuration = consoleDurationEncoder(opts)
+	}
+	switch {
+	case text && opts.Env.IsDev() && opts.PrettyDev:
+		// Development with WithPrettyDev: one field per line under the message
+		return newPrettyEncoder(encCfg)
+	case text:
+		// Development by default: use console encoder for human-readable output
+		return zapcore.NewConsoleEncoder(encCfg)
+	default:
+		// Production by default: use JSON encoder for structured output
+		return zapcore.NewJSONEncoder(encCfg)
+	}
 }
 
 // consoleWriter writes to stdout with optional metrics support
@@ -56,8 +76,13 @@ type consoleWriter struct {
 
 func (cw *consoleWriter) Write(p []byte) (int, error) {
 	n, err := os.Stdout.Write(p)
-	if err != nil && cw.metrics != nil {
+	if err != nil {
 		cw.metrics.RecordLogDropped("console", "write_error")
 	}
 	return n, err
 }
+
+// Sync is a no-op: stdout is unbuffered, and often a pipe that can't be synced
+func (cw *consoleWriter) Sync() error {
+	return nil
+}
@@ -0,0 +1,121 @@
+package corefactories
+
+import (
+	"io"
+	"sync"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+)
+
+// maxLineBatch caps the bytes lineWriter hands to one Write
+const maxLineBatch = 64 << 10
+
+// lineWriter queues encoded lines for a single goroutine that writes them to
+// out, batching the lines waiting together into one Write. Each zap entry is
+// one Write of a whole line, so lines never tear or interleave.
+type lineWriter struct {
+	out     io.Writer
+	metrics *logger.Metrics
+	queue   chan lineJob
+	stopped chan struct{}
+
+	mu     sync.RWMutex // Guards closed against sends on the closed queue
+	closed bool
+}
+
+// lineJob is a line to write, or a flush marker when done is set
+type lineJob struct {
+	line []byte
+	done chan struct{}
+}
+
+func newLineWriter(out io.Writer, size int, metrics *logger.Metrics) *lineWriter {
+	w := &lineWriter{
+		out:     out,
+		metrics: metrics,
+		queue:   make(chan lineJob, size),
+		stopped: make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *lineWriter) run() {
+	defer close(w.stopped)
+	var batch []byte
+	for job := range w.queue {
+		var done []chan struct{}
+		for {
+			if job.done != nil {
+				done = append(done, job.done)
+			} else {
+				batch = append(batch, job.line...)
+			}
+			if len(batch) >= maxLineBatch || !w.next(&job) {
+				break
+			}
+		}
+		if len(batch) > 0 {
+			_, _ = w.out.Write(batch) // out records its own failures
+			batch = batch[:0]
+		}
+		for _, d := range done {
+			close(d)
+		}
+	}
+}
+
+// next takes the following job if one is already queued
+func (w *lineWriter) next(job *lineJob) bool {
+	select {
+	case j, ok := <-w.queue:
+		if ok {
+			*job = j
+		}
+		return ok
+	default:
+		return false
+	}
+}
+
+// Write queues a copy of p, as zap reuses its buffer. A full queue drops the
+// line; after Close lines are written directly.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return w.out.Write(p)
+	}
+	select {
+	case w.queue <- lineJob{line: append([]byte(nil), p...)}:
+	default:
+		w.metrics.RecordLogDropped("console", "buffer_full")
+	}
+	return len(p), nil
+}
+
+// Sync waits until the lines queued so far are written
+func (w *lineWriter) Sync() error {
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return nil
+	}
+	done := make(chan struct{})
+	w.queue <- lineJob{done: done}
+	w.mu.RUnlock()
+	<-done
+	return nil
+}
+
+// Close writes the queued lines and ends the goroutine
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+	<-w.stopped
+	return nil
+}
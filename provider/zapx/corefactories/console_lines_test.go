@@ -0,0 +1,163 @@
+package corefactories
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// recordingWriter keeps every Write separately; gate, when set, holds writes back
+type recordingWriter struct {
+	mu     sync.Mutex
+	writes [][]byte
+	gate   chan struct{}
+}
+
+func (r *recordingWriter) Write(p []byte) (int, error) {
+	if r.gate != nil {
+		<-r.gate
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writes = append(r.writes, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+// lines splits the writes into lines, failing t if a write ends mid-line
+func (r *recordingWriter) lines(t *testing.T) []string {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var lines []string
+	for _, w := range r.writes {
+		if len(w) == 0 || w[len(w)-1] != '\n' {
+			t.Fatalf("Expected writes of whole lines, got %q", w)
+		}
+		lines = append(lines, strings.Split(strings.TrimSuffix(string(w), "\n"), "\n")...)
+	}
+	return lines
+}
+
+func TestLineWriterKeepsLinesWhole(t *testing.T) {
+	out := &recordingWriter{}
+	w := newLineWriter(out, 4096, logger.GetMetrics())
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), w, zapcore.InfoLevel)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				ent := zapcore.Entry{Level: zapcore.InfoLevel, Message: fmt.Sprintf("g%d-%d", g, i)}
+				_ = core.Write(ent, []zapcore.Field{zap.String("pad", strings.Repeat("x", 200))})
+			}
+		}(g)
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	lines := out.lines(t)
+	if len(lines) != 1600 {
+		t.Fatalf("Expected 1600 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "{") || !strings.HasSuffix(line, "}") {
+			t.Fatalf("Expected whole JSON lines, got %q", line)
+		}
+	}
+	if len(out.writes) >= len(lines) {
+		t.Errorf("Expected queued lines batched into fewer writes, got %d for %d lines", len(out.writes), len(lines))
+	}
+}
+
+func TestLineWriterDropsWhenFull(t *testing.T) {
+	out := &recordingWriter{gate: make(chan struct{})}
+	w := newLineWriter(out, 2, logger.GetMetrics())
+	dropped := logger.GetMetrics().LogsDropped.WithLabelValues("console", "buffer_full")
+	before := promtestutil.ToFloat64(dropped)
+
+	// The first line is taken and blocks in Write, two more fill the queue
+	_, _ = w.Write([]byte("first\n"))
+	for len(w.queue) != 0 {
+		runtime.Gosched()
+	}
+	for i := 0; i < 5; i++ {
+		_, _ = w.Write([]byte(fmt.Sprintf("line %d\n", i)))
+	}
+	if got := promtestutil.ToFloat64(dropped) - before; got != 3 {
+		t.Errorf("Expected 3 lines dropped as buffer_full, got %v", got)
+	}
+
+	close(out.gate)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if got := strings.Join(out.lines(t), ","); got != "first,line 0,line 1" {
+		t.Errorf("Expected Close to write the queued lines, got %q", got)
+	}
+
+	// Lines after Close are written directly
+	_, _ = w.Write([]byte("late\n"))
+	if lines := out.lines(t); lines[len(lines)-1] != "late" {
+		t.Errorf("Expected a late line written directly, got %q", lines)
+	}
+}
+
+func TestLineWriterSync(t *testing.T) {
+	out := &recordingWriter{}
+	w := newLineWriter(out, 16, nil)
+	defer w.Close()
+
+	_, _ = w.Write([]byte("before sync\n"))
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if lines := out.lines(t); len(lines) != 1 || lines[0] != "before sync" {
+		t.Errorf("Expected Sync to wait for the queued line, got %q", lines)
+	}
+}
+
+// BenchmarkConsoleWriter compares the locked writer with the line-buffered one
+// at 16 concurrent loggers writing to a file descriptor
+func BenchmarkConsoleWriter(b *testing.B) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatalf("Failed to open %s: %v", os.DevNull, err)
+	}
+	defer devNull.Close()
+	encCfg := zap.NewProductionEncoderConfig()
+	fields := []zapcore.Field{zap.String("key1", "value1"), zap.Int("key2", 42), zap.Bool("key3", true)}
+
+	run := func(b *testing.B, ws zapcore.WriteSyncer) {
+		core := zapcore.NewCore(zapcore.NewJSONEncoder(encCfg), ws, zapcore.InfoLevel)
+		ent := zapcore.Entry{Level: zapcore.InfoLevel, Message: "Benchmark message"}
+		b.SetParallelism((16 + runtime.GOMAXPROCS(0) - 1) / runtime.GOMAXPROCS(0))
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_ = core.Write(ent, fields)
+			}
+		})
+	}
+
+	b.Run("Locked", func(b *testing.B) {
+		run(b, zapcore.Lock(zapcore.AddSync(devNull)))
+	})
+	b.Run("LineBuffered", func(b *testing.B) {
+		w := newLineWriter(devNull, 4096, nil)
+		defer w.Close()
+		run(b, w)
+	})
+}
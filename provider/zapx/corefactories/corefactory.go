@@ -1,7 +1,11 @@
 package corefactories
 
 import (
+	"context"
+	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 
 	logger "github.com/HoangAnhNguyen269/loggerkit"
 	"go.uber.org/zap/zapcore"
@@ -15,32 +19,251 @@ type CoreFactory interface {
 	// Enabled determines if this factory should create a core based on the options
 	Enabled(opts logger.Options) bool
 
-	// Build creates a zapcore.Core and returns it along with an optional closer function
+	// Build creates a zapcore.Core and returns it along with an optional closer function.
+	// lvl is below DebugLevel: a core enabled at lvl follows Options.Level, which the
+	// logger applies on top so it can change at runtime and per logger name.
 	Build(encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, func() error, error)
 }
 
-// Global registry for CoreFactory instances
-var (
-	factoriesMu sync.RWMutex
-	factories   []CoreFactory
+// Import paths of the factories that register themselves from their own package,
+// so their dependencies are only linked into binaries that use the sink
+const (
+	ElasticPackage = "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/elasticx"
+	OTLPPackage    = "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/otlpx"
+	SQLitePackage  = "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/sqlitex"
 )
 
-// RegisterFactory registers a CoreFactory in the global registry
+// CloseFunc releases a sink's resources. It should give up and return once ctx
+// is done, even if buffered entries could not be flushed.
+type CloseFunc func(ctx context.Context) error
+
+// ContextFactory is implemented by factories whose closer honors the context
+// passed to Logger.Close. BuildCore prefers BuildContext over Build.
+type ContextFactory interface {
+	CoreFactory
+
+	// BuildContext is Build with a context-aware closer
+	BuildContext(encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, CloseFunc, error)
+}
+
+// Flusher is implemented by cores that buffer entries beyond what Sync writes
+// out, such as a bulk indexer. FlushSink prefers Flush over Sync.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// HealthReporter is implemented by cores that know whether their sink is
+// currently delivering entries
+type HealthReporter interface {
+	Healthy() bool
+}
+
+// healthCore adds a HealthReporter to a core built by zapcore.NewCore
+type healthCore struct {
+	zapcore.Core
+	healthy func() bool
+}
+
+func (c *healthCore) Healthy() bool {
+	return c.healthy()
+}
+
+func (c *healthCore) With(fields []zapcore.Field) zapcore.Core {
+	return &healthCore{Core: c.Core.With(fields), healthy: c.healthy}
+}
+
+// BuildCore builds a core from f. The closer of a factory that only implements
+// Build ignores the context; callers bound the wait for it instead.
+func BuildCore(f CoreFactory, encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, CloseFunc, error) {
+	if cf, ok := f.(ContextFactory); ok {
+		return cf.BuildContext(encCfg, lvl, metrics, opts)
+	}
+	core, closer, err := f.Build(encCfg, lvl, metrics, opts)
+	if err != nil || closer == nil {
+		return core, nil, err
+	}
+	return core, func(context.Context) error { return closer() }, nil
+}
+
+// DefaultPriority is the priority of factories registered with RegisterFactory
+const DefaultPriority = 0
+
+// registeredFactory is a factory with its build priority
+type registeredFactory struct {
+	factory  CoreFactory
+	priority int
+}
+
+// FactoryRegistry is a set of CoreFactory instances, kept sorted by priority
+// then name. All doesn't lock: each change publishes a new copy of the set, so
+// loggers built concurrently don't contend. After Freeze it can't change.
+type FactoryRegistry struct {
+	mu        sync.Mutex // Serializes changes
+	frozen    bool
+	factories atomic.Pointer[[]registeredFactory]
+}
+
+// NewRegistry returns an empty registry. Use it with zapx.WithFactoryRegistry
+// to build loggers from other factories than the global registry's, such as
+// in parallel tests.
+func NewRegistry() *FactoryRegistry {
+	r := &FactoryRegistry{}
+	r.factories.Store(&[]registeredFactory{})
+	return r
+}
+
+// defaultRegistry is the global registry the built-in factories register in
+var defaultRegistry = NewRegistry()
+
+// Register adds f with DefaultPriority
+func (r *FactoryRegistry) Register(f CoreFactory) {
+	r.RegisterWithPriority(f, DefaultPriority)
+}
+
+// RegisterWithPriority adds f. Factories with a lower priority are built
+// first, ties are broken by name. It panics if a factory with the same name is
+// already registered or the registry is frozen; use Replace to substitute one.
+func (r *FactoryRegistry) RegisterWithPriority(f CoreFactory, priority int) {
+	r.update(func(fs []registeredFactory) []registeredFactory {
+		if indexOfFactory(fs, f.Name()) >= 0 {
+			panic(fmt.Sprintf("corefactories: factory %q already registered", f.Name()))
+		}
+		return insertFactory(fs, registeredFactory{factory: f, priority: priority})
+	})
+}
+
+// Replace substitutes f for the factory registered as name, keeping its
+// priority, and reports whether one was replaced. If none was, f is added with
+// DefaultPriority. It panics if the registry is frozen.
+func (r *FactoryRegistry) Replace(name string, f CoreFactory) bool {
+	replaced := false
+	r.update(func(fs []registeredFactory) []registeredFactory {
+		priority := DefaultPriority
+		if i := indexOfFactory(fs, name); i >= 0 {
+			priority = fs[i].priority
+			fs = append(fs[:i], fs[i+1:]...)
+			replaced = true
+		}
+		if j := indexOfFactory(fs, f.Name()); j >= 0 {
+			fs = append(fs[:j], fs[j+1:]...)
+		}
+		return insertFactory(fs, registeredFactory{factory: f, priority: priority})
+	})
+	return replaced
+}
+
+// Unregister removes the factory registered as name and reports whether there
+// was one. It panics if the registry is frozen.
+func (r *FactoryRegistry) Unregister(name string) bool {
+	removed := false
+	r.update(func(fs []registeredFactory) []registeredFactory {
+		if i := indexOfFactory(fs, name); i >= 0 {
+			fs = append(fs[:i], fs[i+1:]...)
+			removed = true
+		}
+		return fs
+	})
+	return removed
+}
+
+// Clear removes every factory. It panics if the registry is frozen.
+func (r *FactoryRegistry) Clear() {
+	r.update(func([]registeredFactory) []registeredFactory { return nil })
+}
+
+// Freeze makes the registry immutable: later changes panic. Call it once every
+// factory is registered, so none can be swapped while loggers are built.
+func (r *FactoryRegistry) Freeze() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.frozen = true
+}
+
+// Frozen reports whether Freeze was called
+func (r *FactoryRegistry) Frozen() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.frozen
+}
+
+// All returns a copy of the registered factories in build order
+func (r *FactoryRegistry) All() []CoreFactory {
+	fs := *r.factories.Load()
+	result := make([]CoreFactory, len(fs))
+	for i := range fs {
+		result[i] = fs[i].factory
+	}
+	return result
+}
+
+// update publishes fn's changes to a copy of the factories
+func (r *FactoryRegistry) update(fn func([]registeredFactory) []registeredFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.frozen {
+		panic("corefactories: registry is frozen")
+	}
+	fs := fn(append([]registeredFactory(nil), *r.factories.Load()...))
+	r.factories.Store(&fs)
+}
+
+// RegisterFactory registers a CoreFactory in the global registry with DefaultPriority
 func RegisterFactory(f CoreFactory) {
-	factoriesMu.Lock()
-	defer factoriesMu.Unlock()
-	factories = append(factories, f)
+	defaultRegistry.Register(f)
 }
 
-// Factories returns a copy of all registered factories (read-only)
+// RegisterFactoryWithPriority registers a CoreFactory in the global registry.
+// Factories with a lower priority are built first, ties are broken by name. It
+// panics if a factory with the same name is already registered; use
+// ReplaceFactory to substitute one.
+func RegisterFactoryWithPriority(f CoreFactory, priority int) {
+	defaultRegistry.RegisterWithPriority(f, priority)
+}
+
+// ReplaceFactory substitutes f for the factory registered as name, keeping its
+// priority, and reports whether one was replaced. If none was, f is registered
+// with DefaultPriority.
+func ReplaceFactory(name string, f CoreFactory) bool {
+	return defaultRegistry.Replace(name, f)
+}
+
+// UnregisterFactory removes the factory registered as name and reports whether
+// there was one
+func UnregisterFactory(name string) bool {
+	return defaultRegistry.Unregister(name)
+}
+
+// Factories returns a copy of all registered factories in build order
 func Factories() []CoreFactory {
-	factoriesMu.RLock()
-	defer factoriesMu.RUnlock()
+	return defaultRegistry.All()
+}
 
-	// Return a copy to prevent external modification
-	result := make([]CoreFactory, len(factories))
-	copy(result, factories)
-	return result
+// Freeze makes the global registry immutable, see FactoryRegistry.Freeze
+func Freeze() {
+	defaultRegistry.Freeze()
+}
+
+func indexOfFactory(fs []registeredFactory, name string) int {
+	for i := range fs {
+		if fs[i].factory.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertFactory keeps fs sorted
+func insertFactory(fs []registeredFactory, rf registeredFactory) []registeredFactory {
+	i := sort.Search(len(fs), func(i int) bool {
+		if fs[i].priority != rf.priority {
+			return fs[i].priority > rf.priority
+		}
+		return fs[i].factory.Name() > rf.factory.Name()
+	})
+	fs = append(fs, registeredFactory{})
+	copy(fs[i+1:], fs[i:])
+	fs[i] = rf
+	return fs
 }
 
 // ✅ New: thin interface with concrete type, no interface{}
@@ -48,15 +271,13 @@ type Registry interface {
 	All() []CoreFactory
 }
 
-type globalRegistry struct{}
-
-func (globalRegistry) All() []CoreFactory { return Factories() }
-
-func DefaultRegistry() Registry { return globalRegistry{} }
+// DefaultRegistry returns the global registry
+func DefaultRegistry() Registry { return defaultRegistry }
 
-// For tests
+// ClearFactories removes every factory from the global registry
+//
+// Deprecated: build loggers from a NewRegistry with zapx.WithFactoryRegistry
+// instead of clearing the registry every logger shares
 func ClearFactories() {
-	factoriesMu.Lock()
-	defer factoriesMu.Unlock()
-	factories = nil
+	defaultRegistry.Clear()
 }
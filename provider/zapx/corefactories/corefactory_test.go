@@ -1,6 +1,10 @@
 package corefactories
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 
 	logger "github.com/HoangAnhNguyen269/loggerkit"
@@ -73,3 +77,158 @@ func TestGlobalRegistryInterface(t *testing.T) {
 		t.Errorf("Expected factory name to be 'test', got '%s'", factories[0].Name())
 	}
 }
+
+func factoryNames() []string {
+	var names []string
+	for _, f := range Factories() {
+		names = append(names, f.Name())
+	}
+	return names
+}
+
+func TestFactoryOrdering(t *testing.T) {
+	ClearFactories()
+
+	RegisterFactory(&MockFactory{name: "zeta"})
+	RegisterFactoryWithPriority(&MockFactory{name: "late"}, 10)
+	RegisterFactory(&MockFactory{name: "alpha"})
+	RegisterFactoryWithPriority(&MockFactory{name: "early"}, -10)
+
+	got := strings.Join(factoryNames(), ",")
+	if got != "early,alpha,zeta,late" {
+		t.Errorf("Expected order by priority then name, got %s", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic when registering a duplicate name")
+		}
+	}()
+	RegisterFactory(&MockFactory{name: "alpha"})
+}
+
+func TestReplaceAndUnregisterFactory(t *testing.T) {
+	ClearFactories()
+	RegisterFactoryWithPriority(&MockFactory{name: "console"}, -1)
+	RegisterFactory(&MockFactory{name: "file"})
+
+	custom := &MockFactory{name: "console", enabled: true}
+	if !ReplaceFactory("console", custom) {
+		t.Error("Expected ReplaceFactory to report the replaced factory")
+	}
+	if fs := Factories(); len(fs) != 2 || fs[0] != custom {
+		t.Errorf("Expected the replacement to keep its priority, got %v", factoryNames())
+	}
+
+	if ReplaceFactory("elasticsearch", &MockFactory{name: "elasticsearch"}) {
+		t.Error("Expected ReplaceFactory to report that nothing was replaced")
+	}
+	if got := strings.Join(factoryNames(), ","); got != "console,elasticsearch,file" {
+		t.Errorf("Expected the new factory to be added, got %s", got)
+	}
+
+	if !UnregisterFactory("file") || UnregisterFactory("file") {
+		t.Error("Expected UnregisterFactory to remove the factory exactly once")
+	}
+	if got := strings.Join(factoryNames(), ","); got != "console,elasticsearch" {
+		t.Errorf("Unexpected factories after unregister: %s", got)
+	}
+}
+
+func TestRegistryConcurrentReplaceAndUnregister(t *testing.T) {
+	ClearFactories()
+	for i := 0; i < 10; i++ {
+		RegisterFactory(&MockFactory{name: fmt.Sprintf("sink%d", i)})
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("sink%d", i)
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			ReplaceFactory(name, &MockFactory{name: name, enabled: true})
+		}()
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				UnregisterFactory(name)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			names := factoryNames()
+			if !sort.StringsAreSorted(names) {
+				t.Errorf("Factories() observed out of order: %v", names)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Odd sinks were only replaced; even ones may have been re-added by a later replace
+	names := factoryNames()
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			t.Errorf("Duplicate factory %s after concurrent updates", name)
+		}
+		seen[name] = true
+	}
+	for i := 1; i < 10; i += 2 {
+		if !seen[fmt.Sprintf("sink%d", i)] {
+			t.Errorf("Expected sink%d to survive", i)
+		}
+	}
+}
+
+func TestRegistryFreeze(t *testing.T) {
+	r := NewRegistry()
+	r.Register(&MockFactory{name: "file"})
+	r.Freeze()
+	if !r.Frozen() {
+		t.Fatal("Expected the registry to report it is frozen")
+	}
+
+	changes := map[string]func(){
+		"Register":   func() { r.Register(&MockFactory{name: "console"}) },
+		"Replace":    func() { r.Replace("file", &MockFactory{name: "file"}) },
+		"Unregister": func() { r.Unregister("file") },
+		"Clear":      func() { r.Clear() },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected %s to panic on a frozen registry", name)
+				}
+			}()
+			change()
+		})
+	}
+	if fs := r.All(); len(fs) != 1 || fs[0].Name() != "file" {
+		t.Errorf("Expected the frozen registry unchanged, got %v", fs)
+	}
+}
+
+func TestNewRegistryIsolated(t *testing.T) {
+	before := strings.Join(factoryNames(), ",")
+
+	r := NewRegistry()
+	r.RegisterWithPriority(&MockFactory{name: "late"}, 10)
+	r.Register(&MockFactory{name: "zeta"})
+	r.Register(&MockFactory{name: "alpha"})
+	if !r.Replace("zeta", &MockFactory{name: "omega"}) || !r.Unregister("alpha") {
+		t.Error("Expected Replace and Unregister to find the factories")
+	}
+
+	var names []string
+	for _, f := range r.All() {
+		names = append(names, f.Name())
+	}
+	if got := strings.Join(names, ","); got != "omega,late" {
+		t.Errorf("Expected the registry's own factories in order, got %s", got)
+	}
+	if got := strings.Join(factoryNames(), ","); got != before {
+		t.Errorf("Expected the global registry untouched, got %s (was %s)", got, before)
+	}
+}
@@ -0,0 +1,46 @@
+package corefactories
+
+import (
+	"fmt"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap/zapcore"
+)
+
+// DurationEncoder maps unit onto a zap duration encoder; empty is seconds
+func DurationEncoder(unit logger.DurationUnit) (zapcore.DurationEncoder, error) {
+	switch unit {
+	case "", logger.DurationSeconds:
+		return zapcore.SecondsDurationEncoder, nil
+	case logger.DurationMillis:
+		return millisDurationEncoder, nil
+	case logger.DurationNanos:
+		return zapcore.NanosDurationEncoder, nil
+	case logger.DurationString:
+		return zapcore.StringDurationEncoder, nil
+	default:
+		return nil, fmt.Errorf("invalid duration unit %q (want %q, %q, %q or %q)", unit,
+			logger.DurationSeconds, logger.DurationMillis, logger.DurationNanos, logger.DurationString)
+	}
+}
+
+// millisDurationEncoder writes whole milliseconds, for integer mappings such as
+// Elasticsearch's long; zapcore.MillisDurationEncoder writes a float
+func millisDurationEncoder(d time.Duration, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendInt64(d.Milliseconds())
+}
+
+// consoleDurationEncoder is the text console's encoder, "string" by default
+func consoleDurationEncoder(opts logger.Options) zapcore.DurationEncoder {
+	unit := opts.Encoder.ConsoleDurationUnit
+	if unit == "" {
+		unit = logger.DurationString
+	}
+	enc, err := DurationEncoder(unit)
+	if err != nil {
+		// Validated when the logger is built
+		return zapcore.StringDurationEncoder
+	}
+	return enc
+}
@@ -0,0 +1,41 @@
+package corefactories
+
+import (
+	"fmt"
+
+	"github.com/HoangAnhNguyen269/loggerkit/internal/dlqfile"
+)
+
+// DLQEntry is one entry of a DLQ file
+//
+// Deprecated: use elasticx.DLQEntry
+type DLQEntry = dlqfile.Entry
+
+// DLQFilter selects DLQ entries
+//
+// Deprecated: use elasticx.DLQFilter
+type DLQFilter = dlqfile.Filter
+
+// ScanDLQFunc calls fn with each entry of the DLQ file at path
+//
+// Deprecated: use elasticx.ScanDLQFunc
+func ScanDLQFunc(path string, fn func(DLQEntry) error) error {
+	return dlqfile.Scan(path, fn)
+}
+
+// FilterDLQ returns the entries of the DLQ file at path that match opts
+//
+// Deprecated: use elasticx.FilterDLQ
+func FilterDLQ(path string, opts DLQFilter) ([]DLQEntry, error) {
+	return dlqfile.Collect(path, opts)
+}
+
+// ScanDLQ prints each entry of the DLQ file at path on its own line
+//
+// Deprecated: use elasticx.ScanDLQFunc or elasticx.FilterDLQ
+func ScanDLQ(path string) error {
+	return dlqfile.Scan(path, func(e DLQEntry) error {
+		fmt.Println(e)
+		return nil
+	})
+}
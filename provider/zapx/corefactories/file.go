@@ -1,6 +1,8 @@
 package corefactories
 
 import (
+	"fmt"
+
 	logger "github.com/HoangAnhNguyen269/loggerkit"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -36,29 +38,26 @@ func (ff *FileFactory) Build(encCfg zapcore.EncoderConfig, lvl zapcore.Level, me
 		Compress:   fileConfig.Compress,
 	}
 
-	// Create file writer with metrics if enabled
-	var writer zapcore.WriteSyncer
-	if metrics != nil {
-		writer = &fileWriter{
-			Logger:  lj,
-			metrics: metrics,
-		}
-	} else {
-		writer = zapcore.AddSync(lj)
-	}
+	// lumberjack.Logger serializes writes with its own mutex, so the core needs no Lock
+	writer := &fileWriter{Logger: lj, metrics: metrics}
+	ws, stop := bufferWrites(writer, opts)
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encCfg), ws, lvl)
 
-	encoder := zapcore.NewJSONEncoder(encCfg)
-	core := zapcore.NewCore(encoder, zapcore.Lock(writer), lvl)
-
-	// Closer function for lumberjack
+	// Closer function for lumberjack, flushing the write buffer first
 	closer := func() error {
+		if stop != nil {
+			if err := stop(); err != nil {
+				lj.Close()
+				return fmt.Errorf("failed to flush file buffer: %w", err)
+			}
+		}
 		return lj.Close()
 	}
 
 	return core, closer, nil
 }
 
-// fileWriter wraps lumberjack.Logger with metrics support
+// fileWriter wraps lumberjack.Logger with metrics support; metrics may be nil
 type fileWriter struct {
 	*lumberjack.Logger
 	metrics *logger.Metrics
@@ -66,7 +65,7 @@ type fileWriter struct {
 
 func (fw *fileWriter) Write(p []byte) (int, error) {
 	n, err := fw.Logger.Write(p)
-	if err != nil && fw.metrics != nil {
+	if err != nil {
 		fw.metrics.RecordLogDropped("file", "write_error")
 	}
 	return n, err
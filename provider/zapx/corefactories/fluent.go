@@ -0,0 +1,130 @@
+package corefactories
+
+import (
+	"strings"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/internal/msgpack"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultFluentTag routes entries per service and level, e.g. "checkout.error"
+const defaultFluentTag = "<service>.<level>"
+
+// FluentFactory creates cores that ship entries to Fluentd/Fluent Bit over the forward protocol
+type FluentFactory struct{}
+
+func init() {
+	RegisterFactory(&FluentFactory{})
+}
+
+// Name returns the unique name of this factory
+func (ff *FluentFactory) Name() string {
+	return "fluent"
+}
+
+// Enabled determines if forward logging should be enabled based on options
+func (ff *FluentFactory) Enabled(opts logger.Options) bool {
+	return opts.Fluent != nil
+}
+
+// Build creates a forward-protocol core backed by a background sender
+func (ff *FluentFactory) Build(encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, func() error, error) {
+	w := newFluentWriter(opts.Fluent, metrics, opts.Diagnosticf)
+
+	tmpl := opts.Fluent.Tag
+	if tmpl == "" {
+		tmpl = defaultFluentTag
+	}
+	core := &fluentCore{
+		LevelEnabler: lvl,
+		encCfg:       encCfg,
+		tags:         fluentTags(tmpl, opts.Service),
+		out:          w,
+	}
+	return core, w.Close, nil
+}
+
+// fluentTags expands the tag template once per level
+func fluentTags(tmpl, service string) map[zapcore.Level]string {
+	tags := make(map[zapcore.Level]string)
+	for l := zapcore.DebugLevel; l <= zapcore.FatalLevel; l++ {
+		tags[l] = strings.NewReplacer("<service>", service, "<level>", l.String()).Replace(tmpl)
+	}
+	return tags
+}
+
+// fluentCore turns entries into forward-protocol events: the entry time becomes the
+// event time and everything else goes into the record map
+type fluentCore struct {
+	zapcore.LevelEnabler
+	encCfg zapcore.EncoderConfig
+	tags   map[zapcore.Level]string
+	fields []zapcore.Field
+	out    *fluentWriter
+}
+
+func (c *fluentCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	return &clone
+}
+
+func (c *fluentCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *fluentCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for i := range c.fields {
+		c.fields[i].AddTo(enc)
+	}
+	for i := range fields {
+		fields[i].AddTo(enc)
+	}
+
+	record := enc.Fields
+	if key := c.encCfg.LevelKey; key != "" {
+		record[key] = ent.Level.String()
+	}
+	if key := c.encCfg.MessageKey; key != "" {
+		record[key] = ent.Message
+	}
+	if key := c.encCfg.NameKey; key != "" && ent.LoggerName != "" {
+		record[key] = ent.LoggerName
+	}
+	if key := c.encCfg.CallerKey; key != "" && ent.Caller.Defined {
+		record[key] = ent.Caller.TrimmedPath()
+	}
+	if key := c.encCfg.StacktraceKey; key != "" && ent.Stack != "" {
+		record[key] = ent.Stack
+	}
+
+	// Each event is [time, record]; the sender frames batches of them per tag
+	event := msgpack.AppendArrayHeader(nil, 2)
+	event = msgpack.AppendEventTime(event, ent.Time)
+	event, err := msgpack.AppendAny(event, record)
+	if err != nil {
+		c.out.drop(1, "encode_error")
+		return err
+	}
+
+	tag, ok := c.tags[ent.Level]
+	if !ok {
+		tag = c.tags[zapcore.FatalLevel]
+	}
+	c.out.add(tag, event)
+	return nil
+}
+
+func (c *fluentCore) Sync() error {
+	return nil
+}
+
+// Healthy reports whether delivery to the forward server is not failing
+func (c *fluentCore) Healthy() bool {
+	return c.out.healthy()
+}
@@ -0,0 +1,369 @@
+package corefactories
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/internal/msgpack"
+)
+
+const (
+	defaultFluentAddress    = "localhost:24224"
+	defaultFluentBufferSize = 8192
+
+	fluentDialTimeout  = 5 * time.Second
+	fluentIOTimeout    = 5 * time.Second // Per write, and per ack wait
+	fluentCloseTimeout = 10 * time.Second
+	fluentBackoffMin   = 100 * time.Millisecond
+	fluentBackoffMax   = 5 * time.Second
+	fluentMaxBatch     = 1024 // Events per forward message
+)
+
+// fluentEvent is one encoded [time, record] pair waiting to be sent
+type fluentEvent struct {
+	tag   string
+	event []byte
+}
+
+// fluentWriter queues events and sends them from a single goroutine, which owns
+// the connection. While the server is unreachable the queue absorbs BufferSize
+// events; beyond that new events are dropped and counted.
+type fluentWriter struct {
+	address     string
+	sharedKey   string
+	requireAck  bool
+	hostname    string
+	metrics     *logger.Metrics
+	diagnosticf func(format string, args ...any)
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan fluentEvent
+	done    chan struct{}
+	stopped chan struct{}
+
+	failing atomic.Bool // A delivery failure was reported and delivery has not resumed yet
+
+	// Owned by the run goroutine
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+func newFluentWriter(config *logger.FluentSink, metrics *logger.Metrics, diagnosticf func(string, ...any)) *fluentWriter {
+	address := config.Address
+	if address == "" {
+		address = defaultFluentAddress
+	}
+	size := config.BufferSize
+	if size <= 0 {
+		size = defaultFluentBufferSize
+	}
+	hostname, _ := os.Hostname()
+
+	w := &fluentWriter{
+		address:     address,
+		sharedKey:   config.SharedKey,
+		requireAck:  config.RequireAck,
+		hostname:    hostname,
+		metrics:     metrics,
+		diagnosticf: diagnosticf,
+		queue:       make(chan fluentEvent, size),
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// add queues an event without blocking the caller
+func (w *fluentWriter) add(tag string, event []byte) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		w.drop(1, "writer_closed")
+		return
+	}
+	select {
+	case w.queue <- fluentEvent{tag: tag, event: event}:
+	default:
+		w.drop(1, "buffer_full")
+	}
+}
+
+func (w *fluentWriter) drop(n int, reason string) {
+	for i := 0; i < n; i++ {
+		w.metrics.RecordLogDropped("fluent", reason)
+	}
+}
+
+func (w *fluentWriter) run() {
+	defer close(w.stopped)
+	defer w.disconnect()
+
+	for {
+		select {
+		case ev := <-w.queue:
+			w.deliver(w.batch(ev))
+		case <-w.done:
+			// Close stops add before signalling, so the queue only shrinks from here
+			for {
+				select {
+				case ev := <-w.queue:
+					w.deliver(w.batch(ev))
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// batch collects whatever else is already queued behind first
+func (w *fluentWriter) batch(first fluentEvent) []fluentEvent {
+	events := []fluentEvent{first}
+	for len(events) < fluentMaxBatch {
+		select {
+		case ev := <-w.queue:
+			events = append(events, ev)
+		default:
+			return events
+		}
+	}
+	return events
+}
+
+// deliver sends events as one forward message per run of equal tags
+func (w *fluentWriter) deliver(events []fluentEvent) {
+	for len(events) > 0 {
+		n := 1
+		for n < len(events) && events[n].tag == events[0].tag {
+			n++
+		}
+		w.sendWithRetry(events[0].tag, events[:n])
+		events = events[n:]
+	}
+}
+
+// sendWithRetry reconnects with backoff until the message is delivered. Once the
+// writer is closing, a message gets one more attempt and is then dropped.
+func (w *fluentWriter) sendWithRetry(tag string, events []fluentEvent) {
+	msg, chunk := w.forwardMessage(tag, events)
+	backoff := fluentBackoffMin
+
+	for {
+		err := w.send(msg, chunk)
+		if err == nil {
+			if w.failing.Load() {
+				w.failing.Store(false)
+				w.diagnosticf("fluent: delivery to %s resumed", w.address)
+			}
+			return
+		}
+
+		w.disconnect()
+		if !w.failing.Load() {
+			w.failing.Store(true)
+			w.diagnosticf("fluent: delivery to %s failed, retrying: %v", w.address, err)
+		}
+
+		select {
+		case <-w.done:
+			if err := w.send(msg, chunk); err == nil {
+				return
+			}
+			w.disconnect()
+			w.drop(len(events), "write_error")
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, fluentBackoffMax)
+	}
+}
+
+// forwardMessage frames events in Forward mode: [tag, [[time, record], ...], option].
+// When acks are required the option carries a unique chunk id for the server to echo.
+func (w *fluentWriter) forwardMessage(tag string, events []fluentEvent) ([]byte, string) {
+	size := 0
+	for _, ev := range events {
+		size += len(ev.event)
+	}
+
+	msg := make([]byte, 0, size+len(tag)+64)
+	msg = msgpack.AppendArrayHeader(msg, 3)
+	msg = msgpack.AppendString(msg, tag)
+	msg = msgpack.AppendArrayHeader(msg, len(events))
+	for _, ev := range events {
+		msg = append(msg, ev.event...)
+	}
+
+	var chunk string
+	if w.requireAck {
+		chunk = newChunkID()
+		msg = msgpack.AppendMapHeader(msg, 2)
+		msg = msgpack.AppendString(msg, "chunk")
+		msg = msgpack.AppendString(msg, chunk)
+	} else {
+		msg = msgpack.AppendMapHeader(msg, 1)
+	}
+	msg = msgpack.AppendString(msg, "size")
+	msg = msgpack.AppendInt(msg, int64(len(events)))
+	return msg, chunk
+}
+
+func (w *fluentWriter) send(msg []byte, chunk string) error {
+	if w.conn == nil {
+		if err := w.connect(); err != nil {
+			return err
+		}
+	}
+
+	_ = w.conn.SetWriteDeadline(time.Now().Add(fluentIOTimeout))
+	if _, err := w.conn.Write(msg); err != nil {
+		return fmt.Errorf("failed to write forward message: %w", err)
+	}
+	if chunk == "" {
+		return nil
+	}
+
+	_ = w.conn.SetReadDeadline(time.Now().Add(fluentIOTimeout))
+	resp, err := msgpack.Decode(w.rd)
+	if err != nil {
+		return fmt.Errorf("failed to read ack: %w", err)
+	}
+	if m, ok := resp.(map[string]any); !ok || m["ack"] != chunk {
+		return fmt.Errorf("unexpected ack response: %v", resp)
+	}
+	return nil
+}
+
+func (w *fluentWriter) connect() error {
+	conn, err := net.DialTimeout("tcp", w.address, fluentDialTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	rd := bufio.NewReader(conn)
+
+	if w.sharedKey != "" {
+		if err := w.handshake(conn, rd); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+	w.conn, w.rd = conn, rd
+	return nil
+}
+
+// handshake answers the server's HELO with a PING proving we know the shared key,
+// then checks the PONG proves the server knows it too
+func (w *fluentWriter) handshake(conn net.Conn, rd *bufio.Reader) error {
+	_ = conn.SetDeadline(time.Now().Add(fluentDialTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	helo, err := readFluentMessage(rd, "HELO", 2)
+	if err != nil {
+		return err
+	}
+	heloOpts, _ := helo[1].(map[string]any)
+	nonce := fluentString(heloOpts["nonce"])
+
+	salt := newChunkID()
+	ping := msgpack.AppendArrayHeader(nil, 6)
+	ping = msgpack.AppendString(ping, "PING")
+	ping = msgpack.AppendString(ping, w.hostname)
+	ping = msgpack.AppendString(ping, salt)
+	ping = msgpack.AppendString(ping, sharedKeyDigest(salt, w.hostname, nonce, w.sharedKey))
+	ping = msgpack.AppendString(ping, "") // Username: user auth is not supported
+	ping = msgpack.AppendString(ping, "")
+	if _, err := conn.Write(ping); err != nil {
+		return fmt.Errorf("failed to send handshake: %w", err)
+	}
+
+	pong, err := readFluentMessage(rd, "PONG", 5)
+	if err != nil {
+		return err
+	}
+	if ok, _ := pong[1].(bool); !ok {
+		return fmt.Errorf("fluent handshake rejected: %s", fluentString(pong[2]))
+	}
+	serverHost := fluentString(pong[3])
+	if fluentString(pong[4]) != sharedKeyDigest(salt, serverHost, nonce, w.sharedKey) {
+		return fmt.Errorf("fluent handshake failed: server %q did not prove the shared key", serverHost)
+	}
+	return nil
+}
+
+func readFluentMessage(rd *bufio.Reader, kind string, minLen int) ([]any, error) {
+	v, err := msgpack.Decode(rd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", kind, err)
+	}
+	arr, ok := v.([]any)
+	if !ok || len(arr) < minLen || fluentString(arr[0]) != kind {
+		return nil, fmt.Errorf("unexpected handshake message, want %s: %v", kind, v)
+	}
+	return arr, nil
+}
+
+// fluentString reads a str or bin value; servers differ in which they send
+func fluentString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
+
+func sharedKeyDigest(salt, hostname, nonce, sharedKey string) string {
+	sum := sha512.Sum512([]byte(salt + hostname + nonce + sharedKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func newChunkID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return base64.StdEncoding.EncodeToString(id[:])
+}
+
+func (w *fluentWriter) disconnect() {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn, w.rd = nil, nil
+	}
+}
+
+// healthy reports whether delivery is not failing
+func (w *fluentWriter) healthy() bool {
+	return !w.failing.Load()
+}
+
+// Close stops accepting events and waits for the queue to drain
+func (w *fluentWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	w.mu.Unlock()
+
+	select {
+	case <-w.stopped:
+		return nil
+	case <-time.After(fluentCloseTimeout):
+		return fmt.Errorf("failed to flush fluent buffer within %s", fluentCloseTimeout)
+	}
+}
@@ -0,0 +1,263 @@
+package corefactories
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap/zapcore"
+)
+
+// MirrorFactory creates a core that writes every entry to a primary and a shadow sink
+type MirrorFactory struct{}
+
+func init() {
+	RegisterFactory(&MirrorFactory{})
+}
+
+// Name returns the unique name of this factory
+func (mf *MirrorFactory) Name() string {
+	return "mirror"
+}
+
+// Enabled determines if mirroring should be enabled based on options
+func (mf *MirrorFactory) Enabled(opts logger.Options) bool {
+	return opts.Shadow != nil
+}
+
+// Build creates both sinks from their SinkRefs
+func (mf *MirrorFactory) Build(encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, func() error, error) {
+	core, closer, err := mf.BuildContext(encCfg, lvl, metrics, opts)
+	if err != nil || closer == nil {
+		return core, nil, err
+	}
+	return core, func() error { return closer(context.Background()) }, nil
+}
+
+// BuildContext creates both sinks from their SinkRefs. A shadow that cannot be
+// built is reported and left out, so it never prevents the logger from starting.
+func (mf *MirrorFactory) BuildContext(encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, CloseFunc, error) {
+	config := opts.Shadow
+
+	primary, primaryClose, err := buildSinkRef(config.Primary, encCfg, lvl, metrics, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build primary sink: %w", err)
+	}
+
+	shadowName := config.Shadow.Factory
+	shadowMetrics := metrics.Shadow(shadowName)
+	shadow, shadowClose, err := buildSinkRef(config.Shadow, encCfg, lvl, shadowMetrics, opts)
+	if err != nil {
+		opts.Diagnosticf("mirror: shadow sink %s disabled: %v", shadowName, err)
+		shadowMetrics.RecordShadowFailure(shadowName, "build_error")
+		return primary, primaryClose, nil
+	}
+
+	queue := newShadowQueue(shadowQueueSize, shadowName, shadowMetrics)
+	core := &mirrorCore{primary: primary, shadow: shadow, shadowName: shadowName, metrics: shadowMetrics, queue: queue}
+	closer := func(ctx context.Context) error {
+		if err := queue.close(ctx); err != nil {
+			opts.Diagnosticf("mirror: shadow sink %s left entries unwritten: %v", shadowName, err)
+		}
+		if shadowClose != nil {
+			if err := shadowClose(ctx); err != nil {
+				opts.Diagnosticf("mirror: failed to close shadow sink %s: %v", shadowName, err)
+			}
+		}
+		if primaryClose != nil {
+			return primaryClose(ctx)
+		}
+		return nil
+	}
+	return core, closer, nil
+}
+
+// buildSinkRef builds the registered factory named by ref from the logger's options
+// with ref.Options applied
+func buildSinkRef(ref logger.SinkRef, encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, CloseFunc, error) {
+	var factory CoreFactory
+	for _, f := range Factories() {
+		if f.Name() == ref.Factory {
+			factory = f
+			break
+		}
+	}
+	if factory == nil || ref.Factory == "mirror" {
+		return nil, nil, fmt.Errorf("unknown sink %q", ref.Factory)
+	}
+
+	refOpts := opts
+	refOpts.Shadow = nil
+	for _, opt := range ref.Options {
+		opt(&refOpts)
+	}
+	if !factory.Enabled(refOpts) {
+		return nil, nil, fmt.Errorf("sink %q is not configured by its options", ref.Factory)
+	}
+
+	core, closer, err := BuildCore(factory, encCfg, lvl, metrics, refOpts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build %s sink: %w", ref.Factory, err)
+	}
+	return core, closer, nil
+}
+
+// mirrorCore returns the primary's result; the shadow's errors and panics are
+// only counted. The shadow is written through queue, so a slow shadow never
+// holds up the caller.
+type mirrorCore struct {
+	primary    zapcore.Core
+	shadow     zapcore.Core
+	shadowName string
+	metrics    *logger.Metrics // Shadow metrics
+	queue      *shadowQueue    // Shared by the clones of With
+}
+
+func (c *mirrorCore) Enabled(lvl zapcore.Level) bool {
+	return c.primary.Enabled(lvl) || c.shadow.Enabled(lvl)
+}
+
+func (c *mirrorCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.primary = c.primary.With(fields)
+	clone.shadow = c.shadowWith(fields)
+	return &clone
+}
+
+func (c *mirrorCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *mirrorCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var err error
+	if c.primary.Enabled(ent.Level) {
+		err = c.primary.Write(ent, fields)
+	}
+	if c.shadow.Enabled(ent.Level) {
+		// Copy: the caller reuses fields once Write returns
+		c.queue.push(shadowJob{core: c, ent: ent, fields: append([]zapcore.Field(nil), fields...)})
+	}
+	return err
+}
+
+// Sync syncs the primary and queues a sync of the shadow without waiting for it
+func (c *mirrorCore) Sync() error {
+	err := c.primary.Sync()
+	c.queue.push(shadowJob{core: c, sync: true})
+	return err
+}
+
+func (c *mirrorCore) shadowWith(fields []zapcore.Field) (core zapcore.Core) {
+	defer func() {
+		if recover() != nil {
+			c.metrics.RecordShadowFailure(c.shadowName, "panic")
+			core = zapcore.NewNopCore()
+		}
+	}()
+	return c.shadow.With(fields)
+}
+
+func (c *mirrorCore) shadowWrite(ent zapcore.Entry, fields []zapcore.Field) {
+	defer func() {
+		if recover() != nil {
+			c.metrics.RecordShadowFailure(c.shadowName, "panic")
+		}
+	}()
+	if err := c.shadow.Write(ent, fields); err != nil {
+		c.metrics.RecordShadowFailure(c.shadowName, "write_error")
+	}
+}
+
+func (c *mirrorCore) shadowSync() {
+	defer func() {
+		if recover() != nil {
+			c.metrics.RecordShadowFailure(c.shadowName, "panic")
+		}
+	}()
+	if err := c.shadow.Sync(); err != nil {
+		c.metrics.RecordShadowFailure(c.shadowName, "sync_error")
+	}
+}
+
+// shadowQueueSize is the number of entries waiting for the shadow sink
+const shadowQueueSize = 1024
+
+// shadowJob is an entry to write to the shadow of core, or a sync of it when
+// sync is set
+type shadowJob struct {
+	core   *mirrorCore
+	ent    zapcore.Entry
+	fields []zapcore.Field
+	sync   bool
+}
+
+// shadowQueue hands shadow writes to a single goroutine. A full queue drops
+// them, counted as shadow failures with reason "queue_full".
+type shadowQueue struct {
+	name    string
+	metrics *logger.Metrics
+	jobs    chan shadowJob
+	stopped chan struct{}
+
+	mu     sync.RWMutex // Guards closed against sends on the closed channel
+	closed bool
+}
+
+func newShadowQueue(size int, name string, metrics *logger.Metrics) *shadowQueue {
+	q := &shadowQueue{
+		name:    name,
+		metrics: metrics,
+		jobs:    make(chan shadowJob, size),
+		stopped: make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+func (q *shadowQueue) run() {
+	defer close(q.stopped)
+	for job := range q.jobs {
+		if job.sync {
+			job.core.shadowSync()
+		} else {
+			job.core.shadowWrite(job.ent, job.fields)
+		}
+	}
+}
+
+// push queues job, dropping it when the queue is full or closed
+func (q *shadowQueue) push(job shadowJob) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return
+	}
+	select {
+	case q.jobs <- job:
+	default:
+		if !job.sync {
+			q.metrics.RecordShadowFailure(q.name, "queue_full")
+		}
+	}
+}
+
+// close stops accepting jobs and waits until the queued ones are written or ctx
+// is done
+func (q *shadowQueue) close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+	select {
+	case <-q.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to drain the shadow queue: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,146 @@
+package corefactories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// brokenCore fails every write, or panics when panics is set
+type brokenCore struct {
+	zapcore.LevelEnabler
+	panics bool
+}
+
+func (c *brokenCore) With([]zapcore.Field) zapcore.Core { return c }
+
+func (c *brokenCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c *brokenCore) Write(zapcore.Entry, []zapcore.Field) error {
+	if c.panics {
+		panic("shadow exploded")
+	}
+	return errors.New("shadow unavailable")
+}
+
+func (c *brokenCore) Sync() error { return nil }
+
+func TestMirrorShadowErrorsDoNotReachCaller(t *testing.T) {
+	metrics := logger.GetMetrics()
+	writeErrors := metrics.ShadowFailures.WithLabelValues("broken", "write_error")
+	panics := metrics.ShadowFailures.WithLabelValues("broken", "panic")
+	writeErrorsBefore, panicsBefore := promtestutil.ToFloat64(writeErrors), promtestutil.ToFloat64(panics)
+
+	for _, shadowPanics := range []bool{false, true} {
+		primary, logs := observer.New(zapcore.InfoLevel)
+		queue := newShadowQueue(10, "broken", metrics.Shadow("broken"))
+		core := &mirrorCore{
+			primary:    primary,
+			shadow:     &brokenCore{LevelEnabler: zapcore.DebugLevel, panics: shadowPanics},
+			shadowName: "broken",
+			metrics:    metrics.Shadow("broken"),
+			queue:      queue,
+		}
+
+		child := core.With([]zapcore.Field{{Key: "k", Type: zapcore.StringType, String: "v"}})
+		if err := child.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "hello"}, nil); err != nil {
+			t.Errorf("Shadow failure reached the caller: %v", err)
+		}
+		if logs.Len() != 1 || logs.All()[0].ContextMap()["k"] != "v" {
+			t.Errorf("Expected the primary to get the entry, got %v", logs.All())
+		}
+
+		// Debug is only enabled on the shadow, the primary must not see it
+		if err := child.Write(zapcore.Entry{Level: zapcore.DebugLevel, Message: "debug"}, nil); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if logs.Len() != 1 {
+			t.Errorf("Primary received an entry below its level")
+		}
+		if err := queue.close(context.Background()); err != nil {
+			t.Fatalf("Failed to drain the shadow queue: %v", err)
+		}
+	}
+
+	if got := promtestutil.ToFloat64(writeErrors) - writeErrorsBefore; got != 2 {
+		t.Errorf("Expected 2 shadow write errors, got %v", got)
+	}
+	if got := promtestutil.ToFloat64(panics) - panicsBefore; got != 2 {
+		t.Errorf("Expected 2 shadow panics, got %v", got)
+	}
+}
+
+// blockingCore blocks every write until release is closed
+type blockingCore struct {
+	zapcore.LevelEnabler
+	release chan struct{}
+	writes  int
+}
+
+func (c *blockingCore) With([]zapcore.Field) zapcore.Core { return c }
+
+func (c *blockingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c *blockingCore) Write(zapcore.Entry, []zapcore.Field) error {
+	<-c.release
+	c.writes++
+	return nil
+}
+
+func (c *blockingCore) Sync() error { return nil }
+
+func TestMirrorBlockedShadowDoesNotStallCaller(t *testing.T) {
+	metrics := logger.GetMetrics()
+	queueFull := metrics.ShadowFailures.WithLabelValues("blocked", "queue_full")
+	before := promtestutil.ToFloat64(queueFull)
+
+	primary, logs := observer.New(zapcore.InfoLevel)
+	shadow := &blockingCore{LevelEnabler: zapcore.InfoLevel, release: make(chan struct{})}
+	queue := newShadowQueue(2, "blocked", metrics.Shadow("blocked"))
+	core := &mirrorCore{primary: primary, shadow: shadow, shadowName: "blocked", metrics: metrics.Shadow("blocked"), queue: queue}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "hello"}, nil)
+		}
+		core.Sync()
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected writes to return while the shadow is blocked")
+	}
+	if logs.Len() != 10 {
+		t.Errorf("Expected the primary to get all 10 entries, got %d", logs.Len())
+	}
+
+	// One write is in flight and two are queued; the rest were dropped
+	if got := promtestutil.ToFloat64(queueFull) - before; got < 7 {
+		t.Errorf("Expected at least 7 dropped shadow entries, got %v", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := queue.close(ctx); err == nil {
+		t.Error("Expected close to give up on the blocked shadow")
+	}
+	close(shadow.release)
+	if err := queue.close(context.Background()); err != nil {
+		t.Fatalf("Failed to drain the shadow queue: %v", err)
+	}
+	if shadow.writes+int(promtestutil.ToFloat64(queueFull)-before) != 10 {
+		t.Errorf("Expected every entry written or dropped, got %d written", shadow.writes)
+	}
+}
@@ -0,0 +1,412 @@
+package corefactories
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultNetworkReconnectMin = 100 * time.Millisecond
+	defaultNetworkReconnectMax = 10 * time.Second
+	defaultNetworkWriteTimeout = 5 * time.Second
+	defaultNetworkBufferSize   = 8192
+
+	networkDialTimeout  = 5 * time.Second
+	networkCloseTimeout = 10 * time.Second
+	networkMaxBatch     = 64 << 10 // Bytes per stream write
+	networkMaxDatagram  = 65507    // Largest UDP payload over IPv4
+)
+
+var errNetworkConnClosed = errors.New("connection closed by peer")
+
+// NetworkFactory creates cores that stream JSON lines over tcp, udp or unix sockets
+type NetworkFactory struct{}
+
+func init() {
+	RegisterFactory(&NetworkFactory{})
+}
+
+// Name returns the unique name of this factory
+func (nf *NetworkFactory) Name() string {
+	return "network"
+}
+
+// Enabled determines if network logging should be enabled based on options
+func (nf *NetworkFactory) Enabled(opts logger.Options) bool {
+	return opts.Network != nil
+}
+
+// Build creates a JSON-lines core backed by a background sender
+func (nf *NetworkFactory) Build(encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, func() error, error) {
+	core, closer, err := nf.BuildContext(encCfg, lvl, metrics, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return core, func() error { return closer(context.Background()) }, nil
+}
+
+// BuildContext is Build with a closer that drains the queue until the context is done
+func (nf *NetworkFactory) BuildContext(encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, CloseFunc, error) {
+	w, err := newNetworkWriter(opts.Network, metrics, opts.Diagnosticf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Always JSON: the receiving end splits on newlines and parses each line
+	encCfg.LineEnding = "\n"
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encCfg), w, lvl)
+	return &healthCore{Core: core, healthy: w.healthy}, w.CloseContext, nil
+}
+
+// networkWriter queues encoded lines and sends them from a single goroutine, which
+// owns the connection. While disconnected the queue absorbs BufferSize lines;
+// beyond that new lines are dropped and counted.
+type networkWriter struct {
+	network      string
+	address      string
+	tlsConfig    *tls.Config
+	reconnectMin time.Duration
+	reconnectMax time.Duration
+	writeTimeout time.Duration
+	datagram     bool
+	metrics      *logger.Metrics
+	diagnosticf  func(format string, args ...any)
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan []byte
+	done    chan struct{}
+	stopped chan struct{}
+	ctx     context.Context // Base of dials and writes; Close cancels it
+	cancel  context.CancelFunc
+
+	failing atomic.Bool // Delivery failed and has not resumed yet
+
+	// Owned by the run goroutine
+	conn net.Conn
+	dead chan struct{} // Closed when the peer hangs up
+	buf  []byte
+}
+
+func newNetworkWriter(config *logger.NetworkSink, metrics *logger.Metrics, diagnosticf func(string, ...any)) (*networkWriter, error) {
+	network := config.Network
+	if network == "" {
+		network = "tcp"
+	}
+	switch network {
+	case "tcp", "udp", "unix":
+	default:
+		return nil, fmt.Errorf("unsupported network %q (want tcp, udp or unix)", network)
+	}
+	if config.Address == "" {
+		return nil, fmt.Errorf("network sink address is required")
+	}
+	if config.TLS != nil && network != "tcp" {
+		return nil, fmt.Errorf("TLS is only supported over tcp, not %s", network)
+	}
+
+	w := &networkWriter{
+		network:      network,
+		address:      config.Address,
+		tlsConfig:    config.TLS,
+		reconnectMin: config.ReconnectMin,
+		reconnectMax: config.ReconnectMax,
+		writeTimeout: config.WriteTimeout,
+		datagram:     network == "udp",
+		metrics:      metrics,
+		diagnosticf:  diagnosticf,
+		done:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	if w.reconnectMin <= 0 {
+		w.reconnectMin = defaultNetworkReconnectMin
+	}
+	if w.reconnectMax <= 0 {
+		w.reconnectMax = defaultNetworkReconnectMax
+	}
+	if w.reconnectMax < w.reconnectMin {
+		w.reconnectMax = w.reconnectMin
+	}
+	if w.writeTimeout <= 0 {
+		w.writeTimeout = defaultNetworkWriteTimeout
+	}
+	size := config.BufferSize
+	if size <= 0 {
+		size = defaultNetworkBufferSize
+	}
+	w.queue = make(chan []byte, size)
+
+	go w.run()
+	return w, nil
+}
+
+// Write queues one encoded entry without blocking the caller
+func (w *networkWriter) Write(p []byte) (int, error) {
+	line := frameLine(p)
+	if w.datagram && len(line) > networkMaxDatagram {
+		w.drop(1, "too_large")
+		return len(p), nil
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		w.drop(1, "writer_closed")
+		return len(p), nil
+	}
+	select {
+	case w.queue <- line:
+	default:
+		w.drop(1, "buffer_full")
+	}
+	return len(p), nil
+}
+
+// Sync is a no-op: lines are sent asynchronously and Close drains the queue
+func (w *networkWriter) Sync() error {
+	return nil
+}
+
+// frameLine copies p as exactly one line, escaping any newline inside it so a
+// receiver splitting on "\n" never sees a partial entry
+func frameLine(p []byte) []byte {
+	p = bytes.TrimRight(p, "\r\n")
+	line := make([]byte, 0, len(p)+1)
+	for {
+		i := bytes.IndexAny(p, "\r\n")
+		if i < 0 {
+			break
+		}
+		line = append(line, p[:i]...)
+		if p[i] == '\n' {
+			line = append(line, `\n`...)
+		} else {
+			line = append(line, `\r`...)
+		}
+		p = p[i+1:]
+	}
+	line = append(line, p...)
+	return append(line, '\n')
+}
+
+func (w *networkWriter) drop(n int, reason string) {
+	for i := 0; i < n; i++ {
+		w.metrics.RecordLogDropped("network", reason)
+	}
+}
+
+func (w *networkWriter) run() {
+	defer close(w.stopped)
+	defer w.disconnect()
+
+	for {
+		select {
+		case line := <-w.queue:
+			w.deliver(w.batch(line))
+		case <-w.done:
+			// Close stops Write before signalling, so the queue only shrinks from here
+			for {
+				select {
+				case line := <-w.queue:
+					w.deliver(w.batch(line))
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// batch collects lines already queued behind first into one stream write.
+// Datagrams always carry a single line.
+func (w *networkWriter) batch(first []byte) [][]byte {
+	lines := [][]byte{first}
+	if w.datagram {
+		return lines
+	}
+	size := len(first)
+	for size < networkMaxBatch {
+		select {
+		case line := <-w.queue:
+			lines = append(lines, line)
+			size += len(line)
+		default:
+			return lines
+		}
+	}
+	return lines
+}
+
+// deliver reconnects with backoff until every line is written. Once the writer is
+// closing, the remaining lines get one more attempt and are then dropped.
+func (w *networkWriter) deliver(lines [][]byte) {
+	backoff := w.reconnectMin
+	for {
+		n, err := w.write(lines)
+		lines = lines[n:]
+		if err == nil {
+			if w.failing.Load() {
+				w.failing.Store(false)
+				w.diagnosticf("network: delivery to %s resumed", w.address)
+			}
+			return
+		}
+
+		w.disconnect()
+		if !w.failing.Load() {
+			w.failing.Store(true)
+			w.diagnosticf("network: delivery to %s failed, reconnecting: %v", w.address, err)
+		}
+
+		select {
+		case <-w.ctx.Done():
+			w.drop(len(lines), "write_error")
+			return
+		case <-w.done:
+			n, err := w.write(lines)
+			if err != nil {
+				w.disconnect()
+				w.drop(len(lines)-n, "write_error")
+			}
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, w.reconnectMax)
+	}
+}
+
+// write sends lines and reports how many were written completely. A line cut off
+// by a failed stream write is sent again whole on the next connection.
+func (w *networkWriter) write(lines [][]byte) (int, error) {
+	if w.conn == nil {
+		if err := w.connect(); err != nil {
+			return 0, err
+		}
+	}
+	select {
+	case <-w.dead:
+		return 0, errNetworkConnClosed
+	default:
+	}
+
+	// A write blocked on a slow peer ends when Close gives up
+	conn := w.conn
+	stop := context.AfterFunc(w.ctx, func() { _ = conn.SetWriteDeadline(time.Now()) })
+	defer stop()
+
+	if w.datagram {
+		for i, line := range lines {
+			_ = w.conn.SetWriteDeadline(time.Now().Add(w.writeTimeout))
+			if _, err := w.conn.Write(line); err != nil {
+				return i, fmt.Errorf("failed to write: %w", err)
+			}
+		}
+		return len(lines), nil
+	}
+
+	w.buf = w.buf[:0]
+	for _, line := range lines {
+		w.buf = append(w.buf, line...)
+	}
+	_ = w.conn.SetWriteDeadline(time.Now().Add(w.writeTimeout))
+	written, err := w.conn.Write(w.buf)
+	if err == nil {
+		return len(lines), nil
+	}
+
+	n := 0
+	for _, line := range lines {
+		if written < len(line) {
+			break
+		}
+		written -= len(line)
+		n++
+	}
+	return n, fmt.Errorf("failed to write: %w", err)
+}
+
+func (w *networkWriter) connect() error {
+	dialer := &net.Dialer{Timeout: networkDialTimeout}
+	var conn net.Conn
+	var err error
+	if w.tlsConfig != nil {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: w.tlsConfig}
+		conn, err = tlsDialer.DialContext(w.ctx, w.network, w.address)
+	} else {
+		conn, err = dialer.DialContext(w.ctx, w.network, w.address)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+
+	// Receivers never talk back on a JSON-lines stream, so a read returning means
+	// the peer closed the connection. Without this a write into a dead socket can
+	// appear to succeed and the line is lost.
+	dead := make(chan struct{})
+	if !w.datagram {
+		go func() {
+			_, _ = io.Copy(io.Discard, conn)
+			close(dead)
+		}()
+	}
+	w.conn, w.dead = conn, dead
+	return nil
+}
+
+func (w *networkWriter) disconnect() {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn, w.dead = nil, nil
+	}
+}
+
+// healthy reports whether delivery is not failing
+func (w *networkWriter) healthy() bool {
+	return !w.failing.Load()
+}
+
+// Close stops accepting lines and waits for the queue to drain
+func (w *networkWriter) Close() error {
+	return w.CloseContext(context.Background())
+}
+
+// CloseContext is Close bounded by ctx, or by networkCloseTimeout when ctx has
+// no deadline. The dial or write still running then is aborted and the lines
+// left in the queue are dropped.
+func (w *networkWriter) CloseContext(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	w.mu.Unlock()
+	defer w.cancel()
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, networkCloseTimeout)
+		defer cancel()
+	}
+
+	select {
+	case <-w.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to flush network buffer: %w", ctx.Err())
+	}
+}
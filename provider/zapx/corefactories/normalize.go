@@ -0,0 +1,143 @@
+package corefactories
+
+import (
+	"encoding/base64"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NormalizeFields rewrites values some encoders or consumers choke on: NaN and
+// ±Inf become the strings "NaN", "+Inf" and "-Inf", also inside map[string]any
+// and []any values; nil pointers become null; a []byte that isn't valid UTF-8
+// is base64-encoded under the key with a "_b64" suffix, and a valid one is
+// written as a string. fields is only copied when a value changes, since the
+// caller owns the slice.
+func NormalizeFields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i := range fields {
+		f, changed := normalizeField(fields[i])
+		if !changed {
+			continue
+		}
+		if out == nil {
+			out = append([]zapcore.Field(nil), fields...)
+		}
+		out[i] = f
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+func normalizeField(f zapcore.Field) (zapcore.Field, bool) {
+	switch f.Type {
+	case zapcore.Float64Type:
+		if s, ok := nonFinite(math.Float64frombits(uint64(f.Integer))); ok {
+			return zap.String(f.Key, s), true
+		}
+	case zapcore.Float32Type:
+		if s, ok := nonFinite(float64(math.Float32frombits(uint32(f.Integer)))); ok {
+			return zap.String(f.Key, s), true
+		}
+	case zapcore.BinaryType, zapcore.ByteStringType:
+		b, _ := f.Interface.([]byte)
+		switch {
+		case !utf8.Valid(b):
+			return zap.String(f.Key+"_b64", base64.StdEncoding.EncodeToString(b)), true
+		case f.Type == zapcore.BinaryType:
+			return zap.String(f.Key, string(b)), true
+		}
+	case zapcore.ErrorType, zapcore.StringerType, zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType:
+		if isNilPointer(f.Interface) {
+			return zap.Reflect(f.Key, nil), true
+		}
+	case zapcore.ReflectType:
+		if v, changed := normalizeValue(f.Interface); changed {
+			return zap.Reflect(f.Key, v), true
+		}
+	}
+	return f, false
+}
+
+// normalizeValue is normalizeField for the values of a reflected field
+func normalizeValue(v any) (any, bool) {
+	switch x := v.(type) {
+	case float64:
+		if s, ok := nonFinite(x); ok {
+			return s, true
+		}
+	case float32:
+		if s, ok := nonFinite(float64(x)); ok {
+			return s, true
+		}
+	case []float64:
+		if slices.ContainsFunc(x, func(f float64) bool { _, ok := nonFinite(f); return ok }) {
+			out := make([]any, len(x))
+			for i := range x {
+				out[i], _ = normalizeValue(x[i])
+			}
+			return out, true
+		}
+	case map[string]any:
+		var out map[string]any
+		for k, e := range x {
+			if n, changed := normalizeValue(e); changed {
+				if out == nil {
+					out = maps.Clone(x)
+				}
+				out[k] = n
+			}
+		}
+		if out != nil {
+			return out, true
+		}
+	case []any:
+		var out []any
+		for i, e := range x {
+			if n, changed := normalizeValue(e); changed {
+				if out == nil {
+					out = slices.Clone(x)
+				}
+				out[i] = n
+			}
+		}
+		if out != nil {
+			return out, true
+		}
+	default:
+		if isNilPointer(v) {
+			return nil, true
+		}
+	}
+	return v, false
+}
+
+// nonFinite returns the string written for NaN and ±Inf
+func nonFinite(f float64) (string, bool) {
+	switch {
+	case math.IsNaN(f):
+		return "NaN", true
+	case math.IsInf(f, 1):
+		return "+Inf", true
+	case math.IsInf(f, -1):
+		return "-Inf", true
+	}
+	return "", false
+}
+
+// isNilPointer reports whether v is a typed nil pointer, which encoders would
+// otherwise dereference
+func isNilPointer(v any) bool {
+	if v == nil {
+		return false
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
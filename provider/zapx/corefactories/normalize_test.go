@@ -0,0 +1,113 @@
+package corefactories
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type nilStringer struct{}
+
+func (*nilStringer) String() string { return "never called" }
+
+type nilError struct{}
+
+func (*nilError) Error() string { return "never called" }
+
+func TestNormalizeFields(t *testing.T) {
+	var nilInt *int
+	var nilErr *nilError
+	var nilStr *nilStringer
+
+	tests := []struct {
+		name  string
+		field zapcore.Field
+		key   string
+		want  any
+	}{
+		{"nan", zap.Float64("v", math.NaN()), "v", "NaN"},
+		{"pos inf", zap.Float64("v", math.Inf(1)), "v", "+Inf"},
+		{"neg inf", zap.Float32("v", float32(math.Inf(-1))), "v", "-Inf"},
+		{"finite float", zap.Float64("v", 1.5), "v", 1.5},
+		{"nil any", zap.Any("v", nil), "v", nil},
+		{"nil pointer", zap.Any("v", nilInt), "v", nil},
+		{"nil pointer error", zap.NamedError("v", nilErr), "v", nil},
+		{"nil pointer stringer", zap.Stringer("v", nilStr), "v", nil},
+		{"map with nan", zap.Any("v", map[string]any{"x": math.NaN(), "y": 2.0}), "v", map[string]any{"x": "NaN", "y": 2.0}},
+		{"slice with inf", zap.Any("v", []any{math.Inf(1), "ok"}), "v", []any{"+Inf", "ok"}},
+		{"float64 slice with nan", zap.Reflect("v", []float64{1, math.NaN()}), "v", []any{1.0, "NaN"}},
+		{"valid bytes", zap.Binary("v", []byte("hello")), "v", "hello"},
+		{"invalid bytes", zap.Binary("v", []byte{0xff, 0xfe}), "v_b64", "//4="},
+		{"invalid byte string", zap.ByteString("v", []byte{0xc3, 0x28}), "v_b64", "wyg="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := encodeNormalized(t, tt.field)
+			got, ok := doc[tt.key]
+			if !ok {
+				t.Fatalf("Expected key %q in %v", tt.key, doc)
+			}
+			if !jsonEqual(got, tt.want) {
+				t.Errorf("Expected %s=%v, got %v", tt.key, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeFieldsKeepsUnchangedSlice(t *testing.T) {
+	fields := []zapcore.Field{zap.String("a", "b"), zap.Int("n", 1), zap.Duration("d", time.Second)}
+	out := NormalizeFields(fields)
+	if &out[0] != &fields[0] {
+		t.Error("Expected the caller's slice back when nothing changes")
+	}
+
+	fields = append(fields, zap.Float64("f", math.NaN()))
+	out = NormalizeFields(fields)
+	if &out[0] == &fields[0] {
+		t.Error("Expected a copy when a value changes")
+	}
+	if fields[3].Type != zapcore.Float64Type {
+		t.Error("The caller's fields must not be rewritten")
+	}
+}
+
+func TestNormalizeFieldsKeepsNonNilValues(t *testing.T) {
+	err := errors.New("boom")
+	out := NormalizeFields([]zapcore.Field{zap.Error(err)})
+	if out[0].Interface != err {
+		t.Errorf("Expected the error to be kept, got %v", out[0].Interface)
+	}
+}
+
+// encodeNormalized encodes f after NormalizeFields and parses the result
+func encodeNormalized(t *testing.T, f zapcore.Field) map[string]any {
+	t.Helper()
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+	buf, err := enc.EncodeEntry(zapcore.Entry{Message: "m"}, NormalizeFields([]zapcore.Field{f}))
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	defer buf.Free()
+	if !json.Valid(buf.Bytes()) {
+		t.Fatalf("Invalid JSON: %s", buf.String())
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to parse %s: %v", buf.String(), err)
+	}
+	if _, ok := doc["vError"]; ok {
+		t.Fatalf("Unexpected encoding error: %s", buf.String())
+	}
+	return doc
+}
+
+func jsonEqual(a, b any) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
@@ -0,0 +1,119 @@
+package corefactories
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// prettyIndent prefixes each field line under the message
+const prettyIndent = "    "
+
+// prettyEncoder renders the console header line like the console encoder, then
+// one field per line under it: With fields in key order, then call-site fields
+// in order. Maps, slices and structs are indented JSON, multi-line strings such
+// as verbose errors keep their lines, and the stacktrace comes last.
+type prettyEncoder struct {
+	*zapcore.MapObjectEncoder // Fields added by With
+	header                    zapcore.Encoder
+	lineEnding                string
+}
+
+func newPrettyEncoder(encCfg zapcore.EncoderConfig) zapcore.Encoder {
+	lineEnding := encCfg.LineEnding
+	if lineEnding == "" {
+		lineEnding = zapcore.DefaultLineEnding
+	}
+	encCfg.LineEnding = lineEnding
+	return &prettyEncoder{
+		MapObjectEncoder: zapcore.NewMapObjectEncoder(),
+		header:           zapcore.NewConsoleEncoder(encCfg),
+		lineEnding:       lineEnding,
+	}
+}
+
+func (e *prettyEncoder) Clone() zapcore.Encoder {
+	clone := zapcore.NewMapObjectEncoder()
+	for k, v := range e.Fields {
+		clone.Fields[k] = v
+	}
+	return &prettyEncoder{MapObjectEncoder: clone, header: e.header, lineEnding: e.lineEnding}
+}
+
+func (e *prettyEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	stack := ent.Stack
+	ent.Stack = ""
+	buf, err := e.header.EncodeEntry(ent, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		e.writeField(buf, k, e.Fields[k])
+	}
+
+	// A field may add several keys (an error adds errorVerbose), so each gets
+	// its own map, rendered in key order
+	for _, f := range fields {
+		m := zapcore.NewMapObjectEncoder()
+		f.AddTo(m)
+		keys = keys[:0]
+		for k := range m.Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			e.writeField(buf, k, m.Fields[k])
+		}
+	}
+
+	if stack != "" {
+		buf.AppendString(stack)
+		buf.AppendString(e.lineEnding)
+	}
+	return buf, nil
+}
+
+// writeField writes one "key: value" line, indenting the value's further lines
+func (e *prettyEncoder) writeField(buf *buffer.Buffer, key string, val any) {
+	buf.AppendString(prettyIndent)
+	buf.AppendString(key)
+	buf.AppendString(": ")
+	buf.AppendString(strings.ReplaceAll(prettyValue(val), "\n", e.lineEnding+prettyIndent))
+	buf.AppendString(e.lineEnding)
+}
+
+// prettyValue formats scalars as text and composite values as indented JSON,
+// with "\n" between lines
+func prettyValue(val any) string {
+	switch v := val.(type) {
+	case string:
+		// Further lines of e.g. a verbose error sit under the key
+		return strings.ReplaceAll(strings.TrimRight(v, "\n"), "\n", "\n  ")
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case nil:
+		return "null"
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(val)).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if b, err := json.MarshalIndent(val, "", "  "); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(val)
+}
@@ -0,0 +1,77 @@
+package corefactories
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func prettyTestEntry() zapcore.Entry {
+	return zapcore.Entry{
+		Level:   zapcore.ErrorLevel,
+		Time:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message: "checkout failed",
+		Caller:  zapcore.NewEntryCaller(0, "/src/app/checkout.go", 42, true),
+		Stack:   "main.main\n\t/src/main.go:10",
+	}
+}
+
+func prettyTestEncoderConfig() zapcore.EncoderConfig {
+	encCfg := zap.NewDevelopmentEncoderConfig()
+	encCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	encCfg.EncodeLevel = zapcore.LowercaseLevelEncoder
+	return encCfg
+}
+
+func TestPrettyEncoderSnapshot(t *testing.T) {
+	opts := logger.Options{Env: logger.EnvDev, PrettyDev: true}
+	enc := consoleEncoder(prettyTestEncoderConfig(), opts).Clone()
+	enc.AddString("service", "shop") // As bound by With
+
+	buf, err := enc.EncodeEntry(prettyTestEntry(), []zapcore.Field{
+		zap.Any("cart", map[string]any{"items": []string{"book", "pen"}, "total": 12.5}),
+		zap.Error(errors.New("card declined")),
+		zap.Duration("elapsed", 1500*time.Millisecond),
+		zap.String("note", "first line\nsecond line"),
+	})
+	if err != nil {
+		t.Fatalf("EncodeEntry failed: %v", err)
+	}
+
+	want := "2026-01-02T03:04:05.000Z\terror\tapp/checkout.go:42\tcheckout failed\n" +
+		"    service: shop\n" +
+		"    cart: {\n" +
+		"      \"items\": [\n" +
+		"        \"book\",\n" +
+		"        \"pen\"\n" +
+		"      ],\n" +
+		"      \"total\": 12.5\n" +
+		"    }\n" +
+		"    error: card declined\n" +
+		"    elapsed: 1.5s\n" +
+		"    note: first line\n" +
+		"      second line\n" +
+		"main.main\n" +
+		"\t/src/main.go:10\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Unexpected pretty output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrettyDevLeavesProductionJSON(t *testing.T) {
+	opts := logger.Options{Env: logger.EnvProd, PrettyDev: true}
+	buf, err := consoleEncoder(prettyTestEncoderConfig(), opts).EncodeEntry(prettyTestEntry(), []zapcore.Field{
+		zap.Any("cart", map[string]any{"total": 12.5}),
+	})
+	if err != nil {
+		t.Fatalf("EncodeEntry failed: %v", err)
+	}
+	if got := buf.String(); !strings.HasPrefix(got, "{") || strings.Count(got, "\n") != 1 {
+		t.Errorf("Expected one JSON line in production, got %q", got)
+	}
+}
@@ -0,0 +1,153 @@
+package corefactories
+
+import (
+	"encoding/json"
+	"fmt"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultRingCapacity      = 1000
+	defaultRingMaxEntryBytes = 16 << 10
+)
+
+// RingFactory creates cores that keep recent entries in a logger.RingBuffer
+type RingFactory struct{}
+
+func init() {
+	RegisterFactory(&RingFactory{})
+}
+
+// Name returns the unique name of this factory
+func (rf *RingFactory) Name() string {
+	return "ring"
+}
+
+// Enabled determines if the ring buffer should be enabled based on options
+func (rf *RingFactory) Enabled(opts logger.Options) bool {
+	return opts.Ring != nil
+}
+
+// Build creates a ring core; it has its own level so it can keep debug entries
+// that the other sinks filter out
+func (rf *RingFactory) Build(encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, func() error, error) {
+	config := opts.Ring
+	if config.Level != "" {
+		l, err := logger.ParseLevel(string(config.Level))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid ring Level: %w", err)
+		}
+		lvl, _ = zapcore.ParseLevel(string(l))
+	}
+	capacity := config.Capacity
+	if capacity <= 0 {
+		capacity = defaultRingCapacity
+	}
+	maxEntryBytes := config.MaxEntryBytes
+	if maxEntryBytes <= 0 {
+		maxEntryBytes = defaultRingMaxEntryBytes
+	}
+
+	core := &ringCore{
+		LevelEnabler:  lvl,
+		enc:           zapcore.NewJSONEncoder(encCfg),
+		encCfg:        encCfg,
+		maxEntryBytes: maxEntryBytes,
+		ring:          logger.NewRingBuffer(capacity),
+	}
+	return core, nil, nil
+}
+
+// ringCore encodes entries as JSON documents into the ring
+type ringCore struct {
+	zapcore.LevelEnabler
+	enc           zapcore.Encoder
+	encCfg        zapcore.EncoderConfig
+	maxEntryBytes int
+	ring          *logger.RingBuffer
+}
+
+// RingBuffer exposes the buffer so the logger can serve it through logger.RingHandler
+func (c *ringCore) RingBuffer() *logger.RingBuffer {
+	return c.ring
+}
+
+func (c *ringCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(clone.enc)
+	}
+	return &clone
+}
+
+func (c *ringCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *ringCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	data := buf.Bytes()
+	if n := len(data); n > 0 && data[n-1] == '\n' {
+		data = data[:n-1]
+	}
+
+	// Oversized entries are replaced so memory stays within capacity × MaxEntryBytes
+	if len(data) > c.maxEntryBytes {
+		data = c.truncatedStub(ent, len(data))
+	} else {
+		data = append([]byte(nil), data...)
+	}
+	buf.Free()
+
+	c.ring.Add(logger.RingEntry{Time: ent.Time, Level: ringLevel(ent.Level), Data: data})
+	return nil
+}
+
+// truncatedStub keeps the time, level and the start of the message of an entry
+// too large for the ring
+func (c *ringCore) truncatedStub(ent zapcore.Entry, size int) []byte {
+	stub := map[string]any{"ring_truncated": true, "size": size}
+	if c.encCfg.TimeKey != "" {
+		stub[c.encCfg.TimeKey] = ent.Time
+	}
+	if c.encCfg.LevelKey != "" {
+		stub[c.encCfg.LevelKey] = ent.Level.String()
+	}
+	// JSON escaping can grow a string up to 6x, so keep well under the limit
+	msg := ent.Message
+	if budget := c.maxEntryBytes / 8; len(msg) > budget {
+		msg = TruncateString(msg, len(msg)-budget)
+	}
+	if c.encCfg.MessageKey != "" {
+		stub[c.encCfg.MessageKey] = msg
+	}
+	data, _ := json.Marshal(stub)
+	return data
+}
+
+func (c *ringCore) Sync() error {
+	return nil
+}
+
+// ringLevel maps zap levels onto loggerkit's; panic and fatal count as error
+func ringLevel(l zapcore.Level) logger.Level {
+	switch {
+	case l <= zapcore.DebugLevel:
+		return logger.DebugLevel
+	case l == zapcore.InfoLevel:
+		return logger.InfoLevel
+	case l == zapcore.WarnLevel:
+		return logger.WarnLevel
+	default:
+		return logger.ErrorLevel
+	}
+}
@@ -0,0 +1,18 @@
+package corefactories
+
+import "unicode/utf8"
+
+// TruncatedSuffix marks a string shortened by TruncateString
+const TruncatedSuffix = "...(truncated)"
+
+// TruncateString shortens s by at least cut bytes, on a rune boundary, and marks it
+func TruncateString(s string, cut int) string {
+	keep := len(s) - cut - len(TruncatedSuffix)
+	if keep <= 0 {
+		return TruncatedSuffix
+	}
+	for keep > 0 && !utf8.RuneStart(s[keep]) {
+		keep--
+	}
+	return s[:keep] + TruncatedSuffix
+}
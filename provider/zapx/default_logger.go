@@ -18,8 +18,11 @@ func DefaultConfig() *logger.Config {
 
 // NewDefaultLogger khởi một Logger dùng DefaultConfig và panic nếu lỗi
 func NewDefaultLogger() logger.Logger {
-	// Use development defaults for the default logger
-	opts := logger.DefaultDevelopmentOptions()
+	// Same logger as logger.MustNew(DefaultConfig())
+	opts, err := DefaultConfig().Options()
+	if err != nil {
+		panic(fmt.Sprintf("failed to create default logger: %v", err))
+	}
 	log, err := NewWithOptions(opts)
 	if err != nil {
 		panic(fmt.Sprintf("failed to create default logger: %v", err))
@@ -0,0 +1,112 @@
+package elasticx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx/corefactories"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+const (
+	auditRetryMin = 100 * time.Millisecond
+	auditRetryMax = 5 * time.Second
+)
+
+// auditElastic indexes each event with its own request, retrying rejections caused
+// by load until the context is done
+type auditElastic struct {
+	client       esapi.Transport
+	transport    *http.Transport // Non-nil only for a client built here
+	pipeline     string
+	index        *indexRoute
+	indexService string
+	clock        logger.Clock
+}
+
+func newAuditElastic(pattern string, opts logger.Options) (corefactories.AuditOutput, error) {
+	if opts.Elastic == nil {
+		return nil, fmt.Errorf("audit ElasticIndex requires an Elastic sink for the connection")
+	}
+	indexService, err := validateIndexPattern(pattern, opts.Service)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit index: %w", err)
+	}
+	if hasIndexFields(pattern) {
+		return nil, fmt.Errorf("invalid audit index %q: %%{field} tokens are not supported", pattern)
+	}
+
+	es := &auditElastic{
+		client:       opts.Elastic.Client,
+		pipeline:     opts.Elastic.Pipeline,
+		index:        &indexRoute{pattern: pattern},
+		indexService: indexService,
+		clock:        opts.ClockOrDefault(),
+	}
+	if es.client == nil {
+		client, transport, err := newElasticsearchClient(opts.Elastic)
+		if err != nil {
+			return nil, err
+		}
+		es.client, es.transport = client, transport
+	}
+	return es, nil
+}
+
+func (e *auditElastic) Name() string {
+	return "elasticsearch"
+}
+
+func (e *auditElastic) Write(ctx context.Context, doc []byte, t time.Time) error {
+	index := e.index.names.resolve(e.index.pattern, e.indexService, t)
+	backoff := auditRetryMin
+	for {
+		retry, err := e.indexDoc(ctx, index, doc)
+		if err == nil {
+			return nil
+		}
+		if !retry {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-e.clock.After(backoff):
+		}
+		backoff = min(backoff*2, auditRetryMax)
+	}
+}
+
+// indexDoc reports whether a failure is worth retrying: transport errors, 429 and 5xx
+func (e *auditElastic) indexDoc(ctx context.Context, index string, doc []byte) (bool, error) {
+	req := esapi.IndexRequest{
+		Index:    index,
+		Body:     bytes.NewReader(doc),
+		Pipeline: e.pipeline,
+	}
+	res, err := req.Do(ctx, e.client)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer res.Body.Close()
+	if !res.IsError() {
+		return false, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+	err = fmt.Errorf("index request failed: %s: %s", res.Status(), strings.TrimSpace(string(body)))
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500, err
+}
+
+func (e *auditElastic) Close() error {
+	if e.transport != nil {
+		e.transport.CloseIdleConnections()
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package elasticx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// bootstrapTimeout bounds the template/ILM requests made while building the writer
+const bootstrapTimeout = 10 * time.Second
+
+// defaultStartupTimeout bounds the PingOnStartup request when StartupTimeout is unset
+const defaultStartupTimeout = 5 * time.Second
+
+// defaultMappings is an ECS-style mapping for the fields loggerkit emits. Remaining
+// strings become keywords so ad-hoc fields stay aggregatable without exploding into text.
+var defaultMappings = json.RawMessage(`{
+  "dynamic_templates": [
+    {"strings_as_keyword": {"match_mapping_type": "string", "mapping": {"type": "keyword", "ignore_above": 1024}}}
+  ],
+  "properties": {
+    "ts":         {"type": "date"},
+    "level":      {"type": "keyword"},
+    "msg":        {"type": "text"},
+    "logger":     {"type": "keyword"},
+    "caller":     {"type": "keyword"},
+    "stacktrace": {"type": "text", "index": false},
+    "service":    {"type": "keyword"},
+    "error":      {"type": "text"},
+    "trace_id":   {"type": "keyword"},
+    "span_id":    {"type": "keyword"},
+    "request_id": {"type": "keyword"},
+    "user_id":    {"type": "keyword"}
+  }
+}`)
+
+// defaultMappingsFor renames the trace fields of defaultMappings to those
+// configured in keys
+func defaultMappingsFor(keys logger.ContextKeys) (json.RawMessage, error) {
+	traceID, spanID, _ := keys.TraceFieldNames()
+	if traceID == logger.DefaultTraceIDField && spanID == logger.DefaultSpanIDField {
+		return defaultMappings, nil
+	}
+	var m struct {
+		DynamicTemplates json.RawMessage            `json:"dynamic_templates"`
+		Properties       map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(defaultMappings, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode default mappings: %w", err)
+	}
+	keyword := m.Properties[logger.DefaultTraceIDField]
+	delete(m.Properties, logger.DefaultTraceIDField)
+	delete(m.Properties, logger.DefaultSpanIDField)
+	m.Properties[traceID] = keyword
+	m.Properties[spanID] = keyword
+	out, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode default mappings: %w", err)
+	}
+	return out, nil
+}
+
+// pingElasticsearch checks that the cluster answers and accepts our credentials,
+// so a bad address or key fails logger construction instead of dropping logs later
+func pingElasticsearch(client esapi.Transport, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = defaultStartupTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	res, err := esapi.InfoRequest{}.Do(ctx, client)
+	if err != nil {
+		return fmt.Errorf("elasticsearch unreachable at startup: %w", err)
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == 401 || res.StatusCode == 403:
+		return fmt.Errorf("elasticsearch rejected credentials at startup: %s", res.Status())
+	case res.IsError():
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("elasticsearch startup check failed: %s: %s", res.Status(), strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// bootstrapElasticsearch PUTs the ILM policy (if any) and the index template. Both
+// APIs overwrite existing definitions, so repeating the bootstrap is harmless.
+func bootstrapElasticsearch(client esapi.Transport, config *logger.ElasticSink, keys logger.ContextKeys, indexPatterns []string, service string) error {
+	bs := config.Bootstrap
+
+	templateName := bs.TemplateName
+	if templateName == "" {
+		templateName = service + "-logs"
+	}
+	mappings := bs.Mappings
+	if len(mappings) == 0 {
+		var err error
+		if mappings, err = defaultMappingsFor(keys); err != nil {
+			return err
+		}
+	}
+
+	// One template covers the default and every per-level pattern
+	var templatePatterns []string
+	seen := make(map[string]bool, len(indexPatterns))
+	for _, p := range indexPatterns {
+		tp, err := templateIndexPattern(p, service)
+		if err != nil {
+			return err
+		}
+		if !seen[tp] {
+			seen[tp] = true
+			templatePatterns = append(templatePatterns, tp)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), bootstrapTimeout)
+	defer cancel()
+
+	settings := map[string]any{}
+	if len(bs.ILMPolicy) > 0 {
+		policyName := templateName + "-policy"
+		res, err := esapi.ILMPutLifecycleRequest{
+			Policy: policyName,
+			Body:   bytes.NewReader(bs.ILMPolicy),
+		}.Do(ctx, client)
+		if err := checkBootstrapResponse("ILM policy "+policyName, res, err); err != nil {
+			return err
+		}
+		settings["index.lifecycle.name"] = policyName
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"index_patterns": templatePatterns,
+		// Outrank the built-in logs-*-* template (priority 100)
+		"priority": 200,
+		"template": map[string]any{
+			"settings": settings,
+			"mappings": mappings,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode index template: %w", err)
+	}
+
+	res, err := esapi.IndicesPutIndexTemplateRequest{
+		Name: templateName,
+		Body: bytes.NewReader(body),
+	}.Do(ctx, client)
+	return checkBootstrapResponse("index template "+templateName, res, err)
+}
+
+func checkBootstrapResponse(what string, res *esapi.Response, err error) error {
+	if err != nil {
+		return fmt.Errorf("failed to put %s: %w", what, err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("failed to put %s: %s: %s", what, res.Status(), strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// templateIndexPattern turns an index pattern such as "<service>-%Y.%m.%d" into the
+// wildcard matched by the template ("svc-*"). A pattern starting with a
+// placeholder has no literal prefix and would match every index in the cluster.
+func templateIndexPattern(pattern, service string) (string, error) {
+	p := strings.ReplaceAll(pattern, "<service>", service)
+	if i := strings.Index(p, "%"); i >= 0 {
+		p = p[:i]
+	}
+	if p == "" {
+		return "", fmt.Errorf("failed to bootstrap index pattern %q: it must start with a literal prefix or <service>, or the template would match every index", pattern)
+	}
+	return p + "*", nil
+}
@@ -0,0 +1,188 @@
+package elasticx
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx/corefactories"
+	"go.uber.org/zap/zapcore"
+)
+
+// docMeta carries the entry attributes the writer needs to place a document
+type docMeta struct {
+	time  time.Time
+	level zapcore.Level
+}
+
+// docWriter accepts encoded documents; implemented by the writer and its retry wrapper
+type docWriter interface {
+	add(doc []byte, meta docMeta) error
+	reject(doc []byte, reason string) // Drop a document that must not be sent
+}
+
+// elasticCore encodes entries straight into bulk documents. The encoder output is
+// the document, so nothing is parsed or re-marshaled on the way to Elasticsearch.
+type elasticCore struct {
+	zapcore.LevelEnabler
+	enc         zapcore.Encoder
+	enrich      []zapcore.Field // Added at write time unless the entry already has the key
+	policy      fieldPolicy
+	maxDocBytes int             // Encoded size limit (0 = unlimited)
+	bound       int             // Fields already encoded by With
+	overflow    []zapcore.Field // Bound fields beyond MaxDocFields, folded at write time
+	out         docWriter
+}
+
+func newElasticCore(enc zapcore.Encoder, enab zapcore.LevelEnabler, enrich []zapcore.Field, policy fieldPolicy, out docWriter) zapcore.Core {
+	return &elasticCore{LevelEnabler: enab, enc: enc, enrich: enrich, policy: policy, out: out}
+}
+
+func (c *elasticCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	fields = corefactories.NormalizeFields(fields) // Elasticsearch rejects NaN and can't store raw bytes
+	if c.policy.active() {
+		fields = c.policy.apply(fields)
+		var overflow []zapcore.Field
+		fields, overflow = c.policy.split(fields, c.policy.maxFields-c.bound-len(c.enrich))
+		if len(overflow) > 0 {
+			clone.overflow = append(c.overflow[:len(c.overflow):len(c.overflow)], overflow...)
+		}
+	}
+
+	clone.enc = c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(clone.enc)
+	}
+	clone.bound += len(fields)
+	clone.enrich = withoutKeys(c.enrich, fields)
+	return &clone
+}
+
+func (c *elasticCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *elasticCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	fields = corefactories.NormalizeFields(fields)
+	enrich := withoutKeys(c.enrich, fields)
+	overflow := c.overflow
+	if c.policy.active() {
+		fields = c.policy.apply(fields)
+		var extra []zapcore.Field
+		fields, extra = c.policy.split(fields, c.policy.maxFields-c.bound-len(enrich))
+		if len(extra) > 0 {
+			overflow = append(overflow[:len(overflow):len(overflow)], extra...)
+		}
+	}
+
+	all := fields
+	if len(enrich) > 0 || len(overflow) > 0 {
+		all = make([]zapcore.Field, 0, len(fields)+len(enrich)+1)
+		all = append(append(all, fields...), enrich...)
+		if len(overflow) > 0 {
+			all = append(all, c.policy.overflowField(overflow))
+		}
+	}
+
+	doc, err := c.encode(ent, all)
+	if err != nil {
+		return err
+	}
+
+	// Shorten the largest strings of an oversized document; if that is not
+	// enough it would poison the whole bulk request, so it is rejected instead
+	if c.policy.maxDocBytes > 0 && len(doc) > c.policy.maxDocBytes {
+		ent, all = shrinkStrings(ent, all, len(doc)-c.policy.maxDocBytes)
+		if doc, err = c.encode(ent, all); err != nil {
+			return err
+		}
+		if len(doc) > c.policy.maxDocBytes {
+			c.out.reject(doc, "doc_too_large")
+			return nil
+		}
+	}
+
+	return c.out.add(doc, docMeta{time: ent.Time, level: ent.Level})
+}
+
+// encode returns the entry as a standalone document. The bulk indexer holds the
+// body until the flush, so it cannot share the pooled buffer.
+func (c *elasticCore) encode(ent zapcore.Entry, fields []zapcore.Field) ([]byte, error) {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	doc := buf.Bytes()
+	if n := len(doc); n > 0 && doc[n-1] == '\n' {
+		doc = doc[:n-1]
+	}
+	doc = append([]byte(nil), doc...)
+	buf.Free()
+	return doc, nil
+}
+
+func (c *elasticCore) Sync() error {
+	return nil
+}
+
+// Flush sends the documents waiting in the bulk indexer
+func (c *elasticCore) Flush(ctx context.Context) error {
+	if f, ok := c.out.(interface{ flush(context.Context) error }); ok {
+		return f.flush(ctx)
+	}
+	return nil
+}
+
+// Healthy reports whether the last bulk request went through
+func (c *elasticCore) Healthy() bool {
+	if h, ok := c.out.(interface{ healthy() bool }); ok {
+		return h.healthy()
+	}
+	return true
+}
+
+// CloseCounts reports what closing did with the documents still pending
+func (c *elasticCore) CloseCounts() logger.CloseCounts {
+	if cc, ok := c.out.(interface{ closeCounts() logger.CloseCounts }); ok {
+		return cc.closeCounts()
+	}
+	return logger.CloseCounts{}
+}
+
+// IndexerStats reports the counters of the bulk indexers
+func (c *elasticCore) IndexerStats() logger.IndexerStats {
+	if is, ok := c.out.(interface{ indexerStats() logger.IndexerStats }); ok {
+		return is.indexerStats()
+	}
+	return logger.IndexerStats{}
+}
+
+// withoutKeys returns enrich minus the fields whose key appears in fields. The
+// original slice is returned untouched when nothing conflicts.
+func withoutKeys(enrich, fields []zapcore.Field) []zapcore.Field {
+	for i := range enrich {
+		if hasKey(fields, enrich[i].Key) {
+			kept := make([]zapcore.Field, 0, len(enrich)-1)
+			for _, f := range enrich {
+				if !hasKey(fields, f.Key) {
+					kept = append(kept, f)
+				}
+			}
+			return kept
+		}
+	}
+	return enrich
+}
+
+func hasKey(fields []zapcore.Field, key string) bool {
+	for i := range fields {
+		if fields[i].Key == key {
+			return true
+		}
+	}
+	return false
+}
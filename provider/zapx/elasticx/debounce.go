@@ -0,0 +1,53 @@
+package elasticx
+
+import (
+	"sync"
+	"time"
+)
+
+// debouncer runs an action at most once per window: the first trigger runs it
+// immediately, triggers within the window schedule a single trailing run.
+type debouncer struct {
+	window time.Duration
+
+	mu       sync.Mutex
+	last     time.Time
+	trailing *time.Timer
+	stopped  bool
+}
+
+func (d *debouncer) trigger(fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped || d.trailing != nil {
+		return
+	}
+
+	now := time.Now()
+	if wait := d.window - now.Sub(d.last); wait > 0 {
+		d.trailing = time.AfterFunc(wait, func() {
+			d.mu.Lock()
+			d.trailing = nil
+			d.last = time.Now()
+			stopped := d.stopped
+			d.mu.Unlock()
+			if !stopped {
+				fn()
+			}
+		})
+		return
+	}
+	d.last = now
+	fn()
+}
+
+// stop cancels a pending trailing run; later triggers are ignored
+func (d *debouncer) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped = true
+	if d.trailing != nil {
+		d.trailing.Stop()
+		d.trailing = nil
+	}
+}
@@ -0,0 +1,167 @@
+package elasticx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+)
+
+const (
+	// dlqStatInterval is how often the DLQ path is checked for a file that was
+	// deleted or rotated underneath the writer
+	dlqStatInterval = 5 * time.Second
+	// dlqDiagnosticInterval is the least time between two diagnostics lines
+	// about failed DLQ writes
+	dlqDiagnosticInterval = time.Minute
+)
+
+// deadLetterQueue appends entries to the DLQ file. A failed write reopens the
+// file and tries once more; so does a file removed or replaced since it was
+// opened, which is noticed by a periodic stat of the path.
+type deadLetterQueue struct {
+	path        string
+	clock       logger.Clock
+	metrics     *logger.Metrics
+	diagnosticf func(format string, args ...any)
+
+	mu         sync.Mutex
+	file       *os.File // nil after a failed reopen
+	closed     bool
+	lastStat   time.Time
+	failures   uint64 // Entries lost since the last diagnostics line
+	lastReport time.Time
+}
+
+func openDeadLetterQueue(path string, clock logger.Clock, metrics *logger.Metrics, diagnosticf func(string, ...any)) (*deadLetterQueue, error) {
+	file, err := openDLQFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &deadLetterQueue{
+		path:        path,
+		clock:       clock,
+		metrics:     metrics,
+		diagnosticf: diagnosticf,
+		file:        file,
+		lastStat:    clock.Now(),
+	}, nil
+}
+
+func openDLQFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DLQ file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// write appends one entry; detail is Elasticsearch's explanation, if any
+func (q *deadLetterQueue) write(data []byte, reason, detail string) {
+	now := q.clock.Now()
+	entry := map[string]interface{}{
+		"timestamp":    now.UTC().Format(time.RFC3339Nano),
+		"reason":       reason,
+		"original_log": string(data),
+	}
+	if detail != "" {
+		entry["error"] = detail
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return // Can't do much if DLQ serialization fails
+	}
+	line = append(line, '\n')
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+
+	if now.Sub(q.lastStat) >= dlqStatInterval {
+		q.lastStat = now
+		if q.file != nil && q.replaced() {
+			q.reopen()
+		}
+	}
+	if q.file == nil {
+		q.reopen()
+	}
+
+	err = q.append(line)
+	if err != nil && q.file != nil {
+		// The handle may have gone bad underneath; a fresh one may still work
+		q.reopen()
+		err = q.append(line)
+	}
+	if err != nil {
+		q.recordFailure(now, err)
+	}
+}
+
+func (q *deadLetterQueue) append(line []byte) error {
+	if q.file == nil {
+		return errors.New("DLQ file is not open")
+	}
+	if _, err := q.file.Write(line); err != nil {
+		return err
+	}
+	return q.file.Sync() // Force flush to disk
+}
+
+// replaced reports whether the path no longer names the open file
+func (q *deadLetterQueue) replaced() bool {
+	onDisk, err := os.Stat(q.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return true
+	}
+	if err != nil {
+		return false // Leave it to the write to fail
+	}
+	open, err := q.file.Stat()
+	return err != nil || !os.SameFile(onDisk, open)
+}
+
+// reopen replaces the file handle; on failure the next write tries again
+func (q *deadLetterQueue) reopen() {
+	if q.file != nil {
+		_ = q.file.Close()
+		q.file = nil
+	}
+	file, err := openDLQFile(q.path)
+	if err != nil {
+		return
+	}
+	q.file = file
+}
+
+// recordFailure counts an entry lost to the DLQ, reporting through diagnostics
+// at most once per dlqDiagnosticInterval
+func (q *deadLetterQueue) recordFailure(now time.Time, err error) {
+	q.metrics.RecordESDLQWriteFailure()
+	q.failures++
+	if !q.lastReport.IsZero() && now.Sub(q.lastReport) < dlqDiagnosticInterval {
+		return
+	}
+	q.diagnosticf("elasticsearch: failed to write to the DLQ %s, %d entries lost: %v", q.path, q.failures, err)
+	q.failures = 0
+	q.lastReport = now
+}
+
+func (q *deadLetterQueue) close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	if q.file == nil {
+		return nil
+	}
+	err := q.file.Close()
+	q.file = nil
+	return err
+}
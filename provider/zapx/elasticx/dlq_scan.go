@@ -0,0 +1,35 @@
+package elasticx
+
+import (
+	"fmt"
+
+	"github.com/HoangAnhNguyen269/loggerkit/internal/dlqfile"
+)
+
+// DLQEntry is one entry of a DLQ file
+type DLQEntry = dlqfile.Entry
+
+// DLQFilter selects DLQ entries. Zero fields match every entry.
+type DLQFilter = dlqfile.Filter
+
+// ScanDLQFunc calls fn with each entry of the DLQ file at path, in order. The
+// file may be gzip-compressed, as rotated DLQ files often are. Scanning stops
+// at the first error fn returns, which ScanDLQFunc returns as is.
+func ScanDLQFunc(path string, fn func(DLQEntry) error) error {
+	return dlqfile.Scan(path, fn)
+}
+
+// FilterDLQ returns the entries of the DLQ file at path that match opts
+func FilterDLQ(path string, opts DLQFilter) ([]DLQEntry, error) {
+	return dlqfile.Collect(path, opts)
+}
+
+// ScanDLQ prints each entry of the DLQ file at path on its own line
+//
+// Deprecated: use ScanDLQFunc or FilterDLQ
+func ScanDLQ(path string) error {
+	return ScanDLQFunc(path, func(e DLQEntry) error {
+		fmt.Println(e)
+		return nil
+	})
+}
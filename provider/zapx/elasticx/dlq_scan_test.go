@@ -0,0 +1,149 @@
+package elasticx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+)
+
+// writeDLQFile writes entries as DLQ lines to a new file, gzipped if zipped
+func writeDLQFile(t *testing.T, zipped bool, entries ...DLQEntry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			t.Fatalf("Failed to marshal entry: %v", err)
+		}
+		buf.Write(append(line, '\n'))
+	}
+	data := buf.Bytes()
+	name := "dlq.log"
+	if zipped {
+		var gz bytes.Buffer
+		zw := gzip.NewWriter(&gz)
+		_, _ = zw.Write(data)
+		_ = zw.Close()
+		data = gz.Bytes()
+		name += ".1.gz"
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write DLQ file: %v", err)
+	}
+	return path
+}
+
+func TestScanDLQFunc(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	long := `{"level":"info","msg":"` + strings.Repeat("x", 1<<20) + `"}` // Beyond bufio's default 64KiB
+	entries := []DLQEntry{
+		{Timestamp: start, Reason: "index_error_400", Error: "mapper_parsing_exception: bad", OriginalLog: `{"level":"error","msg":"a"}`},
+		{Timestamp: start.Add(time.Minute), Reason: "elasticsearch_unavailable", OriginalLog: long},
+		{Timestamp: start.Add(2 * time.Minute), Reason: "index_error_429", OriginalLog: `{"level":"warn","msg":"c"}`},
+	}
+
+	for _, zipped := range []bool{false, true} {
+		t.Run(fmt.Sprintf("gzip=%v", zipped), func(t *testing.T) {
+			path := writeDLQFile(t, zipped, entries...)
+			var got []DLQEntry
+			if err := ScanDLQFunc(path, func(e DLQEntry) error {
+				got = append(got, e)
+				return nil
+			}); err != nil {
+				t.Fatalf("ScanDLQFunc failed: %v", err)
+			}
+			if len(got) != 3 || got[1].OriginalLog != long || !got[0].Timestamp.Equal(start) || got[0].Error != entries[0].Error {
+				t.Errorf("Expected the entries back, got %d", len(got))
+			}
+		})
+	}
+
+	// fn's error stops the scan and is returned as is
+	stop := errors.New("stop")
+	calls := 0
+	err := ScanDLQFunc(writeDLQFile(t, false, entries...), func(DLQEntry) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("Expected the scan to stop with fn's error after 1 call, got %v after %d", err, calls)
+	}
+
+	broken := filepath.Join(t.TempDir(), "broken.log")
+	_ = os.WriteFile(broken, []byte("{\"reason\":\"x\"}\n{\"reason\":\n"), 0644)
+	if err := ScanDLQFunc(broken, func(DLQEntry) error { return nil }); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected the malformed line to be reported, got %v", err)
+	}
+}
+
+func TestFilterDLQ(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	path := writeDLQFile(t, true,
+		DLQEntry{Timestamp: start, Reason: "index_error_400", OriginalLog: `{"level":"error","msg":"a"}`},
+		DLQEntry{Timestamp: start.Add(time.Minute), Reason: "elasticsearch_unavailable", OriginalLog: `{"level":"info","msg":"b"}`},
+		DLQEntry{Timestamp: start.Add(2 * time.Minute), Reason: "index_error_429", OriginalLog: `{"level":"warn","msg":"c"}`},
+		DLQEntry{Timestamp: start.Add(3 * time.Minute), Reason: "index_error_400", OriginalLog: `not json`},
+	)
+
+	tests := []struct {
+		name   string
+		filter DLQFilter
+		want   []string // Messages, or the raw log
+	}{
+		{"all", DLQFilter{}, []string{"a", "b", "c", "not json"}},
+		{"reason prefix", DLQFilter{Reason: "index_error"}, []string{"a", "c", "not json"}},
+		{"exact reason", DLQFilter{Reason: "index_error_429"}, []string{"c"}},
+		{"since until", DLQFilter{Since: start.Add(time.Minute), Until: start.Add(3 * time.Minute)}, []string{"b", "c"}},
+		{"level", DLQFilter{Level: logger.WarnLevel}, []string{"a", "c"}},
+		{"combined", DLQFilter{Reason: "index_error", Level: "warning", Since: start.Add(time.Second)}, []string{"c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FilterDLQ(path, tt.filter)
+			if err != nil {
+				t.Fatalf("FilterDLQ failed: %v", err)
+			}
+			var msgs []string
+			for _, e := range got {
+				var doc struct{ Msg string }
+				if json.Unmarshal([]byte(e.OriginalLog), &doc) != nil {
+					doc.Msg = e.OriginalLog
+				}
+				msgs = append(msgs, doc.Msg)
+			}
+			if fmt.Sprint(msgs) != fmt.Sprint(tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, msgs)
+			}
+		})
+	}
+
+	if _, err := FilterDLQ(path, DLQFilter{Level: "loud"}); err == nil {
+		t.Error("Expected an unknown level to be rejected")
+	}
+	if _, err := FilterDLQ(filepath.Join(t.TempDir(), "missing.log"), DLQFilter{}); err == nil {
+		t.Error("Expected a missing file to fail")
+	}
+}
+
+func TestDLQEntryString(t *testing.T) {
+	e := DLQEntry{
+		Timestamp:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Reason:      "index_error_400",
+		Error:       "mapper_parsing_exception: bad",
+		OriginalLog: `{"msg":"a"}`,
+	}
+	want := `2026-01-01T00:00:00Z index_error_400 (mapper_parsing_exception: bad) {"msg":"a"}`
+	if got := e.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
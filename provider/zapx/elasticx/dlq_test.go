@@ -0,0 +1,124 @@
+package elasticx
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// captureDLQDiagnostics collects the diagnostics lines of w's DLQ
+func captureDLQDiagnostics(w *elasticsearchWriter) func() []string {
+	var mu sync.Mutex
+	var lines []string
+	w.dlq.diagnosticf = func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), lines...)
+	}
+}
+
+func TestDLQReopensClosedHandle(t *testing.T) {
+	w, dlq := newMockElasticWriter(t, &mockIndexer{failStatus: 400}, logger.ElasticSink{})
+	defer w.Close()
+	before := promtestutil.ToFloat64(logger.GetMetrics().ESDLQWriteFailures)
+
+	// The handle goes bad underneath the writer
+	_ = w.dlq.file.Close()
+	if err := w.add([]byte(`{"msg":"after close"}`), testDocMeta); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, dlq)
+	if len(entries) != 1 || entries[0]["original_log"] != `{"msg":"after close"}` {
+		t.Errorf("Expected the entry written through a reopened file, got %v", entries)
+	}
+	if got := promtestutil.ToFloat64(logger.GetMetrics().ESDLQWriteFailures) - before; got != 0 {
+		t.Errorf("Expected no lost DLQ entries, got %v", got)
+	}
+}
+
+func TestDLQWriteFailuresReported(t *testing.T) {
+	w, dlq := newMockElasticWriter(t, &mockIndexer{failStatus: 400}, logger.ElasticSink{})
+	defer w.Close()
+	diagnostics := captureDLQDiagnostics(w)
+	clock := w.clock.(*testutil.FakeClock)
+	before := promtestutil.ToFloat64(logger.GetMetrics().ESDLQWriteFailures)
+
+	// Nothing can be opened at the path while a directory sits there
+	_ = w.dlq.file.Close()
+	if err := os.Remove(dlq); err != nil {
+		t.Fatalf("Failed to remove DLQ: %v", err)
+	}
+	if err := os.Mkdir(dlq, 0o755); err != nil {
+		t.Fatalf("Failed to block the DLQ path: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		_ = w.add([]byte(fmt.Sprintf(`{"msg":"lost %d"}`, i)), testDocMeta)
+	}
+	if got := promtestutil.ToFloat64(logger.GetMetrics().ESDLQWriteFailures) - before; got != 3 {
+		t.Errorf("Expected 3 lost DLQ entries in es_dlq_write_failures_total, got %v", got)
+	}
+	if lines := diagnostics(); len(lines) != 1 {
+		t.Errorf("Expected one rate-limited diagnostics line, got %q", lines)
+	}
+
+	// The next write after the path is usable again reopens the file
+	if err := os.Remove(dlq); err != nil {
+		t.Fatalf("Failed to unblock the DLQ path: %v", err)
+	}
+	clock.Advance(dlqDiagnosticInterval)
+	_ = w.add([]byte(`{"msg":"recovered"}`), testDocMeta)
+	entries := testutil.ReadJSONLines(t, dlq)
+	if len(entries) != 1 || entries[0]["original_log"] != `{"msg":"recovered"}` {
+		t.Errorf("Expected the DLQ to recover once writable, got %v", entries)
+	}
+	if got := promtestutil.ToFloat64(logger.GetMetrics().ESDLQWriteFailures) - before; got != 3 {
+		t.Errorf("Expected no further losses after recovery, got %v", got)
+	}
+}
+
+func TestDLQReopensDeletedFile(t *testing.T) {
+	w, dlq := newMockElasticWriter(t, &mockIndexer{failStatus: 400}, logger.ElasticSink{})
+	defer w.Close()
+	clock := w.clock.(*testutil.FakeClock)
+
+	_ = w.add([]byte(`{"msg":"before rotation"}`), testDocMeta)
+	if err := os.Rename(dlq, dlq+".1"); err != nil {
+		t.Fatalf("Failed to rotate DLQ: %v", err)
+	}
+
+	clock.Advance(dlqStatInterval)
+	_ = w.add([]byte(`{"msg":"after rotation"}`), testDocMeta)
+
+	if rotated := testutil.ReadJSONLines(t, dlq+".1"); len(rotated) != 1 {
+		t.Errorf("Expected only the first entry in the rotated file, got %v", rotated)
+	}
+	entries := testutil.ReadJSONLines(t, dlq)
+	if len(entries) != 1 || entries[0]["original_log"] != `{"msg":"after rotation"}` {
+		t.Errorf("Expected a new DLQ file at the path after rotation, got %v", entries)
+	}
+}
+
+func TestDLQNotReopenedAfterClose(t *testing.T) {
+	w, dlq := newMockElasticWriter(t, &mockIndexer{failStatus: 400}, logger.ElasticSink{})
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := os.Remove(dlq); err != nil {
+		t.Fatalf("Failed to remove DLQ: %v", err)
+	}
+	w.writeToDLQ([]byte(`{"msg":"late"}`), "writer_closed")
+	if _, err := os.Stat(dlq); !os.IsNotExist(err) {
+		t.Errorf("Expected a closed DLQ to stay closed, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Package elasticx ships logs to Elasticsearch with the bulk API. Import it for
+// its side effect to enable Options.Elastic and AuditSink.ElasticIndex:
+//
+//	import _ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/elasticx"
+//
+// It lives in its own package so that binaries which don't log to
+// Elasticsearch don't link go-elasticsearch.
+package elasticx
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx/corefactories"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Factory creates Elasticsearch-based cores for logging output
+type Factory struct{}
+
+func init() {
+	corefactories.RegisterFactory(&Factory{})
+	corefactories.RegisterElasticAudit(newAuditElastic)
+}
+
+// Name returns the unique name of this factory
+func (f *Factory) Name() string {
+	return "elasticsearch"
+}
+
+// Enabled determines if Elasticsearch logging should be enabled based on options
+func (f *Factory) Enabled(opts logger.Options) bool {
+	return opts.Elastic != nil
+}
+
+// Build creates an Elasticsearch core with bulk indexing and DLQ support
+func (f *Factory) Build(encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, func() error, error) {
+	core, closer, err := f.BuildContext(encCfg, lvl, metrics, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return core, func() error { return closer(context.Background()) }, nil
+}
+
+// BuildContext is Build with a closer that flushes until the context is done
+func (f *Factory) BuildContext(encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, corefactories.CloseFunc, error) {
+	esCfg := opts.Elastic
+
+	// Create the Elasticsearch bulk writer
+	esWriter, err := newElasticsearchWriter(opts, metrics)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create elasticsearch writer: %w", err)
+	}
+
+	var docs docWriter = esWriter
+	if esCfg != nil && esCfg.Retry.Max > 0 {
+		docs = newRetryableWriter(esWriter, esCfg.Retry, metrics)
+	}
+
+	// Documents are enriched at encode time, so the encoded entry goes to the bulk
+	// indexer as-is
+	policy := fieldPolicy{maxDocBytes: maxDocBytes(esCfg), metrics: metrics}
+	if esCfg != nil {
+		policy.deDot, policy.maxFields = esCfg.DeDotKeys, esCfg.MaxDocFields
+	}
+	core := newElasticCore(zapcore.NewJSONEncoder(encCfg), lvl, elasticEnrichment(opts), policy, docs)
+
+	return core, esWriter.CloseContext, nil
+}
+
+// defaultMaxDocBytes keeps single documents well below http.max_content_length
+const defaultMaxDocBytes = 1 << 20
+
+// maxDocBytes resolves ElasticSink.MaxDocBytes: 0 means the default, negative disables
+func maxDocBytes(config *logger.ElasticSink) int {
+	switch {
+	case config == nil || config.MaxDocBytes == 0:
+		return defaultMaxDocBytes
+	case config.MaxDocBytes < 0:
+		return 0
+	}
+	return config.MaxDocBytes
+}
+
+// elasticEnrichment returns the fields added to every document: service, env and
+// ElasticSink.StaticFields, which may override env but not service. Sorted for a
+// stable layout.
+func elasticEnrichment(opts logger.Options) []zapcore.Field {
+	static := make(map[string]string)
+	if opts.Env != "" {
+		static["env"] = string(opts.Env)
+	}
+	if opts.Elastic != nil {
+		for k, v := range opts.Elastic.StaticFields {
+			static[k] = v
+		}
+	}
+	delete(static, "service")
+
+	keys := make([]string, 0, len(static))
+	for k := range static {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	enrich := make([]zapcore.Field, 0, len(keys)+1)
+	enrich = append(enrich, zap.String("service", opts.Service))
+	for _, k := range keys {
+		enrich = append(enrich, zap.String(k, static[k]))
+	}
+	return enrich
+}
@@ -0,0 +1,43 @@
+package elasticx
+
+import (
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+)
+
+func TestFactoryEnabled(t *testing.T) {
+	factory := &Factory{}
+
+	testCases := []struct {
+		name     string
+		opts     logger.Options
+		expected bool
+	}{
+		{
+			name: "No elastic config",
+			opts: logger.Options{
+				Elastic: nil,
+			},
+			expected: false,
+		},
+		{
+			name: "With elastic config",
+			opts: logger.Options{
+				Elastic: &logger.ElasticSink{
+					Addresses: []string{"http://localhost:9200"},
+				},
+			},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			enabled := factory.Enabled(tc.opts)
+			if enabled != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, enabled)
+			}
+		})
+	}
+}
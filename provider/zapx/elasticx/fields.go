@@ -0,0 +1,188 @@
+package elasticx
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx/corefactories"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// overflowKey holds the fields beyond ElasticSink.MaxDocFields, as a JSON string
+const overflowKey = "fields_overflow"
+
+// fieldPolicy shapes document keys before encoding so dynamic mappings stay sane:
+// dots are replaced (DeDotKeys) and field counts are capped (MaxDocFields). Both
+// apply to top-level fields and one level deep into F.Any maps. maxDocBytes
+// limits the encoded size (0 = unlimited).
+type fieldPolicy struct {
+	deDot       bool
+	maxFields   int
+	maxDocBytes int
+	metrics     *logger.Metrics
+}
+
+func (p fieldPolicy) active() bool {
+	return p.deDot || p.maxFields > 0
+}
+
+// apply returns fields with keys de-dotted and maps capped. The caller's slice is
+// copied before the first change and never modified.
+func (p fieldPolicy) apply(fields []zapcore.Field) []zapcore.Field {
+	out := fields
+	copied := false
+	for i := range fields {
+		f, changed := p.applyOne(fields[i])
+		if !changed {
+			continue
+		}
+		if !copied {
+			out = append([]zapcore.Field(nil), fields...)
+			copied = true
+		}
+		out[i] = f
+	}
+	return out
+}
+
+func (p fieldPolicy) applyOne(f zapcore.Field) (zapcore.Field, bool) {
+	changed := false
+	if p.deDot {
+		if key := deDot(f.Key); key != f.Key {
+			f.Key, changed = key, true
+		}
+	}
+	if f.Type != zapcore.ReflectType || f.Interface == nil {
+		return f, changed
+	}
+
+	v := reflect.ValueOf(f.Interface)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return f, changed
+	}
+	if !p.mapNeedsRewrite(v) {
+		return f, changed
+	}
+
+	keys := make([]string, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+
+	m := make(map[string]any, len(keys))
+	var overflow map[string]any
+	for i, k := range keys {
+		val := v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key())).Interface()
+		if p.maxFields > 0 && i >= p.maxFields {
+			if overflow == nil {
+				overflow = make(map[string]any, len(keys)-i)
+			}
+			overflow[k] = val
+			continue
+		}
+		if p.deDot {
+			k = deDot(k)
+		}
+		m[k] = val
+	}
+	if overflow != nil {
+		m[overflowKey] = marshalOverflow(overflow)
+		p.metrics.RecordESFieldsOverflow()
+	}
+	f.Interface = m
+	return f, true
+}
+
+func (p fieldPolicy) mapNeedsRewrite(v reflect.Value) bool {
+	if p.maxFields > 0 && v.Len() > p.maxFields {
+		return true
+	}
+	if p.deDot {
+		for _, k := range v.MapKeys() {
+			if strings.Contains(k.String(), ".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// split keeps the first budget fields and returns the rest as overflow
+func (p fieldPolicy) split(fields []zapcore.Field, budget int) (kept, overflow []zapcore.Field) {
+	if p.maxFields <= 0 || len(fields) <= budget {
+		return fields, nil
+	}
+	if budget < 0 {
+		budget = 0
+	}
+	return fields[:budget:budget], fields[budget:]
+}
+
+// overflowField folds fields into a single string field, so they are kept in the
+// document without adding a mapping per key
+func (p fieldPolicy) overflowField(fields []zapcore.Field) zapcore.Field {
+	enc := zapcore.NewMapObjectEncoder()
+	for i := range fields {
+		fields[i].AddTo(enc)
+	}
+	p.metrics.RecordESFieldsOverflow()
+	return zap.String(overflowKey, marshalOverflow(enc.Fields))
+}
+
+func marshalOverflow(m map[string]any) string {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Sprint(m)
+	}
+	return string(b)
+}
+
+func deDot(key string) string {
+	if !strings.Contains(key, ".") {
+		return key
+	}
+	return strings.ReplaceAll(key, ".", "_")
+}
+
+// shrinkStrings truncates the message and the largest string fields, biggest
+// first, until about excess bytes are saved. Bound fields are already encoded and
+// stay untouched. fields is copied before any change.
+func shrinkStrings(ent zapcore.Entry, fields []zapcore.Field, excess int) (zapcore.Entry, []zapcore.Field) {
+	const message = -1
+	candidates := []int{message}
+	for i := range fields {
+		if fields[i].Type == zapcore.StringType {
+			candidates = append(candidates, i)
+		}
+	}
+	size := func(i int) int {
+		if i == message {
+			return len(ent.Message)
+		}
+		return len(fields[i].String)
+	}
+	sort.SliceStable(candidates, func(a, b int) bool { return size(candidates[a]) > size(candidates[b]) })
+
+	fields = append([]zapcore.Field(nil), fields...)
+	for _, i := range candidates {
+		if excess <= 0 || size(i) <= len(corefactories.TruncatedSuffix) {
+			break
+		}
+		if i == message {
+			short := corefactories.TruncateString(ent.Message, excess)
+			excess -= len(ent.Message) - len(short)
+			ent.Message = short
+			continue
+		}
+		short := corefactories.TruncateString(fields[i].String, excess)
+		excess -= len(fields[i].String) - len(short)
+		fields[i].String = short
+	}
+	return ent, fields
+}
@@ -0,0 +1,211 @@
+package elasticx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap/zapcore"
+)
+
+// indexNameCache memoizes the resolved index name for the most recent UTC hour, the
+// finest granularity a placeholder can have, so substitution only runs when it
+// changes. The hot path is a single atomic load.
+type indexNameCache struct {
+	current atomic.Pointer[resolvedIndex]
+}
+
+// resolvedIndex is the index name for one UTC hour
+type resolvedIndex struct {
+	hour int64 // Unix hour of name
+	name string
+}
+
+func (c *indexNameCache) resolve(pattern, service string, t time.Time) string {
+	hour := t.Unix() / 3600
+	if r := c.current.Load(); r != nil && r.hour == hour {
+		return r.name
+	}
+	// Concurrent writers at a rollover may both generate; the names are equal
+	r := &resolvedIndex{hour: hour, name: generateIndexName(pattern, service, t)}
+	c.current.Store(r)
+	return r.name
+}
+
+// indexRoute is an index pattern together with its resolved-name cache
+type indexRoute struct {
+	pattern string
+	fields  bool // The pattern has %{field} tokens, substituted per document
+	names   indexNameCache
+}
+
+// levelRoute maps an entry level to its IndexByLevel key. Levels above error
+// (dpanic, panic, fatal) share the error override.
+func levelRoute(l zapcore.Level) logger.Level {
+	switch {
+	case l <= zapcore.DebugLevel:
+		return logger.DebugLevel
+	case l == zapcore.InfoLevel:
+		return logger.InfoLevel
+	case l == zapcore.WarnLevel:
+		return logger.WarnLevel
+	default:
+		return logger.ErrorLevel
+	}
+}
+
+// indexPlaceholders are the % sequences accepted in ElasticSink.Index, formatted in UTC
+var indexPlaceholders = map[byte]func(t time.Time) string{
+	'Y': func(t time.Time) string { return fmt.Sprintf("%04d", t.Year()) },
+	'm': func(t time.Time) string { return fmt.Sprintf("%02d", t.Month()) },
+	'd': func(t time.Time) string { return fmt.Sprintf("%02d", t.Day()) },
+	'H': func(t time.Time) string { return fmt.Sprintf("%02d", t.Hour()) },
+	'j': func(t time.Time) string { return fmt.Sprintf("%03d", t.YearDay()) },
+	'W': func(t time.Time) string {
+		_, week := t.ISOWeek()
+		return fmt.Sprintf("%02d", week)
+	},
+	'G': func(t time.Time) string {
+		year, _ := t.ISOWeek()
+		return fmt.Sprintf("%04d", year)
+	},
+}
+
+// indexIllegalChars are the characters Elasticsearch rejects in index names
+const indexIllegalChars = `\/*?"<>| ,#:`
+
+// maxIndexFieldLen caps a substituted %{field} value; index names are limited to 255 bytes
+const maxIndexFieldLen = 64
+
+// indexNamePart is s as substituted into an index name for <service> or a
+// %{field} token: lowercased, with the characters Elasticsearch rejects replaced
+// by '-' and no leading '-', '_' or '+'. Entries keep the values as logged.
+func indexNamePart(s string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(indexIllegalChars, r) {
+			return '-'
+		}
+		return r
+	}, strings.ToLower(s))
+	return strings.TrimLeft(name, "-_+")
+}
+
+// validateIndexPattern checks the pattern at construction time so a typo fails
+// fast instead of on every bulk request. It returns the service name to
+// substitute for <service>, see indexNamePart.
+func validateIndexPattern(pattern, service string) (string, error) {
+	indexService := indexNamePart(service)
+
+	seen := map[byte]bool{}
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' {
+			continue
+		}
+		if i+1 >= len(pattern) {
+			return "", fmt.Errorf("invalid elasticsearch index pattern %q: dangling %%", pattern)
+		}
+		if pattern[i+1] == '{' {
+			end := strings.IndexByte(pattern[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("invalid elasticsearch index pattern %q: unterminated %%{", pattern)
+			}
+			if field := pattern[i+2 : i+end]; field == "" || strings.ContainsAny(field, "%{") {
+				return "", fmt.Errorf("invalid elasticsearch index pattern %q: invalid field token %q", pattern, pattern[i:i+end+1])
+			}
+			i += end
+			continue
+		}
+		if _, ok := indexPlaceholders[pattern[i+1]]; !ok {
+			return "", fmt.Errorf("invalid elasticsearch index pattern %q: unknown placeholder %q", pattern, pattern[i:i+2])
+		}
+		seen[pattern[i+1]] = true
+		i++
+	}
+	// Around New Year the ISO week belongs to the previous or next year
+	if seen['W'] && seen['Y'] {
+		return "", fmt.Errorf("invalid elasticsearch index pattern %q: %%W is an ISO week, pair it with the ISO year %%G instead of %%Y", pattern)
+	}
+
+	// Check the literal parts with a sample time and field value, placeholders
+	// only produce digits and field values are sanitized
+	name := substituteIndexFields(generateIndexName(pattern, indexService, time.Unix(0, 0)), nil, "x")
+	if name != strings.ToLower(name) {
+		return "", fmt.Errorf("invalid elasticsearch index pattern %q: index names must be lowercase", pattern)
+	}
+	if i := strings.IndexAny(name, indexIllegalChars); i >= 0 {
+		return "", fmt.Errorf("invalid elasticsearch index pattern %q: illegal character %q", pattern, name[i])
+	}
+	if name == "" || strings.IndexAny(name[:1], "-_+") == 0 {
+		return "", fmt.Errorf("invalid elasticsearch index pattern %q: index names must not be empty or start with '-', '_' or '+'", pattern)
+	}
+	return indexService, nil
+}
+
+// hasIndexFields reports whether a validated pattern has %{field} tokens
+func hasIndexFields(pattern string) bool {
+	return strings.Contains(pattern, "%{")
+}
+
+// substituteIndexFields replaces the %{field} tokens generateIndexName leaves in
+// name with the top-level fields of doc. Strings, numbers and booleans are
+// substituted through indexNamePart, capped at maxIndexFieldLen; missing,
+// null, nested or empty values become fallback.
+func substituteIndexFields(name string, doc map[string]any, fallback string) string {
+	if !hasIndexFields(name) {
+		return name
+	}
+	var b strings.Builder
+	for {
+		start := strings.Index(name, "%{")
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(name[start:], '}')
+		if end < 0 {
+			break
+		}
+		b.WriteString(name[:start])
+
+		var value string
+		switch v := doc[name[start+2:start+end]].(type) {
+		case string:
+			value = v
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			value = strconv.FormatBool(v)
+		}
+		if len(value) > maxIndexFieldLen {
+			value = strings.ToValidUTF8(value[:maxIndexFieldLen], "")
+		}
+		if value = indexNamePart(value); value == "" {
+			value = fallback
+		}
+		b.WriteString(value)
+		name = name[start+end+1:]
+	}
+	b.WriteString(name)
+	return b.String()
+}
+
+func generateIndexName(pattern, service string, t time.Time) string {
+	t = t.UTC()
+	pattern = strings.ReplaceAll(pattern, "<service>", service)
+
+	var b strings.Builder
+	b.Grow(len(pattern) + 8)
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '%' && i+1 < len(pattern) {
+			if format, ok := indexPlaceholders[pattern[i+1]]; ok {
+				b.WriteString(format(t))
+				i++
+				continue
+			}
+		}
+		b.WriteByte(pattern[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,187 @@
+package elasticx
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	"github.com/elastic/go-elasticsearch/v8/esutil"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap/zapcore"
+)
+
+// mockIndexer stands in for esutil.BulkIndexer so the writer's failure paths run
+// without a server or bulk timing
+type mockIndexer struct {
+	mu         sync.Mutex
+	addErrors  []error // Returned by successive Adds, then nil
+	failStatus int     // Accepted items fail with this status when non-zero
+	adds       int
+	closed     bool
+}
+
+func (m *mockIndexer) Add(ctx context.Context, item esutil.BulkIndexerItem) error {
+	m.mu.Lock()
+	n := m.adds
+	m.adds++
+	m.mu.Unlock()
+
+	if n < len(m.addErrors) && m.addErrors[n] != nil {
+		return m.addErrors[n]
+	}
+	if m.failStatus != 0 {
+		if item.OnFailure != nil {
+			res := esutil.BulkIndexerResponseItem{Status: m.failStatus}
+			res.Error.Type = "mapper_parsing_exception"
+			res.Error.Reason = "mock rejection"
+			item.OnFailure(ctx, item, res, nil)
+		}
+		return nil
+	}
+	if item.OnSuccess != nil {
+		item.OnSuccess(ctx, item, esutil.BulkIndexerResponseItem{Status: 201})
+	}
+	return nil
+}
+
+func (m *mockIndexer) Close(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+func (m *mockIndexer) Stats() esutil.BulkIndexerStats {
+	return esutil.BulkIndexerStats{}
+}
+
+func (m *mockIndexer) addCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.adds
+}
+
+// newMockElasticWriter builds a writer whose bulk indexers are all idx
+func newMockElasticWriter(t *testing.T, idx *mockIndexer, sink logger.ElasticSink) (*elasticsearchWriter, string) {
+	t.Helper()
+	dlq := filepath.Join(t.TempDir(), "dlq.log")
+	sink.DLQPath = dlq
+	opts := logger.DefaultProductionOptions()
+	opts.Service = "svc"
+	opts.Elastic = &sink
+	opts.Diagnostics = &testutil.SafeBuffer{}
+	opts.Clock = testutil.NewFakeClock(time.Date(2026, 3, 9, 14, 0, 0, 0, time.UTC))
+
+	w, err := newElasticsearchWriterWithIndexer(opts, logger.GetMetrics(), func() (esutil.BulkIndexer, error) {
+		return idx, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to create elasticsearch writer: %v", err)
+	}
+	return w, dlq
+}
+
+var testDocMeta = docMeta{level: zapcore.InfoLevel, time: time.Date(2026, 3, 9, 14, 0, 0, 0, time.UTC)}
+
+func TestElasticItemFailureToDLQ(t *testing.T) {
+	idx := &mockIndexer{failStatus: 400}
+	w, dlq := newMockElasticWriter(t, idx, logger.ElasticSink{})
+	dropped := logger.GetMetrics().LogsDropped.WithLabelValues("elasticsearch", "index_failure")
+	before := promtestutil.ToFloat64(dropped)
+
+	if err := w.add([]byte(`{"msg":"rejected"}`), testDocMeta); err != nil {
+		t.Fatalf("Expected the item failure to surface through the DLQ, not add: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, dlq)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 DLQ entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e["reason"] != "index_error_400" || e["original_log"] != `{"msg":"rejected"}` {
+		t.Errorf("Unexpected DLQ entry %v", e)
+	}
+	if e["error"] != "mapper_parsing_exception: mock rejection" {
+		t.Errorf("Expected the rejection reason in the DLQ, got %v", e["error"])
+	}
+	if e["timestamp"] != "2026-03-09T14:00:00Z" {
+		t.Errorf("Expected the DLQ timestamp from the clock, got %v", e["timestamp"])
+	}
+	if got := promtestutil.ToFloat64(dropped) - before; got != 1 {
+		t.Errorf("Expected 1 index_failure drop, got %v", got)
+	}
+}
+
+func TestElasticRetriesExhaustedToDLQ(t *testing.T) {
+	addErr := errors.New("indexer full")
+	idx := &mockIndexer{addErrors: []error{addErr, addErr, addErr}}
+	sink := logger.ElasticSink{Retry: logger.Retry{Max: 2}} // Zero backoff
+	w, dlq := newMockElasticWriter(t, idx, sink)
+	rw := newRetryableWriter(w, sink.Retry, logger.GetMetrics())
+	dropped := logger.GetMetrics().LogsDropped.WithLabelValues("elasticsearch", "retries_exhausted")
+	before := promtestutil.ToFloat64(dropped)
+
+	if err := rw.add([]byte(`{"msg":"lost"}`), testDocMeta); !errors.Is(err, addErr) {
+		t.Errorf("Expected the last add error, got %v", err)
+	}
+	if got := idx.addCount(); got != 3 {
+		t.Errorf("Expected 1 attempt and 2 retries, got %d adds", got)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, dlq)
+	if len(entries) != 1 || entries[0]["reason"] != "retries_exhausted" || entries[0]["original_log"] != `{"msg":"lost"}` {
+		t.Errorf("Expected one retries_exhausted DLQ entry, got %v", entries)
+	}
+	if got := promtestutil.ToFloat64(dropped) - before; got != 1 {
+		t.Errorf("Expected 1 retries_exhausted drop, got %v", got)
+	}
+
+	// A retry that succeeds writes nothing to the DLQ
+	idx2 := &mockIndexer{addErrors: []error{addErr}}
+	w2, dlq2 := newMockElasticWriter(t, idx2, sink)
+	if err := newRetryableWriter(w2, sink.Retry, nil).add([]byte(`{"msg":"saved"}`), testDocMeta); err != nil {
+		t.Errorf("Expected the retry to succeed, got %v", err)
+	}
+	w2.Close()
+	if entries := testutil.ReadJSONLines(t, dlq2); len(entries) != 0 {
+		t.Errorf("Expected an empty DLQ, got %v", entries)
+	}
+}
+
+func TestElasticWriterClosedRejects(t *testing.T) {
+	idx := &mockIndexer{}
+	w, dlq := newMockElasticWriter(t, idx, logger.ElasticSink{})
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !idx.closed {
+		t.Error("Expected Close to close the indexer")
+	}
+	dropped := logger.GetMetrics().LogsDropped.WithLabelValues("elasticsearch", "writer_closed")
+	before := promtestutil.ToFloat64(dropped)
+
+	if err := w.add([]byte(`{"msg":"late"}`), testDocMeta); err == nil {
+		t.Error("Expected add after Close to fail")
+	}
+	if got := idx.addCount(); got != 0 {
+		t.Errorf("Expected no add on a closed writer, got %d", got)
+	}
+	if got := promtestutil.ToFloat64(dropped) - before; got != 1 {
+		t.Errorf("Expected 1 writer_closed drop, got %v", got)
+	}
+	// The DLQ file is closed with the writer, so the rejection only shows in the metric
+	if entries := testutil.ReadJSONLines(t, dlq); len(entries) != 0 {
+		t.Errorf("Expected nothing written to the closed DLQ, got %v", entries)
+	}
+}
@@ -0,0 +1,488 @@
+package elasticx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newTestElasticWriter(t *testing.T, mockURL string, sink logger.ElasticSink) *elasticsearchWriter {
+	t.Helper()
+	sink.Addresses = []string{mockURL}
+	if sink.FlushInterval == 0 {
+		sink.FlushInterval = time.Minute // Tests flush through Close()
+	}
+	opts := logger.DefaultProductionOptions()
+	opts.Service = "svc"
+	opts.Elastic = &sink
+
+	w, err := newElasticsearchWriter(opts, nil)
+	if err != nil {
+		t.Fatalf("Failed to create elasticsearch writer: %v", err)
+	}
+	return w
+}
+
+func newTestElasticCore(w *elasticsearchWriter) zapcore.Core {
+	encCfg := zap.NewProductionEncoderConfig()
+	encCfg.TimeKey = "ts"
+	enrich := []zapcore.Field{zap.String("service", w.service)}
+	return newElasticCore(zapcore.NewJSONEncoder(encCfg), zapcore.DebugLevel, enrich, fieldPolicy{}, w)
+}
+
+func TestElasticIndexFromEntryTimestamp(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	w := newTestElasticWriter(t, mockES.URL, logger.ElasticSink{Index: "logs-%Y.%m.%d"})
+	core := newTestElasticCore(w)
+
+	now := time.Date(2026, 4, 1, 0, 0, 1, 0, time.UTC)
+	yesterday := now.Add(-2 * time.Second)
+
+	// An entry buffered across midnight keeps the day it was logged on
+	for _, ts := range []time.Time{yesterday, now} {
+		ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: ts, Message: "entry"}
+		if err := core.Write(ent, nil); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	meta := mockES.GetReceivedMeta()
+	if len(meta) != 2 {
+		t.Fatalf("Expected 2 documents, got %d", len(meta))
+	}
+	expected := []string{yesterday.Format("logs-2006.01.02"), now.Format("logs-2006.01.02")}
+	for i, want := range expected {
+		if meta[i]["_index"] != want {
+			t.Errorf("Entry %d: expected index %q, got %v", i, want, meta[i]["_index"])
+		}
+	}
+}
+
+func TestDefaultMappingsForCustomTraceFields(t *testing.T) {
+	got, err := defaultMappingsFor(logger.ContextKeys{})
+	if err != nil || string(got) != string(defaultMappings) {
+		t.Errorf("Expected the default mappings unchanged, got %s (%v)", got, err)
+	}
+
+	got, err = defaultMappingsFor(logger.ContextKeys{TraceIDField: "traceId", SpanIDField: "spanId"})
+	if err != nil {
+		t.Fatalf("defaultMappingsFor failed: %v", err)
+	}
+	var m struct {
+		DynamicTemplates []any                     `json:"dynamic_templates"`
+		Properties       map[string]map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal(got, &m); err != nil {
+		t.Fatalf("Invalid mappings %s: %v", got, err)
+	}
+	for _, key := range []string{"traceId", "spanId"} {
+		if m.Properties[key]["type"] != "keyword" {
+			t.Errorf("Expected %s mapped as keyword, got %v", key, m.Properties[key])
+		}
+	}
+	if _, ok := m.Properties["trace_id"]; ok {
+		t.Errorf("Expected trace_id to be renamed, got %v", m.Properties)
+	}
+	if len(m.DynamicTemplates) != 1 || m.Properties["msg"]["type"] != "text" {
+		t.Errorf("Expected the other mappings kept, got %s", got)
+	}
+}
+
+func TestElasticCoreEnrichment(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	w := newTestElasticWriter(t, mockES.URL, logger.ElasticSink{})
+	core := newTestElasticCore(w)
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: "entry"}
+
+	if err := core.Write(ent, []zapcore.Field{zap.String("user", "u1")}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	// An explicit service field, at call site or bound, wins over the enrichment
+	if err := core.Write(ent, []zapcore.Field{zap.String("service", "caller")}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := core.With([]zapcore.Field{zap.String("service", "bound")}).Write(ent, nil); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	bodies := mockES.GetRawDocs()
+	if len(bodies) != 3 {
+		t.Fatalf("Expected 3 documents, got %d", len(bodies))
+	}
+	expected := []string{"svc", "caller", "bound"}
+	for i, body := range bodies {
+		if n := strings.Count(body, `"service":`); n != 1 {
+			t.Errorf("Doc %d: expected exactly one service key, got %d in %s", i, n, body)
+		}
+		if !strings.Contains(body, fmt.Sprintf(`"service":%q`, expected[i])) {
+			t.Errorf("Doc %d: expected service %q in %s", i, expected[i], body)
+		}
+	}
+}
+
+func TestGenerateIndexNamePlaceholders(t *testing.T) {
+	// Thursday of ISO week 1 of 2026 that is still day 1 of the year
+	ts := time.Date(2026, 1, 1, 7, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		pattern  string
+		expected string
+	}{
+		{"Daily", "logs-%Y.%m.%d", "logs-2026.01.01"},
+		{"Hourly", "logs-%Y.%m.%d-%H", "logs-2026.01.01-07"},
+		{"ISOWeek", "logs-%G-w%W", "logs-2026-w01"},
+		{"DayOfYear", "logs-%Y.%j", "logs-2026.001"},
+		{"Service", "<service>-%Y", "checkout-2026"},
+		{"NoPlaceholders", "static-logs", "static-logs"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := validateIndexPattern(tc.pattern, "checkout"); err != nil {
+				t.Fatalf("Expected valid pattern, got %v", err)
+			}
+			if got := generateIndexName(tc.pattern, "checkout", ts); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	// ISO week and year belong to the previous or next year around New Year
+	for _, tc := range []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), "logs-2026-w53"},
+		{time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC), "logs-2026-w01"},
+	} {
+		if got := generateIndexName("logs-%G-w%W", "", tc.t); got != tc.want {
+			t.Errorf("Expected %q for %s, got %q", tc.want, tc.t.Format(time.DateOnly), got)
+		}
+	}
+	if _, err := validateIndexPattern("logs-%Y-w%W", "checkout"); err == nil || !strings.Contains(err.Error(), "%G") {
+		t.Errorf("Expected %%W with the calendar year %%Y to be rejected, got %v", err)
+	}
+}
+
+func TestIndexNameCacheRollsOver(t *testing.T) {
+	var cache indexNameCache
+	pattern := "<service>-%Y.%m.%d"
+	lastSecond := time.Date(2026, 3, 31, 23, 59, 59, 0, time.UTC)
+	midnight := lastSecond.Add(time.Second)
+
+	steps := []struct {
+		t    time.Time
+		want string
+	}{
+		{lastSecond.Add(-time.Minute), "svc-2026.03.31"},
+		{lastSecond, "svc-2026.03.31"},
+		{midnight, "svc-2026.04.01"},
+		{lastSecond, "svc-2026.03.31"}, // A late entry still gets its own day
+		{midnight.Add(time.Hour), "svc-2026.04.01"},
+	}
+	for i, step := range steps {
+		if got := cache.resolve(pattern, "svc", step.t); got != step.want {
+			t.Errorf("Step %d: expected %q, got %q", i, step.want, got)
+		}
+	}
+
+	// Other time zones resolve to the UTC day
+	local := midnight.In(time.FixedZone("UTC-5", -5*3600))
+	if got := cache.resolve(pattern, "svc", local); got != "svc-2026.04.01" {
+		t.Errorf("Expected the UTC day, got %q", got)
+	}
+}
+
+// BenchmarkIndexName compares substituting the pattern per entry with the cache
+func BenchmarkIndexName(b *testing.B) {
+	pattern := "<service>-logs-%Y.%m.%d"
+	ts := time.Now()
+
+	b.Run("Generate", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = generateIndexName(pattern, "svc", ts)
+		}
+	})
+
+	b.Run("Cached", func(b *testing.B) {
+		var cache indexNameCache
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_ = cache.resolve(pattern, "svc", ts)
+			}
+		})
+	})
+}
+
+func TestValidateIndexPattern(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pattern string
+		service string
+		errPart string
+	}{
+		{"UnknownPlaceholder", "logs-%Y.%q", "svc", `"%q"`},
+		{"DanglingPercent", "logs-%", "svc", "dangling"},
+		{"UppercaseLiteral", "Logs-%Y", "svc", "lowercase"},
+		{"IllegalLiteral", "logs*%Y", "svc", "illegal character"},
+		{"LeadingUnderscore", "_logs-%Y", "svc", "must not"},
+		{"NothingLeftOfService", "<service>", "#_", "must not"},
+		{"UnterminatedField", "logs-%{tenant-%Y", "svc", "unterminated"},
+		{"EmptyField", "logs-%{}-%Y", "svc", `"%{}"`},
+		{"UppercaseAroundField", "Logs-%{tenant}", "svc", "lowercase"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := validateIndexPattern(tc.pattern, tc.service)
+			if err == nil {
+				t.Fatalf("Expected error for pattern %q with service %q", tc.pattern, tc.service)
+			}
+			if !strings.Contains(err.Error(), tc.errPart) {
+				t.Errorf("Expected error containing %q, got %v", tc.errPart, err)
+			}
+		})
+	}
+
+	// Service names are normalized instead of rejected
+	for service, want := range map[string]string{
+		"Checkout-API":   "checkout-api",
+		"my service":     "my-service",
+		"team/app":       "team-app",
+		"_Billing:EU#1":  "billing-eu-1",
+		"Zahlungsdienst": "zahlungsdienst",
+	} {
+		indexService, err := validateIndexPattern("<service>-%Y", service)
+		if err != nil {
+			t.Errorf("Expected service %q to be accepted, got %v", service, err)
+			continue
+		}
+		if indexService != want {
+			t.Errorf("Expected service %q as %q in index names, got %q", service, want, indexService)
+		}
+	}
+}
+
+func TestSubstituteIndexFields(t *testing.T) {
+	pattern := "logs-<service>-%{tenant}-%Y.%m"
+	if _, err := validateIndexPattern(pattern, "svc"); err != nil {
+		t.Fatalf("Expected valid pattern, got %v", err)
+	}
+	name := generateIndexName(pattern, "svc", time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC))
+
+	testCases := []struct {
+		name     string
+		doc      map[string]any
+		expected string
+	}{
+		{"Present", map[string]any{"tenant": "acme"}, "logs-svc-acme-2026.05"},
+		{"Number", map[string]any{"tenant": float64(42)}, "logs-svc-42-2026.05"},
+		{"Missing", map[string]any{"msg": "no tenant"}, "logs-svc-other-2026.05"},
+		{"Null", map[string]any{"tenant": nil}, "logs-svc-other-2026.05"},
+		{"Nested", map[string]any{"tenant": map[string]any{"id": "acme"}}, "logs-svc-other-2026.05"},
+		{"Sanitized", map[string]any{"tenant": "+Acme Corp/EU*"}, "logs-svc-acme-corp-eu--2026.05"},
+		{"NothingLeft", map[string]any{"tenant": "__"}, "logs-svc-other-2026.05"},
+		{"Long", map[string]any{"tenant": strings.Repeat("a", 100)}, "logs-svc-" + strings.Repeat("a", maxIndexFieldLen) + "-2026.05"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := substituteIndexFields(name, tc.doc, "other"); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	if got := substituteIndexFields("%{a}-%{b}", map[string]any{"a": "x", "b": true}, "other"); got != "x-true" {
+		t.Errorf("Expected every token substituted, got %q", got)
+	}
+}
+
+func TestElasticIndexFieldFallbackValidated(t *testing.T) {
+	opts := logger.DefaultProductionOptions()
+	opts.Service = "svc"
+	opts.Elastic = &logger.ElasticSink{Addresses: []string{"http://localhost:9200"}, Index: "logs-%{tenant}", IndexFieldFallback: "__"}
+
+	if _, err := newElasticsearchWriter(opts, nil); err == nil || !strings.Contains(err.Error(), "IndexFieldFallback") {
+		t.Errorf("Expected the fallback rejected, got %v", err)
+	}
+}
+
+func TestElasticInvalidIndexPatternFailsConstruction(t *testing.T) {
+	opts := logger.DefaultProductionOptions()
+	opts.Service = "svc"
+	opts.Elastic = &logger.ElasticSink{Addresses: []string{"http://localhost:9200"}, Index: "logs-%Y.%x"}
+
+	_, err := newElasticsearchWriter(opts, nil)
+	if err == nil || !strings.Contains(err.Error(), `"%x"`) {
+		t.Errorf("Expected error naming the %%x token, got %v", err)
+	}
+}
+
+type discardDocs struct{}
+
+func (discardDocs) add([]byte, docMeta) error { return nil }
+func (discardDocs) reject([]byte, string)     {}
+
+// BenchmarkElasticEncode compares the former encode → unmarshal → enrich → marshal
+// path with encoding the enriched entry directly
+func BenchmarkElasticEncode(b *testing.B) {
+	encCfg := zap.NewProductionEncoderConfig()
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: "request served"}
+	fields := []zapcore.Field{
+		zap.String("method", "GET"),
+		zap.String("path", "/api/v1/orders"),
+		zap.Int("status", 200),
+		zap.Duration("latency", 1500*time.Microsecond),
+		zap.String("user_id", "u-12345"),
+	}
+
+	b.Run("Reparse", func(b *testing.B) {
+		enc := zapcore.NewJSONEncoder(encCfg)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf, _ := enc.EncodeEntry(ent, fields)
+			var doc map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+				b.Fatal(err)
+			}
+			buf.Free()
+			doc["service"] = "svc"
+			if _, err := json.Marshal(doc); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Direct", func(b *testing.B) {
+		core := newElasticCore(zapcore.NewJSONEncoder(encCfg), zapcore.DebugLevel,
+			[]zapcore.Field{zap.String("service", "svc")}, fieldPolicy{}, discardDocs{})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := core.Write(ent, fields); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestElasticCloseHonorsDeadline(t *testing.T) {
+	// Bulk requests hang until the test ends, so only the deadline can end Close
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+	mockES.SetLatency(time.Hour)
+
+	w := newTestElasticWriter(t, mockES.URL, logger.ElasticSink{})
+	core := newTestElasticCore(w)
+	if err := core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: "stuck"}, nil); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := w.CloseContext(ctx)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Close ignored the deadline, took %v", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline error, got %v", err)
+	}
+}
+
+// bulkWorkers counts the goroutines of every live esutil bulk indexer worker
+func bulkWorkers() int {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return strings.Count(string(buf[:n]), "created by github.com/elastic/go-elasticsearch/v8/esutil.(*worker).run")
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+func TestElasticCountFlushDoesNotLeakIndexers(t *testing.T) {
+	// Every count flush swaps in a fresh bulk indexer; under load the retired ones
+	// must not pile up, and Close must leave none of their workers behind
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+	mockES.SetLatency(5 * time.Millisecond)
+
+	const numWorkers = 4
+	w := newTestElasticWriter(t, mockES.URL, logger.ElasticSink{BulkActions: 5, NumWorkers: numWorkers})
+	core := newTestElasticCore(w)
+
+	var maxWorkers atomic.Int64
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		for {
+			if n := int64(bulkWorkers()); n > maxWorkers.Load() {
+				maxWorkers.Store(n)
+			}
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}()
+
+	const writers, perWriter = 20, 50
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perWriter; j++ {
+				ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: fmt.Sprintf("w%d-%d", i, j)}
+				if err := core.Write(ent, nil); err != nil {
+					t.Errorf("Write failed: %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	close(done)
+	<-sampled
+
+	// The live indexer plus the single one a flush is draining
+	if n := maxWorkers.Load(); n > 2*numWorkers {
+		t.Errorf("Expected at most %d bulk workers at once, saw %d", 2*numWorkers, n)
+	}
+	if got := len(mockES.GetReceivedDocs()); got != writers*perWriter {
+		t.Errorf("Expected %d documents delivered, got %d", writers*perWriter, got)
+	}
+	if n := bulkWorkers(); n != 0 {
+		t.Errorf("Expected no bulk workers after Close, got %d", n)
+	}
+}
@@ -0,0 +1,54 @@
+package zapx
+
+import (
+	"context"
+	"os"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap/zapcore"
+)
+
+// fatalExitTimeout bounds closing the sinks before the default Fatal exits
+const fatalExitTimeout = 5 * time.Second
+
+// ToCheckWriteHook maps hook onto zap's CheckWriteHook, for use with
+// zap.WithFatalHook on zap loggers that should behave like loggerkit's
+func ToCheckWriteHook(hook logger.FatalHook) zapcore.CheckWriteHook {
+	return fatalWriteHook{hook: hook}
+}
+
+type fatalWriteHook struct {
+	hook logger.FatalHook
+}
+
+func (h fatalWriteHook) OnWrite(ce *zapcore.CheckedEntry, fields []zapcore.Field) {
+	h.hook(toFatalEntry(ce, fields))
+}
+
+func toFatalEntry(ce *zapcore.CheckedEntry, fields []zapcore.Field) logger.FatalEntry {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	var caller string
+	if ce.Caller.Defined {
+		caller = ce.Caller.TrimmedPath()
+	}
+	return logger.FatalEntry{Time: ce.Time, Message: ce.Message, Caller: caller, Fields: enc.Fields}
+}
+
+// exitOnFatal is the default hook: it closes the sinks of root, so entries
+// buffered by Elasticsearch, Fluent and the like are not lost, then exits
+type exitOnFatal struct {
+	root *zapAdapter // Set once the root logger is built
+}
+
+func (h *exitOnFatal) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {
+	if h.root != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), fatalExitTimeout)
+		h.root.Close(ctx)
+		cancel()
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,118 @@
+package zapx
+
+import (
+	"fmt"
+	"sync"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+)
+
+// fieldCheck reports duplicate field keys; zap writes both, which breaks
+// some JSON parsers downstream
+type fieldCheck struct {
+	strict      bool
+	diagnosticf func(format string, args ...any)
+	warned      sync.Map // message + key already reported
+}
+
+// newFieldCheck returns nil, disabling the check, outside dev mode without
+// StrictFields, and with DedupFields, which leaves no duplicates to report
+func newFieldCheck(opts logger.Options) *fieldCheck {
+	if (!opts.StrictFields && !opts.Env.IsDev()) || opts.DedupFields {
+		return nil
+	}
+	return &fieldCheck{strict: opts.StrictFields, diagnosticf: opts.Diagnosticf}
+}
+
+// check reports the first key found both in bound and fields, or twice in fields
+func (c *fieldCheck) check(msg string, bound []string, fields []logger.Field) {
+	if len(bound)+len(fields) < 2 {
+		return
+	}
+	seen := make(map[string]struct{}, len(bound)+len(fields))
+	for _, k := range bound {
+		seen[k] = struct{}{}
+	}
+	for _, f := range fields {
+		if _, dup := seen[f.Key]; dup {
+			c.report(msg, f.Key)
+			return
+		}
+		seen[f.Key] = struct{}{}
+	}
+}
+
+func (c *fieldCheck) report(msg, key string) {
+	if c.strict {
+		panic(fmt.Sprintf("loggerkit: duplicate field key %q in entry %q", key, msg))
+	}
+	if _, loaded := c.warned.LoadOrStore(msg+"\x00"+key, struct{}{}); !loaded {
+		c.diagnosticf("duplicate field key %q in entry %q", key, msg)
+	}
+}
+
+// mergeFieldsLinear bounds the inputs deduplicated without a map
+const mergeFieldsLinear = 32
+
+// mergeFields concatenates groups keeping only the last field of each key, in
+// the order of those last fields
+func mergeFields(groups ...[]logger.Field) []logger.Field {
+	n := 0
+	for _, g := range groups {
+		n += len(g)
+	}
+	all := make([]logger.Field, 0, n)
+	for _, g := range groups {
+		all = append(all, g...)
+	}
+	if n < 2 {
+		return all
+	}
+
+	// Walk backwards so the first occurrence seen is the one kept
+	var keepBuf [mergeFieldsLinear]bool
+	var keep []bool
+	var seen map[string]struct{}
+	if n > mergeFieldsLinear {
+		keep = make([]bool, n)
+		seen = make(map[string]struct{}, n)
+	} else {
+		keep = keepBuf[:n]
+	}
+	dups := 0
+	for i := n - 1; i >= 0; i-- {
+		key := all[i].Key
+		if seen != nil {
+			if _, dup := seen[key]; dup {
+				dups++
+				continue
+			}
+			seen[key] = struct{}{}
+		} else if containsKey(all[i+1:], keep[i+1:], key) {
+			dups++
+			continue
+		}
+		keep[i] = true
+	}
+	if dups == 0 {
+		return all
+	}
+
+	out := all[:0]
+	for i, f := range all {
+		if keep[i] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// containsKey reports whether a kept field of fields has key
+func containsKey(fields []logger.Field, kept []bool, key string) bool {
+	for i, f := range fields {
+		if kept[i] && f.Key == key {
+			return true
+		}
+	}
+	return false
+}
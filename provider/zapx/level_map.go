@@ -16,9 +16,31 @@ func ToZapLevel(l logger.Level) (zapcore.Level, error) {
 		return zapcore.WarnLevel, nil
 	case logger.ErrorLevel:
 		return zapcore.ErrorLevel, nil
+	case logger.DPanicLevel:
+		return zapcore.DPanicLevel, nil
+	case logger.FatalLevel:
+		return zapcore.FatalLevel, nil
 	case "":
 		return zapcore.InvalidLevel, nil
 	default:
 		return zapcore.InvalidLevel, fmt.Errorf("invalid level %q", l)
 	}
 }
+
+// fromZapLevel maps zap levels onto loggerkit's; panic counts as dpanic
+func fromZapLevel(l zapcore.Level) logger.Level {
+	switch {
+	case l <= zapcore.DebugLevel:
+		return logger.DebugLevel
+	case l == zapcore.InfoLevel:
+		return logger.InfoLevel
+	case l == zapcore.WarnLevel:
+		return logger.WarnLevel
+	case l == zapcore.ErrorLevel:
+		return logger.ErrorLevel
+	case l < zapcore.FatalLevel:
+		return logger.DPanicLevel
+	default:
+		return logger.FatalLevel
+	}
+}
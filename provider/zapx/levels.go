@@ -0,0 +1,176 @@
+package zapx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap/zapcore"
+)
+
+// followLevel is the level passed to the factories. A core enabled at it follows
+// Options.Level, which a levelFilter applies so it can change at runtime and per
+// logger name; sinks with a level of their own (RingSink.Level,
+// AlertSink.MinLevel) keep it.
+const followLevel = zapcore.DebugLevel - 1
+
+// levelTable holds the minimum levels of a logger tree, shared by all loggers
+// derived from the root
+type levelTable struct {
+	mu      sync.Mutex // Serializes updates; reads only load current
+	current atomic.Pointer[levelSnapshot]
+}
+
+type levelSnapshot struct {
+	base      zapcore.Level
+	overrides map[string]zapcore.Level
+	names     []string      // Override names, longest first
+	min       zapcore.Level // Lowest of base and overrides
+}
+
+func newLevelTable(base zapcore.Level, overrides map[string]logger.Level) (*levelTable, error) {
+	levels := make(map[string]zapcore.Level, len(overrides))
+	for name, l := range overrides {
+		lvl, err := requireLevel(l)
+		if err != nil {
+			return nil, fmt.Errorf("invalid level override for %q: %w", name, err)
+		}
+		levels[name] = lvl
+	}
+	t := &levelTable{}
+	t.current.Store(newLevelSnapshot(base, levels))
+	return t, nil
+}
+
+func newLevelSnapshot(base zapcore.Level, overrides map[string]zapcore.Level) *levelSnapshot {
+	s := &levelSnapshot{base: base, overrides: overrides, min: base}
+	for name, lvl := range overrides {
+		s.names = append(s.names, name)
+		s.min = min(s.min, lvl)
+	}
+	sort.Slice(s.names, func(i, j int) bool { return len(s.names[i]) > len(s.names[j]) })
+	return s
+}
+
+// requireLevel is ToZapLevel without the empty level
+func requireLevel(l logger.Level) (zapcore.Level, error) {
+	if l == "" {
+		return zapcore.InvalidLevel, errors.New("empty level")
+	}
+	return ToZapLevel(l)
+}
+
+// levelFor returns the level of the longest override matching name, where
+// "billing" matches "billing" and "billing.invoices" but not "billingx"
+func (t *levelTable) levelFor(name string) zapcore.Level {
+	s := t.current.Load()
+	if name == "" || len(s.names) == 0 {
+		return s.base
+	}
+	for _, n := range s.names {
+		if name == n || (strings.HasPrefix(name, n) && name[len(n)] == '.') {
+			return s.overrides[n]
+		}
+	}
+	return s.base
+}
+
+func (t *levelTable) min() zapcore.Level {
+	return t.current.Load().min
+}
+
+func (t *levelTable) setBase(lvl zapcore.Level) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.current.Load()
+	t.current.Store(newLevelSnapshot(lvl, s.overrides))
+}
+
+// setOverride sets the level of name, or removes its override when lvl is
+// InvalidLevel
+func (t *levelTable) setOverride(name string, lvl zapcore.Level) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.current.Load()
+	overrides := make(map[string]zapcore.Level, len(s.overrides)+1)
+	for n, l := range s.overrides {
+		overrides[n] = l
+	}
+	if lvl == zapcore.InvalidLevel {
+		delete(overrides, name)
+	} else {
+		overrides[name] = lvl
+	}
+	t.current.Store(newLevelSnapshot(s.base, overrides))
+}
+
+// levelFilter applies a levelTable to a sink core built at followLevel
+type levelFilter struct {
+	zapcore.Core
+	levels *levelTable
+}
+
+func (f *levelFilter) Enabled(l zapcore.Level) bool {
+	return l >= f.levels.min() && f.Core.Enabled(l)
+}
+
+func (f *levelFilter) Level() zapcore.Level {
+	return f.levels.min()
+}
+
+func (f *levelFilter) With(fields []zapcore.Field) zapcore.Core {
+	return &levelFilter{Core: f.Core.With(fields), levels: f.levels}
+}
+
+func (f *levelFilter) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !f.allows(ent) {
+		return ce
+	}
+	return f.Core.Check(ent, ce)
+}
+
+// allows reports whether ent reaches the level of its logger name
+func (f *levelFilter) allows(ent zapcore.Entry) bool {
+	return ent.Level >= f.levels.levelFor(ent.LoggerName)
+}
+
+// accepts is Check without a CheckedEntry, for cores written outside zap's
+// Check (parallelTee)
+func accepts(c zapcore.Core, ent zapcore.Entry) bool {
+	if f, ok := c.(*levelFilter); ok && !f.allows(ent) {
+		return false
+	}
+	return c.Enabled(ent.Level)
+}
+
+// SetLevel changes the minimum level of every logger derived from the same
+// root, except names with an override
+func (l *zapAdapter) SetLevel(level logger.Level) error {
+	if l.levels == nil {
+		return logger.ErrLevelsNotSupported
+	}
+	lvl, err := requireLevel(level)
+	if err != nil {
+		return err
+	}
+	l.levels.setBase(lvl)
+	return nil
+}
+
+// SetLevelFor overrides the minimum level of loggers named name and their
+// children; an empty level removes the override
+func (l *zapAdapter) SetLevelFor(name string, level logger.Level) error {
+	if l.levels == nil {
+		return logger.ErrLevelsNotSupported
+	}
+	lvl, err := ToZapLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid level override for %q: %w", name, err)
+	}
+	l.levels.setOverride(name, lvl)
+	return nil
+}
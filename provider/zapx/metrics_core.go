@@ -28,8 +28,12 @@ func (m *metricsCore) With(fields []zapcore.Field) zapcore.Core {
 	}
 }
 
+// Check adds m itself, not the inner core, so Write can count the entry
 func (m *metricsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
-	return m.inner.Check(ent, ce)
+	if m.Enabled(ent.Level) {
+		return ce.AddCore(ent, m)
+	}
+	return ce
 }
 
 func (m *metricsCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
@@ -0,0 +1,227 @@
+package otlpx
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+// instrumentationName identifies loggerkit as the emitter of the records
+const instrumentationName = "github.com/HoangAnhNguyen269/loggerkit"
+
+// otlpCore converts zap entries into OTel log records
+type otlpCore struct {
+	zapcore.LevelEnabler
+	provider *sdklog.LoggerProvider
+	logger   otellog.Logger
+	attrs    []otellog.KeyValue // From With
+	// Trace context fields written by WithContext; they become the record's
+	// TraceID/SpanID instead of string attributes
+	traceIDKey string
+	spanIDKey  string
+	traceID    trace.TraceID
+	spanID     trace.SpanID
+}
+
+func newCore(provider *sdklog.LoggerProvider, enab zapcore.LevelEnabler, keys logger.ContextKeys) zapcore.Core {
+	traceIDKey, spanIDKey, _ := keys.TraceFieldNames()
+	return &otlpCore{
+		LevelEnabler: enab,
+		provider:     provider,
+		logger:       provider.Logger(instrumentationName),
+		traceIDKey:   traceIDKey,
+		spanIDKey:    spanIDKey,
+	}
+}
+
+func (c *otlpCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.attrs = c.addFields(c.attrs[:len(c.attrs):len(c.attrs)], fields, &clone.traceID, &clone.spanID)
+	return &clone
+}
+
+func (c *otlpCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *otlpCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var r otellog.Record
+	r.SetTimestamp(ent.Time)
+	r.SetObservedTimestamp(time.Now())
+	r.SetSeverity(severity(ent.Level))
+	r.SetSeverityText(ent.Level.CapitalString())
+	r.SetBody(otellog.StringValue(ent.Message))
+
+	traceID, spanID := c.traceID, c.spanID
+	attrs := make([]otellog.KeyValue, 0, len(c.attrs)+len(fields)+3)
+	attrs = append(attrs, c.attrs...)
+	attrs = c.addFields(attrs, fields, &traceID, &spanID)
+	if ent.LoggerName != "" {
+		attrs = append(attrs, otellog.String("logger", ent.LoggerName))
+	}
+	if ent.Caller.Defined {
+		attrs = append(attrs,
+			otellog.String("code.filepath", ent.Caller.File),
+			otellog.Int("code.lineno", ent.Caller.Line),
+		)
+		if ent.Caller.Function != "" {
+			attrs = append(attrs, otellog.String("code.function", ent.Caller.Function))
+		}
+	}
+	if ent.Stack != "" {
+		attrs = append(attrs, otellog.String("code.stacktrace", ent.Stack))
+	}
+	r.AddAttributes(attrs...)
+
+	// The SDK takes TraceID/SpanID from the span context in ctx
+	ctx := context.Background()
+	if traceID.IsValid() {
+		ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		}))
+	}
+	c.logger.Emit(ctx, r)
+	return nil
+}
+
+func (c *otlpCore) Sync() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return c.provider.ForceFlush(ctx)
+}
+
+// addFields appends fields as attributes, pulling valid trace/span IDs out of them
+func (c *otlpCore) addFields(attrs []otellog.KeyValue, fields []zapcore.Field, traceID *trace.TraceID, spanID *trace.SpanID) []otellog.KeyValue {
+	if len(fields) == 0 {
+		return attrs
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		if f.Type == zapcore.StringType {
+			switch f.Key {
+			case c.traceIDKey:
+				if id, err := trace.TraceIDFromHex(f.String); err == nil {
+					*traceID = id
+					continue
+				}
+			case c.spanIDKey:
+				if id, err := trace.SpanIDFromHex(f.String); err == nil {
+					*spanID = id
+					continue
+				}
+			}
+		}
+
+		f.AddTo(enc)
+		// AddTo may write more than one key (e.g. errorVerbose), so drain the map
+		for k, v := range enc.Fields {
+			attrs = append(attrs, otellog.KeyValue{Key: k, Value: toValue(v)})
+			delete(enc.Fields, k)
+		}
+	}
+	return attrs
+}
+
+// toValue converts a value produced by zapcore.MapObjectEncoder
+func toValue(v any) otellog.Value {
+	switch v := v.(type) {
+	case nil:
+		return otellog.Value{}
+	case string:
+		return otellog.StringValue(v)
+	case bool:
+		return otellog.BoolValue(v)
+	case int:
+		return otellog.IntValue(v)
+	case int8:
+		return otellog.Int64Value(int64(v))
+	case int16:
+		return otellog.Int64Value(int64(v))
+	case int32:
+		return otellog.Int64Value(int64(v))
+	case int64:
+		return otellog.Int64Value(v)
+	case uint8:
+		return otellog.Int64Value(int64(v))
+	case uint16:
+		return otellog.Int64Value(int64(v))
+	case uint32:
+		return otellog.Int64Value(int64(v))
+	case uint:
+		return uintValue(uint64(v))
+	case uint64:
+		return uintValue(v)
+	case uintptr:
+		return uintValue(uint64(v))
+	case float32:
+		return otellog.Float64Value(float64(v))
+	case float64:
+		return otellog.Float64Value(v)
+	case complex64, complex128:
+		return otellog.StringValue(fmt.Sprint(v))
+	case time.Duration:
+		return otellog.StringValue(v.String())
+	case time.Time:
+		return otellog.StringValue(v.Format(time.RFC3339Nano))
+	case []byte:
+		return otellog.BytesValue(v)
+	case []any:
+		values := make([]otellog.Value, len(v))
+		for i := range v {
+			values[i] = toValue(v[i])
+		}
+		return otellog.SliceValue(values...)
+	case map[string]any:
+		kvs := make([]otellog.KeyValue, 0, len(v))
+		for k, val := range v {
+			kvs = append(kvs, otellog.KeyValue{Key: k, Value: toValue(val)})
+		}
+		return otellog.MapValue(kvs...)
+	default:
+		return otellog.StringValue(fmt.Sprint(v))
+	}
+}
+
+func uintValue(v uint64) otellog.Value {
+	if v > math.MaxInt64 {
+		return otellog.StringValue(fmt.Sprint(v))
+	}
+	return otellog.Int64Value(int64(v))
+}
+
+// severity maps zap levels onto the OTel severity ranges
+func severity(l zapcore.Level) otellog.Severity {
+	switch l {
+	case zapcore.DebugLevel:
+		return otellog.SeverityDebug
+	case zapcore.InfoLevel:
+		return otellog.SeverityInfo
+	case zapcore.WarnLevel:
+		return otellog.SeverityWarn
+	case zapcore.ErrorLevel:
+		return otellog.SeverityError
+	case zapcore.DPanicLevel:
+		return otellog.SeverityError2
+	case zapcore.PanicLevel:
+		return otellog.SeverityFatal
+	case zapcore.FatalLevel:
+		return otellog.SeverityFatal2
+	default:
+		if l < zapcore.DebugLevel {
+			return otellog.SeverityTrace
+		}
+		return otellog.SeverityFatal4
+	}
+}
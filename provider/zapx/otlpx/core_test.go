@@ -0,0 +1,179 @@
+package otlpx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// memoryExporter keeps exported records for inspection
+type memoryExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *memoryExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *memoryExporter) Shutdown(context.Context) error   { return nil }
+func (e *memoryExporter) ForceFlush(context.Context) error { return nil }
+
+func (e *memoryExporter) Records() []sdklog.Record {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]sdklog.Record(nil), e.records...)
+}
+
+func newTestCore(t *testing.T) (zapcore.Core, *memoryExporter) {
+	t.Helper()
+	exp := &memoryExporter{}
+	opts := logger.Options{Service: "checkout", Env: logger.EnvProd}
+	provider, err := newProvider(opts, sdklog.NewSimpleProcessor(exp))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return newCore(provider, zapcore.DebugLevel, logger.ContextKeys{}), exp
+}
+
+func attributes(r sdklog.Record) map[string]otellog.Value {
+	attrs := make(map[string]otellog.Value)
+	r.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	return attrs
+}
+
+func TestOTLPRecordConversion(t *testing.T) {
+	core, exp := newTestCore(t)
+	log := zap.New(core)
+
+	ts := time.Date(2026, 3, 9, 14, 0, 0, 0, time.UTC)
+	ce := log.With(zap.String("tenant", "acme")).Check(zap.WarnLevel, "Slow query")
+	ce.Time = ts
+	ce.Write(
+		zap.Int("rows", 42),
+		zap.Float64("ratio", 0.5),
+		zap.Bool("cached", false),
+		zap.Duration("took", 1500*time.Millisecond),
+		zap.Error(errors.New("deadline exceeded")),
+		zap.Any("labels", map[string]any{"region": "eu"}),
+	)
+
+	records := exp.Records()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	r := records[0]
+
+	if !r.Timestamp().Equal(ts) {
+		t.Errorf("Expected timestamp %v, got %v", ts, r.Timestamp())
+	}
+	if r.Severity() != otellog.SeverityWarn || r.SeverityText() != "WARN" {
+		t.Errorf("Expected WARN severity, got %v %q", r.Severity(), r.SeverityText())
+	}
+	if r.Body().AsString() != "Slow query" {
+		t.Errorf("Expected body to be the message, got %v", r.Body())
+	}
+
+	attrs := attributes(r)
+	if attrs["tenant"].AsString() != "acme" {
+		t.Errorf("Expected bound attribute tenant=acme, got %v", attrs["tenant"])
+	}
+	if attrs["rows"].AsInt64() != 42 || attrs["ratio"].AsFloat64() != 0.5 || attrs["cached"].AsBool() {
+		t.Errorf("Expected typed attributes, got rows=%v ratio=%v cached=%v", attrs["rows"], attrs["ratio"], attrs["cached"])
+	}
+	if attrs["took"].AsString() != "1.5s" {
+		t.Errorf("Expected duration attribute, got %v", attrs["took"])
+	}
+	if attrs["error"].AsString() != "deadline exceeded" {
+		t.Errorf("Expected error attribute, got %v", attrs["error"])
+	}
+	if labels := attrs["labels"]; labels.Kind() != otellog.KindMap || len(labels.AsMap()) != 1 {
+		t.Errorf("Expected map attribute, got %v", labels)
+	}
+
+	resAttrs := map[string]string{}
+	res := r.Resource()
+	for _, kv := range res.Attributes() {
+		resAttrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if resAttrs["service.name"] != "checkout" || resAttrs["deployment.environment"] != "prod" {
+		t.Errorf("Expected service and env resource attributes, got %v", resAttrs)
+	}
+}
+
+func TestOTLPTraceContext(t *testing.T) {
+	core, exp := newTestCore(t)
+	log := zap.New(core)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	const spanID = "00f067aa0ba902b7"
+
+	log.With(zap.String("trace_id", traceID), zap.String("span_id", spanID)).Info("Bound trace")
+	log.Info("Call-site trace", zap.String("trace_id", traceID), zap.String("span_id", spanID))
+	log.Info("Invalid trace", zap.String("trace_id", "not-hex"))
+
+	records := exp.Records()
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+	for _, r := range records[:2] {
+		if r.TraceID().String() != traceID || r.SpanID().String() != spanID {
+			t.Errorf("%s: expected trace %s/%s, got %s/%s", r.Body().AsString(), traceID, spanID, r.TraceID(), r.SpanID())
+		}
+		if _, ok := attributes(r)["trace_id"]; ok {
+			t.Errorf("%s: trace_id must not be duplicated as an attribute", r.Body().AsString())
+		}
+	}
+
+	invalid := records[2]
+	if invalid.TraceID().IsValid() {
+		t.Errorf("Expected no trace ID for an invalid value, got %s", invalid.TraceID())
+	}
+	if attributes(invalid)["trace_id"].AsString() != "not-hex" {
+		t.Errorf("Expected invalid trace_id to stay an attribute")
+	}
+}
+
+func TestOTLPSeverityMapping(t *testing.T) {
+	testCases := []struct {
+		level    zapcore.Level
+		expected otellog.Severity
+	}{
+		{zapcore.DebugLevel, otellog.SeverityDebug},
+		{zapcore.InfoLevel, otellog.SeverityInfo},
+		{zapcore.WarnLevel, otellog.SeverityWarn},
+		{zapcore.ErrorLevel, otellog.SeverityError},
+		{zapcore.DPanicLevel, otellog.SeverityError2},
+		{zapcore.PanicLevel, otellog.SeverityFatal},
+		{zapcore.FatalLevel, otellog.SeverityFatal2},
+	}
+
+	for _, tc := range testCases {
+		if got := severity(tc.level); got != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.level, tc.expected, got)
+		}
+	}
+}
+
+func TestOTLPUnsupportedProtocol(t *testing.T) {
+	if _, err := newExporter(logger.OTLPSink{Protocol: "thrift"}); err == nil {
+		t.Error("Expected error for unsupported protocol")
+	}
+}
@@ -0,0 +1,119 @@
+// Package otlpx exports logs over OTLP so an OpenTelemetry collector can fan them
+// out. Import it for its side effect to enable Options.OTLP:
+//
+//	import _ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/otlpx"
+package otlpx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx/corefactories"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.uber.org/zap/zapcore"
+)
+
+// shutdownTimeout bounds the final export when the logger is closed
+const shutdownTimeout = 10 * time.Second
+
+// Factory creates OTLP-based cores for logging output
+type Factory struct{}
+
+func init() {
+	corefactories.RegisterFactory(&Factory{})
+}
+
+// Name returns the unique name of this factory
+func (f *Factory) Name() string {
+	return "otlp"
+}
+
+// Enabled determines if OTLP export should be enabled based on options
+func (f *Factory) Enabled(opts logger.Options) bool {
+	return opts.OTLP != nil
+}
+
+// Build creates an OTLP core backed by a batching logger provider
+func (f *Factory) Build(encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, func() error, error) {
+	exporter, err := newExporter(*opts.OTLP)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+	}
+
+	provider, err := newProvider(opts, sdklog.NewBatchProcessor(exporter))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closer := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return provider.Shutdown(ctx)
+	}
+	return newCore(provider, lvl, opts.Context), closer, nil
+}
+
+func newExporter(cfg logger.OTLPSink) (sdklog.Exporter, error) {
+	ctx := context.Background()
+
+	switch strings.ToLower(cfg.Protocol) {
+	case "", "grpc":
+		var options []otlploggrpc.Option
+		if cfg.Endpoint != "" {
+			options = append(options, otlploggrpc.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			options = append(options, otlploggrpc.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			options = append(options, otlploggrpc.WithHeaders(cfg.Headers))
+		}
+		if cfg.Timeout > 0 {
+			options = append(options, otlploggrpc.WithTimeout(cfg.Timeout))
+		}
+		return otlploggrpc.New(ctx, options...)
+
+	case "http", "http/protobuf":
+		var options []otlploghttp.Option
+		if cfg.Endpoint != "" {
+			options = append(options, otlploghttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			options = append(options, otlploghttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			options = append(options, otlploghttp.WithHeaders(cfg.Headers))
+		}
+		if cfg.Timeout > 0 {
+			options = append(options, otlploghttp.WithTimeout(cfg.Timeout))
+		}
+		return otlploghttp.New(ctx, options...)
+
+	default:
+		return nil, fmt.Errorf("unsupported protocol %q (want grpc or http)", cfg.Protocol)
+	}
+}
+
+// newProvider builds a logger provider whose resource identifies the service
+func newProvider(opts logger.Options, processor sdklog.Processor) (*sdklog.LoggerProvider, error) {
+	attrs := []attribute.KeyValue{attribute.String("service.name", opts.Service)}
+	if opts.Env != "" {
+		attrs = append(attrs, attribute.String("deployment.environment", string(opts.Env)))
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build otlp resource: %w", err)
+	}
+
+	return sdklog.NewLoggerProvider(
+		sdklog.WithResource(res),
+		sdklog.WithProcessor(processor),
+	), nil
+}
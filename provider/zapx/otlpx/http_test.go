@@ -0,0 +1,89 @@
+package otlpx_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/otlpx"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestOTLPHTTPExport(t *testing.T) {
+	var mu sync.Mutex
+	var records []*logspb.LogRecord
+	var authHeader string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/logs" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req collogspb.ExportLogsServiceRequest
+		if err := proto.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		authHeader = r.Header.Get("Authorization")
+		for _, rl := range req.ResourceLogs {
+			for _, sl := range rl.ScopeLogs {
+				records = append(records, sl.LogRecords...)
+			}
+		}
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		out, _ := proto.Marshal(&collogspb.ExportLogsServiceResponse{})
+		_, _ = w.Write(out)
+	}))
+	defer server.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithService("otlp-test"),
+		logger.WithConsoleDisabled(),
+		logger.WithOTLP(logger.OTLPSink{
+			Endpoint: strings.TrimPrefix(server.URL, "http://"),
+			Insecure: true,
+			Protocol: "http",
+			Headers:  map[string]string{"Authorization": "Bearer token"},
+			Timeout:  2 * time.Second,
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	log.Info("Order placed", logger.F.String("order_id", "o-1"))
+	log.Error("Payment failed", logger.F.Int("attempt", 3))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := log.Close(ctx); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 exported records, got %d", len(records))
+	}
+	if authHeader != "Bearer token" {
+		t.Errorf("Expected configured headers to be sent, got %q", authHeader)
+	}
+	if records[0].Body.GetStringValue() != "Order placed" || records[0].SeverityText != "INFO" {
+		t.Errorf("Unexpected first record: %v", records[0])
+	}
+	if records[1].SeverityText != "ERROR" {
+		t.Errorf("Expected ERROR severity, got %q", records[1].SeverityText)
+	}
+}
@@ -0,0 +1,245 @@
+package zapx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap/zapcore"
+)
+
+// parallelQueueSize is the number of entries each sink may fall behind by before
+// new ones are dropped
+const parallelQueueSize = 1024
+
+// sinkExecutor writes one sink's entries from a single goroutine, so the sink
+// sees them in logging order. Write errors and drops are kept for the next Sync.
+type sinkExecutor struct {
+	name    string
+	metrics *logger.Metrics
+	queue   chan teeJob
+	stopped chan struct{}
+
+	mu     sync.RWMutex // Guards closed against sends on the closed queue
+	closed bool
+
+	errMu   sync.Mutex
+	failed  int
+	lastErr error
+	dropped int
+}
+
+// teeJob is an entry to write with core, or a flush marker when done is set
+type teeJob struct {
+	core   zapcore.Core
+	ent    zapcore.Entry
+	fields []zapcore.Field
+	done   chan struct{}
+}
+
+func newSinkExecutor(name string, metrics *logger.Metrics) *sinkExecutor {
+	e := &sinkExecutor{
+		name:    name,
+		metrics: metrics,
+		queue:   make(chan teeJob, parallelQueueSize),
+		stopped: make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+func (e *sinkExecutor) run() {
+	defer close(e.stopped)
+	for job := range e.queue {
+		if job.done != nil {
+			close(job.done)
+			continue
+		}
+		if err := job.core.Write(job.ent, job.fields); err != nil {
+			e.errMu.Lock()
+			e.failed++
+			e.lastErr = err
+			e.errMu.Unlock()
+		}
+	}
+}
+
+// enqueue hands job to the sink without waiting for it. It reports false when
+// the executor is stopped or, unless wait is set, its queue is full.
+func (e *sinkExecutor) enqueue(job teeJob, wait bool) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return false
+	}
+	if wait {
+		e.queue <- job
+		return true
+	}
+	select {
+	case e.queue <- job:
+		return true
+	default:
+		e.errMu.Lock()
+		e.dropped++
+		e.errMu.Unlock()
+		e.metrics.RecordLogDropped(e.name, "buffer_full")
+		return false
+	}
+}
+
+// drain waits until every entry queued so far is written
+func (e *sinkExecutor) drain() {
+	done := make(chan struct{})
+	if e.enqueue(teeJob{done: done}, true) {
+		<-done
+	}
+}
+
+// takeErr returns and resets the failures seen since the last call
+func (e *sinkExecutor) takeErr() error {
+	e.errMu.Lock()
+	defer e.errMu.Unlock()
+	var errs []error
+	if e.failed > 0 {
+		errs = append(errs, fmt.Errorf("%s: %d writes failed, last: %w", e.name, e.failed, e.lastErr))
+	}
+	if e.dropped > 0 {
+		errs = append(errs, fmt.Errorf("%s: %d entries dropped, queue full", e.name, e.dropped))
+	}
+	e.failed, e.lastErr, e.dropped = 0, nil, 0
+	return errors.Join(errs...)
+}
+
+// stop writes the queued entries and ends the goroutine, giving up when ctx is done
+func (e *sinkExecutor) stop(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-e.stopped:
+		return e.takeErr()
+	case <-ctx.Done():
+		return fmt.Errorf("%s: entries still queued: %w", e.name, ctx.Err())
+	}
+}
+
+// parallelTee is zapcore.NewTee with each sink written by its own executor, so
+// a slow or failing sink delays neither the caller nor the other sinks. Write
+// returns once the entry is queued; errors surface on the next Sync.
+type parallelTee struct {
+	cores     []zapcore.Core
+	executors []*sinkExecutor // executors[i] writes cores[i], shared by derived tees
+}
+
+// newParallelTee starts one executor per core and wraps each closer so the sink
+// is only closed once its executor has written everything queued. Sinks without
+// a closer get one that just stops the executor.
+func newParallelTee(cores []zapcore.Core, names []string, closers []sinkCloser, metrics *logger.Metrics) (zapcore.Core, []sinkCloser) {
+	t := &parallelTee{cores: cores, executors: make([]*sinkExecutor, len(cores))}
+	byName := make(map[string]*sinkExecutor, len(cores))
+	for i := range cores {
+		t.executors[i] = newSinkExecutor(names[i], metrics)
+		byName[names[i]] = t.executors[i]
+	}
+
+	wrapped := make([]sinkCloser, 0, len(closers)+len(cores))
+	for _, c := range closers {
+		e, ok := byName[c.name]
+		if !ok {
+			wrapped = append(wrapped, c)
+			continue
+		}
+		delete(byName, c.name)
+		closeSink := c.close
+		wrapped = append(wrapped, sinkCloser{name: c.name, counts: c.counts, indexer: c.indexer, close: func(ctx context.Context) error {
+			if err := e.stop(ctx); err != nil {
+				return errors.Join(err, closeSink(ctx))
+			}
+			return closeSink(ctx)
+		}})
+	}
+	for _, e := range t.executors {
+		if byName[e.name] == e {
+			wrapped = append(wrapped, sinkCloser{name: e.name, close: e.stop})
+		}
+	}
+	return t, wrapped
+}
+
+func (t *parallelTee) Enabled(l zapcore.Level) bool {
+	for _, c := range t.cores {
+		if c.Enabled(l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *parallelTee) With(fields []zapcore.Field) zapcore.Core {
+	cores := make([]zapcore.Core, len(t.cores))
+	for i, c := range t.cores {
+		cores[i] = c.With(fields)
+	}
+	return &parallelTee{cores: cores, executors: t.executors}
+}
+
+func (t *parallelTee) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if t.Enabled(ent.Level) {
+		return ce.AddCore(ent, t)
+	}
+	return ce
+}
+
+// Write queues the entry for every sink enabled at its level. Entries above
+// error level are written before Write returns, as the process may exit next.
+func (t *parallelTee) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	// The caller reuses fields once Write returns; the sinks share one copy
+	fields = append([]zapcore.Field(nil), fields...)
+	for i, c := range t.cores {
+		if s, ok := c.(*markerSampler); ok {
+			// Write bypasses Check, where the sink's sampler decides
+			if !accepts(s.Core, ent) {
+				continue
+			}
+			if c = s.sample(ent); c == nil {
+				continue
+			}
+		} else if !accepts(c, ent) {
+			continue
+		}
+		t.executors[i].enqueue(teeJob{core: c, ent: ent, fields: fields}, false)
+	}
+	if ent.Level > zapcore.ErrorLevel {
+		return t.Sync()
+	}
+	return nil
+}
+
+// Sync waits for every sink to write what is queued, then syncs them. It
+// returns the write errors and drops since the previous Sync along with the
+// sync errors.
+func (t *parallelTee) Sync() error {
+	errs := make([]error, len(t.cores))
+	var wg sync.WaitGroup
+	for i := range t.cores {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e := t.executors[i]
+			e.drain()
+			errs[i] = e.takeErr()
+			if err := t.cores[i].Sync(); err != nil {
+				errs[i] = errors.Join(errs[i], fmt.Errorf("%s: %w", e.name, err))
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
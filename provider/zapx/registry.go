@@ -1,12 +1,43 @@
 package zapx
 
-import "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/corefactories"
+import (
+	"sync/atomic"
 
-var getRegistry = func() corefactories.Registry {
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx/corefactories"
+)
+
+// registryExtension is the Options.Extensions key of WithFactoryRegistry
+const registryExtension = "zapx.registry"
+
+// registryHolder lets an atomic.Value hold Registry implementations of any type
+type registryHolder struct{ r corefactories.Registry }
+
+var injectedRegistry atomic.Value // registryHolder
+
+func getRegistry() corefactories.Registry {
+	if h, ok := injectedRegistry.Load().(registryHolder); ok {
+		return h.r
+	}
 	return corefactories.DefaultRegistry()
 }
 
-// UseFactoryRegistry allows tests to inject a custom registry.
+// UseFactoryRegistry allows tests to inject a custom registry. It applies to
+// every logger built afterwards; WithFactoryRegistry scopes one to a logger.
 func UseFactoryRegistry(r corefactories.Registry) {
-	getRegistry = func() corefactories.Registry { return r }
+	injectedRegistry.Store(registryHolder{r})
+}
+
+// WithFactoryRegistry builds the logger from the factories of r, such as one
+// from corefactories.NewRegistry, instead of the global registry
+func WithFactoryRegistry(r corefactories.Registry) logger.Option {
+	return logger.WithExtension(registryExtension, r)
+}
+
+// optionsRegistry is the registry a logger built from opts uses
+func optionsRegistry(opts logger.Options) corefactories.Registry {
+	if r, ok := logger.GetExtension[corefactories.Registry](opts, registryExtension); ok && r != nil {
+		return r
+	}
+	return getRegistry()
 }
@@ -0,0 +1,137 @@
+package zapx
+
+import (
+	"sync/atomic"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// samplerCountersPerLevel matches zapcore's sampler: messages hashing to the
+// same slot share a counter
+const samplerCountersPerLevel = 4096
+
+type samplerCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+	dropped atomic.Uint64 // Since the last entry emitted; survives resets
+}
+
+type samplerCounters [zapcore.FatalLevel - zapcore.DebugLevel + 1][samplerCountersPerLevel]samplerCounter
+
+func (cs *samplerCounters) get(lvl zapcore.Level, msg string) *samplerCounter {
+	return &cs[lvl-zapcore.DebugLevel][fnv32a(msg)%samplerCountersPerLevel]
+}
+
+// fnv32a hashes without converting s to []byte
+func fnv32a(s string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	hash := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		hash ^= uint32(s[i])
+		hash *= prime32
+	}
+	return hash
+}
+
+// incCheckReset counts an entry at t, starting over once tick has passed
+func (c *samplerCounter) incCheckReset(t time.Time, tick time.Duration) uint64 {
+	tn := t.UnixNano()
+	resetAfter := c.resetAt.Load()
+	if resetAfter > tn {
+		return c.count.Add(1)
+	}
+
+	c.count.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAfter, tn+tick.Nanoseconds()) {
+		// Another goroutine reset the counter to 1 as well
+		return c.count.Add(1)
+	}
+	return 1
+}
+
+// markerSampler samples like zapcore.NewSamplerWithOptions, but remembers how
+// many entries it dropped per level and message. With marker set, the next
+// entry it lets through for them carries that count in sampled_dropped.
+type markerSampler struct {
+	zapcore.Core
+	counts     *samplerCounters
+	tick       time.Duration
+	first      uint64
+	thereafter uint64
+	marker     bool
+}
+
+func newMarkerSampler(core zapcore.Core, tick time.Duration, first, thereafter int) zapcore.Core {
+	return &markerSampler{
+		Core:       core,
+		counts:     &samplerCounters{},
+		tick:       tick,
+		first:      uint64(first),
+		thereafter: uint64(thereafter),
+		marker:     true,
+	}
+}
+
+// newSinkSampler samples one sink's entries per second; parallelTee relies on
+// it being a *markerSampler, as it bypasses Check
+func newSinkSampler(core zapcore.Core, s logger.Sampling, marker bool) zapcore.Core {
+	return &markerSampler{
+		Core:       core,
+		counts:     &samplerCounters{},
+		tick:       time.Second,
+		first:      uint64(max(s.Initial, 0)),
+		thereafter: uint64(max(s.Thereafter, 0)),
+		marker:     marker,
+	}
+}
+
+func (s *markerSampler) Level() zapcore.Level {
+	return zapcore.LevelOf(s.Core)
+}
+
+func (s *markerSampler) With(fields []zapcore.Field) zapcore.Core {
+	clone := *s
+	clone.Core = s.Core.With(fields)
+	return &clone
+}
+
+func (s *markerSampler) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !s.Enabled(ent.Level) {
+		return ce
+	}
+	if core := s.sample(ent); core != nil {
+		return core.Check(ent, ce)
+	}
+	return ce
+}
+
+// sample counts ent and returns the core to write it to, nil when it is
+// sampled away
+func (s *markerSampler) sample(ent zapcore.Entry) zapcore.Core {
+	if ent.Level < zapcore.DebugLevel || ent.Level > zapcore.FatalLevel {
+		return s.Core
+	}
+
+	c := s.counts.get(ent.Level, ent.Message)
+	n := c.incCheckReset(ent.Time, s.tick)
+	if n > s.first && (s.thereafter == 0 || (n-s.first)%s.thereafter != 0) {
+		if s.marker {
+			c.dropped.Add(1)
+		}
+		return nil
+	}
+	if !s.marker {
+		return s.Core
+	}
+	if dropped := c.dropped.Swap(0); dropped > 0 {
+		// Rare enough that cloning the core for the field doesn't matter
+		return s.Core.With([]zapcore.Field{zap.Uint64(logger.SampledDroppedKey, dropped)})
+	}
+	return s.Core
+}
@@ -0,0 +1,117 @@
+package zapx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+)
+
+// sanitizeEnabled resolves Options.Sanitize, which defaults to on outside dev
+func sanitizeEnabled(opts logger.Options) bool {
+	if opts.Sanitize != nil {
+		return *opts.Sanitize
+	}
+	return !opts.Env.IsDev()
+}
+
+// sanitizeString escapes control characters, so user input can't start a forged
+// line in console or file output, and replaces invalid UTF-8 with U+FFFD. Clean
+// strings are returned as is, without allocating.
+func sanitizeString(s string) string {
+	if isSanitized(s) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b.WriteRune(utf8.RuneError)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case unicode.IsControl(r):
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+func isSanitized(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c == 0x7f {
+			return false
+		}
+		if c >= utf8.RuneSelf {
+			// Non-ASCII: decode the rest to find invalid sequences and C1 controls
+			for _, r := range s[i:] {
+				if r == utf8.RuneError || unicode.IsControl(r) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return true
+}
+
+// sanitizeFields sanitizes string, []string and error values. fields is only
+// copied when one of them needs it, since the caller owns the slice.
+func sanitizeFields(fields []logger.Field) []logger.Field {
+	var out []logger.Field
+	for i, f := range fields {
+		val, changed := sanitizeValue(f.Val)
+		if !changed {
+			continue
+		}
+		if out == nil {
+			out = append([]logger.Field(nil), fields...)
+		}
+		out[i].Val = val
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+func sanitizeValue(val any) (any, bool) {
+	switch v := val.(type) {
+	case string:
+		if !isSanitized(v) {
+			return sanitizeString(v), true
+		}
+	case []string:
+		for i, s := range v {
+			if isSanitized(s) {
+				continue
+			}
+			clean := append([]string(nil), v...)
+			for j := i; j < len(clean); j++ {
+				clean[j] = sanitizeString(clean[j])
+			}
+			return clean, true
+		}
+	case error:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			break // Error would dereference it; zap writes these as "<nil>"
+		}
+		if msg := v.Error(); !isSanitized(msg) {
+			return errors.New(sanitizeString(msg)), true
+		}
+	}
+	return val, false
+}
@@ -0,0 +1,70 @@
+package zapx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx/corefactories"
+	"go.uber.org/zap/zapcore"
+)
+
+// builtSink is a sink as listed by Sinks and flushed by FlushSink
+type builtSink struct {
+	name    string
+	typ     string       // Go type of the factory
+	core    zapcore.Core // As the factory built it, before the metrics and level wrappers
+	follows bool         // The level follows Options.Level
+}
+
+// indexerStatsReporter is implemented by cores with a bulk indexer
+type indexerStatsReporter interface {
+	IndexerStats() logger.IndexerStats
+}
+
+// Sinks lists the sinks the logger writes to, in build order
+func (l *zapAdapter) Sinks() []logger.SinkInfo {
+	infos := make([]logger.SinkInfo, 0, len(l.sinks))
+	for _, s := range l.sinks {
+		info := logger.SinkInfo{Name: s.name, Type: s.typ, Healthy: true}
+		if h, ok := s.core.(corefactories.HealthReporter); ok {
+			info.Healthy = h.Healthy()
+		}
+		if is, ok := s.core.(indexerStatsReporter); ok {
+			stats := is.IndexerStats()
+			info.Indexer = &stats
+		}
+		if s.follows && l.levels != nil {
+			info.Level = fromZapLevel(l.levels.current.Load().base)
+		} else {
+			info.Level = fromZapLevel(zapcore.LevelOf(s.core))
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// FlushSink writes out what the sink named name buffers: cores implementing
+// corefactories.Flusher flush, the others sync
+func (l *zapAdapter) FlushSink(ctx context.Context, name string) error {
+	if l.closed.Load() {
+		return errors.New("logger is closed")
+	}
+	for _, s := range l.sinks {
+		if s.name != name {
+			continue
+		}
+		var err error
+		if f, ok := s.core.(corefactories.Flusher); ok {
+			err = f.Flush(ctx)
+		} else {
+			err = s.core.Sync()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to flush %s sink: %w", name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %q", logger.ErrUnknownSink, name)
+}
@@ -0,0 +1,76 @@
+package zapx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+// recordingSpan returns the span of ctx when span events are on and it records
+func (l *zapAdapter) recordingSpan(ctx context.Context) trace.Span {
+	if l.spanEventsAt == zapcore.InvalidLevel {
+		return nil
+	}
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		return span
+	}
+	return nil
+}
+
+// addSpanEvent records an entry as an event on l.span. It is best-effort: a
+// panicking span implementation never fails the log call.
+func (l *zapAdapter) addSpanEvent(level zapcore.Level, msg string, fields []logger.Field) {
+	if !l.zl.Core().Enabled(level) || !l.span.IsRecording() {
+		return
+	}
+	defer func() { recover() }()
+
+	attrs := make([]attribute.KeyValue, 0, len(fields)+1)
+	attrs = append(attrs, attribute.String("level", level.String()))
+	var err error
+	for _, f := range fields {
+		if e, ok := f.Val.(error); ok && err == nil {
+			err = e
+		}
+		attrs = append(attrs, toAttribute(f))
+	}
+	l.span.AddEvent(msg, trace.WithAttributes(attrs...))
+
+	if level >= zapcore.ErrorLevel {
+		if err == nil {
+			err = errors.New(msg)
+		}
+		l.span.RecordError(err)
+		l.span.SetStatus(codes.Error, msg)
+	}
+}
+
+func toAttribute(f logger.Field) attribute.KeyValue {
+	switch v := f.Val.(type) {
+	case string:
+		return attribute.String(f.Key, v)
+	case bool:
+		return attribute.Bool(f.Key, v)
+	case int:
+		return attribute.Int(f.Key, v)
+	case int64:
+		return attribute.Int64(f.Key, v)
+	case float64:
+		return attribute.Float64(f.Key, v)
+	case time.Duration:
+		return attribute.String(f.Key, v.String())
+	case error:
+		return attribute.String(f.Key, v.Error())
+	case fmt.Stringer:
+		return attribute.String(f.Key, v.String())
+	default:
+		return attribute.String(f.Key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,270 @@
+package sqlitex
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+
+	queueSize     = 8192
+	pruneInterval = time.Hour
+	closeTimeout  = 10 * time.Second
+
+	// tsLayout is fixed-width UTC so that text comparison orders rows by time
+	tsLayout = "2006-01-02T15:04:05.000000000Z"
+)
+
+// row is one pending insert
+type row struct {
+	ts     string
+	level  string
+	msg    string
+	fields string
+}
+
+// sqliteCore encodes fields as JSON; time, level and message get their own columns
+type sqliteCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	w   *writer
+}
+
+func newCore(encCfg zapcore.EncoderConfig, enab zapcore.LevelEnabler, w *writer) zapcore.Core {
+	encCfg.TimeKey = ""
+	encCfg.LevelKey = ""
+	encCfg.MessageKey = ""
+	encCfg.LineEnding = ""
+	return &sqliteCore{
+		LevelEnabler: enab,
+		enc:          zapcore.NewJSONEncoder(encCfg),
+		w:            w,
+	}
+}
+
+func (c *sqliteCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(clone.enc)
+	}
+	return &clone
+}
+
+func (c *sqliteCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *sqliteCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	r := row{
+		ts:     ent.Time.UTC().Format(tsLayout),
+		level:  ent.Level.String(),
+		msg:    ent.Message,
+		fields: string(bytes.TrimRight(buf.Bytes(), "\n")),
+	}
+	buf.Free()
+
+	c.w.add(r)
+	return nil
+}
+
+func (c *sqliteCore) Sync() error {
+	return nil
+}
+
+// writer owns the database and inserts queued rows in batches from a single goroutine
+type writer struct {
+	db            *sql.DB
+	owned         bool // Close the database on Close; false for a borrowed Options.SQLite.DB
+	insertSQL     string
+	deleteSQL     string
+	batchSize     int
+	flushInterval time.Duration
+	retention     time.Duration
+	metrics       *logger.Metrics
+	diagnosticf   func(format string, args ...any)
+	clock         logger.Clock
+
+	queue   chan row
+	mu      sync.Mutex
+	closed  bool
+	done    chan struct{}
+	stopped chan struct{}
+	failing bool // Only touched by run
+}
+
+func newWriter(db *sql.DB, owned bool, table string, config *logger.SQLiteSink, metrics *logger.Metrics, diagnosticf func(string, ...any), clock logger.Clock) *writer {
+	w := &writer{
+		db:            db,
+		owned:         owned,
+		insertSQL:     `INSERT INTO ` + table + ` (ts, level, msg, fields) VALUES (?, ?, ?, ?)`,
+		deleteSQL:     `DELETE FROM ` + table + ` WHERE ts < ?`,
+		batchSize:     config.BatchSize,
+		flushInterval: config.FlushInterval,
+		retention:     time.Duration(config.RetentionDays) * 24 * time.Hour,
+		metrics:       metrics,
+		diagnosticf:   diagnosticf,
+		clock:         clock,
+		queue:         make(chan row, queueSize),
+		done:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+	if w.batchSize <= 0 {
+		w.batchSize = defaultBatchSize
+	}
+	if w.flushInterval <= 0 {
+		w.flushInterval = defaultFlushInterval
+	}
+	go w.run()
+	return w
+}
+
+// add queues r without blocking; rows are dropped when the queue is full
+func (w *writer) add(r row) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		w.metrics.RecordLogDropped("sqlite", "writer_closed")
+		return
+	}
+	select {
+	case w.queue <- r:
+	default:
+		w.metrics.RecordLogDropped("sqlite", "buffer_full")
+	}
+}
+
+func (w *writer) run() {
+	defer close(w.stopped)
+
+	flush := w.clock.NewTicker(w.flushInterval)
+	defer flush.Stop()
+
+	var prune <-chan time.Time
+	if w.retention > 0 {
+		w.prune()
+		t := w.clock.NewTicker(pruneInterval)
+		defer t.Stop()
+		prune = t.C
+	}
+
+	batch := make([]row, 0, w.batchSize)
+	for {
+		select {
+		case r := <-w.queue:
+			batch = append(batch, r)
+			if len(batch) >= w.batchSize {
+				w.insert(batch)
+				batch = batch[:0]
+			}
+		case <-flush.C:
+			if len(batch) > 0 {
+				w.insert(batch)
+				batch = batch[:0]
+			}
+		case <-prune:
+			w.prune()
+		case <-w.done:
+			// Close stops add before signalling, so the queue only shrinks from here
+			for {
+				select {
+				case r := <-w.queue:
+					batch = append(batch, r)
+					if len(batch) >= w.batchSize {
+						w.insert(batch)
+						batch = batch[:0]
+					}
+				default:
+					if len(batch) > 0 {
+						w.insert(batch)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// insert writes batch in one transaction; a failed batch is dropped
+func (w *writer) insert(batch []row) {
+	if err := w.insertTx(batch); err != nil {
+		if !w.failing {
+			w.failing = true
+			w.diagnosticf("sqlite: insert failed, dropping batch: %v", err)
+		}
+		for range batch {
+			w.metrics.RecordLogDropped("sqlite", "write_error")
+		}
+		return
+	}
+	if w.failing {
+		w.failing = false
+		w.diagnosticf("sqlite: inserts resumed")
+	}
+}
+
+func (w *writer) insertTx(batch []row) error {
+	tx, err := w.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(w.insertSQL)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, r := range batch {
+		if _, err := stmt.Exec(r.ts, r.level, r.msg, r.fields); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// prune deletes rows older than the retention period
+func (w *writer) prune() {
+	cutoff := w.clock.Now().UTC().Add(-w.retention).Format(tsLayout)
+	if _, err := w.db.Exec(w.deleteSQL, cutoff); err != nil {
+		w.diagnosticf("sqlite: failed to prune old rows: %v", err)
+	}
+}
+
+// Close flushes pending rows and closes the database if the sink opened it
+func (w *writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	w.mu.Unlock()
+
+	select {
+	case <-w.stopped:
+	case <-time.After(closeTimeout):
+		return fmt.Errorf("failed to flush sqlite rows within %s", closeTimeout)
+	}
+	if w.owned {
+		return w.db.Close()
+	}
+	return nil
+}
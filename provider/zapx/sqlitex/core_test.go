@@ -0,0 +1,192 @@
+package sqlitex
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	_ "github.com/mattn/go-sqlite3"
+	"go.uber.org/zap/zapcore"
+)
+
+// openMemoryDB returns an in-memory database; a single connection keeps every
+// statement on the same database
+func openMemoryDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func buildCore(t *testing.T, sink logger.SQLiteSink) (zapcore.Core, func() error) {
+	t.Helper()
+	opts := logger.DefaultProductionOptions()
+	opts.SQLite = &sink
+	core, closer, err := (&Factory{}).Build(zapcore.EncoderConfig{
+		TimeKey:    "ts",
+		LevelKey:   "level",
+		MessageKey: "msg",
+		NameKey:    "logger",
+	}, zapcore.InfoLevel, logger.GetMetrics(), opts)
+	if err != nil {
+		t.Fatalf("Failed to build sqlite core: %v", err)
+	}
+	return core, closer
+}
+
+func countRows(t *testing.T, db *sql.DB, table string) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&n); err != nil {
+		t.Fatalf("Failed to count rows: %v", err)
+	}
+	return n
+}
+
+func TestSQLiteRows(t *testing.T) {
+	db := openMemoryDB(t)
+	core, closer := buildCore(t, logger.SQLiteSink{DB: db, Table: "app_logs"})
+
+	ts := time.Date(2026, 3, 9, 14, 0, 0, 123, time.FixedZone("CET", 3600))
+	child := core.With([]zapcore.Field{{Key: "component", Type: zapcore.StringType, String: "sync"}})
+	if err := child.Write(zapcore.Entry{Level: zapcore.WarnLevel, Time: ts, Message: "retrying", LoggerName: "app"},
+		[]zapcore.Field{{Key: "attempt", Type: zapcore.Int64Type, Integer: 2}}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if core.Enabled(zapcore.DebugLevel) {
+		t.Error("Expected the global level to apply")
+	}
+	if err := closer(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	var gotTS, level, msg, fields string
+	err := db.QueryRow(`SELECT ts, level, msg, fields FROM app_logs`).Scan(&gotTS, &level, &msg, &fields)
+	if err != nil {
+		t.Fatalf("Failed to read row: %v", err)
+	}
+	if gotTS != "2026-03-09T13:00:00.000000123Z" || level != "warn" || msg != "retrying" {
+		t.Errorf("Unexpected columns: ts=%q level=%q msg=%q", gotTS, level, msg)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(fields), &doc); err != nil {
+		t.Fatalf("Fields are not JSON: %v", err)
+	}
+	if doc["component"] != "sync" || doc["attempt"] != float64(2) || doc["logger"] != "app" {
+		t.Errorf("Unexpected fields: %v", doc)
+	}
+	for _, key := range []string{"ts", "level", "msg"} {
+		if _, ok := doc[key]; ok {
+			t.Errorf("%q should only be stored in its own column: %v", key, doc)
+		}
+	}
+
+	// The database is borrowed, so it must still be usable
+	if err := db.Ping(); err != nil {
+		t.Errorf("Borrowed database was closed: %v", err)
+	}
+}
+
+func TestSQLiteBatchingAndCloseFlush(t *testing.T) {
+	db := openMemoryDB(t)
+	core, closer := buildCore(t, logger.SQLiteSink{DB: db, BatchSize: 3, FlushInterval: time.Hour})
+
+	for i := 0; i < 7; i++ {
+		core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: "entry"}, nil)
+	}
+
+	// Two full batches are inserted right away, the last row waits for its batch
+	deadline := time.Now().Add(5 * time.Second)
+	for countRows(t, db, "logs") < 6 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := countRows(t, db, "logs"); n != 6 {
+		t.Fatalf("Expected 6 rows from full batches, got %d", n)
+	}
+
+	if err := closer(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if n := countRows(t, db, "logs"); n != 7 {
+		t.Errorf("Expected Close to flush the pending row, got %d rows", n)
+	}
+}
+
+func TestSQLiteRetention(t *testing.T) {
+	db := openMemoryDB(t)
+	if err := createSchema(db, "logs"); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	for _, age := range []time.Duration{10 * 24 * time.Hour, 8 * 24 * time.Hour, time.Hour} {
+		_, err := db.Exec(`INSERT INTO logs (ts, level, msg) VALUES (?, 'info', 'seed')`, now.Add(-age).Format(tsLayout))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, closer := buildCore(t, logger.SQLiteSink{DB: db, RetentionDays: 7})
+	closer()
+
+	if n := countRows(t, db, "logs"); n != 1 {
+		t.Errorf("Expected rows older than 7 days to be pruned, %d rows left", n)
+	}
+}
+
+func TestSQLiteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.db")
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithSQLite(logger.SQLiteSink{Path: path}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	log.Info("saved", logger.F.String("doc", "report.pdf"))
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var msg, fields string
+	if err := db.QueryRow(`SELECT msg, fields FROM logs`).Scan(&msg, &fields); err != nil {
+		t.Fatalf("Failed to read row: %v", err)
+	}
+	if msg != "saved" || !json.Valid([]byte(fields)) {
+		t.Errorf("Unexpected row: msg=%q fields=%q", msg, fields)
+	}
+}
+
+func TestSQLiteInvalidConfig(t *testing.T) {
+	testCases := []struct {
+		name string
+		sink logger.SQLiteSink
+	}{
+		{"NoPathOrDB", logger.SQLiteSink{}},
+		{"InvalidTable", logger.SQLiteSink{Path: ":memory:", Table: "logs; DROP TABLE users"}},
+		{"UnknownDriver", logger.SQLiteSink{Path: ":memory:", Driver: "nosuchdriver"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := logger.DefaultProductionOptions()
+			opts.SQLite = &tc.sink
+			if _, _, err := (&Factory{}).Build(zapcore.EncoderConfig{}, zapcore.InfoLevel, nil, opts); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Package sqlitex stores logs in a local SQLite database, for desktop apps and
+// tools that have no log pipeline. Import it for its side effect together with a
+// database/sql SQLite driver to enable Options.SQLite:
+//
+//	import (
+//		_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/sqlitex"
+//		_ "github.com/mattn/go-sqlite3"
+//	)
+package sqlitex
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx/corefactories"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultDriver = "sqlite3"
+	defaultTable  = "logs"
+)
+
+// validTable keeps the table name safe to interpolate into statements
+var validTable = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Factory creates SQLite-based cores for logging output
+type Factory struct{}
+
+func init() {
+	corefactories.RegisterFactory(&Factory{})
+}
+
+// Name returns the unique name of this factory
+func (f *Factory) Name() string {
+	return "sqlite"
+}
+
+// Enabled determines if SQLite output should be enabled based on options
+func (f *Factory) Enabled(opts logger.Options) bool {
+	return opts.SQLite != nil
+}
+
+// Build opens the database, creates the schema if needed and starts the
+// background writer
+func (f *Factory) Build(encCfg zapcore.EncoderConfig, lvl zapcore.Level, metrics *logger.Metrics, opts logger.Options) (zapcore.Core, func() error, error) {
+	config := opts.SQLite
+
+	table := config.Table
+	if table == "" {
+		table = defaultTable
+	}
+	if !validTable.MatchString(table) {
+		return nil, nil, fmt.Errorf("invalid sqlite table name %q", table)
+	}
+
+	db, owned, err := openDB(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := createSchema(db, table); err != nil {
+		if owned {
+			db.Close()
+		}
+		return nil, nil, err
+	}
+
+	w := newWriter(db, owned, table, config, metrics, opts.Diagnosticf, opts.ClockOrDefault())
+	return newCore(encCfg, lvl, w), w.Close, nil
+}
+
+// openDB returns the configured database and whether the sink owns it
+func openDB(config *logger.SQLiteSink) (*sql.DB, bool, error) {
+	if config.DB != nil {
+		return config.DB, false, nil
+	}
+	if config.Path == "" {
+		return nil, false, fmt.Errorf("sqlite Path or DB is required")
+	}
+
+	driver := config.Driver
+	if driver == "" {
+		driver = defaultDriver
+	}
+	db, err := sql.Open(driver, config.Path)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	// SQLite serializes writers anyway; one connection avoids "database is locked"
+	db.SetMaxOpenConns(1)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, false, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	return db, true, nil
+}
+
+func createSchema(db *sql.DB, table string) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS ` + table + ` (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			ts TEXT NOT NULL,
+			level TEXT NOT NULL,
+			msg TEXT NOT NULL,
+			fields TEXT
+		)`,
+		`CREATE INDEX IF NOT EXISTS ` + table + `_ts ON ` + table + ` (ts)`,
+	}
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to create sqlite schema: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package zapx
+
+import (
+	"sync/atomic"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ZapUnwrapper is implemented by loggers built by this package. It is an
+// escape hatch for libraries that need a *zap.Logger: entries logged through
+// it reach the same sinks, but bypass loggerkit itself (WithContext fields,
+// span events, dropping after Close).
+type ZapUnwrapper interface {
+	UnwrapZap() *zap.Logger
+}
+
+// UnwrapZap returns the underlying zap logger, with its fields and cores
+func (l *zapAdapter) UnwrapZap() *zap.Logger {
+	// Undo the skip of the adapter's own frames
+	zl := l.zl.WithOptions(zap.AddCallerSkip(-2))
+	if len(l.boundFields)+len(l.contextBound) > 0 {
+		// Kept out of zl by DedupFields
+		zl = zl.With(toZapFields(mergeFields(l.contextBound, l.boundFields)...)...)
+	}
+	return zl
+}
+
+// NewFromZap adapts an existing zap logger to logger.Logger. Of opts, only the
+// env, fatal hook, context, service, metrics and span events settings apply:
+// the sinks are those of zl, and Close only syncs it.
+func NewFromZap(zl *zap.Logger, opts ...logger.Option) logger.Logger {
+	var o logger.Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	_ = o.Validate() // An unknown env gets production behavior
+
+	var metrics *logger.Metrics
+	if o.Metrics.Enabled {
+		metrics = logger.GetMetrics()
+		if o.Metrics.ServiceLabel {
+			metrics = metrics.ForService(o.Service)
+		}
+	}
+	spanLvl := zapcore.InvalidLevel
+	if o.SpanEvents != "" {
+		if lvl, err := ToZapLevel(o.SpanEvents); err == nil {
+			spanLvl = lvl
+		}
+	}
+
+	zapOpts := []zap.Option{zap.AddCallerSkip(2)}
+	if o.Env.IsDev() {
+		zapOpts = append(zapOpts, zap.Development())
+	}
+	if o.FatalHook != nil {
+		zapOpts = append(zapOpts, zap.WithFatalHook(ToCheckWriteHook(o.FatalHook)))
+	}
+	if o.Clock != nil {
+		zapOpts = append(zapOpts, zap.WithClock(o.Clock))
+	}
+
+	return &zapAdapter{
+		zl:             zl.WithOptions(zapOpts...),
+		closed:         new(atomic.Bool),
+		metrics:        metrics,
+		metricsEnabled: o.Metrics.Enabled,
+		contextKeys:    o.Context,
+		contextFields:  o.Context.Mappings(),
+		spanEventsAt:   spanLvl,
+		service:        o.Service,
+		development:    o.Env.IsDev(),
+	}
+}
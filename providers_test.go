@@ -3,9 +3,12 @@ package logger_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -54,7 +57,10 @@ func TestConsoleDisabledNoSinksFails(t *testing.T) {
 	)
 
 	if err == nil {
-		t.Error("Expected error when console disabled and no other sinks configured")
+		t.Fatal("Expected error when console disabled and no other sinks configured")
+	}
+	if !errors.Is(err, logger.ErrNoSinks) {
+		t.Errorf("Expected ErrNoSinks, got: %v", err)
 	}
 
 	expectedMsg := "no log sinks configured"
@@ -63,6 +69,63 @@ func TestConsoleDisabledNoSinksFails(t *testing.T) {
 	}
 }
 
+func TestConsoleSinkByEnv(t *testing.T) {
+	// Console is built by the registry for every environment unless disabled;
+	// Env only selects the encoding
+	testCases := []struct {
+		name        string
+		build       func(...logger.Option) (logger.Logger, error)
+		disable     bool
+		withFile    bool
+		wantConsole bool
+		wantJSON    bool
+	}{
+		{"Development", logger.NewDevelopment, false, false, true, false},
+		{"Production", logger.NewProduction, false, false, true, true},
+		{"ProductionWithFile", logger.NewProduction, false, true, true, true},
+		{"DevelopmentDisabled", logger.NewDevelopment, true, true, false, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var opts []logger.Option
+			if tc.disable {
+				opts = append(opts, logger.WithConsoleDisabled())
+			}
+			if tc.withFile {
+				opts = append(opts, logger.WithFile(logger.FileSink{Path: filepath.Join(t.TempDir(), "app.log")}))
+			}
+
+			var sinks []string
+			output, err := testutil.CaptureStdout(func() {
+				log, err := tc.build(opts...)
+				if err != nil {
+					t.Fatalf("Failed to create logger: %v", err)
+				}
+				defer log.Close(context.Background())
+				sinks = logger.Report(log).Sinks
+				log.Info("console probe")
+			})
+			if err != nil {
+				t.Fatalf("Failed to capture stdout: %v", err)
+			}
+
+			hasConsole := false
+			for _, sink := range sinks {
+				hasConsole = hasConsole || sink == "console"
+			}
+			if hasConsole != tc.wantConsole {
+				t.Errorf("Expected console sink=%v, built sinks %v", tc.wantConsole, sinks)
+			}
+			if got := strings.Contains(output, "console probe"); got != tc.wantConsole {
+				t.Fatalf("Expected console output=%v, got %q", tc.wantConsole, output)
+			}
+			if tc.wantConsole && json.Valid([]byte(strings.TrimSpace(output))) != tc.wantJSON {
+				t.Errorf("Expected JSON console output=%v, got %q", tc.wantJSON, output)
+			}
+		})
+	}
+}
+
 func TestConsoleDisabledWithOtherSinks(t *testing.T) {
 	tempFile, cleanup := testutil.TempFile(t, "test-log", ".log")
 	defer cleanup()
@@ -242,3 +305,70 @@ func TestMultipleCoresTee(t *testing.T) {
 		t.Error("Expected correct message content")
 	}
 }
+
+func TestConcurrentConsoleWritesStayWhole(t *testing.T) {
+	output, err := testutil.CaptureStdout(func() {
+		log, err := logger.NewProduction()
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		var wg sync.WaitGroup
+		for g := 0; g < 16; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					// Distinct messages so production sampling keeps every entry
+					log.Info(fmt.Sprintf("concurrent %d-%d", g, i), logger.F.String("pad", strings.Repeat("x", 512)))
+				}
+			}()
+		}
+		wg.Wait()
+		log.Close(context.Background())
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture stdout: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 1600 {
+		t.Fatalf("Expected 1600 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Fatalf("Interleaved console line: %q", line)
+		}
+	}
+}
+
+func TestBufferedWritesFlushOnClose(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	output, err := testutil.CaptureStdout(func() {
+		log, err := logger.NewProduction(
+			logger.WithFile(logger.FileSink{Path: logPath}),
+			logger.WithBufferedWrites(logger.BufferOptions{FlushInterval: time.Hour}),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		log.Info("last words")
+		if data, _ := os.ReadFile(logPath); len(data) != 0 {
+			t.Errorf("Expected the entry to wait in the buffer, file has %q", data)
+		}
+		if err := log.Close(context.Background()); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture stdout: %v", err)
+	}
+
+	if !strings.Contains(output, "last words") {
+		t.Errorf("Expected the buffered console entry after Close, got %q", output)
+	}
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 1 || entries[0]["msg"] != "last words" {
+		t.Errorf("Expected the buffered file entry after Close, got %v", entries)
+	}
+}
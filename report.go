@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoSinks is returned when a logger is built without any enabled sink, for
+// example with the console disabled and no other sink configured
+var ErrNoSinks = errors.New("no log sinks configured")
+
+// BuildReport describes how a logger was built and which sinks it uses
+type BuildReport struct {
+	Env     Env         // Environment the logger was built for
+	Sinks   []string    // Sinks in use, in build order
+	Skipped []SinkError // Sinks left out under SinkErrorSkip
+}
+
+// BuildReporter is implemented by loggers that can report how they were built
+type BuildReporter interface {
+	BuildReport() BuildReport
+}
+
+// Report returns the build report of log, or an empty report when log can't
+// tell how it was built
+func Report(log Logger) BuildReport {
+	if r, ok := log.(BuildReporter); ok {
+		return r.BuildReport()
+	}
+	return BuildReport{}
+}
+
+// Degraded reports whether any sink was left out
+func (r BuildReport) Degraded() bool {
+	return len(r.Skipped) > 0
+}
+
+// SinkError is a sink that failed to build
+type SinkError struct {
+	Sink string
+	Err  error
+}
+
+func (e SinkError) Error() string {
+	return fmt.Sprintf("%s sink: %v", e.Sink, e.Err)
+}
+
+func (e SinkError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RingEntry is one encoded log entry kept by a RingBuffer
+type RingEntry struct {
+	Time  time.Time
+	Level Level
+	Data  []byte // One JSON document, without a trailing newline
+}
+
+// RingBuffer keeps the most recent entries in memory, evicting the oldest once
+// it holds Cap() entries. It is safe for concurrent use.
+type RingBuffer struct {
+	mu      sync.Mutex
+	entries []RingEntry
+	next    int // Slot the next entry goes into
+	full    bool
+}
+
+// NewRingBuffer creates a ring holding up to capacity entries
+func NewRingBuffer(capacity int) *RingBuffer {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &RingBuffer{entries: make([]RingEntry, capacity)}
+}
+
+// Add stores e, evicting the oldest entry when the ring is full. The ring keeps
+// e.Data, so the caller must not modify it afterwards.
+func (r *RingBuffer) Add(e RingEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = e
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// Entries returns the buffered entries, oldest first
+func (r *RingBuffer) Entries() []RingEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]RingEntry(nil), r.entries[:r.next]...)
+	}
+	out := make([]RingEntry, 0, len(r.entries))
+	out = append(out, r.entries[r.next:]...)
+	return append(out, r.entries[:r.next]...)
+}
+
+// Len returns the number of buffered entries
+func (r *RingBuffer) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.full {
+		return len(r.entries)
+	}
+	return r.next
+}
+
+// Cap returns the maximum number of entries kept
+func (r *RingBuffer) Cap() int {
+	return len(r.entries)
+}
+
+var newline = []byte{'\n'}
+
+// ringLogger is implemented by loggers built with a RingSink
+type ringLogger interface {
+	RingBuffer() *RingBuffer
+}
+
+// RingHandler serves the entries buffered by log's RingSink as NDJSON, oldest
+// first. Optional query parameters:
+//
+//	level  lowest level to include (debug, info, warn, error)
+//	since  RFC 3339 timestamp, or a duration such as "5m" meaning the last 5 minutes
+//
+// It responds 404 when log has no RingSink. Mount it on an internal/admin listener:
+// the entries contain whatever the application logs.
+func RingHandler(log Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rl, ok := log.(ringLogger)
+		if !ok || rl.RingBuffer() == nil {
+			http.Error(w, "ring sink not enabled", http.StatusNotFound)
+			return
+		}
+
+		minRank := levelRank(DebugLevel)
+		if v := r.URL.Query().Get("level"); v != "" {
+			lvl, err := ParseLevel(v)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			minRank = levelRank(lvl)
+		}
+
+		var since time.Time
+		if v := r.URL.Query().Get("since"); v != "" {
+			var err error
+			if since, err = parseSince(v, time.Now()); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, e := range rl.RingBuffer().Entries() {
+			if levelRank(e.Level) < minRank || e.Time.Before(since) {
+				continue
+			}
+			// Data is shared with other readers, so the newline is written separately
+			if _, err := w.Write(e.Data); err != nil {
+				return
+			}
+			if _, err := w.Write(newline); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func parseSince(v string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid since %q: want an RFC 3339 time or a duration", v)
+}
+
+// levelRank orders levels by severity; unknown levels rank as error
+func levelRank(l Level) int {
+	switch l {
+	case DebugLevel:
+		return 0
+	case InfoLevel:
+		return 1
+	case WarnLevel:
+		return 2
+	default:
+		return 3
+	}
+}
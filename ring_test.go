@@ -0,0 +1,163 @@
+package logger_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+// In-memory ring buffer sink
+
+func ringDump(t *testing.T, log logger.Logger, query string) (int, []map[string]any) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	logger.RingHandler(log).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/logs"+query, nil))
+	if rec.Code != http.StatusOK {
+		return rec.Code, nil
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Expected NDJSON content type, got %q", ct)
+	}
+
+	var docs []map[string]any
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var doc map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			t.Fatalf("Invalid NDJSON line %q: %v", scanner.Text(), err)
+		}
+		docs = append(docs, doc)
+	}
+	return rec.Code, docs
+}
+
+func TestRingSinkEvictsOldest(t *testing.T) {
+	var log logger.Logger
+	output, _ := testutil.CaptureStdout(func() {
+		var err error
+		log, err = logger.NewProduction(
+			logger.WithLevel(logger.InfoLevel),
+			logger.WithRing(logger.RingSink{Capacity: 5, Level: logger.DebugLevel}),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		for i := 0; i < 8; i++ {
+			log.Debug("debug entry", logger.F.Int("i", i))
+		}
+	})
+	defer log.Close(context.Background())
+
+	if strings.Contains(output, "debug entry") {
+		t.Error("Console must keep the global info level")
+	}
+
+	_, docs := ringDump(t, log, "")
+	if len(docs) != 5 {
+		t.Fatalf("Expected capacity of 5 entries, got %d", len(docs))
+	}
+	for j, doc := range docs {
+		if doc["i"] != float64(j+3) {
+			t.Errorf("Expected entries 3..7 oldest first, got i=%v at %d", doc["i"], j)
+		}
+	}
+}
+
+func TestRingHandlerFilters(t *testing.T) {
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithRing(logger.RingSink{Level: logger.DebugLevel}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+
+	log.Debug("old debug")
+	log.Warn("old warn")
+	time.Sleep(20 * time.Millisecond)
+	cutoff := time.Now()
+	log.Info("new info")
+	log.Error("new error")
+
+	testCases := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"old debug", "old warn", "new info", "new error"}},
+		{"?level=warn", []string{"old warn", "new error"}},
+		{"?since=" + cutoff.UTC().Format(time.RFC3339Nano), []string{"new info", "new error"}},
+		{"?since=1h&level=error", []string{"new error"}},
+	}
+	for _, tc := range testCases {
+		_, docs := ringDump(t, log, tc.query)
+		var got []string
+		for _, doc := range docs {
+			got = append(got, doc["msg"].(string))
+		}
+		if strings.Join(got, ",") != strings.Join(tc.expected, ",") {
+			t.Errorf("%q: expected %v, got %v", tc.query, tc.expected, got)
+		}
+	}
+
+	for _, query := range []string{"?level=verbose", "?since=yesterday"} {
+		if code, _ := ringDump(t, log, query); code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d", query, code)
+		}
+	}
+
+	// Derived loggers serve the same buffer
+	if _, docs := ringDump(t, log.With(logger.F.String("k", "v")), ""); len(docs) != 4 {
+		t.Errorf("Expected derived logger to expose the ring, got %d entries", len(docs))
+	}
+}
+
+func TestRingHandlerWithoutRing(t *testing.T) {
+	log, err := logger.NewProduction()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+
+	if code, _ := ringDump(t, log, ""); code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a ring sink, got %d", code)
+	}
+}
+
+func TestRingMaxEntryBytes(t *testing.T) {
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithRing(logger.RingSink{MaxEntryBytes: 1024}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close(context.Background())
+
+	log.Info("small", logger.F.String("k", "v"))
+	log.Info(strings.Repeat("m", 4000), logger.F.String("blob", strings.Repeat("x", 10000)))
+
+	_, docs := ringDump(t, log, "")
+	if len(docs) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(docs))
+	}
+	if docs[0]["k"] != "v" || docs[0]["ring_truncated"] != nil {
+		t.Errorf("Small entry should be kept as is: %v", docs[0])
+	}
+	big := docs[1]
+	if big["ring_truncated"] != true || big["blob"] != nil || big["level"] != "info" {
+		t.Errorf("Expected a truncated stub, got %v", big)
+	}
+	if raw, _ := json.Marshal(big); len(raw) > 1024 {
+		t.Errorf("Stub exceeds MaxEntryBytes: %d bytes", len(raw))
+	}
+}
@@ -0,0 +1,114 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+// Sanitization of messages and fields
+
+func TestSanitizeProductionByDefault(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+
+	broken := string([]byte{'a', 0xff, 0xfe, 'b'})
+	log.With(logger.F.String("user_agent", "curl\x1b[31m")).Info("login failed\r\nfake-entry",
+		logger.F.String("raw", broken),
+		logger.F.Any("tags", []string{"ok", "bad\ntag"}),
+		logger.F.Err(errors.New("denied\nlevel=info")),
+		logger.F.String("city", "Hà Nội"),
+	)
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 1 {
+		t.Fatalf("Expected a single entry, got %d", len(entries))
+	}
+	e := entries[0]
+	checks := map[string]any{
+		"msg":        `login failed\r\nfake-entry`,
+		"raw":        "a\uFFFD\uFFFDb",
+		"error":      `denied\nlevel=info`,
+		"user_agent": `curl\u001b[31m`,
+		"city":       "Hà Nội",
+	}
+	for key, want := range checks {
+		if e[key] != want {
+			t.Errorf("Expected %s = %q, got %q", key, want, e[key])
+		}
+	}
+	if tags, _ := e["tags"].([]any); len(tags) != 2 || tags[1] != `bad\ntag` {
+		t.Errorf("Expected sanitized tags, got %v", e["tags"])
+	}
+}
+
+func TestSanitizeDevelopment(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		opts  []logger.Option
+		lines int
+	}{
+		{"OffByDefault", nil, 2},
+		{"Enabled", []logger.Option{logger.WithSanitize(true)}, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := testutil.CaptureStdout(func() {
+				log, err := logger.NewDevelopment(tc.opts...)
+				if err != nil {
+					t.Fatalf("Failed to create logger: %v", err)
+				}
+				log.Info("login failed\r\nfake-entry")
+				log.Close(context.Background())
+			})
+			if err != nil {
+				t.Fatalf("Failed to capture stdout: %v", err)
+			}
+			if lines := strings.Count(output, "\n"); lines != tc.lines {
+				t.Errorf("Expected %d output lines, got %d: %q", tc.lines, lines, output)
+			}
+		})
+	}
+}
+
+func TestNormalizeValuesOption(t *testing.T) {
+	for _, normalize := range []bool{false, true} {
+		logPath := filepath.Join(t.TempDir(), "app.log")
+		opts := []logger.Option{logger.WithConsoleDisabled(), logger.WithFile(logger.FileSink{Path: logPath})}
+		if normalize {
+			opts = append(opts, logger.WithNormalizeValues())
+		}
+		log, err := logger.NewProduction(opts...)
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		log.Info("stats", logger.F.Any("ratio", map[string]any{"x": math.NaN()}))
+		if err := log.Close(context.Background()); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+
+		entries := testutil.ReadJSONLines(t, logPath)
+		if len(entries) != 1 {
+			t.Fatalf("Expected a single entry, got %d", len(entries))
+		}
+		e := entries[0]
+		if !normalize {
+			// encoding/json rejects NaN, so zap reports the error instead
+			if _, ok := e["ratioError"]; !ok {
+				t.Errorf("Expected ratioError without normalization, got %v", e)
+			}
+			continue
+		}
+		if ratio, _ := e["ratio"].(map[string]any); ratio["x"] != "NaN" {
+			t.Errorf("Expected ratio.x=NaN, got %v", e["ratio"])
+		}
+	}
+}
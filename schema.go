@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Keys of the entry attributes loggerkit writes in its JSON outputs. The
+// providers encode with these, so Schema describes what they emit.
+const (
+	TimeKey           = "ts"
+	LevelKey          = "level"
+	LoggerKey         = "logger"
+	CallerKey         = "caller"
+	MessageKey        = "msg"
+	StacktraceKey     = "stacktrace"
+	DPanicKey         = "dpanic"          // true on DPanic entries outside EnvDev
+	SampledDroppedKey = "sampled_dropped" // See WithSamplingMarker
+)
+
+// SchemaVersion changes when the layout of EntrySchema does
+const SchemaVersion = 1
+
+// SchemaType is the JSON type of a field
+type SchemaType string
+
+const (
+	SchemaString SchemaType = "string"
+	SchemaNumber SchemaType = "number"
+	SchemaBool   SchemaType = "bool"
+	SchemaAny    SchemaType = "any" // Whatever the value logged encodes to
+)
+
+// SchemaField is one key an entry may carry
+type SchemaField struct {
+	Name     string     `json:"name"`
+	Type     SchemaType `json:"type"`
+	Required bool       `json:"required"`         // In every entry
+	Source   string     `json:"source"`           // builtin, context, trace, baggage or static
+	Format   string     `json:"format,omitempty"` // Time layout of ts
+	Sinks    []string   `json:"sinks,omitempty"`  // Only written by these sinks (empty: every JSON output)
+}
+
+// EntrySchema describes the keys loggerkit writes into a JSON entry for a
+// configuration. Fields logged by the application are not part of it.
+type EntrySchema struct {
+	Version int           `json:"version"`
+	Fields  []SchemaField `json:"fields"`
+}
+
+// Field returns the schema field named name
+func (s EntrySchema) Field(name string) (SchemaField, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return SchemaField{}, false
+}
+
+// Schema describes the keys loggers built from opts write: the entry
+// attributes, the WithContext fields, and the fields Elasticsearch adds
+func Schema(opts Options) EntrySchema {
+	timeFormat := opts.TimeFormat
+	if timeFormat == "" {
+		timeFormat = "2006-01-02T15:04:05.000Z0700" // ISO8601 with milliseconds
+	}
+	fields := []SchemaField{
+		{Name: TimeKey, Type: SchemaString, Required: true, Source: "builtin", Format: timeFormat},
+		{Name: LevelKey, Type: SchemaString, Required: true, Source: "builtin"},
+		{Name: MessageKey, Type: SchemaString, Required: true, Source: "builtin"},
+		{Name: LoggerKey, Type: SchemaString, Source: "builtin"},
+		{Name: CallerKey, Type: SchemaString, Required: opts.EnableCaller, Source: "builtin"},
+		{Name: StacktraceKey, Type: SchemaString, Source: "builtin"},
+		{Name: DPanicKey, Type: SchemaBool, Source: "builtin"},
+	}
+	if opts.SamplingMarker {
+		fields = append(fields, SchemaField{Name: SampledDroppedKey, Type: SchemaNumber, Source: "builtin"})
+	}
+
+	for _, m := range opts.Context.Mappings() {
+		fields = append(fields, SchemaField{Name: m.Field, Type: SchemaAny, Source: "context"})
+	}
+	if !opts.Context.DisableTraceExtraction {
+		traceID, spanID, flags := opts.Context.TraceFieldNames()
+		fields = append(fields,
+			SchemaField{Name: traceID, Type: SchemaString, Source: "trace"},
+			SchemaField{Name: spanID, Type: SchemaString, Source: "trace"},
+			SchemaField{Name: flags, Type: SchemaBool, Source: "trace"},
+		)
+	}
+	for _, key := range opts.Context.Baggage {
+		fields = append(fields, SchemaField{Name: key, Type: SchemaString, Source: "baggage"})
+	}
+
+	if opts.Elastic != nil {
+		es := []string{"elasticsearch"}
+		static := []SchemaField{{Name: "service", Type: SchemaString, Required: true, Source: "static", Sinks: es}}
+		if opts.Env != "" {
+			static = append(static, SchemaField{Name: "env", Type: SchemaString, Required: true, Source: "static", Sinks: es})
+		}
+		keys := make([]string, 0, len(opts.Elastic.StaticFields))
+		for k := range opts.Elastic.StaticFields {
+			if k != "service" && k != "env" {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			static = append(static, SchemaField{Name: k, Type: SchemaString, Required: true, Source: "static", Sinks: es})
+		}
+		fields = append(fields, static...)
+	}
+	return EntrySchema{Version: SchemaVersion, Fields: fields}
+}
+
+// VerifyEntry checks that entry, one JSON line as written by a logger, has
+// the required fields of schema and that the schema's fields it has are of
+// the right type. Fields only written by some sinks are not required.
+func VerifyEntry(schema EntrySchema, entry []byte) error {
+	var doc map[string]any
+	if err := json.Unmarshal(entry, &doc); err != nil {
+		return fmt.Errorf("failed to parse entry: %w", err)
+	}
+
+	var errs []error
+	for _, f := range schema.Fields {
+		v, ok := doc[f.Name]
+		if !ok {
+			if f.Required && len(f.Sinks) == 0 {
+				errs = append(errs, fmt.Errorf("missing required field %q", f.Name))
+			}
+			continue
+		}
+		if !schemaTypeOf(f.Type, v) {
+			errs = append(errs, fmt.Errorf("field %q: expected %s, got %T", f.Name, f.Type, v))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func schemaTypeOf(t SchemaType, v any) bool {
+	switch t {
+	case SchemaString:
+		_, ok := v.(string)
+		return ok
+	case SchemaNumber:
+		_, ok := v.(float64)
+		return ok
+	case SchemaBool:
+		_, ok := v.(bool)
+		return ok
+	default:
+		return true
+	}
+}
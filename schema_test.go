@@ -0,0 +1,138 @@
+package logger_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSchemaMatchesEmittedEntries(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	options := []logger.Option{
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithContext(logger.ContextKeys{RequestIDKey: "request_id"}),
+		logger.WithTimeFormat(time.RFC3339),
+	}
+	log, err := logger.NewProduction(options...)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), "request_id", "req-1")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	log.Info("plain")
+	log.WithContext(ctx).Info("in request", logger.F.Int("attempt", 2))
+	log.Named("db").Error("failed", logger.F.Err(errors.New("boom")))
+	log.DPanic("unexpected")
+	closeLogger(t, log)
+
+	opts := logger.DefaultProductionOptions()
+	for _, opt := range options {
+		opt(&opts)
+	}
+	schema := logger.Schema(opts)
+	if f, ok := schema.Field(logger.TimeKey); !ok || f.Format != time.RFC3339 {
+		t.Errorf("Expected ts with the configured format, got %+v", f)
+	}
+	for _, name := range []string{"level", "msg", "caller", "request_id", "trace_id", "span_id"} {
+		if _, ok := schema.Field(name); !ok {
+			t.Errorf("Expected %q in the schema", name)
+		}
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 entries, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if err := logger.VerifyEntry(schema, []byte(line)); err != nil {
+			t.Errorf("Entry does not match the schema: %v\n%s", err, line)
+		}
+	}
+	entries := testutil.ReadJSONLines(t, logPath)
+	if _, err := time.Parse(time.RFC3339, entries[0][logger.TimeKey].(string)); err != nil {
+		t.Errorf("Expected ts in the schema's format: %v", err)
+	}
+	if entries[1]["request_id"] != "req-1" || entries[1]["trace_id"] == nil {
+		t.Errorf("Expected the context fields: %v", entries[1])
+	}
+	if entries[2][logger.LoggerKey] != "db" || entries[2][logger.StacktraceKey] == nil {
+		t.Errorf("Expected logger and stacktrace keys: %v", entries[2])
+	}
+	if entries[3][logger.DPanicKey] != true {
+		t.Errorf("Expected the dpanic key: %v", entries[3])
+	}
+}
+
+func TestSchemaElasticStaticFields(t *testing.T) {
+	opts := logger.DefaultProductionOptions()
+	logger.WithService("api")(&opts)
+	logger.WithElastic(logger.ElasticSink{
+		Addresses:    []string{"http://localhost:9200"},
+		StaticFields: map[string]string{"region": "eu"},
+	})(&opts)
+
+	schema := logger.Schema(opts)
+	for _, name := range []string{"service", "env", "region"} {
+		f, ok := schema.Field(name)
+		if !ok || !f.Required || len(f.Sinks) != 1 || f.Sinks[0] != "elasticsearch" {
+			t.Errorf("Expected %q as a required elasticsearch field, got %+v", name, f)
+		}
+	}
+	// Not required of file or console lines
+	if err := logger.VerifyEntry(schema, []byte(`{"ts":"x","level":"info","msg":"m","caller":"a.go:1"}`)); err != nil {
+		t.Errorf("Expected sink-specific fields to be optional: %v", err)
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("Failed to marshal schema: %v", err)
+	}
+	var decoded logger.EntrySchema
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal schema: %v", err)
+	}
+	if decoded.Version != logger.SchemaVersion || len(decoded.Fields) != len(schema.Fields) {
+		t.Errorf("Expected the schema to round-trip, got %s", data)
+	}
+}
+
+func TestVerifyEntryRejects(t *testing.T) {
+	schema := logger.Schema(logger.DefaultProductionOptions())
+
+	tests := []struct {
+		name  string
+		entry string
+		want  string
+	}{
+		{"missing msg", `{"ts":"x","level":"info","caller":"a.go:1"}`, `missing required field "msg"`},
+		{"wrong type", `{"ts":"x","level":1,"msg":"m","caller":"a.go:1"}`, `field "level": expected string`},
+		{"not json", `{"ts":`, "failed to parse entry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := logger.VerifyEntry(schema, []byte(tt.entry))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
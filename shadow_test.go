@@ -0,0 +1,97 @@
+package logger_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/elasticx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// Shadow (dual-write) sinks
+
+func TestShadowFailuresOnlyCounted(t *testing.T) {
+	primary := testutil.NewElasticsearchMock()
+	defer primary.Close()
+	shadow := testutil.NewElasticsearchMock()
+	defer shadow.Close()
+
+	rejected := testutil.MockBulkItem{Index: testutil.MockBulkItemResult{
+		Status: http.StatusBadRequest,
+		Error:  &testutil.MockBulkItemError{Type: "illegal_argument_exception", Reason: "only write ops with an op_type of create are allowed in data streams"},
+	}}
+	shadow.SetBulkResponse(http.StatusOK, []testutil.MockBulkItem{rejected, rejected, rejected})
+
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithShadow(
+			logger.ElasticRef(logger.ElasticSink{Addresses: []string{primary.URL}, FlushInterval: time.Minute}),
+			logger.ElasticRef(logger.ElasticSink{Addresses: []string{shadow.URL}, Index: "logs-app-default", FlushInterval: time.Minute}),
+		),
+		logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+		logger.WithDiagnostics(&testutil.SafeBuffer{}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	dropped := logger.GetMetrics().LogsDropped.WithLabelValues("elasticsearch", "index_failure")
+	failures := logger.GetMetrics().ShadowFailures.WithLabelValues("elasticsearch", "index_failure")
+	droppedBefore, before := promtestutil.ToFloat64(dropped), promtestutil.ToFloat64(failures)
+
+	for i := 0; i < 3; i++ {
+		log.Info("order placed", logger.F.Int("i", i))
+	}
+	closeLogger(t, log)
+
+	if !primary.WaitForDocs(3, 2*time.Second) {
+		t.Fatalf("Expected 3 documents in the primary, got %d", len(primary.GetReceivedDocs()))
+	}
+	if n := len(shadow.GetReceivedDocs()); n != 3 {
+		t.Errorf("Expected the shadow to receive all 3 documents, got %d", n)
+	}
+	if got := promtestutil.ToFloat64(failures) - before; got != 3 {
+		t.Errorf("Expected 3 shadow failures, got %v", got)
+	}
+
+	// Shadow rejections must not look like primary drops
+	if got := promtestutil.ToFloat64(dropped) - droppedBefore; got != 0 {
+		t.Errorf("Expected no primary drops, got %v", got)
+	}
+}
+
+func TestShadowBuildFailureKeepsPrimary(t *testing.T) {
+	primary := testutil.NewElasticsearchMock()
+	defer primary.Close()
+
+	diag := &testutil.SafeBuffer{}
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithShadow(
+			logger.ElasticRef(logger.ElasticSink{Addresses: []string{primary.URL}}),
+			logger.ElasticRef(logger.ElasticSink{Addresses: []string{"http://127.0.0.1:1"}, PingOnStartup: true, StartupTimeout: 200 * time.Millisecond}),
+		),
+		logger.WithDiagnostics(diag),
+	)
+	if err != nil {
+		t.Fatalf("A broken shadow must not fail the logger: %v", err)
+	}
+	log.Info("still delivered")
+	closeLogger(t, log)
+
+	if !primary.WaitForDocs(1, 2*time.Second) {
+		t.Error("Expected the primary to keep working")
+	}
+	if !strings.Contains(diag.String(), "shadow sink elasticsearch disabled") {
+		t.Error("Expected the disabled shadow to be reported")
+	}
+
+	if _, err := logger.NewProduction(logger.WithShadow(logger.SinkRef{Factory: "nope"}, logger.FileRef(logger.FileSink{Path: "x.log"}))); err == nil {
+		t.Error("Expected an error for an unknown primary sink")
+	}
+}
@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultShutdownGrace bounds the Close run by FlushOnShutdown
+const DefaultShutdownGrace = 10 * time.Second
+
+// ShutdownOption configures FlushOnShutdown
+type ShutdownOption func(*shutdownConfig)
+
+type shutdownConfig struct {
+	signals []os.Signal
+	grace   time.Duration
+	message string
+	reraise bool
+}
+
+// WithShutdownSignals replaces the signals that trigger the flush (SIGTERM and SIGINT)
+func WithShutdownSignals(sigs ...os.Signal) ShutdownOption {
+	return func(c *shutdownConfig) {
+		c.signals = sigs
+	}
+}
+
+// WithShutdownGrace sets how long Close may take once a signal arrives
+func WithShutdownGrace(d time.Duration) ShutdownOption {
+	return func(c *shutdownConfig) {
+		c.grace = d
+	}
+}
+
+// WithShutdownMessage logs msg at info level with a "signal" field before closing
+func WithShutdownMessage(msg string) ShutdownOption {
+	return func(c *shutdownConfig) {
+		c.message = msg
+	}
+}
+
+// WithShutdownReraise raises the signal again once the logger is closed, with
+// every handler for it reset, so the process terminates as it would have without
+// FlushOnShutdown. Use it in programs that don't handle the signal themselves.
+func WithShutdownReraise() ShutdownOption {
+	return func(c *shutdownConfig) {
+		c.reraise = true
+	}
+}
+
+// FlushOnShutdown closes log when the process receives SIGTERM or SIGINT, so
+// buffered entries are flushed before exit. It stops listening when ctx is done.
+//
+// Catching the signal suppresses its default action: on its own, FlushOnShutdown
+// leaves the process running with a closed logger that drops every entry. Either
+// the program handles the signal too, e.g. with signal.NotifyContext, whose
+// handlers still receive it, or WithShutdownReraise terminates the process once
+// the logs are flushed. A second signal gets the default behavior.
+//
+// stop deregisters the handler; it waits for a flush in progress, so deferring it
+// in main keeps the process alive until the logs are out.
+func FlushOnShutdown(ctx context.Context, log Logger, opts ...ShutdownOption) (stop func()) {
+	config := shutdownConfig{
+		signals: []os.Signal{syscall.SIGTERM, os.Interrupt},
+		grace:   DefaultShutdownGrace,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, config.signals...)
+	quit := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer signal.Stop(sigs)
+
+		var sig os.Signal
+		select {
+		case sig = <-sigs:
+		case <-ctx.Done():
+			return
+		case <-quit:
+			return
+		}
+		signal.Stop(sigs) // A second signal gets the default behavior
+
+		if config.message != "" {
+			log.Info(config.message, F.String("signal", sig.String()))
+		}
+		// The grace period is not cut short by ctx, which is often cancelled by the same signal
+		closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), config.grace)
+		defer cancel()
+		if err := log.Close(closeCtx); err != nil {
+			Options{}.Diagnosticf("failed to flush logs on %s: %v", sig, err)
+		}
+		if config.reraise {
+			reraise(sig)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(quit) })
+		<-done
+	}
+}
+
+// reraise delivers sig to the process with its default action restored. When
+// the signal can't be sent, e.g. os.Interrupt on Windows, or doesn't terminate
+// the process, it exits with status 1.
+func reraise(sig os.Signal) {
+	signal.Reset(sig)
+	p, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = p.Signal(sig)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+	time.Sleep(time.Second) // Delivery is asynchronous
+	os.Exit(1)
+}
@@ -2,13 +2,22 @@ package logger_test
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	logger "github.com/HoangAnhNguyen269/loggerkit"
 	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx/corefactories"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/elasticx"
 	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap/zapcore"
 )
 
 // I) Graceful Shutdown & Resource Management
@@ -64,22 +73,64 @@ func TestLoggerCloseFlushesAll(t *testing.T) {
 	// Note: File flushing is harder to test deterministically, but no panics is a good sign
 }
 
+// closerFactory is enabled by an extension named after it and builds a no-op core
+// with the given closer
+type closerFactory struct {
+	name   string
+	closer func() error
+}
+
+func (f *closerFactory) Name() string { return f.name }
+
+func (f *closerFactory) Enabled(opts logger.Options) bool {
+	_, ok := logger.GetExtension[bool](opts, f.name)
+	return ok
+}
+
+func (f *closerFactory) Build(_ zapcore.EncoderConfig, lvl zapcore.Level, _ *logger.Metrics, _ logger.Options) (zapcore.Core, func() error, error) {
+	return zapcore.NewNopCore(), f.closer, nil
+}
+
+// registerCloserFactory registers f until the test ends
+func registerCloserFactory(t *testing.T, f *closerFactory) logger.Option {
+	t.Helper()
+	corefactories.RegisterFactory(f)
+	t.Cleanup(func() { corefactories.UnregisterFactory(f.name) })
+	return logger.WithExtension(f.name, true)
+}
+
 func TestCloseWithTimeout(t *testing.T) {
-	log, err := logger.NewDevelopment()
+	release := make(chan struct{})
+	defer close(release)
+	blocking := registerCloserFactory(t, &closerFactory{name: "blocking", closer: func() error {
+		<-release
+		return nil
+	}})
+
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: filepath.Join(t.TempDir(), "app.log")}),
+		blocking,
+	)
 	if err != nil {
 		t.Fatalf("Failed to create logger: %v", err)
 	}
-
-	// Log a message
 	log.Info("Test message")
 
-	// Close with very short timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Nanosecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
-
-	// This might timeout, but should not panic
+	start := time.Now()
 	err = log.Close(ctx)
-	// We don't check error since timeout is expected with such short duration
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Close ignored the deadline, took %v", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected a deadline error, got %v", err)
+	}
+	// Only the sink that didn't finish is named
+	if !strings.Contains(err.Error(), "blocking") || strings.Contains(err.Error(), "file") {
+		t.Errorf("Expected the error to name only the blocking sink, got %v", err)
+	}
 
 	// Should be able to create another logger after close
 	log2, err := logger.NewDevelopment()
@@ -91,6 +142,120 @@ func TestCloseWithTimeout(t *testing.T) {
 	}
 }
 
+// closeError is the error of a failing test sink
+type closeError struct {
+	sink string
+}
+
+func (e *closeError) Error() string {
+	return e.sink + " flush failed"
+}
+
+func TestCloseReportsEveryError(t *testing.T) {
+	errDisk := errors.New("disk full")
+	disk := registerCloserFactory(t, &closerFactory{name: "disk", closer: func() error { return errDisk }})
+	remote := registerCloserFactory(t, &closerFactory{name: "remote", closer: func() error {
+		return &closeError{sink: "remote"}
+	}})
+
+	log, err := logger.NewProduction(logger.WithConsoleDisabled(), disk, remote)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	err = log.Close(context.Background())
+	if err == nil {
+		t.Fatal("Expected Close to fail")
+	}
+
+	for _, want := range []string{"disk: disk full", "remote: remote flush failed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in %q", want, err)
+		}
+	}
+	if !errors.Is(err, errDisk) {
+		t.Error("Expected errors.Is to find the disk error")
+	}
+	var ce *closeError
+	if !errors.As(err, &ce) || ce.sink != "remote" {
+		t.Error("Expected errors.As to find the remote error")
+	}
+}
+
+func TestDerivedLoggerCloseKeepsSinks(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithElastic(logger.ElasticSink{Addresses: []string{mockES.URL}, FlushInterval: 50 * time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	child := log.With(logger.F.String("request_id", "r-1"))
+	child.Info("from child")
+	if err := child.Close(context.Background()); err != nil {
+		t.Fatalf("Child Close failed: %v", err)
+	}
+
+	log.Info("from parent")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "from parent") {
+		t.Errorf("Expected the parent to keep writing to the file, got %q", content)
+	}
+	if !mockES.WaitForDocs(2, 2*time.Second) {
+		t.Fatalf("Expected both entries in Elasticsearch, got %d", len(mockES.GetReceivedDocs()))
+	}
+	if docs := mockES.GetReceivedDocs(); docs[len(docs)-1]["msg"] != "from parent" {
+		t.Errorf("Expected the parent entry last, got %v", docs)
+	}
+}
+
+func TestWithContextCloseKeepsSinks(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []logger.Option
+	}{
+		{"NothingToExtract", []logger.Option{logger.WithoutTraceExtraction()}},
+		{"NothingInContext", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logPath := filepath.Join(t.TempDir(), "app.log")
+			log, err := logger.NewProduction(append([]logger.Option{
+				logger.WithConsoleDisabled(),
+				logger.WithFile(logger.FileSink{Path: logPath}),
+			}, tc.opts...)...)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+
+			scoped := log.WithContext(context.Background())
+			if err := scoped.Close(context.Background()); err != nil {
+				t.Fatalf("Scoped Close failed: %v", err)
+			}
+			log.Info("from parent")
+			if err := log.Close(context.Background()); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			if entries := testutil.ReadJSONLines(t, logPath); len(entries) != 1 || entries[0]["msg"] != "from parent" {
+				t.Errorf("Expected the parent to keep writing after the scoped Close, got %v", entries)
+			}
+		})
+	}
+}
+
 // J) Concurrency & Race
 
 func TestConcurrentLoggingNoDataRace(t *testing.T) {
@@ -211,7 +376,13 @@ func TestConcurrentWithChaining(t *testing.T) {
 }
 
 func TestConcurrentClose(t *testing.T) {
-	log, err := logger.NewProduction()
+	var closes atomic.Int32
+	counting := registerCloserFactory(t, &closerFactory{name: "counting", closer: func() error {
+		closes.Add(1)
+		return nil
+	}})
+
+	log, err := logger.NewProduction(counting)
 	if err != nil {
 		t.Fatalf("Failed to create logger: %v", err)
 	}
@@ -230,15 +401,59 @@ func TestConcurrentClose(t *testing.T) {
 				log.Info("Message before concurrent close", logger.F.Int("goroutine", id))
 			}
 
-			// Try to close concurrently (only one should succeed)
+			// Try to close concurrently (only one should close the sinks)
 			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 			defer cancel()
-			log.Close(ctx) // Multiple closes should be safe
+			if err := log.Close(ctx); err != nil {
+				t.Errorf("Close failed: %v", err)
+			}
 		}(i)
 	}
 
 	wg.Wait()
-	// Should not panic or cause data races
+	if n := closes.Load(); n != 1 {
+		t.Errorf("Expected the sink to be closed exactly once, got %d", n)
+	}
+}
+
+func TestLoggingAfterCloseIsDropped(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewProduction(
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithMetrics(logger.MetricsOptions{Enabled: true}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	child := log.With(logger.F.String("component", "worker"))
+
+	log.Info("before close")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := log.Close(context.Background()); err != nil {
+		t.Errorf("Expected a second Close to return nil, got %v", err)
+	}
+
+	closedDrops := logger.GetMetrics().LogsDropped.WithLabelValues("logger", "logger_closed")
+	before := promtestutil.ToFloat64(closedDrops)
+	log.Info("after close")
+	child.Error("child after close")
+	if err := child.Close(context.Background()); err != nil {
+		t.Errorf("Expected Close on a child of a closed logger to return nil, got %v", err)
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if strings.Contains(string(content), "after close") {
+		t.Errorf("Expected entries after Close to be dropped, got %q", content)
+	}
+	if got := promtestutil.ToFloat64(closedDrops) - before; got != 2 {
+		t.Errorf("Expected 2 logger_closed drops, got %v", got)
+	}
 }
 
 // Test factory registry isolation between tests
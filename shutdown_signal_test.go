@@ -0,0 +1,147 @@
+//go:build unix
+
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+// Flush on shutdown. The tests use SIGUSR1 and keep their own handler registered,
+// so a signal the helper doesn't catch can't terminate the test binary.
+
+func notifyUSR1(t *testing.T) chan os.Signal {
+	t.Helper()
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR1)
+	t.Cleanup(func() { signal.Stop(ch) })
+	return ch
+}
+
+func waitSignal(t *testing.T, ch chan os.Signal) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the test's own handler to receive the signal")
+	}
+}
+
+func TestFlushOnShutdownClosesOnSignal(t *testing.T) {
+	own := notifyUSR1(t)
+	closed := make(chan struct{})
+	sink := registerCloserFactory(t, &closerFactory{name: "flushed", closer: func() error {
+		close(closed)
+		return nil
+	}})
+	log, logPath := testutil.FileLogger(t, sink)
+
+	stop := logger.FlushOnShutdown(context.Background(), log,
+		logger.WithShutdownSignals(syscall.SIGUSR1),
+		logger.WithShutdownGrace(time.Second),
+		logger.WithShutdownMessage("shutting down"),
+	)
+	defer stop()
+
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the logger to be closed on the signal")
+	}
+	// Other handlers still see the signal
+	waitSignal(t, own)
+	stop()
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), `"msg":"shutting down","signal":"user defined signal 1"`) {
+		t.Errorf("Expected the final entry with the signal name, got %q", content)
+	}
+}
+
+func TestFlushOnShutdownStop(t *testing.T) {
+	testCases := []struct {
+		name string
+		stop func(cancel context.CancelFunc, stop func())
+	}{
+		{"Stop", func(_ context.CancelFunc, stop func()) { stop() }},
+		{"ContextDone", func(cancel context.CancelFunc, stop func()) { cancel(); stop() }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			own := notifyUSR1(t)
+			closes := 0
+			sink := registerCloserFactory(t, &closerFactory{name: "deregistered", closer: func() error {
+				closes++
+				return nil
+			}})
+			log, err := logger.NewProduction(logger.WithConsoleDisabled(), sink)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			defer log.Close(context.Background())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			stop := logger.FlushOnShutdown(ctx, log, logger.WithShutdownSignals(syscall.SIGUSR1))
+			tc.stop(cancel, stop)
+			stop() // Safe to call again
+
+			syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+			waitSignal(t, own)
+			if closes != 0 {
+				t.Error("Expected no Close after the handler was stopped")
+			}
+		})
+	}
+}
+
+func TestFlushOnShutdownReraise(t *testing.T) {
+	if logPath := os.Getenv("LOGGERKIT_RERAISE_LOG"); logPath != "" {
+		log, err := logger.NewProduction(logger.WithConsoleDisabled(), logger.WithFile(logger.FileSink{Path: logPath}),
+			logger.WithBufferedWrites(logger.BufferOptions{FlushInterval: time.Hour})) // Only Close flushes
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		// SIGHUP, unlike SIGUSR1, terminates a Go program by default. Only this
+		// child process receives it.
+		logger.FlushOnShutdown(context.Background(), log,
+			logger.WithShutdownSignals(syscall.SIGHUP),
+			logger.WithShutdownReraise(),
+		)
+		log.Info("before signal")
+		syscall.Kill(os.Getpid(), syscall.SIGHUP)
+		time.Sleep(5 * time.Second)
+		return // Not reached when the signal terminates the process
+	}
+
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFlushOnShutdownReraise$")
+	cmd.Env = append(os.Environ(), "LOGGERKIT_RERAISE_LOG="+logPath)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Expected the process to be terminated, got %v", err)
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); !ok || !status.Signaled() || status.Signal() != syscall.SIGHUP {
+		t.Errorf("Expected termination by SIGHUP, got %v", err)
+	}
+	if entries := testutil.ReadJSONLines(t, logPath); len(entries) != 1 || entries[0]["msg"] != "before signal" {
+		t.Errorf("Expected the entry flushed before the process terminated, got %v", entries)
+	}
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrSinksNotSupported is returned by FlushSink for a logger that can't
+	// list its sinks
+	ErrSinksNotSupported = errors.New("sink listing not supported")
+	// ErrUnknownSink is returned by FlushSink for a name the logger has no sink for
+	ErrUnknownSink = errors.New("unknown sink")
+)
+
+// SinkInfo describes a sink of a running logger
+type SinkInfo struct {
+	Name    string        // Factory name, as in BuildReport.Sinks and accepted by FlushSink
+	Type    string        // Go type of the factory that built the sink
+	Healthy bool          // False while the sink fails to deliver; sinks that can't tell report true
+	Level   Level         // Lowest level the sink writes
+	Indexer *IndexerStats // Bulk indexer counters so far; nil for sinks without one
+}
+
+// SinkController is implemented by loggers that can list and flush their sinks
+type SinkController interface {
+	Sinks() []SinkInfo
+	FlushSink(ctx context.Context, name string) error
+}
+
+// Sinks lists the sinks of log in build order, or nil when log can't list them
+func Sinks(log Logger) []SinkInfo {
+	c, ok := log.(SinkController)
+	if !ok {
+		return nil
+	}
+	return c.Sinks()
+}
+
+// FlushSink writes out what the sink named name still buffers, e.g. the
+// documents queued for the next Elasticsearch bulk request
+func FlushSink(ctx context.Context, log Logger, name string) error {
+	c, ok := log.(SinkController)
+	if !ok {
+		return ErrSinksNotSupported
+	}
+	return c.FlushSink(ctx, name)
+}
@@ -0,0 +1,157 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/elasticx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+func TestSinksListing(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithLevel(logger.WarnLevel),
+		logger.WithFile(logger.FileSink{Path: filepath.Join(t.TempDir(), "app.log")}),
+		logger.WithElastic(logger.ElasticSink{Addresses: []string{mockES.URL}}),
+		logger.WithRing(logger.RingSink{Capacity: 10, Level: logger.DebugLevel}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer closeLogger(t, log)
+
+	sinks := logger.Sinks(log)
+	byName := make(map[string]logger.SinkInfo, len(sinks))
+	names := make([]string, 0, len(sinks))
+	for _, s := range sinks {
+		byName[s.Name] = s
+		names = append(names, s.Name)
+	}
+	for _, name := range []string{"console", "file", "elasticsearch", "ring"} {
+		s, ok := byName[name]
+		if !ok {
+			t.Errorf("Expected a %s sink, got %v", name, names)
+			continue
+		}
+		if s.Type == "" || !s.Healthy {
+			t.Errorf("Expected a healthy %s sink with its factory type, got %+v", name, s)
+		}
+	}
+	if got := byName["file"].Level; got != logger.WarnLevel {
+		t.Errorf("Expected the file sink to follow the logger level, got %q", got)
+	}
+	if got := byName["ring"].Level; got != logger.DebugLevel {
+		t.Errorf("Expected the ring sink to keep its own level, got %q", got)
+	}
+	if report := logger.Report(log).Sinks; len(report) != len(names) {
+		t.Errorf("Expected the sinks of the build report %v, got %v", report, names)
+	}
+
+	if err := logger.SetLevel(log, logger.ErrorLevel); err != nil {
+		t.Fatalf("SetLevel failed: %v", err)
+	}
+	for _, s := range logger.Sinks(log) {
+		if s.Name == "console" && s.Level != logger.ErrorLevel {
+			t.Errorf("Expected the console level to follow SetLevel, got %q", s.Level)
+		}
+	}
+}
+
+func TestFlushSinkElasticsearch(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			FlushInterval: time.Hour, // Only FlushSink sends
+		}),
+		logger.WithConsoleDisabled(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer closeLogger(t, log)
+
+	log.Info("Buffered one")
+	log.Info("Buffered two")
+	if mockES.WaitForDocs(1, 100*time.Millisecond) {
+		t.Fatal("Expected the documents to wait in the bulk indexer")
+	}
+
+	if err := logger.FlushSink(context.Background(), log, "elasticsearch"); err != nil {
+		t.Fatalf("FlushSink failed: %v", err)
+	}
+	if !mockES.WaitForDocs(2, 2*time.Second) {
+		t.Fatalf("Expected FlushSink to push both documents, got %d", len(mockES.GetReceivedDocs()))
+	}
+
+	err = logger.FlushSink(context.Background(), log, "kafka")
+	if !errors.Is(err, logger.ErrUnknownSink) {
+		t.Errorf("Expected ErrUnknownSink for a sink that isn't configured, got %v", err)
+	}
+}
+
+func TestSinksHealth(t *testing.T) {
+	mockES := testutil.NewElasticsearchMock()
+	defer mockES.Close()
+
+	log, err := logger.NewProduction(
+		logger.WithElastic(logger.ElasticSink{
+			Addresses:     []string{mockES.URL},
+			FlushInterval: time.Hour,
+		}),
+		logger.WithConsoleDisabled(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer closeLogger(t, log)
+
+	healthy := func() bool {
+		for _, s := range logger.Sinks(log) {
+			if s.Name == "elasticsearch" {
+				return s.Healthy
+			}
+		}
+		t.Fatal("Expected an elasticsearch sink")
+		return false
+	}
+
+	mockES.CloseConnections()
+	log.Info("Lost to a reset")
+	_ = logger.FlushSink(context.Background(), log, "elasticsearch")
+	if healthy() {
+		t.Error("Expected the sink to be unhealthy after a failed bulk request")
+	}
+
+	mockES.RestoreConnections()
+	log.Info("Delivered")
+	if err := logger.FlushSink(context.Background(), log, "elasticsearch"); err != nil {
+		t.Fatalf("FlushSink failed: %v", err)
+	}
+	if !healthy() {
+		t.Error("Expected the sink to be healthy again once a bulk request went through")
+	}
+}
+
+func TestSinksNotSupported(t *testing.T) {
+	log, err := logger.NewProduction(logger.WithProvider("nop"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	if sinks := logger.Sinks(log); sinks != nil {
+		t.Errorf("Expected no sinks for a nop logger, got %v", sinks)
+	}
+	if err := logger.FlushSink(context.Background(), log, "console"); !errors.Is(err, logger.ErrSinksNotSupported) {
+		t.Errorf("Expected ErrSinksNotSupported, got %v", err)
+	}
+}
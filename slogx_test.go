@@ -0,0 +1,105 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/slogx"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/elasticx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The slogx provider, checked against zapx
+
+// logThroughProvider writes the same entries through provider and returns them
+func logThroughProvider(t *testing.T, provider string) []map[string]any {
+	t.Helper()
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.NewProduction(
+		logger.WithProvider(provider),
+		logger.WithConsoleDisabled(),
+		logger.WithFile(logger.FileSink{Path: logPath}),
+		logger.WithTimeFormat(time.RFC3339),
+		logger.WithContext(logger.ContextKeys{RequestIDKey: "request_id"}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create %s logger: %v", provider, err)
+	}
+
+	ctx := context.WithValue(context.Background(), "request_id", "req-1")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01},
+		SpanID:     trace.SpanID{0x02},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	child := log.Named("api").Named("users").With(logger.F.String("component", "handler")).WithContext(ctx)
+	child.Debug("filtered out")
+	child.Info("served",
+		logger.F.Int("status", 200),
+		logger.F.Duration("elapsed", 1500*time.Millisecond),
+		logger.F.Time("at", time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)),
+		logger.F.Err(errors.New("partial content")),
+	)
+	child.Warn("slow")
+
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := log.Close(context.Background()); err != nil {
+		t.Errorf("Expected a second Close to return nil, got %v", err)
+	}
+	log.Error("after close")
+	return testutil.ReadJSONLines(t, logPath)
+}
+
+func TestSlogxMatchesZapx(t *testing.T) {
+	want := logThroughProvider(t, logger.DefaultProvider)
+	got := logThroughProvider(t, slogx.ProviderName)
+	if len(got) != 2 || len(want) != 2 {
+		t.Fatalf("Expected 2 entries from each provider, got zapx %d, slogx %d", len(want), len(got))
+	}
+
+	for i := range want {
+		if wk, gk := sortedKeys(want[i]), sortedKeys(got[i]); !reflect.DeepEqual(wk, gk) {
+			t.Errorf("Entry %d: zapx keys %v, slogx keys %v", i, wk, gk)
+		}
+		for key, w := range want[i] {
+			if key == "ts" {
+				continue
+			}
+			if !reflect.DeepEqual(got[i][key], w) {
+				t.Errorf("Entry %d: %s = %v from zapx, %v from slogx", i, key, w, got[i][key])
+			}
+		}
+	}
+	if _, err := time.Parse(time.RFC3339, got[0]["ts"].(string)); err != nil {
+		t.Errorf("Expected an RFC3339 ts from slogx, got %v", got[0]["ts"])
+	}
+}
+
+func TestSlogxUnsupportedSink(t *testing.T) {
+	_, err := logger.NewProduction(
+		logger.WithProvider(slogx.ProviderName),
+		logger.WithElastic(logger.ElasticSink{Addresses: []string{"http://localhost:9200"}}),
+	)
+	if err == nil {
+		t.Fatal("Expected slogx to reject the Elasticsearch sink")
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
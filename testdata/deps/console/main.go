@@ -0,0 +1,19 @@
+// Command console logs to the console only. TestSinkDependencies asserts its
+// dependency graph has no go-elasticsearch.
+package main
+
+import (
+	"context"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+)
+
+func main() {
+	log, err := logger.NewProduction()
+	if err != nil {
+		panic(err)
+	}
+	defer log.Close(context.Background())
+	log.Info("hello")
+}
@@ -0,0 +1,7 @@
+module example.com/elastic
+
+go 1.23.0
+
+require github.com/HoangAnhNguyen269/loggerkit v0.0.0
+
+replace github.com/HoangAnhNguyen269/loggerkit => ../../..
@@ -0,0 +1,20 @@
+// Command elastic also logs to Elasticsearch, so its dependency graph has
+// go-elasticsearch
+package main
+
+import (
+	"context"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx/elasticx"
+)
+
+func main() {
+	log, err := logger.NewProduction(logger.WithElastic(logger.ElasticSink{Addresses: []string{"http://localhost:9200"}}))
+	if err != nil {
+		panic(err)
+	}
+	defer log.Close(context.Background())
+	log.Info("hello")
+}
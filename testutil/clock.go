@@ -0,0 +1,91 @@
+package testutil
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a logger.Clock that only moves when Advance is called. Tickers
+// and After channels fire from Advance, so tests don't need to sleep.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// fakeWaiter is a pending After (period 0) or a ticker
+type fakeWaiter struct {
+	at     time.Time
+	period time.Duration
+	ch     chan time.Time
+}
+
+// NewFakeClock returns a clock stopped at now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker returns a ticker driven by Advance. Like a real ticker it drops
+// ticks a slow reader misses. Stop is a no-op.
+func (c *FakeClock) NewTicker(d time.Duration) *time.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+	ch := make(chan time.Time, 1)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waiters = append(c.waiters, &fakeWaiter{at: c.now.Add(d), period: d, ch: ch})
+	return &time.Ticker{C: ch}
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, &fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward by d and fires the timers and tickers due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		select {
+		case w.ch <- c.now:
+		default:
+		}
+		if w.period > 0 {
+			for !w.at.After(c.now) {
+				w.at = w.at.Add(w.period)
+			}
+			pending = append(pending, w)
+		}
+	}
+	c.waiters = pending
+}
+
+// Waiters returns the number of pending After timers and tickers, so a test can
+// wait for a goroutine to start waiting before it advances the clock
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
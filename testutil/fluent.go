@@ -0,0 +1,343 @@
+package testutil
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/HoangAnhNguyen269/loggerkit/internal/msgpack"
+)
+
+// FluentEvent is one event received by FluentForwardServer
+type FluentEvent struct {
+	Tag    string
+	Time   time.Time
+	Record map[string]interface{}
+}
+
+// FluentForwardOptions configures FluentForwardServer
+type FluentForwardOptions struct {
+	SharedKey string // Require the HELO/PING/PONG handshake with this key
+	NoAck     bool   // Never acknowledge chunks, to exercise client timeouts
+}
+
+// FluentForwardServer is an in-process Fluentd forward input. It accepts Message,
+// Forward and PackedForward modes, answers ack requests and can be stopped and
+// restarted on the same address to simulate an outage.
+type FluentForwardServer struct {
+	opts FluentForwardOptions
+	addr string
+
+	mu          sync.Mutex
+	listener    net.Listener
+	conns       map[net.Conn]bool
+	events      []FluentEvent
+	messages    int
+	acks        int
+	handshakes  int
+	rejected    int
+	connections int
+	wg          sync.WaitGroup
+}
+
+const fluentServerHostname = "fluent-mock"
+
+// NewFluentForwardServer starts a forward server on a random local port
+func NewFluentForwardServer(opts FluentForwardOptions) (*FluentForwardServer, error) {
+	s := &FluentForwardServer{opts: opts, conns: make(map[net.Conn]bool)}
+	if err := s.listen("127.0.0.1:0"); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Addr returns the host:port the server listens on
+func (s *FluentForwardServer) Addr() string {
+	return s.addr
+}
+
+func (s *FluentForwardServer) listen(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	s.mu.Lock()
+	s.listener = l
+	s.addr = l.Addr().String()
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns[conn] = true
+			s.connections++
+			s.mu.Unlock()
+
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serve(conn)
+			}()
+		}
+	}()
+	return nil
+}
+
+// Stop closes the listener and every open connection, like a crashed server
+func (s *FluentForwardServer) Stop() {
+	s.mu.Lock()
+	if s.listener != nil {
+		s.listener.Close()
+		s.listener = nil
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// Restart listens again on the address used before Stop
+func (s *FluentForwardServer) Restart() error {
+	return s.listen(s.addr)
+}
+
+// Close stops the server
+func (s *FluentForwardServer) Close() {
+	s.Stop()
+}
+
+func (s *FluentForwardServer) serve(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+	rd := bufio.NewReader(conn)
+
+	if s.opts.SharedKey != "" && !s.handshake(conn, rd) {
+		return
+	}
+
+	for {
+		v, err := msgpack.Decode(rd)
+		if err != nil {
+			return
+		}
+		msg, ok := v.([]interface{})
+		if !ok || len(msg) < 2 {
+			return
+		}
+		chunk := s.record(msg)
+		if chunk == "" || s.opts.NoAck {
+			continue
+		}
+		if _, err := conn.Write(appendStringMap(nil, map[string]string{"ack": chunk})); err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.acks++
+		s.mu.Unlock()
+	}
+}
+
+// record stores the events of one message and returns its chunk id, if any
+func (s *FluentForwardServer) record(msg []interface{}) string {
+	tag, _ := msg[0].(string)
+	var events []FluentEvent
+	var option interface{}
+
+	switch entries := msg[1].(type) {
+	case []interface{}: // Forward mode
+		for _, e := range entries {
+			if pair, ok := e.([]interface{}); ok && len(pair) == 2 {
+				events = append(events, newFluentEvent(tag, pair[0], pair[1]))
+			}
+		}
+		if len(msg) > 2 {
+			option = msg[2]
+		}
+	case string, []byte: // PackedForward mode
+		var packed []byte
+		if str, ok := entries.(string); ok {
+			packed = []byte(str)
+		} else {
+			packed = entries.([]byte)
+		}
+		rd := bufio.NewReader(bytes.NewReader(packed))
+		for {
+			v, err := msgpack.Decode(rd)
+			if err != nil {
+				break
+			}
+			if pair, ok := v.([]interface{}); ok && len(pair) == 2 {
+				events = append(events, newFluentEvent(tag, pair[0], pair[1]))
+			}
+		}
+		if len(msg) > 2 {
+			option = msg[2]
+		}
+	default: // Message mode: [tag, time, record, option]
+		if len(msg) >= 3 {
+			events = append(events, newFluentEvent(tag, msg[1], msg[2]))
+		}
+		if len(msg) > 3 {
+			option = msg[3]
+		}
+	}
+
+	s.mu.Lock()
+	s.events = append(s.events, events...)
+	s.messages++
+	s.mu.Unlock()
+
+	if opt, ok := option.(map[string]interface{}); ok {
+		chunk, _ := opt["chunk"].(string)
+		return chunk
+	}
+	return ""
+}
+
+func newFluentEvent(tag string, t, record interface{}) FluentEvent {
+	ev := FluentEvent{Tag: tag}
+	switch t := t.(type) {
+	case msgpack.Ext:
+		ev.Time, _ = msgpack.ParseEventTime(t)
+	case int64:
+		ev.Time = time.Unix(t, 0)
+	case uint64:
+		ev.Time = time.Unix(int64(t), 0)
+	}
+	ev.Record, _ = record.(map[string]interface{})
+	return ev
+}
+
+// handshake runs the server side of the shared key authentication
+func (s *FluentForwardServer) handshake(conn net.Conn, rd *bufio.Reader) bool {
+	nonce := "mock-nonce"
+	helo := msgpack.AppendArrayHeader(nil, 2)
+	helo = msgpack.AppendString(helo, "HELO")
+	helo = msgpack.AppendMapHeader(helo, 3)
+	helo = msgpack.AppendString(helo, "nonce")
+	helo = msgpack.AppendBytes(helo, []byte(nonce))
+	helo = msgpack.AppendString(helo, "auth")
+	helo = msgpack.AppendBytes(helo, nil)
+	helo = msgpack.AppendString(helo, "keepalive")
+	helo = msgpack.AppendBool(helo, true)
+	if _, err := conn.Write(helo); err != nil {
+		return false
+	}
+
+	v, err := msgpack.Decode(rd)
+	if err != nil {
+		return false
+	}
+	ping, ok := v.([]interface{})
+	if !ok || len(ping) < 4 || ping[0] != "PING" {
+		return false
+	}
+	clientHost, _ := ping[1].(string)
+	salt, _ := ping[2].(string)
+	digest, _ := ping[3].(string)
+
+	valid := digest == fluentDigest(salt, clientHost, nonce, s.opts.SharedKey)
+	reason := ""
+	if !valid {
+		reason = "shared_key mismatch"
+	}
+
+	pong := msgpack.AppendArrayHeader(nil, 5)
+	pong = msgpack.AppendString(pong, "PONG")
+	pong = msgpack.AppendBool(pong, valid)
+	pong = msgpack.AppendString(pong, reason)
+	pong = msgpack.AppendString(pong, fluentServerHostname)
+	pong = msgpack.AppendString(pong, fluentDigest(salt, fluentServerHostname, nonce, s.opts.SharedKey))
+	if _, err := conn.Write(pong); err != nil {
+		return false
+	}
+
+	s.mu.Lock()
+	if valid {
+		s.handshakes++
+	} else {
+		s.rejected++
+	}
+	s.mu.Unlock()
+	return valid
+}
+
+func fluentDigest(salt, hostname, nonce, key string) string {
+	sum := sha512.Sum512([]byte(salt + hostname + nonce + key))
+	return hex.EncodeToString(sum[:])
+}
+
+func appendStringMap(b []byte, m map[string]string) []byte {
+	b = msgpack.AppendMapHeader(b, len(m))
+	for k, v := range m {
+		b = msgpack.AppendString(b, k)
+		b = msgpack.AppendString(b, v)
+	}
+	return b
+}
+
+// GetEvents returns a copy of the received events
+func (s *FluentForwardServer) GetEvents() []FluentEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]FluentEvent(nil), s.events...)
+}
+
+// GetMessageCount returns the number of forward messages received
+func (s *FluentForwardServer) GetMessageCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.messages
+}
+
+// GetAckCount returns the number of chunks acknowledged
+func (s *FluentForwardServer) GetAckCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.acks
+}
+
+// GetHandshakeCount returns the number of successful and rejected handshakes
+func (s *FluentForwardServer) GetHandshakeCount() (ok, rejected int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.handshakes, s.rejected
+}
+
+// GetConnectionCount returns the number of accepted connections
+func (s *FluentForwardServer) GetConnectionCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// WaitForEvents waits until at least count events were received
+func (s *FluentForwardServer) WaitForEvents(count int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		n := len(s.events)
+		s.mu.Unlock()
+		if n >= count {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
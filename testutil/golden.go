@@ -0,0 +1,165 @@
+package testutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+)
+
+// UpdateGoldenEnv rewrites testdata/*.golden from the current output when set
+// to a non-empty value. An environment variable rather than a flag, so packages
+// importing testutil can still define their own -update flag.
+const UpdateGoldenEnv = "LOGGERKIT_UPDATE_GOLDEN"
+
+// NormalizeRule rewrites the value of Key in every entry that has it, so
+// volatile values don't break golden comparisons
+type NormalizeRule struct {
+	Key     string
+	Replace func(v any) any
+}
+
+// Placeholder replaces the value of key with a fixed string
+func Placeholder(key, placeholder string) NormalizeRule {
+	return NormalizeRule{Key: key, Replace: func(any) any { return placeholder }}
+}
+
+var callerLine = regexp.MustCompile(`:\d+$`)
+
+// DefaultNormalizeRules replace timestamps, trace and span IDs and stack traces
+// with placeholders and drop the line number from callers
+func DefaultNormalizeRules() []NormalizeRule {
+	return []NormalizeRule{
+		Placeholder("ts", "<ts>"),
+		Placeholder("trace_id", "<trace_id>"),
+		Placeholder("span_id", "<span_id>"),
+		Placeholder("stacktrace", "<stacktrace>"),
+		{Key: "caller", Replace: func(v any) any {
+			s, ok := v.(string)
+			if !ok {
+				return v
+			}
+			return callerLine.ReplaceAllString(s, ":<line>")
+		}},
+	}
+}
+
+// NormalizeLines applies rules to JSON lines and re-encodes each entry with
+// sorted keys
+func NormalizeLines(data []byte, rules []NormalizeRule) ([]byte, error) {
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false) // Keep placeholders readable
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse log line %q: %w", line, err)
+		}
+		for _, r := range rules {
+			if v, ok := entry[r.Key]; ok {
+				entry[r.Key] = r.Replace(v)
+			}
+		}
+		if err := enc.Encode(entry); err != nil {
+			return nil, fmt.Errorf("failed to encode log line: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read log lines: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// GoldenLog is a Logger whose output can be compared against a golden file
+type GoldenLog struct {
+	logger.Logger
+	// Rules normalize the output before comparison (default DefaultNormalizeRules)
+	Rules []NormalizeRule
+	// Update rewrites the golden file instead of comparing, e.g. from the
+	// caller's own -update flag; UpdateGoldenEnv does the same
+	Update bool
+	path   string
+}
+
+// GoldenLogger returns a production logger without sampling that writes JSON
+// lines to a temporary file only; opts apply on top. A provider must be
+// registered, e.g. by importing provider/zapx. The logger is closed when the
+// test ends.
+func GoldenLogger(t testing.TB, opts ...logger.Option) *GoldenLog {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "golden.log")
+	base := []logger.Option{
+		func(o *logger.Options) { o.Sampling = nil },
+		logger.WithFile(logger.FileSink{Path: path}),
+	}
+	log := NewLogger(t, append(base, opts...)...)
+	return &GoldenLog{Logger: log, Rules: DefaultNormalizeRules(), path: path}
+}
+
+// Output returns the entries written so far, normalized
+func (g *GoldenLog) Output(t testing.TB) []byte {
+	t.Helper()
+	data, err := os.ReadFile(g.path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatalf("Failed to read golden log output: %v", err)
+	}
+	out, err := NormalizeLines(data, g.Rules)
+	if err != nil {
+		t.Fatalf("Failed to normalize golden log output: %v", err)
+	}
+	return out
+}
+
+// AssertGolden compares the normalized output with testdata/<name>.golden, or
+// rewrites that file when Update or UpdateGoldenEnv is set
+func (g *GoldenLog) AssertGolden(t testing.TB, name string) {
+	t.Helper()
+	got := g.Output(t)
+	path := filepath.Join("testdata", name+".golden")
+
+	if g.Update || os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatalf("Failed to create testdata: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("Failed to update %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s (set %s=1 to create it): %v", path, UpdateGoldenEnv, err)
+	}
+	if bytes.Equal(got, want) {
+		return
+	}
+	gotLines := strings.Split(strings.TrimSuffix(string(got), "\n"), "\n")
+	wantLines := strings.Split(strings.TrimSuffix(string(want), "\n"), "\n")
+	for i := 0; i < max(len(gotLines), len(wantLines)); i++ {
+		var g, w string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if g != w {
+			t.Errorf("Output differs from %s at entry %d (set %s=1 to accept it)\n got: %s\nwant: %s", path, i+1, UpdateGoldenEnv, g, w)
+			return
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package testutil_test
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Golden log output
+
+func TestNormalizeLines(t *testing.T) {
+	in := []byte(`{"ts":"2026-10-16T09:00:00Z","msg":"served","caller":"api/handler.go:42","trace_id":"0a0b","user":"u-1"}` + "\n\n" +
+		`{"msg":"no volatile fields","level":"info"}` + "\n")
+
+	got, err := testutil.NormalizeLines(in, testutil.DefaultNormalizeRules())
+	if err != nil {
+		t.Fatalf("NormalizeLines failed: %v", err)
+	}
+	want := `{"caller":"api/handler.go:<line>","msg":"served","trace_id":"<trace_id>","ts":"<ts>","user":"u-1"}` + "\n" +
+		`{"level":"info","msg":"no volatile fields"}` + "\n"
+	if string(got) != want {
+		t.Errorf("Unexpected normalization\n got: %s\nwant: %s", got, want)
+	}
+
+	// Rules are configurable
+	rules := append(testutil.DefaultNormalizeRules(), testutil.Placeholder("user", "<user>"))
+	got, err = testutil.NormalizeLines([]byte(`{"ts":"2026-10-16T09:00:00Z","user":"u-1"}`), rules)
+	if err != nil {
+		t.Fatalf("NormalizeLines failed: %v", err)
+	}
+	if want := `{"ts":"<ts>","user":"<user>"}` + "\n"; string(got) != want {
+		t.Errorf("Expected the custom rule to apply\n got: %s\nwant: %s", got, want)
+	}
+
+	if _, err := testutil.NormalizeLines([]byte("not json\n"), nil); err == nil {
+		t.Error("Expected an error for a line that isn't JSON")
+	}
+}
+
+func TestGoldenLogger(t *testing.T) {
+	log := testutil.GoldenLogger(t, logger.WithService("checkout"), logger.WithCaller(true))
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x0a},
+		SpanID:     trace.SpanID{0x0b},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	api := log.Named("api").With(logger.F.String("route", "/orders"))
+	api.WithContext(ctx).Info("Order placed", logger.F.String("order_id", "o-1"), logger.F.Int("items", 3))
+	api.Warn("Slow checkout", logger.F.Bool("retried", true))
+
+	log.AssertGolden(t, "request")
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	// testutil must not claim flag names test binaries commonly define
+	if flag.Lookup("update") != nil {
+		t.Fatal("testutil registered an -update flag")
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	log := testutil.GoldenLogger(t)
+	log.Info("first run")
+	log.Update = true
+	log.AssertGolden(t, "update")
+	log.Update = false
+	log.AssertGolden(t, "update") // Now matches the file just written
+
+	if data, err := os.ReadFile(filepath.Join("testdata", "update.golden")); err != nil || !strings.Contains(string(data), "first run") {
+		t.Errorf("Expected the golden file to be written, got %q, %v", data, err)
+	}
+}
@@ -2,9 +2,12 @@ package testutil
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -41,6 +44,26 @@ func CaptureStdout(fn func()) (string, error) {
 	return buf.String(), nil
 }
 
+// SafeBuffer is a bytes.Buffer safe for concurrent writers, handy as a log or
+// diagnostics destination in tests
+type SafeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *SafeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// String returns the buffered contents
+func (b *SafeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
 // TempFile creates a temporary file for testing
 func TempFile(t testing.TB, prefix, suffix string) (string, func()) {
 	t.Helper()
@@ -80,8 +103,27 @@ type ElasticsearchMockServer struct {
 	mu            sync.RWMutex
 	responses     []MockResponse
 	receivedDocs  []map[string]interface{}
+	receivedMeta  []map[string]interface{} // Bulk action metadata, parallel to receivedDocs
+	receivedRaw   []string                 // Document lines as sent, parallel to receivedDocs
+	docIndex      map[string]int           // "_index/_id" -> position, so replays overwrite
 	requestCount  int
 	bulkResponses []MockBulkResponse
+	bytesReceived int64 // Request body bytes as sent on the wire (compressed if gzip)
+	gzipRequests  int
+	requests      []MockRequest
+	failDocs      int           // Documents left to reject, see FailNextDocs
+	failStatus    int           // Item status for rejected documents
+	latency       time.Duration // Delay before every response
+	resetting     bool          // Drop connections instead of responding
+	closing       chan struct{} // Closed by Close to cut latency short
+	closeOnce     sync.Once
+}
+
+// MockRequest records a request received by the mock server
+type MockRequest struct {
+	Method string
+	Path   string
+	Query  string
 }
 
 type MockResponse struct {
@@ -100,8 +142,14 @@ type MockBulkItem struct {
 }
 
 type MockBulkItemResult struct {
-	Status int    `json:"status"`
-	Error  string `json:"error,omitempty"`
+	Status int                `json:"status"`
+	Error  *MockBulkItemError `json:"error,omitempty"`
+}
+
+// MockBulkItemError mirrors the error object of a failed bulk item
+type MockBulkItemError struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
 }
 
 // NewElasticsearchMock creates a new mock Elasticsearch server
@@ -109,14 +157,35 @@ func NewElasticsearchMock() *ElasticsearchMockServer {
 	mock := &ElasticsearchMockServer{
 		responses:     []MockResponse{},
 		receivedDocs:  []map[string]interface{}{},
+		docIndex:      map[string]int{},
 		bulkResponses: []MockBulkResponse{},
+		closing:       make(chan struct{}),
 	}
 
 	mock.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		mock.mu.Lock()
 		mock.requestCount++
+		mock.requests = append(mock.requests, MockRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery})
+		latency, resetting := mock.latency, mock.resetting
 		mock.mu.Unlock()
 
+		if resetting {
+			mock.resetConnection(w)
+			return
+		}
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			case <-mock.closing:
+				return
+			}
+		}
+
+		// go-elasticsearch v8 verifies the product header on responses
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+
 		switch r.URL.Path {
 		case "/_bulk":
 			mock.handleBulkRequest(w, r)
@@ -128,33 +197,79 @@ func NewElasticsearchMock() *ElasticsearchMockServer {
 	return mock
 }
 
+// readBody reads the request body, recording its wire size and transparently
+// decompressing gzip-encoded payloads
+func (m *ElasticsearchMockServer) readBody(r *http.Request) ([]byte, error) {
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	gzipped := r.Header.Get("Content-Encoding") == "gzip"
+
+	m.mu.Lock()
+	m.bytesReceived += int64(len(raw))
+	if gzipped {
+		m.gzipRequests++
+	}
+	m.mu.Unlock()
+
+	if !gzipped {
+		return raw, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
 func (m *ElasticsearchMockServer) handleBulkRequest(w http.ResponseWriter, r *http.Request) {
-	body, _ := io.ReadAll(r.Body)
+	body, err := m.readBody(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 	lines := bytes.Split(body, []byte("\n"))
 
-	// Parse bulk request
+	// Parse bulk request: an action line ({"index":{...}}) and a doc line per item
+	var items []MockBulkItem
 	for i := 0; i < len(lines)-1; i += 2 {
 		if len(lines[i]) == 0 {
 			continue
 		}
-		// Skip action line, parse doc line
+		var action map[string]map[string]interface{}
+		_ = json.Unmarshal(lines[i], &action)
+		var meta map[string]interface{}
+		for _, v := range action {
+			meta = v
+		}
+
+		if status := m.takeDocFailure(); status != 0 {
+			items = append(items, MockBulkItem{Index: MockBulkItemResult{
+				Status: status,
+				Error:  &MockBulkItemError{Type: "mock_rejection", Reason: "rejected by FailNextDocs"},
+			}})
+			continue
+		}
 		if i+1 < len(lines) && len(lines[i+1]) > 0 {
 			var doc map[string]interface{}
 			if err := json.Unmarshal(lines[i+1], &doc); err == nil {
-				m.mu.Lock()
-				m.receivedDocs = append(m.receivedDocs, doc)
-				m.mu.Unlock()
+				m.recordDoc(meta, doc, string(lines[i+1]))
 			}
 		}
+		items = append(items, MockBulkItem{Index: MockBulkItemResult{Status: http.StatusCreated}})
 	}
 
-	m.mu.RLock()
+	m.mu.Lock()
 	if len(m.bulkResponses) > 0 {
 		resp := m.bulkResponses[0]
 		if len(m.bulkResponses) > 1 {
 			m.bulkResponses = m.bulkResponses[1:]
 		}
-		m.mu.RUnlock()
+		m.mu.Unlock()
 
 		w.WriteHeader(resp.StatusCode)
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -162,25 +277,83 @@ func (m *ElasticsearchMockServer) handleBulkRequest(w http.ResponseWriter, r *ht
 		})
 		return
 	}
-	m.mu.RUnlock()
+	m.mu.Unlock()
 
-	// Default success response
+	// One result per document, as Elasticsearch sends
 	w.WriteHeader(200)
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"items": []MockBulkItem{
-			{Index: MockBulkItemResult{Status: 201}},
-		},
+		"errors": anyFailed(items),
+		"items":  items,
 	})
 }
 
+// anyFailed reports whether a bulk response has item errors
+func anyFailed(items []MockBulkItem) bool {
+	for _, item := range items {
+		if item.Index.Status > 299 {
+			return true
+		}
+	}
+	return false
+}
+
+// takeDocFailure returns the item status for the next document, 0 to accept it
+func (m *ElasticsearchMockServer) takeDocFailure() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failDocs == 0 {
+		return 0
+	}
+	m.failDocs--
+	return m.failStatus
+}
+
+// resetConnection drops the client connection without a response
+func (m *ElasticsearchMockServer) resetConnection(w http.ResponseWriter) {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	conn, _, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		_ = tcp.SetLinger(0) // RST rather than FIN
+	}
+	conn.Close()
+}
+
+// recordDoc stores a received document; a document whose _index/_id was already
+// seen replaces the earlier copy, as Elasticsearch would
+func (m *ElasticsearchMockServer) recordDoc(meta, doc map[string]interface{}, raw string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if id, ok := meta["_id"].(string); ok && id != "" {
+		key := fmt.Sprintf("%v/%s", meta["_index"], id)
+		if pos, seen := m.docIndex[key]; seen {
+			m.receivedDocs[pos] = doc
+			m.receivedMeta[pos] = meta
+			m.receivedRaw[pos] = raw
+			return
+		}
+		m.docIndex[key] = len(m.receivedDocs)
+	}
+	m.receivedDocs = append(m.receivedDocs, doc)
+	m.receivedMeta = append(m.receivedMeta, meta)
+	m.receivedRaw = append(m.receivedRaw, raw)
+}
+
 func (m *ElasticsearchMockServer) handleGenericRequest(w http.ResponseWriter, r *http.Request) {
-	m.mu.RLock()
+	m.mu.Lock()
 	if len(m.responses) > 0 {
 		resp := m.responses[0]
 		if len(m.responses) > 1 {
 			m.responses = m.responses[1:]
 		}
-		m.mu.RUnlock()
+		m.mu.Unlock()
 
 		for k, v := range resp.Headers {
 			w.Header().Set(k, v)
@@ -189,10 +362,15 @@ func (m *ElasticsearchMockServer) handleGenericRequest(w http.ResponseWriter, r
 		w.Write([]byte(resp.Body))
 		return
 	}
-	m.mu.RUnlock()
+	m.mu.Unlock()
 
 	// Default response
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(200)
+	if r.Method == http.MethodPut {
+		w.Write([]byte(`{"acknowledged": true}`))
+		return
+	}
 	w.Write([]byte(`{"version": {"number": "8.0.0"}}`))
 }
 
@@ -216,6 +394,47 @@ func (m *ElasticsearchMockServer) SetBulkResponse(statusCode int, items []MockBu
 	})
 }
 
+// FailNextDocs rejects the next n documents with the given item status (e.g.
+// 400 for a mapping conflict, 429 for backpressure), while the rest of their
+// bulk requests succeed. Rejected documents are not recorded.
+func (m *ElasticsearchMockServer) FailNextDocs(n int, status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failDocs = n
+	m.failStatus = status
+}
+
+// SetLatency delays every response by d; Close cuts pending delays short
+func (m *ElasticsearchMockServer) SetLatency(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latency = d
+}
+
+// CloseConnections drops the open client connections and resets every later
+// one without a response, until RestoreConnections
+func (m *ElasticsearchMockServer) CloseConnections() {
+	m.mu.Lock()
+	m.resetting = true
+	m.mu.Unlock()
+	m.Server.CloseClientConnections()
+}
+
+// RestoreConnections undoes CloseConnections
+func (m *ElasticsearchMockServer) RestoreConnections() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.resetting = false
+}
+
+// Close shuts the server down, releasing requests held by SetLatency
+func (m *ElasticsearchMockServer) Close() {
+	m.closeOnce.Do(func() {
+		close(m.closing)
+		m.Server.Close()
+	})
+}
+
 // GetReceivedDocs returns all documents received by the mock server
 func (m *ElasticsearchMockServer) GetReceivedDocs() []map[string]interface{} {
 	m.mu.RLock()
@@ -225,6 +444,35 @@ func (m *ElasticsearchMockServer) GetReceivedDocs() []map[string]interface{} {
 	return result
 }
 
+// GetReceivedMeta returns the bulk action metadata (_index, _id, routing) of each
+// received document, in the same order as GetReceivedDocs
+func (m *ElasticsearchMockServer) GetReceivedMeta() []map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]map[string]interface{}, len(m.receivedMeta))
+	copy(result, m.receivedMeta)
+	return result
+}
+
+// GetRawDocs returns the document lines exactly as sent, in the same order as
+// GetReceivedDocs; useful for asserting key order or duplicate keys
+func (m *ElasticsearchMockServer) GetRawDocs() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]string, len(m.receivedRaw))
+	copy(result, m.receivedRaw)
+	return result
+}
+
+// GetRequests returns every request received so far
+func (m *ElasticsearchMockServer) GetRequests() []MockRequest {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]MockRequest, len(m.requests))
+	copy(result, m.requests)
+	return result
+}
+
 // GetRequestCount returns the total number of requests received
 func (m *ElasticsearchMockServer) GetRequestCount() int {
 	m.mu.RLock()
@@ -232,8 +480,36 @@ func (m *ElasticsearchMockServer) GetRequestCount() int {
 	return m.requestCount
 }
 
-// WaitForDocs waits for a specific number of documents to be received
-func (m *ElasticsearchMockServer) WaitForDocs(count int, timeout time.Duration) bool {
+// CountRequests returns how many requests matched the method and path
+func (m *ElasticsearchMockServer) CountRequests(method, path string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	n := 0
+	for _, r := range m.requests {
+		if r.Method == method && r.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+// GetBytesReceived returns the total request body bytes received on the wire
+func (m *ElasticsearchMockServer) GetBytesReceived() int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.bytesReceived
+}
+
+// GetGzipRequestCount returns the number of requests sent with Content-Encoding: gzip
+func (m *ElasticsearchMockServer) GetGzipRequestCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.gzipRequests
+}
+
+// WaitForDocs waits until count documents were received, or count documents
+// matching every predicate when some are given
+func (m *ElasticsearchMockServer) WaitForDocs(count int, timeout time.Duration, match ...func(doc map[string]interface{}) bool) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
@@ -245,12 +521,72 @@ func (m *ElasticsearchMockServer) WaitForDocs(count int, timeout time.Duration)
 		case <-ctx.Done():
 			return false
 		case <-ticker.C:
-			m.mu.RLock()
-			docCount := len(m.receivedDocs)
-			m.mu.RUnlock()
-			if docCount >= count {
+			if m.countDocs(match) >= count {
 				return true
 			}
 		}
 	}
 }
+
+func (m *ElasticsearchMockServer) countDocs(match []func(doc map[string]interface{}) bool) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	n := 0
+docs:
+	for _, doc := range m.receivedDocs {
+		for _, fn := range match {
+			if !fn(doc) {
+				continue docs
+			}
+		}
+		n++
+	}
+	return n
+}
+
+// MessageIs matches documents with the given msg, for WaitForDocs
+func MessageIs(msg string) func(doc map[string]interface{}) bool {
+	return func(doc map[string]interface{}) bool { return doc["msg"] == msg }
+}
+
+// AssertReceivedMessage fails t unless a document with msg was received
+func (m *ElasticsearchMockServer) AssertReceivedMessage(t testing.TB, msg string) map[string]interface{} {
+	t.Helper()
+	for _, doc := range m.GetReceivedDocs() {
+		if doc["msg"] == msg {
+			return doc
+		}
+	}
+	t.Errorf("Expected a document with msg %q, got %d others", msg, len(m.GetReceivedDocs()))
+	return nil
+}
+
+// AssertNotReceivedMessage fails t if a document with msg was received
+func (m *ElasticsearchMockServer) AssertNotReceivedMessage(t testing.TB, msg string) {
+	t.Helper()
+	for _, doc := range m.GetReceivedDocs() {
+		if doc["msg"] == msg {
+			t.Errorf("Expected no document with msg %q, got %v", msg, doc)
+			return
+		}
+	}
+}
+
+// AssertReceivedMeta fails t unless the document with msg was sent with the
+// given bulk metadata (e.g. "_index", "_id", "routing")
+func (m *ElasticsearchMockServer) AssertReceivedMeta(t testing.TB, msg string, want map[string]interface{}) {
+	t.Helper()
+	docs, meta := m.GetReceivedDocs(), m.GetReceivedMeta()
+	for i, doc := range docs {
+		if doc["msg"] != msg {
+			continue
+		}
+		for k, v := range want {
+			if meta[i][k] != v {
+				t.Errorf("Document %q: expected %s %v, got %v", msg, k, v, meta[i][k])
+			}
+		}
+		return
+	}
+	t.Errorf("Expected a document with msg %q", msg)
+}
@@ -0,0 +1,63 @@
+package testutil
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+)
+
+// NewLogger returns a production logger with the console disabled; opts apply
+// on top. A provider must be registered, e.g. by importing provider/zapx. The
+// test fails if the logger can't be built, and the logger is closed when it ends.
+func NewLogger(t testing.TB, opts ...logger.Option) logger.Logger {
+	t.Helper()
+	log, err := logger.NewProduction(append([]logger.Option{logger.WithConsoleDisabled()}, opts...)...)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	t.Cleanup(func() { _ = log.Close(context.Background()) })
+	return log
+}
+
+// FileLogger is NewLogger writing JSON lines to a temporary file, whose path it
+// returns for ReadJSONLines
+func FileLogger(t testing.TB, opts ...logger.Option) (logger.Logger, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.log")
+	return NewLogger(t, append([]logger.Option{logger.WithFile(logger.FileSink{Path: path})}, opts...)...), path
+}
+
+// ReadJSONLines decodes each non-empty line of the file at path, such as a log
+// file or a DLQ, failing the test on a line that is not a JSON object
+func ReadJSONLines(t testing.TB, path string) []map[string]any {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var entries []map[string]any
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 100<<20) // DLQ lines may hold oversized documents
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatalf("Invalid JSON line %q in %s: %v", line, path, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return entries
+}
@@ -0,0 +1,180 @@
+package testutil
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LineServer is a local JSON-lines receiver (like a Logstash tcp/udp input) that
+// records every line it gets. Stream servers can be stopped and restarted on the
+// same address to simulate an outage.
+type LineServer struct {
+	network string
+	addr    string
+
+	mu          sync.Mutex
+	listener    net.Listener
+	packetConn  net.PacketConn
+	conns       map[net.Conn]bool
+	lines       []string
+	connections int
+	wg          sync.WaitGroup
+}
+
+// NewLineServer starts a receiver for network "tcp", "udp" or "unix". For unix
+// sockets addr is the socket path; otherwise a random local port is used.
+func NewLineServer(network, addr string) (*LineServer, error) {
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+	s := &LineServer{network: network, conns: make(map[net.Conn]bool)}
+	if err := s.listen(addr); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Addr returns the address the server listens on
+func (s *LineServer) Addr() string {
+	return s.addr
+}
+
+func (s *LineServer) listen(addr string) error {
+	if s.network == "udp" {
+		pc, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen: %w", err)
+		}
+		s.mu.Lock()
+		s.packetConn = pc
+		s.addr = pc.LocalAddr().String()
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			buf := make([]byte, 65536)
+			for {
+				n, _, err := pc.ReadFrom(buf)
+				if err != nil {
+					return
+				}
+				s.record(strings.TrimSuffix(string(buf[:n]), "\n"))
+			}
+		}()
+		return nil
+	}
+
+	l, err := net.Listen(s.network, addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	s.mu.Lock()
+	s.listener = l
+	s.addr = l.Addr().String()
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns[conn] = true
+			s.connections++
+			s.mu.Unlock()
+
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serve(conn)
+			}()
+		}
+	}()
+	return nil
+}
+
+func (s *LineServer) serve(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		s.record(scanner.Text())
+	}
+}
+
+func (s *LineServer) record(line string) {
+	s.mu.Lock()
+	s.lines = append(s.lines, line)
+	s.mu.Unlock()
+}
+
+// Stop closes the listener and every open connection, like a crashed receiver
+func (s *LineServer) Stop() {
+	s.mu.Lock()
+	if s.listener != nil {
+		s.listener.Close()
+		s.listener = nil
+	}
+	if s.packetConn != nil {
+		s.packetConn.Close()
+		s.packetConn = nil
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// Restart listens again on the address used before Stop
+func (s *LineServer) Restart() error {
+	return s.listen(s.addr)
+}
+
+// Close stops the server
+func (s *LineServer) Close() {
+	s.Stop()
+}
+
+// GetLines returns a copy of the received lines
+func (s *LineServer) GetLines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.lines...)
+}
+
+// GetConnectionCount returns the number of accepted stream connections
+func (s *LineServer) GetConnectionCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// WaitForLines waits until at least count lines were received
+func (s *LineServer) WaitForLines(count int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		n := len(s.lines)
+		s.mu.Unlock()
+		if n >= count {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// throttleKeys bounds the messages tracked by Once and EveryN; the least
+// recently seen message is forgotten first
+const throttleKeys = 1024
+
+// Once wraps log so each message is only logged the first time it is seen at a
+// given level. Loggers derived with With or WithContext share the record. A
+// message forgotten after 1024 more recent ones is logged again. Only Debug,
+// Info, Warn and Error are throttled; DPanic and Fatal always go through.
+func Once(log Logger) Logger {
+	return newThrottledLogger(log, newThrottleState(), 0)
+}
+
+// EveryN wraps log so each message is logged on its first occurrence at a given
+// level and then every n occurrences, with an "occurrences" field counting them
+// all. Loggers derived with With or WithContext share the counts. As with
+// Once, DPanic and Fatal are never throttled.
+func EveryN(log Logger, n int) Logger {
+	if n < 1 {
+		n = 1
+	}
+	return newThrottledLogger(log, newThrottleState(), uint64(n))
+}
+
+type throttleKey struct {
+	level Level
+	msg   string
+}
+
+type throttleCount struct {
+	key   throttleKey
+	count uint64
+}
+
+// throttleState counts occurrences per message in an LRU of throttleKeys entries
+type throttleState struct {
+	mu     sync.Mutex
+	counts map[throttleKey]*list.Element
+	lru    *list.List // Most recently seen first
+}
+
+func newThrottleState() *throttleState {
+	return &throttleState{counts: make(map[throttleKey]*list.Element), lru: list.New()}
+}
+
+// incr counts one occurrence of key and returns the total
+func (s *throttleState) incr(key throttleKey) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.counts[key]; ok {
+		s.lru.MoveToFront(e)
+		c := e.Value.(*throttleCount)
+		c.count++
+		return c.count
+	}
+	if s.lru.Len() >= throttleKeys {
+		oldest := s.lru.Back()
+		delete(s.counts, oldest.Value.(*throttleCount).key)
+		s.lru.Remove(oldest)
+	}
+	s.counts[key] = s.lru.PushFront(&throttleCount{key: key, count: 1})
+	return 1
+}
+
+// throttledLogger implements Once (every == 0) and EveryN
+type throttledLogger struct {
+	log     Logger
+	skipped Logger // log reporting the caller of the throttledLogger method
+	state   *throttleState
+	every   uint64
+}
+
+func newThrottledLogger(log Logger, state *throttleState, every uint64) *throttledLogger {
+	skipped := log
+	if cs, ok := log.(callerSkipper); ok {
+		skipped = cs.WithCallerSkip(1)
+	}
+	return &throttledLogger{log: log, skipped: skipped, state: state, every: every}
+}
+
+// admit counts one occurrence of msg at level and reports whether to log it,
+// with the fields to log
+func (l *throttledLogger) admit(level Level, msg string, fields []Field) ([]Field, bool) {
+	n := l.state.incr(throttleKey{level, msg})
+	if l.every == 0 {
+		return fields, n == 1
+	}
+	if (n-1)%l.every != 0 {
+		return nil, false
+	}
+	return append(fields[:len(fields):len(fields)], F.Any("occurrences", n)), true
+}
+
+func (l *throttledLogger) Debug(msg string, fields ...Field) {
+	if fields, ok := l.admit(DebugLevel, msg, fields); ok {
+		l.skipped.Debug(msg, fields...)
+	}
+}
+
+func (l *throttledLogger) Info(msg string, fields ...Field) {
+	if fields, ok := l.admit(InfoLevel, msg, fields); ok {
+		l.skipped.Info(msg, fields...)
+	}
+}
+
+func (l *throttledLogger) Warn(msg string, fields ...Field) {
+	if fields, ok := l.admit(WarnLevel, msg, fields); ok {
+		l.skipped.Warn(msg, fields...)
+	}
+}
+
+func (l *throttledLogger) Error(msg string, fields ...Field) {
+	if fields, ok := l.admit(ErrorLevel, msg, fields); ok {
+		l.skipped.Error(msg, fields...)
+	}
+}
+
+func (l *throttledLogger) DPanic(msg string, fields ...Field) { l.skipped.DPanic(msg, fields...) }
+func (l *throttledLogger) Fatal(msg string, fields ...Field)  { l.skipped.Fatal(msg, fields...) }
+
+func (l *throttledLogger) Log(level Level, msg string, fields ...Field) {
+	switch level {
+	case DebugLevel, InfoLevel, WarnLevel, ErrorLevel:
+		var ok bool
+		if fields, ok = l.admit(level, msg, fields); !ok {
+			return
+		}
+	}
+	l.skipped.Log(level, msg, fields...)
+}
+
+func (l *throttledLogger) With(fields ...Field) Logger {
+	return newThrottledLogger(l.log.With(fields...), l.state, l.every)
+}
+
+func (l *throttledLogger) WithContext(ctx context.Context) Logger {
+	return newThrottledLogger(l.log.WithContext(ctx), l.state, l.every)
+}
+
+func (l *throttledLogger) Named(name string) Logger {
+	return newThrottledLogger(l.log.Named(name), l.state, l.every)
+}
+
+func (l *throttledLogger) Close(ctx context.Context) error {
+	return l.log.Close(ctx)
+}
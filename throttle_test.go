@@ -0,0 +1,145 @@
+package logger_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	_ "github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+)
+
+// Log-once and log-every-N
+
+func hammer(goroutines, perGoroutine int, fn func()) {
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				fn()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestOnce(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+	once := logger.Once(log)
+	child := once.With(logger.F.String("component", "cache"))
+
+	hammer(10, 100, func() {
+		once.Warn("cache miss storm")
+		child.Warn("cache miss storm") // Shares the record with its parent
+		once.Error("cache miss storm") // Another level is another message
+	})
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 2 {
+		t.Fatalf("Expected one warn and one error entry, got %d: %v", len(entries), entries)
+	}
+}
+
+func TestEveryN(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+	every := logger.EveryN(log, 10)
+
+	hammer(10, 100, func() {
+		every.Info("retrying")
+	})
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 100 {
+		t.Fatalf("Expected 100 entries for 1000 occurrences, got %d", len(entries))
+	}
+	seen := map[float64]bool{}
+	for _, e := range entries {
+		n, _ := e["occurrences"].(float64)
+		if int(n)%10 != 1 || seen[n] {
+			t.Errorf("Unexpected occurrences %v", e["occurrences"])
+		}
+		seen[n] = true
+	}
+}
+
+func TestOnceForgetsLeastRecentMessages(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+	once := logger.Once(log)
+
+	once.Info("first")
+	for i := 0; i < 1024; i++ {
+		once.Info(fmt.Sprintf("dynamic %d", i))
+	}
+	once.Info("first") // Evicted by the dynamic messages, so logged again
+	once.Info("dynamic 1023")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if entries := testutil.ReadJSONLines(t, logPath); len(entries) != 1026 {
+		t.Fatalf("Expected 1026 entries, got %d", len(entries))
+	}
+}
+
+func TestThrottleCallerAndFields(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+	every := logger.EveryN(log, 1)
+
+	fields := make([]logger.Field, 1, 2)
+	fields[0] = logger.F.String("key", "a")
+	spare := fields[:2]
+	every.Info("tick", fields...)
+	every.Log(logger.WarnLevel, "tock", fields...)
+	logger.Once(log).With(logger.F.Int("n", 1)).Error("boom")
+	if spare[1].Key != "" {
+		t.Errorf("The caller's backing array was written: %v", spare[1])
+	}
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if caller, _ := e["caller"].(string); !strings.Contains(caller, "throttle_test.go") {
+			t.Errorf("Expected the call site as caller, got %q", caller)
+		}
+	}
+}
+
+func TestThrottleSkipsDPanicAndFatal(t *testing.T) {
+	log, logPath := testutil.FileLogger(t, logger.WithFatalBehavior(logger.FatalReturn))
+	for _, throttled := range []logger.Logger{logger.Once(log), logger.EveryN(log, 10)} {
+		for i := 0; i < 2; i++ {
+			throttled.DPanic("invariant broken")
+			throttled.Fatal("cannot continue")
+			throttled.Log(logger.FatalLevel, "cannot continue")
+		}
+	}
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 12 {
+		t.Fatalf("Expected every DPanic and Fatal entry, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if _, ok := e["occurrences"]; ok {
+			t.Errorf("Unexpected occurrences on %v", e)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// TransportOption configures NewLoggingTransport
+type TransportOption func(*transportConfig)
+
+type transportConfig struct {
+	redactParams []string
+	dropQuery    bool
+	bodyLimit    int
+}
+
+// WithRedactedQueryParams replaces the values of the named query parameters in
+// the logged URL, e.g. tokens or signatures
+func WithRedactedQueryParams(names ...string) TransportOption {
+	return func(c *transportConfig) {
+		c.redactParams = append(c.redactParams, names...)
+	}
+}
+
+// WithoutQuery logs URLs without their query string
+func WithoutQuery() TransportOption {
+	return func(c *transportConfig) {
+		c.dropQuery = true
+	}
+}
+
+// WithErrorBodyCapture logs up to maxBytes of the response body for non-2xx
+// responses. The body is still fully readable by the caller.
+func WithErrorBodyCapture(maxBytes int) TransportOption {
+	return func(c *transportConfig) {
+		c.bodyLimit = maxBytes
+	}
+}
+
+type retryAttemptKey struct{}
+
+// WithRetryAttempt marks requests made with ctx as retry number n, logged as
+// the retry field by NewLoggingTransport
+func WithRetryAttempt(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, retryAttemptKey{}, n)
+}
+
+// NewLoggingTransport wraps base (http.DefaultTransport when nil) so every
+// outbound request is logged with its method, URL, status and duration, plus the
+// context fields of the request. Transport errors and 5xx responses are logged at
+// error level, 4xx at warn and the rest at info. Passwords in URLs are masked.
+func NewLoggingTransport(base http.RoundTripper, log Logger, opts ...TransportOption) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &loggingTransport{base: base, log: log}
+	for _, opt := range opts {
+		opt(&t.config)
+	}
+	return t
+}
+
+type loggingTransport struct {
+	base   http.RoundTripper
+	log    Logger
+	config transportConfig
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	fields := []Field{
+		F.String("method", req.Method),
+		F.String("url", t.logURL(req)),
+		F.Duration("duration", time.Since(start)),
+	}
+	if n, ok := req.Context().Value(retryAttemptKey{}).(int); ok && n > 0 {
+		fields = append(fields, F.Int("retry", n))
+	}
+	log := t.log.WithContext(req.Context())
+
+	if err != nil {
+		log.Error("http client request failed", append(fields, F.Err(err))...)
+		return resp, err
+	}
+
+	fields = append(fields, F.Int("status", resp.StatusCode))
+	if t.config.bodyLimit > 0 && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+		fields = append(fields, F.String("response_body", captureBody(resp, t.config.bodyLimit)))
+	}
+	switch {
+	case resp.StatusCode >= 500:
+		log.Error("http client request", fields...)
+	case resp.StatusCode >= 400:
+		log.Warn("http client request", fields...)
+	default:
+		log.Info("http client request", fields...)
+	}
+	return resp, nil
+}
+
+func (t *loggingTransport) logURL(req *http.Request) string {
+	u := *req.URL
+	switch {
+	case t.config.dropQuery:
+		u.RawQuery = ""
+	case len(t.config.redactParams) > 0 && u.RawQuery != "":
+		q := u.Query()
+		for _, name := range t.config.redactParams {
+			if q.Has(name) {
+				q.Set(name, "REDACTED")
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.Redacted()
+}
+
+// captureBody reads up to limit bytes of the body and puts them back in front
+// of the unread rest
+func captureBody(resp *http.Response, limit int) string {
+	buf, _ := io.ReadAll(io.LimitReader(resp.Body, int64(limit)))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), resp.Body), resp.Body}
+	return string(buf)
+}
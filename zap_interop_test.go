@@ -0,0 +1,65 @@
+package logger_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	logger "github.com/HoangAnhNguyen269/loggerkit"
+	"github.com/HoangAnhNguyen269/loggerkit/provider/zapx"
+	"github.com/HoangAnhNguyen269/loggerkit/testutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// Interop with *zap.Logger
+
+func TestUnwrapZap(t *testing.T) {
+	log, logPath := testutil.FileLogger(t)
+
+	uw, ok := log.With(logger.F.String("component", "grpc")).(zapx.ZapUnwrapper)
+	if !ok {
+		t.Fatal("Expected the zapx logger to implement ZapUnwrapper")
+	}
+	uw.UnwrapZap().Info("from zap", zap.Int("attempt", 2))
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := testutil.ReadJSONLines(t, logPath)
+	if len(entries) != 1 {
+		t.Fatalf("Expected one entry, got %v", entries)
+	}
+	e := entries[0]
+	if e["msg"] != "from zap" || e["attempt"] != float64(2) || e["component"] != "grpc" {
+		t.Errorf("Expected the zap entry with the logger's fields: %v", e)
+	}
+	if caller, _ := e["caller"].(string); !strings.Contains(caller, "zap_interop_test.go:") {
+		t.Errorf("Expected the test as caller, got %q", caller)
+	}
+}
+
+func TestNewFromZap(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := zapx.NewFromZap(zap.New(core, zap.AddCaller()), logger.WithContext(logger.ContextKeys{RequestIDKey: "request_id"}))
+
+	ctx := context.WithValue(context.Background(), "request_id", "req-1")
+	log.WithContext(ctx).Warn("adapted", logger.F.String("k", "v"))
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected one entry, got %d", len(entries))
+	}
+	e := entries[0]
+	fields := e.ContextMap()
+	if e.Message != "adapted" || e.Level != zapcore.WarnLevel || fields["request_id"] != "req-1" || fields["k"] != "v" {
+		t.Errorf("Unexpected entry: %+v %v", e, fields)
+	}
+	if !strings.HasSuffix(e.Caller.File, "zap_interop_test.go") {
+		t.Errorf("Expected the test as caller, got %s", e.Caller.File)
+	}
+}